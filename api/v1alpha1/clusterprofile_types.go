@@ -31,6 +31,11 @@ const (
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:path=clusterprofiles,scope=Cluster
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Matching",type="integer",JSONPath=".status.deploymentSummary.matching",description="Number of matching clusters"
+//+kubebuilder:printcolumn:name="Provisioned",type="integer",JSONPath=".status.deploymentSummary.provisioned",description="Number of clusters where all features are provisioned"
+//+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.deploymentSummary.ready",description="All matching clusters are provisioned with current configuration"
+//+kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.deploymentSummary.progress",description="Percentage of matching clusters provisioned with current configuration"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterProfile is the Schema for the clusterprofiles API
 type ClusterProfile struct {
@@ -0,0 +1,145 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ClusterProfilePlanKind = "ClusterProfilePlan"
+)
+
+// +kubebuilder:validation:Enum:=Pending;Ready;Applied
+type PlanPhase string
+
+const (
+	// PlanPhasePending indicates the plan is not complete yet: ClusterProfile is missing,
+	// is not in DryRun mode or the dry run is still in progress in some clusters
+	PlanPhasePending = PlanPhase("Pending")
+
+	// PlanPhaseReady indicates the dry run completed in all matching clusters and the plan
+	// can be reviewed
+	PlanPhaseReady = PlanPhase("Ready")
+
+	// PlanPhaseApplied indicates the plan was approved and the ClusterProfile moved out of
+	// DryRun mode
+	PlanPhaseApplied = PlanPhase("Applied")
+)
+
+// ClusterProfilePlanSpec defines the desired state of ClusterProfilePlan
+type ClusterProfilePlanSpec struct {
+	// ClusterProfileName is the name of the ClusterProfile this plan is for.
+	// ClusterProfile must be in DryRun mode.
+	// +kubebuilder:validation:MinLength=1
+	ClusterProfileName string `json:"clusterProfileName"`
+
+	// Approved, once set, makes the ClusterProfile leave DryRun mode and switch to
+	// ApprovedSyncMode, so changes listed in the plan are applied to the matching clusters.
+	// Approval is effective only if ApprovedGeneration matches the reviewed ClusterProfile
+	// generation. Otherwise Approved is reset.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+
+	// ApprovedGeneration is the ClusterProfile generation being approved. It must be set
+	// to Status.ReviewedGeneration, so changes made to the ClusterProfile after the plan
+	// was reviewed are never applied without a new review.
+	// +optional
+	ApprovedGeneration int64 `json:"approvedGeneration,omitempty"`
+
+	// ApprovedSyncMode is the sync mode ClusterProfile is set to once plan is approved
+	// +kubebuilder:default:=Continuous
+	// +kubebuilder:validation:Enum:=OneTime;Continuous;ContinuousWithDriftDetection
+	// +optional
+	ApprovedSyncMode SyncMode `json:"approvedSyncMode,omitempty"`
+}
+
+// ClusterPlan lists what would be created, updated or deleted in a matching cluster
+type ClusterPlan struct {
+	// ClusterRef references the matching cluster
+	ClusterRef corev1.ObjectReference `json:"clusterRef"`
+
+	// Ready is true once dry run completed in this cluster
+	Ready bool `json:"ready"`
+
+	// ReleaseReports lists the helm releases which would be installed, upgraded or deleted
+	// +optional
+	ReleaseReports []ReleaseReport `json:"releaseReports,omitempty"`
+
+	// ResourceReports lists the resources, deployed because of PolicyRefs, which would be
+	// created, updated or deleted
+	// +optional
+	ResourceReports []ResourceReport `json:"resourceReports,omitempty"`
+
+	// KustomizeResourceReports lists the resources, deployed because of KustomizationRefs,
+	// which would be created, updated or deleted
+	// +optional
+	KustomizeResourceReports []ResourceReport `json:"kustomizeResourceReports,omitempty"`
+}
+
+// ClusterProfilePlanStatus defines the observed state of ClusterProfilePlan
+type ClusterProfilePlanStatus struct {
+	// Phase of the plan
+	// +optional
+	Phase PlanPhase `json:"phase,omitempty"`
+
+	// Message explains why plan is Pending, or why its approval was reset
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ReviewedGeneration is the ClusterProfile generation this plan was computed for
+	// +optional
+	ReviewedGeneration int64 `json:"reviewedGeneration,omitempty"`
+
+	// Clusters contains, for each cluster matching the ClusterProfile, the changes
+	// which would be applied
+	// +listType=atomic
+	// +optional
+	Clusters []ClusterPlan `json:"clusters,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=clusterprofileplans,scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ClusterProfile",type="string",JSONPath=".spec.clusterProfileName"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Approved",type="boolean",JSONPath=".spec.approved"
+//+kubebuilder:printcolumn:name="Reviewed Generation",type="integer",JSONPath=".status.reviewedGeneration"
+
+// ClusterProfilePlan materializes, across all clusters matching a ClusterProfile in DryRun mode,
+// what would be created, updated or deleted. Approving the plan applies those changes.
+type ClusterProfilePlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterProfilePlanSpec   `json:"spec,omitempty"`
+	Status ClusterProfilePlanStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterProfilePlanList contains a list of ClusterProfilePlan
+type ClusterProfilePlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterProfilePlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterProfilePlan{}, &ClusterProfilePlanList{})
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -36,9 +37,14 @@ const (
 	ClusterSummaryFinalizer = "clustersummaryfinalizer.projectsveltos.io"
 
 	ClusterSummaryKind = "ClusterSummary"
+
+	// ReconcileNowAnnotation, when set on a ClusterSummary, makes the controller deploy again all its
+	// features, even if their configuration did not change. The annotation is removed once the request
+	// is processed. It allows remediating a single cluster without reconciling all the others.
+	ReconcileNowAnnotation = "projectsveltos.io/reconcile-now"
 )
 
-// +kubebuilder:validation:Enum:=Resources;Helm;Kustomize
+// +kubebuilder:validation:Enum:=Resources;Helm;Kustomize;PodSecurity
 type FeatureID string
 
 const (
@@ -50,9 +56,67 @@ const (
 
 	// FeatureKustomize is the identifier for Kustomize feature
 	FeatureKustomize = FeatureID("Kustomize")
+
+	// FeaturePodSecurity is the identifier for PodSecurity feature
+	FeaturePodSecurity = FeatureID("PodSecurity")
+)
+
+const (
+	// ClusterSummaryStalledCondition is set on a ClusterSummary when deploying a feature
+	// failed too many consecutive times because the managed cluster API was unavailable.
+	// Deployment keeps being retried and the condition is removed once feature is provisioned.
+	ClusterSummaryStalledCondition = "Stalled"
+
+	// ClusterSummaryStalledReason is the reason of the Stalled condition
+	ClusterSummaryStalledReason = "ConsecutiveFailures"
+
+	// ClusterSummaryMaintenanceCondition is set on a ClusterSummary while one of the
+	// maintenance windows is active. Nothing is deployed until the window ends.
+	ClusterSummaryMaintenanceCondition = "InMaintenanceWindow"
+
+	// ClusterSummaryMaintenanceReason is the reason of the InMaintenanceWindow condition
+	// when workloads are not scaled down
+	ClusterSummaryMaintenanceReason = "DeploymentsPaused"
+
+	// ClusterSummaryScaledDownReason is the reason of the InMaintenanceWindow condition
+	// when workloads have been scaled down
+	ClusterSummaryScaledDownReason = "ScaledDown"
+
+	// ClusterSummaryCircuitOpenCondition is set on a ClusterSummary while deployments to its
+	// managed cluster are suspended because of repeated severe failures. After a cooldown
+	// a single deployment is attempted again; the condition is removed once it succeeds.
+	ClusterSummaryCircuitOpenCondition = "CircuitOpen"
+
+	// ClusterSummaryCircuitOpenReason is the reason of the CircuitOpen condition
+	ClusterSummaryCircuitOpenReason = "RepeatedSevereFailures"
+)
+
+// +kubebuilder:validation:Enum:=Reachable;Unreachable
+type ClusterConnectivity string
+
+const (
+	// ClusterReachable indicates the managed cluster API server answered the connectivity probe
+	ClusterReachable = ClusterConnectivity("Reachable")
+
+	// ClusterUnreachable indicates the managed cluster API server could not be reached, for
+	// instance because kubeconfig is missing or invalid, or the cluster is down
+	ClusterUnreachable = ClusterConnectivity("Unreachable")
 )
 
-// +kubebuilder:validation:Enum:=Provisioning;Provisioned;Failed;FailedNonRetriable;Removing;Removed
+// ConnectivityStatus reports the outcome of the last connectivity probe of the managed cluster
+type ConnectivityStatus struct {
+	// Status is the outcome of the last connectivity probe
+	Status ClusterConnectivity `json:"status"`
+
+	// LastProbeTime is the time the managed cluster was last probed
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+
+	// Message reports why the managed cluster is not reachable
+	// +optional
+	Message *string `json:"message,omitempty"`
+}
+
+// +kubebuilder:validation:Enum:=Provisioning;Provisioned;Failed;FailedNonRetriable;Removing;Removed;WaitingForCluster;Disabled;UnsupportedKubernetesVersion;PreconditionNotMet
 type FeatureStatus string
 
 const (
@@ -72,6 +136,23 @@ const (
 	// in the workload cluster failed with a non retriable error
 	FeatureStatusFailedNonRetriable = FeatureStatus("FailedNonRetriable")
 
+	// FeatureStatusWaitingForCluster indicates that the feature is not
+	// provisioned yet because the workload cluster is not ready
+	FeatureStatusWaitingForCluster = FeatureStatus("WaitingForCluster")
+
+	// FeatureStatusDisabled indicates that the feature is disabled and
+	// all its resources have been removed from the workload cluster
+	FeatureStatusDisabled = FeatureStatus("Disabled")
+
+	// FeatureStatusUnsupportedKubernetesVersion indicates that the feature is not
+	// provisioned because the workload cluster Kubernetes version is older than the
+	// minimum version the feature requires
+	FeatureStatusUnsupportedKubernetesVersion = FeatureStatus("UnsupportedKubernetesVersion")
+
+	// FeatureStatusPreconditionNotMet indicates that the feature is not
+	// provisioned because at least one of its preconditions is not met
+	FeatureStatusPreconditionNotMet = FeatureStatus("PreconditionNotMet")
+
 	// FeatureStatusRemoving indicates that feature is being
 	// removed
 	FeatureStatusRemoving = FeatureStatus("Removing")
@@ -91,6 +172,10 @@ type FeatureSummary struct {
 	// +optional
 	Hash []byte `json:"hash,omitempty"`
 
+	// HashAlgorithm is the algorithm Hash was computed with. Empty means sha256.
+	// +optional
+	HashAlgorithm string `json:"hashAlgorithm,omitempty"`
+
 	// Status represents the state of the feature in the workload cluster
 	// +optional
 	Status FeatureStatus `json:"status,omitempty"`
@@ -113,6 +198,66 @@ type FeatureSummary struct {
 	// LastAppliedTime is the time feature was last reconciled
 	// +optional
 	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// ConsecutiveFailures is the number of consecutive deployments which failed
+	// because the managed cluster API was temporarily unavailable.
+	// It is reset as soon as the feature is provisioned.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// ResolvedReferences lists the resources referenced by this feature which were found
+	// during the last reconciliation.
+	// +optional
+	ResolvedReferences []corev1.ObjectReference `json:"resolvedReferences,omitempty"`
+
+	// MissingReferences lists the resources referenced by this feature which did not
+	// exist during the last reconciliation.
+	// +optional
+	MissingReferences []corev1.ObjectReference `json:"missingReferences,omitempty"`
+
+	// DryRunDiff lists, when in DryRun mode, the resources which would be added, changed
+	// or removed in the managed cluster if the feature was deployed.
+	// Resources which would not change are not listed.
+	// +optional
+	DryRunDiff []DryRunDiffEntry `json:"dryRunDiff,omitempty"`
+}
+
+// DryRunChange is the type of change a resource would undergo.
+// +kubebuilder:validation:Enum:=Added;Changed;Removed
+type DryRunChange string
+
+const (
+	// DryRunChangeAdded indicates the resource would be created
+	DryRunChangeAdded = DryRunChange("Added")
+
+	// DryRunChangeChanged indicates the resource exists and would be updated
+	DryRunChangeChanged = DryRunChange("Changed")
+
+	// DryRunChangeRemoved indicates the resource would be deleted
+	DryRunChangeRemoved = DryRunChange("Removed")
+)
+
+// DryRunDiffEntry describes the change a resource would undergo
+type DryRunDiffEntry struct {
+	// Group of the resource
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the resource
+	Version string `json:"version"`
+
+	// Kind of the resource
+	Kind string `json:"kind"`
+
+	// Namespace of the resource. Empty for cluster wide resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource
+	Name string `json:"name"`
+
+	// Change is the type of change the resource would undergo
+	Change DryRunChange `json:"change"`
 }
 
 type FeatureDeploymentInfo struct {
@@ -205,11 +350,32 @@ type ClusterSummaryStatus struct {
 	// +listType=atomic
 	// +optional
 	HelmReleaseSummaries []HelmChartSummary `json:"helmReleaseSummaries,omitempty"`
+
+	// Connectivity reports whether the managed cluster API server is reachable using the
+	// cluster kubeconfig. It is independent from the status of each feature.
+	// +optional
+	Connectivity *ConnectivityStatus `json:"connectivity,omitempty"`
+
+	// Conditions reports the ClusterSummary conditions. The Stalled condition is set
+	// while deploying any feature keeps failing because the managed cluster API is
+	// unavailable. The InMaintenanceWindow condition is set while a maintenance window
+	// is active. The CircuitOpen condition is set while deployments to the managed cluster
+	// are suspended because of repeated severe failures.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:path=clustersummaries,scope=Namespaced
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName",description="Cluster features are deployed to"
+//+kubebuilder:printcolumn:name="Resources",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Resources\")].status",description="Resources feature status"
+//+kubebuilder:printcolumn:name="Helm",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Helm\")].status",description="Helm feature status"
+//+kubebuilder:printcolumn:name="Kustomize",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"Kustomize\")].status",description="Kustomize feature status"
+//+kubebuilder:printcolumn:name="PodSecurity",type="string",JSONPath=".status.featureSummaries[?(@.featureID==\"PodSecurity\")].status",description="PodSecurity feature status",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ClusterSummary is the Schema for the clustersummaries API
 type ClusterSummary struct {
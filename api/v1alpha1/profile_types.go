@@ -31,8 +31,19 @@ const (
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:path=profiles,scope=Namespaced
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Matching",type="integer",JSONPath=".status.deploymentSummary.matching",description="Number of matching clusters"
+//+kubebuilder:printcolumn:name="Provisioned",type="integer",JSONPath=".status.deploymentSummary.provisioned",description="Number of clusters where all features are provisioned"
+//+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.deploymentSummary.ready",description="All matching clusters are provisioned with current configuration"
+//+kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.deploymentSummary.progress",description="Percentage of matching clusters provisioned with current configuration"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
-// Profile is the Schema for the profiles API
+// Profile is the Schema for the profiles API.
+// Profile is the namespaced counterpart of ClusterProfile: it only matches clusters
+// (and ClusterSets/Sets) in its own namespace and every referenced ConfigMap, Secret
+// and Flux Source is looked up in that same namespace, whatever namespace is set in
+// its spec. Granting a team create/update on Profiles in a namespace therefore only
+// lets that team deploy to the clusters registered in such namespace using resources
+// stored there.
 type Profile struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
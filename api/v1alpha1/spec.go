@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -44,6 +45,9 @@ func (m *DryRunReconciliationError) Error() string {
 	return "mode is DryRun. Nothing is reconciled"
 }
 
+// ValidateHealth defines what makes resources deployed because of a feature healthy.
+// Feature is marked as Provisioned only once all resources fetched are healthy according
+// to every criteria set (Script, Condition and WorkloadReady).
 type ValidateHealth struct {
 	// Name is the name of this check
 	Name string `json:"name"`
@@ -82,6 +86,221 @@ type ValidateHealth struct {
 	// representing whether object is a match (true or false)
 	// +optional
 	Script string `json:"script,omitempty"`
+
+	// Condition, when set, requires each resource to report, in its status conditions,
+	// a condition of this type with the expected status. This covers custom resources
+	// reporting their readiness through a condition (for instance a Ready condition).
+	// +optional
+	Condition *HealthCondition `json:"condition,omitempty"`
+
+	// WorkloadReady, when set, requires each resource, which must be a Deployment, StatefulSet,
+	// DaemonSet or ReplicaSet, to have all its replicas updated and ready.
+	// +optional
+	WorkloadReady bool `json:"workloadReady,omitempty"`
+}
+
+// HealthCondition is a condition a resource must report to be considered healthy
+type HealthCondition struct {
+	// Type of the condition
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// Status the condition must have. Defaults to True.
+	// +kubebuilder:validation:Enum:=True;False;Unknown
+	// +kubebuilder:default:=True
+	// +optional
+	Status metav1.ConditionStatus `json:"status,omitempty"`
+}
+
+// DeletionPolicy is the propagation policy used when deleting resources deployed because of a feature
+type DeletionPolicy struct {
+	// FeatureID is an indentifier of the feature (Resources/Kustomize) whose
+	// resources are deleted using PropagationPolicy
+	FeatureID FeatureID `json:"featureID"`
+
+	// PropagationPolicy determines whether and how garbage collection
+	// is performed on the dependents of a deleted resource
+	// +kubebuilder:validation:Enum:=Foreground;Background;Orphan
+	PropagationPolicy metav1.DeletionPropagation `json:"propagationPolicy"`
+}
+
+// +kubebuilder:validation:Enum:=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// MaintenanceWindow is a recurring time window during which Sveltos does not deploy add-ons
+// and applications
+type MaintenanceWindow struct {
+	// Days of the week the window starts on. When empty, window starts every day.
+	// +listType=set
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start is the time of the day, in the form HH:MM, the window starts at
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration of the window (for instance 2h or 90m)
+	Duration metav1.Duration `json:"duration"`
+}
+
+// KubernetesVersionRequirement is the minimum managed cluster Kubernetes version a feature requires
+type KubernetesVersionRequirement struct {
+	// FeatureID is an indentifier of the feature (Helm/Kustomize/Resources/PodSecurity)
+	// requiring the version
+	FeatureID FeatureID `json:"featureID"`
+
+	// MinKubernetesVersion is the minimum Kubernetes version (for instance v1.28 or v1.28.3)
+	// the managed cluster must run for the feature to be deployed
+	// +kubebuilder:validation:Pattern=`^v?[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	MinKubernetesVersion string `json:"minKubernetesVersion"`
+}
+
+// PreconditionResource identifies a resource in the managed cluster
+type PreconditionResource struct {
+	// Group of the resource. Empty for the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the resource
+	Version string `json:"version"`
+
+	// Kind of the resource
+	Kind string `json:"kind"`
+
+	// Namespace of the resource. Empty for cluster wide resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource. When empty, any resource of this Kind (in Namespace) satisfies
+	// the precondition.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// Precondition is a condition which must be met for a feature to be deployed in a cluster.
+// When both Resource and ClusterLabels are set, both must be satisfied.
+type Precondition struct {
+	// FeatureID is an indentifier of the feature (Helm/Kustomize/Resources/PodSecurity)
+	// requiring the precondition
+	FeatureID FeatureID `json:"featureID"`
+
+	// Resource, when set, must exist in the managed cluster
+	// +optional
+	Resource *PreconditionResource `json:"resource,omitempty"`
+
+	// ClusterLabels, when set, must all be present on the Cluster
+	// +optional
+	ClusterLabels map[string]string `json:"clusterLabels,omitempty"`
+}
+
+// FeaturePriority sets when a feature is deployed relative to the other features
+type FeaturePriority struct {
+	// FeatureID is an indentifier of the feature (Helm/Kustomize/Resources/PodSecurity)
+	FeatureID FeatureID `json:"featureID"`
+
+	// Priority of the feature. Features with a lower priority are deployed first.
+	Priority int32 `json:"priority"`
+}
+
+// Patch is a patch applied to the resources deployed because of a feature
+type Patch struct {
+	// FeatureID is an indentifier of the feature (Resources/Kustomize) whose
+	// resources are patched
+	FeatureID FeatureID `json:"featureID"`
+
+	// Patch is an inline strategic merge patch or an inline JSON6902 patch
+	// +kubebuilder:validation:MinLength=1
+	Patch string `json:"patch"`
+
+	// Target points to the resources the patch is applied to
+	Target PatchSelector `json:"target"`
+}
+
+// PatchSelector selects the resources a patch is applied to. A resource is selected
+// only if it matches all the fields set.
+type PatchSelector struct {
+	// Group of the resources
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the resources
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Kind of the resources
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the resources
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resources. Regular expressions are supported.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// LabelSelector is a label selector expression (e.g. app=nginx,tier!=frontend)
+	// matched against the resource labels
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// AnnotationSelector is a label selector expression matched against the
+	// resource annotations
+	// +optional
+	AnnotationSelector string `json:"annotationSelector,omitempty"`
+}
+
+// ExcludedObject identifies a resource, contained in the content referenced by a feature,
+// which must not be deployed
+type ExcludedObject struct {
+	// FeatureID is an indentifier of the feature (Resources/Kustomize) whose
+	// resources are filtered
+	FeatureID FeatureID `json:"featureID"`
+
+	// Group of the resource. Empty for the core group.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the resource. If not set, any version matches.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Kind of the resource
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Namespace of the resource. If not set, any namespace matches.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// PostDeployJob is a Job Sveltos creates in the managed cluster once all the resources
+// of a feature are deployed
+type PostDeployJob struct {
+	// Name of the Job created in the managed cluster
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Job created in the managed cluster. Namespace is created if it
+	// does not exist already.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// FeatureID is an indentifier of the feature (Helm/Kustomize/Resources)
+	// This field indicates when to run this Job.
+	// For instance, if set to Helm this Job will be created after all helm
+	// charts specified in the ClusterProfile are deployed.
+	FeatureID FeatureID `json:"featureID"`
+
+	// JobSpec is the specification of the Job
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	JobSpec batchv1.JobSpec `json:"jobSpec"`
 }
 
 // SyncMode specifies how features are synced in a workload cluster.
@@ -424,6 +643,43 @@ type KustomizationRef struct {
 // a ClusterProfile. By default, withdrawpolicies, deployed Helm charts and Kubernetes
 // resources will be removed from Cluster. LeavePolicy instead leaves Helm charts
 // and Kubernetes policies in the Cluster.
+// +kubebuilder:validation:Enum:=Newest;Oldest
+type ClusterOrder string
+
+const (
+	// ClusterOrderNewest selects the most recently created clusters first
+	ClusterOrderNewest = ClusterOrder("Newest")
+
+	// ClusterOrderOldest selects the least recently created clusters first
+	ClusterOrderOldest = ClusterOrder("Oldest")
+)
+
+// CompletionWebhook is an HTTP endpoint notified when deploying or removing a feature completes
+type CompletionWebhook struct {
+	// URL is the endpoint notifications are POSTed to, in JSON format
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// SecretRef references a Secret, in the management cluster, whose "key" data is used to
+	// sign notifications with HMAC-SHA256. Signature is sent in the X-Sveltos-Signature header.
+	// +optional
+	SecretRef *corev1.SecretReference `json:"secretRef,omitempty"`
+}
+
+// ClusterLimit limits the clusters a ClusterProfile/Profile is deployed to.
+// Matching clusters are ordered by creationTimestamp (ties are broken by namespace and name)
+// and only the first Count are targeted.
+type ClusterLimit struct {
+	// Count is the maximum number of matching clusters targeted
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count"`
+
+	// Order indicates whether newest or oldest clusters are targeted first
+	// +kubebuilder:default:=Oldest
+	// +optional
+	Order ClusterOrder `json:"order,omitempty"`
+}
+
 type StopMatchingBehavior string
 
 // Define the StopMatchingBehavior constants.
@@ -471,6 +727,82 @@ type PolicyRef struct {
 	// +kubebuilder:default:=Remote
 	// +optional
 	DeploymentType DeploymentType `json:"deploymentType,omitempty"`
+
+	// Keys, when set, restricts the content deployed to the listed data keys of the
+	// referenced ConfigMap/Secret. All other keys are ignored.
+	// Defaults to all keys. Used only for ConfigMap;Secret
+	// +listType=set
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+}
+
+// CopyRef references a ConfigMap/Secret copied, as it is, to the managed clusters
+type CopyRef struct {
+	// Namespace of the referenced resource.
+	// For ClusterProfile namespace can be left empty. In such a case, namespace will
+	// be implicit set to cluster's namespace.
+	// For Profile namespace must be left empty. Profile namespace will be used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the referenced resource.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the resource. Supported kinds are: ConfigMap/Secret.
+	// As for PolicyRefs, Secrets must be of type addons.projectsveltos.io/cluster-profile.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// TargetNamespace is the namespace, in the managed cluster, the copy is created in.
+	// Defaults to the namespace of the referenced resource.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+}
+
+// PodSecurityLevel is a Pod Security Standard level
+// +kubebuilder:validation:Enum:=privileged;baseline;restricted
+type PodSecurityLevel string
+
+const (
+	// PodSecurityLevelPrivileged is the unrestricted Pod Security Standard
+	PodSecurityLevelPrivileged = PodSecurityLevel("privileged")
+
+	// PodSecurityLevelBaseline is the minimally restrictive Pod Security Standard
+	PodSecurityLevelBaseline = PodSecurityLevel("baseline")
+
+	// PodSecurityLevelRestricted is the heavily restricted Pod Security Standard
+	PodSecurityLevelRestricted = PodSecurityLevel("restricted")
+)
+
+// PodSecurityConfiguration describes the Pod Security Admission labels
+// (pod-security.kubernetes.io/<mode> and pod-security.kubernetes.io/<mode>-version)
+// to set on namespaces of a managed cluster.
+type PodSecurityConfiguration struct {
+	// NamespaceSelector identifies the namespaces, in the managed cluster, the
+	// Pod Security Admission labels are set on. It cannot be empty, as an empty
+	// selector would match all namespaces, including system ones like kube-system.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:MinLength=1
+	NamespaceSelector libsveltosv1alpha1.Selector `json:"namespaceSelector"`
+
+	// Enforce is the Pod Security Standard level enforced. Pods violating it are rejected.
+	// +optional
+	Enforce PodSecurityLevel `json:"enforce,omitempty"`
+
+	// Audit is the Pod Security Standard level audited. Violations are recorded in the audit log.
+	// +optional
+	Audit PodSecurityLevel `json:"audit,omitempty"`
+
+	// Warn is the Pod Security Standard level warned about. Violations trigger a user-facing warning.
+	// +optional
+	Warn PodSecurityLevel `json:"warn,omitempty"`
+
+	// Version is the Kubernetes minor version of the Pod Security Standards (for instance v1.29)
+	// applied for each of the modes above.
+	// +kubebuilder:default:=latest
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 type Clusters struct {
@@ -485,11 +817,32 @@ type Clusters struct {
 	Clusters []corev1.ObjectReference `json:"clusters,omitempty"`
 }
 
+// Impersonation describes the identity Sveltos impersonates when deploying
+// add-ons and applications in a managed cluster.
+type Impersonation struct {
+	// UserName is the username to impersonate on each request.
+	// For a ServiceAccount use system:serviceaccount:<namespace>:<name>
+	UserName string `json:"userName"`
+
+	// Groups are the groups to impersonate on each request.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
 type Spec struct {
 	// ClusterSelector identifies clusters to associate to.
 	// +optional
 	ClusterSelector libsveltosv1alpha1.Selector `json:"clusterSelector,omitempty"`
 
+	// ClusterSelectors identifies clusters to associate to, with OR semantics: a cluster
+	// is associated if it matches any of these selectors.
+	// Clusters matching ClusterSelectors are added to the ones matching ClusterSelector,
+	// ClusterRefs and SetRefs. Use it when a single ClusterSelector (an AND of labels)
+	// is not enough, for instance to match clusters that are prod OR labeled critical.
+	// +listType=set
+	// +optional
+	ClusterSelectors []libsveltosv1alpha1.Selector `json:"clusterSelectors,omitempty"`
+
 	// ClusterRefs identifies clusters to associate to.
 	// +optional
 	ClusterRefs []corev1.ObjectReference `json:"clusterRefs,omitempty"`
@@ -536,6 +889,14 @@ type Spec struct {
 	// +optional
 	ContinueOnConflict bool `json:"continueOnConflict,omitempty"`
 
+	// By default (when ContinueOnError is unset or set to false), Sveltos stops deployment after
+	// failing to apply a resource, and the remaining resources are not deployed.
+	// If set to true, Sveltos will attempt to deploy remaining resources even if previous ones
+	// failed to apply. All errors are then reported together in the feature's FailureMessage.
+	// +kubebuilder:default:=false
+	// +optional
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+
 	// The maximum number of clusters that can be updated concurrently.
 	// Value can be an absolute number (ex: 5) or a percentage of desired cluster (ex: 10%).
 	// Defaults to 100%.
@@ -547,6 +908,18 @@ type Spec struct {
 	// +optional
 	MaxUpdate *intstr.IntOrString `json:"maxUpdate,omitempty"`
 
+	// ClusterLimit, when set, limits deployment to a number of matching clusters, selected
+	// by creation time. Clusters beyond the limit are not targeted until the limit is raised.
+	// +optional
+	ClusterLimit *ClusterLimit `json:"clusterLimit,omitempty"`
+
+	// RequiredClusterLabels, when set, lists labels (key and value) a cluster must have to be
+	// targeted. It is evaluated in addition to ClusterSelector, ClusterSelectors, ClusterRefs and
+	// SetRefs: a cluster missing any of those labels is never targeted.
+	// This is meant as a guardrail (for instance restricting deployment to some regions).
+	// +optional
+	RequiredClusterLabels map[string]string `json:"requiredClusterLabels,omitempty"`
+
 	// StopMatchingBehavior indicates what behavior should be when a Cluster stop matching
 	// the ClusterProfile. By default all deployed Helm charts and Kubernetes resources will
 	// be withdrawn from Cluster. Setting StopMatchingBehavior to LeavePolicies will instead
@@ -582,9 +955,30 @@ type Spec struct {
 
 	// PolicyRefs references all the ConfigMaps/Secrets containing kubernetes resources
 	// that need to be deployed in the matching CAPI clusters.
+	// Resources contained in the same ConfigMap/Secret are applied in dependency order:
+	// Namespaces, then ServiceAccounts, Secrets and ConfigMaps, storage,
+	// CustomResourceDefinitions, RBAC, Services, workloads and finally any other kind.
 	// +optional
 	PolicyRefs []PolicyRef `json:"policyRefs,omitempty"`
 
+	// InlinePolicies contains kubernetes resources, in YAML or JSON format, that need to be
+	// deployed in the matching clusters. Meant for small policies, avoiding the need to create
+	// a ConfigMap/Secret. Each entry can contain multiple resources separated by "---".
+	// Resources are deployed along with the ones referenced by PolicyRefs.
+	// +kubebuilder:validation:MaxItems=20
+	// +kubebuilder:validation:items:MaxLength=16384
+	// +listType=atomic
+	// +optional
+	InlinePolicies []string `json:"inlinePolicies,omitempty"`
+
+	// CopyRefs references ConfigMaps/Secrets copied, as they are, to the matching clusters.
+	// Unlike PolicyRefs, their content is not deployed: the ConfigMap/Secret itself is. Meant for
+	// add-ons expecting their configuration in a ConfigMap/Secret. Copies are removed from the
+	// matching clusters once not referenced anymore.
+	// +listType=atomic
+	// +optional
+	CopyRefs []CopyRef `json:"copyRefs,omitempty"`
+
 	// Helm charts is a list of helm charts that need to be deployed
 	HelmCharts []HelmChart `json:"helmCharts,omitempty"`
 
@@ -597,6 +991,87 @@ type Spec struct {
 	// is healthy
 	ValidateHealths []ValidateHealth `json:"validateHealths,omitempty"`
 
+	// PostDeployJobs is a list of Jobs created in the managed cluster after all resources
+	// of a feature are deployed, for instance to initialize an add-on. A feature is marked as
+	// Provisioned only once all its Jobs have completed. Jobs are recreated when their
+	// specification changes.
+	// +listType=atomic
+	// +optional
+	PostDeployJobs []PostDeployJob `json:"postDeployJobs,omitempty"`
+
+	// DisabledFeatures lists the features Sveltos must not deploy. Resources deployed because
+	// of a disabled feature are removed from the managed clusters, while the feature configuration
+	// is preserved so the feature can easily be re-enabled.
+	// +listType=set
+	// +optional
+	DisabledFeatures []FeatureID `json:"disabledFeatures,omitempty"`
+
+	// DeletionPolicies sets, per feature, the propagation policy used when Sveltos deletes
+	// resources it previously deployed in the managed cluster.
+	// When not set for a feature, Background is used, except for CustomResourceDefinitions
+	// which are deleted in Foreground so their instances are removed first.
+	// +listType=map
+	// +listMapKey=featureID
+	// +optional
+	DeletionPolicies []DeletionPolicy `json:"deletionPolicies,omitempty"`
+
+	// KubernetesVersionRequirements sets, per feature, the minimum Kubernetes version the managed
+	// cluster must run. When the managed cluster runs an older version, the feature is not deployed
+	// and its status is set to UnsupportedKubernetesVersion.
+	// +listType=map
+	// +listMapKey=featureID
+	// +optional
+	KubernetesVersionRequirements []KubernetesVersionRequirement `json:"kubernetesVersionRequirements,omitempty"`
+
+	// FeaturePriorities sets, per feature, the order features are deployed in each matching cluster.
+	// A feature is deployed only once all the features with a lower priority are provisioned (for
+	// instance Resources deploying RBAC before Helm charts needing it). Features not listed have
+	// priority 0. By default all features are deployed at the same time.
+	// +listType=map
+	// +listMapKey=featureID
+	// +optional
+	FeaturePriorities []FeaturePriority `json:"featurePriorities,omitempty"`
+
+	// Preconditions are evaluated, in each matching cluster, before a feature is deployed.
+	// When any precondition of a feature is not met, the feature is not deployed and its
+	// status is set to PreconditionNotMet. Preconditions are evaluated again on next reconciliations.
+	// +listType=atomic
+	// +optional
+	Preconditions []Precondition `json:"preconditions,omitempty"`
+
+	// MaintenanceWindows is a list of recurring time windows. While any window is active,
+	// Sveltos does not deploy add-ons and applications in the matching clusters. Deployments
+	// resume once the window ends.
+	// +listType=atomic
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// MaintenanceTimeZone is the IANA time zone (for instance Europe/Rome) MaintenanceWindows
+	// are evaluated in
+	// +kubebuilder:default:=UTC
+	// +optional
+	MaintenanceTimeZone string `json:"maintenanceTimeZone,omitempty"`
+
+	// ScaleDownDuringMaintenance, when set, scales to zero replicas the Deployments and
+	// StatefulSets deployed because of PolicyRefs and KustomizationRefs while a maintenance
+	// window is active. Previous replicas are restored once the window ends.
+	// +optional
+	ScaleDownDuringMaintenance bool `json:"scaleDownDuringMaintenance,omitempty"`
+
+	// Patches is a list of patches applied to the resources Sveltos deploys because of
+	// PolicyRefs (Resources feature) or KustomizationRefs (Kustomize feature), before
+	// those are deployed. Each patch is either a strategic merge patch or a JSON6902 patch.
+	// +listType=atomic
+	// +optional
+	Patches []Patch `json:"patches,omitempty"`
+
+	// ExcludedObjects lists resources, contained in the ConfigMaps/Secrets/Flux sources referenced
+	// by PolicyRefs (Resources feature) or KustomizationRefs (Kustomize feature), which are not
+	// deployed. Excluded resources are not tracked, so if previously deployed, they are removed.
+	// +listType=atomic
+	// +optional
+	ExcludedObjects []ExcludedObject `json:"excludedObjects,omitempty"`
+
 	// ExtraLabels: These labels will be added by Sveltos to all Kubernetes resources deployed in
 	// a managed cluster based on this ClusterProfile/Profile instance.
 	// **Important:** If a resource deployed by Sveltos already has a label with a key present in
@@ -610,4 +1085,66 @@ type Spec struct {
 	// `ExtraAnnotations`, the value from `ExtraAnnotations` will override the existing value.
 	// +optional
 	ExtraAnnotations map[string]string `json:"extraAnnotations,omitempty"`
+
+	// PriorityClassName: if set, Sveltos sets this PriorityClass on the pod template of all
+	// Deployments, StatefulSets, DaemonSets, Jobs, CronJobs and Pods deployed in a managed cluster based on this
+	// ClusterProfile/Profile instance, so add-on pods are not evicted on busy clusters.
+	// The PriorityClass must exist in the managed cluster, unless PriorityClassValue is set.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PriorityClassValue: if set, and the PriorityClass named PriorityClassName does not exist
+	// in the managed cluster, Sveltos creates it with this value.
+	// +optional
+	PriorityClassValue *int32 `json:"priorityClassValue,omitempty"`
+
+	// NodeSelector: if set, Sveltos adds these labels to the nodeSelector of the pod template of all
+	// Deployments, StatefulSets, DaemonSets, Jobs, CronJobs and Pods deployed in a managed cluster based on this
+	// ClusterProfile/Profile instance. Existing nodeSelector entries with the same key are overridden.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations: if set, Sveltos adds these tolerations to the pod template of all
+	// Deployments, StatefulSets, DaemonSets, Jobs, CronJobs and Pods deployed in a managed cluster based on this
+	// ClusterProfile/Profile instance.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// RegistryOverrides: if set, Sveltos rewrites the container images of the pod template of all
+	// Deployments, StatefulSets, DaemonSets, Jobs, CronJobs and Pods deployed in a managed cluster based on this
+	// ClusterProfile/Profile instance, for instance to pull images from a mirror in air-gapped
+	// environments. Each key is an image prefix (a registry, optionally followed by a repository path)
+	// and its value is the prefix to replace it with. Images with no registry are considered hosted
+	// on docker.io. When more keys match, the longest one is used.
+	// For instance, docker.io: mirror.example.com/dockerhub rewrites nginx:1.25 into
+	// mirror.example.com/dockerhub/library/nginx:1.25.
+	// +optional
+	RegistryOverrides map[string]string `json:"registryOverrides,omitempty"`
+
+	// ImagePullSecrets: if set, Sveltos adds these imagePullSecrets to the pod template of all
+	// Deployments, StatefulSets, DaemonSets, Jobs, CronJobs and Pods deployed in a managed cluster based on this
+	// ClusterProfile/Profile instance. Each referenced Secret must exist in the management cluster,
+	// in the namespace of the managed cluster, and be of type kubernetes.io/dockerconfigjson or
+	// kubernetes.io/dockercfg. Sveltos copies it in the namespace of the workloads before
+	// deploying them.
+	// +listType=atomic
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// CompletionWebhook, when set, is notified every time deploying or removing a feature
+	// in a matching cluster completes. It takes precedence over the controller-wide webhook.
+	// +optional
+	CompletionWebhook *CompletionWebhook `json:"completionWebhook,omitempty"`
+
+	// Impersonation, when set, instructs Sveltos to impersonate the given user/groups
+	// when deploying add-ons and applications in the managed clusters. This allows
+	// managed cluster audit logs to attribute changes to a specific identity.
+	// +optional
+	Impersonation *Impersonation `json:"impersonation,omitempty"`
+
+	// PodSecurity, when set, stamps Pod Security Admission labels onto the selected
+	// namespaces of the managed clusters. Labels are reverted to their previous values
+	// when no longer needed.
+	// +optional
+	PodSecurity *PodSecurityConfiguration `json:"podSecurity,omitempty"`
 }
@@ -18,14 +18,37 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ProfileInvalidSelectorCondition is set on a ClusterProfile/Profile when ClusterSelector
+	// or any of ClusterSelectors cannot be parsed. Matching clusters are not recomputed
+	// until the selector is fixed.
+	ProfileInvalidSelectorCondition = "InvalidSelector"
+
+	// ProfileInvalidSelectorReason is the reason of the InvalidSelector condition
+	ProfileInvalidSelectorReason = "ParseError"
+
+	// ExplainAnnotation, when set on a ClusterProfile/Profile, makes the controller report in
+	// Status.MatchExplanation why each cluster is, or is not, matching. It is a debugging aid for
+	// selector misconfigurations and should be removed afterwards, as it evaluates all clusters
+	// at every reconciliation.
+	ExplainAnnotation = "projectsveltos.io/explain"
 )
 
 // Status defines the observed state of ClusterProfile/Profile
 type Status struct {
 	// MatchingClusterRefs reference all the clusters currently matching
-	// ClusterProfile ClusterSelector
+	// ClusterProfile ClusterSelector. When ClusterLimit is set, only the clusters
+	// within the limit are listed.
 	MatchingClusterRefs []corev1.ObjectReference `json:"matchingClusters,omitempty"`
 
+	// ExcludedClusterRefs reference the clusters matching ClusterProfile ClusterSelector
+	// but not targeted because beyond ClusterLimit
+	// +optional
+	ExcludedClusterRefs []corev1.ObjectReference `json:"excludedClusters,omitempty"`
+
 	// UpdatingClusters reference all the cluster currently matching
 	// ClusterProfile ClusterSelector and being updated
 	UpdatingClusters Clusters `json:"updatingClusters,omitempty"`
@@ -34,4 +57,99 @@ type Status struct {
 	// ClusterProfile ClusterSelector and already updated to latest ClusterProfile
 	// Spec
 	UpdatedClusters Clusters `json:"updatedClusters,omitempty"`
+
+	// DeploymentSummary aggregates the deployment status of all the ClusterSummaries
+	// created because of this ClusterProfile/Profile
+	// +optional
+	DeploymentSummary *DeploymentSummary `json:"deploymentSummary,omitempty"`
+
+	// Conditions reports the ClusterProfile/Profile conditions. The InvalidSelector
+	// condition is set while a cluster selector cannot be parsed.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// MatchExplanation explains why each cluster is, or is not, matching.
+	// Only set while the projectsveltos.io/explain annotation is present.
+	// +optional
+	MatchExplanation *MatchExplanation `json:"matchExplanation,omitempty"`
+}
+
+// MatchExplanation details how the clusters matching a ClusterProfile/Profile were computed
+type MatchExplanation struct {
+	// ClusterSelector is the parsed ClusterSelector, if any
+	// +optional
+	ClusterSelector string `json:"clusterSelector,omitempty"`
+
+	// ClusterSelectors are the parsed ClusterSelectors, if any
+	// +listType=atomic
+	// +optional
+	ClusterSelectors []string `json:"clusterSelectors,omitempty"`
+
+	// Clusters lists the evaluated clusters and why each one is, or is not, matching
+	// +listType=atomic
+	// +optional
+	Clusters []ClusterExplanation `json:"clusters,omitempty"`
+
+	// Truncated is true when more clusters were evaluated than listed in Clusters
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ClusterExplanation explains why a cluster is, or is not, matching
+type ClusterExplanation struct {
+	// Cluster references the evaluated cluster
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// Matching is true if the cluster is currently matching
+	Matching bool `json:"matching"`
+
+	// Reason explains why the cluster is, or is not, matching
+	Reason string `json:"reason"`
+}
+
+// DeploymentSummary counts matching clusters by deployment status and lists
+// the clusters where deployment is failing
+type DeploymentSummary struct {
+	// Matching is the number of clusters matching the ClusterProfile/Profile
+	Matching int32 `json:"matching"`
+
+	// Provisioned is the number of clusters where all features are provisioned
+	Provisioned int32 `json:"provisioned"`
+
+	// Provisioning is the number of clusters where at least one feature is being
+	// provisioned and none is failing
+	Provisioning int32 `json:"provisioning"`
+
+	// Failed is the number of clusters where at least one feature is failing
+	Failed int32 `json:"failed"`
+
+	// Progress is the percentage of matching clusters where all features are provisioned
+	// with the current ClusterProfile/Profile configuration
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Progress int32 `json:"progress"`
+
+	// Ready is true when all matching clusters are provisioned with the current
+	// ClusterProfile/Profile configuration
+	Ready bool `json:"ready"`
+
+	// FailingClusters lists the clusters where at least one feature is failing
+	// +listType=atomic
+	// +optional
+	FailingClusters []FailingCluster `json:"failingClusters,omitempty"`
+}
+
+// FailingCluster is a cluster where deploying a feature is failing
+type FailingCluster struct {
+	// Cluster references the failing cluster
+	Cluster corev1.ObjectReference `json:"cluster"`
+
+	// FeatureID is the first failing feature
+	FeatureID FeatureID `json:"featureID"`
+
+	// FailureMessage explains why deploying the feature is failing
+	// +optional
+	FailureMessage string `json:"failureMessage,omitempty"`
 }
@@ -22,8 +22,8 @@ package v1alpha1
 
 import (
 	apiv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -134,6 +134,72 @@ func (in *ClusterConfigurationStatus) DeepCopy() *ClusterConfigurationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterExplanation) DeepCopyInto(out *ClusterExplanation) {
+	*out = *in
+	out.Cluster = in.Cluster
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExplanation.
+func (in *ClusterExplanation) DeepCopy() *ClusterExplanation {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterExplanation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterLimit) DeepCopyInto(out *ClusterLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterLimit.
+func (in *ClusterLimit) DeepCopy() *ClusterLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPlan) DeepCopyInto(out *ClusterPlan) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.ReleaseReports != nil {
+		in, out := &in.ReleaseReports, &out.ReleaseReports
+		*out = make([]ReleaseReport, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceReports != nil {
+		in, out := &in.ResourceReports, &out.ResourceReports
+		*out = make([]ResourceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KustomizeResourceReports != nil {
+		in, out := &in.KustomizeResourceReports, &out.KustomizeResourceReports
+		*out = make([]ResourceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPlan.
+func (in *ClusterPlan) DeepCopy() *ClusterPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterProfile) DeepCopyInto(out *ClusterProfile) {
 	*out = *in
@@ -193,6 +259,102 @@ func (in *ClusterProfileList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProfilePlan) DeepCopyInto(out *ClusterProfilePlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfilePlan.
+func (in *ClusterProfilePlan) DeepCopy() *ClusterProfilePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProfilePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProfilePlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProfilePlanList) DeepCopyInto(out *ClusterProfilePlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterProfilePlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfilePlanList.
+func (in *ClusterProfilePlanList) DeepCopy() *ClusterProfilePlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProfilePlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProfilePlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProfilePlanSpec) DeepCopyInto(out *ClusterProfilePlanSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfilePlanSpec.
+func (in *ClusterProfilePlanSpec) DeepCopy() *ClusterProfilePlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProfilePlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProfilePlanStatus) DeepCopyInto(out *ClusterProfilePlanStatus) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProfilePlanStatus.
+func (in *ClusterProfilePlanStatus) DeepCopy() *ClusterProfilePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProfilePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterProfileResource) DeepCopyInto(out *ClusterProfileResource) {
 	*out = *in
@@ -427,6 +589,18 @@ func (in *ClusterSummaryStatus) DeepCopyInto(out *ClusterSummaryStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Connectivity != nil {
+		in, out := &in.Connectivity, &out.Connectivity
+		*out = new(ConnectivityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSummaryStatus.
@@ -449,7 +623,7 @@ func (in *Clusters) DeepCopyInto(out *Clusters) {
 	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
-		*out = make([]corev1.ObjectReference, len(*in))
+		*out = make([]v1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -464,6 +638,112 @@ func (in *Clusters) DeepCopy() *Clusters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompletionWebhook) DeepCopyInto(out *CompletionWebhook) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompletionWebhook.
+func (in *CompletionWebhook) DeepCopy() *CompletionWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(CompletionWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityStatus) DeepCopyInto(out *ConnectivityStatus) {
+	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
+	if in.Message != nil {
+		in, out := &in.Message, &out.Message
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityStatus.
+func (in *ConnectivityStatus) DeepCopy() *ConnectivityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CopyRef) DeepCopyInto(out *CopyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CopyRef.
+func (in *CopyRef) DeepCopy() *CopyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CopyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentSummary) DeepCopyInto(out *DeploymentSummary) {
+	*out = *in
+	if in.FailingClusters != nil {
+		in, out := &in.FailingClusters, &out.FailingClusters
+		*out = make([]FailingCluster, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSummary.
+func (in *DeploymentSummary) DeepCopy() *DeploymentSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunDiffEntry) DeepCopyInto(out *DryRunDiffEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunDiffEntry.
+func (in *DryRunDiffEntry) DeepCopy() *DryRunDiffEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunDiffEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DryRunReconciliationError) DeepCopyInto(out *DryRunReconciliationError) {
 	*out = *in
@@ -479,6 +759,37 @@ func (in *DryRunReconciliationError) DeepCopy() *DryRunReconciliationError {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedObject) DeepCopyInto(out *ExcludedObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedObject.
+func (in *ExcludedObject) DeepCopy() *ExcludedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailingCluster) DeepCopyInto(out *FailingCluster) {
+	*out = *in
+	out.Cluster = in.Cluster
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailingCluster.
+func (in *FailingCluster) DeepCopy() *FailingCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FailingCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Feature) DeepCopyInto(out *Feature) {
 	*out = *in
@@ -528,6 +839,21 @@ func (in *FeatureDeploymentInfo) DeepCopy() *FeatureDeploymentInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeaturePriority) DeepCopyInto(out *FeaturePriority) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeaturePriority.
+func (in *FeaturePriority) DeepCopy() *FeaturePriority {
+	if in == nil {
+		return nil
+	}
+	out := new(FeaturePriority)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FeatureSummary) DeepCopyInto(out *FeatureSummary) {
 	*out = *in
@@ -555,6 +881,21 @@ func (in *FeatureSummary) DeepCopyInto(out *FeatureSummary) {
 		in, out := &in.LastAppliedTime, &out.LastAppliedTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ResolvedReferences != nil {
+		in, out := &in.ResolvedReferences, &out.ResolvedReferences
+		*out = make([]v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.MissingReferences != nil {
+		in, out := &in.MissingReferences, &out.MissingReferences
+		*out = make([]v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRunDiff != nil {
+		in, out := &in.DryRunDiff, &out.DryRunDiff
+		*out = make([]DryRunDiffEntry, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureSummary.
@@ -567,6 +908,21 @@ func (in *FeatureSummary) DeepCopy() *FeatureSummary {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCondition) DeepCopyInto(out *HealthCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCondition.
+func (in *HealthCondition) DeepCopy() *HealthCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChart) DeepCopyInto(out *HelmChart) {
 	*out = *in
@@ -632,7 +988,7 @@ func (in *HelmOptions) DeepCopyInto(out *HelmOptions) {
 	*out = *in
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
-		*out = new(v1.Duration)
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	if in.Labels != nil {
@@ -687,6 +1043,41 @@ func (in *HelmUpgradeOptions) DeepCopy() *HelmUpgradeOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Impersonation) DeepCopyInto(out *Impersonation) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Impersonation.
+func (in *Impersonation) DeepCopy() *Impersonation {
+	if in == nil {
+		return nil
+	}
+	out := new(Impersonation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesVersionRequirement) DeepCopyInto(out *KubernetesVersionRequirement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesVersionRequirement.
+func (in *KubernetesVersionRequirement) DeepCopy() *KubernetesVersionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesVersionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KustomizationRef) DeepCopyInto(out *KustomizationRef) {
 	*out = *in
@@ -714,9 +1105,106 @@ func (in *KustomizationRef) DeepCopy() *KustomizationRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchExplanation) DeepCopyInto(out *MatchExplanation) {
+	*out = *in
+	if in.ClusterSelectors != nil {
+		in, out := &in.ClusterSelectors, &out.ClusterSelectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterExplanation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchExplanation.
+func (in *MatchExplanation) DeepCopy() *MatchExplanation {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchExplanation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Patch) DeepCopyInto(out *Patch) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Patch.
+func (in *Patch) DeepCopy() *Patch {
+	if in == nil {
+		return nil
+	}
+	out := new(Patch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatchSelector) DeepCopyInto(out *PatchSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchSelector.
+func (in *PatchSelector) DeepCopy() *PatchSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(PatchSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSecurityConfiguration) DeepCopyInto(out *PodSecurityConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecurityConfiguration.
+func (in *PodSecurityConfiguration) DeepCopy() *PodSecurityConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSecurityConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRef) DeepCopyInto(out *PolicyRef) {
 	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRef.
@@ -729,6 +1217,64 @@ func (in *PolicyRef) DeepCopy() *PolicyRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostDeployJob) DeepCopyInto(out *PostDeployJob) {
+	*out = *in
+	in.JobSpec.DeepCopyInto(&out.JobSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostDeployJob.
+func (in *PostDeployJob) DeepCopy() *PostDeployJob {
+	if in == nil {
+		return nil
+	}
+	out := new(PostDeployJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Precondition) DeepCopyInto(out *Precondition) {
+	*out = *in
+	if in.Resource != nil {
+		in, out := &in.Resource, &out.Resource
+		*out = new(PreconditionResource)
+		**out = **in
+	}
+	if in.ClusterLabels != nil {
+		in, out := &in.ClusterLabels, &out.ClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Precondition.
+func (in *Precondition) DeepCopy() *Precondition {
+	if in == nil {
+		return nil
+	}
+	out := new(Precondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreconditionResource) DeepCopyInto(out *PreconditionResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreconditionResource.
+func (in *PreconditionResource) DeepCopy() *PreconditionResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PreconditionResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Profile) DeepCopyInto(out *Profile) {
 	*out = *in
@@ -864,9 +1410,14 @@ func (in *ResourceReport) DeepCopy() *ResourceReport {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Spec) DeepCopyInto(out *Spec) {
 	*out = *in
+	if in.ClusterSelectors != nil {
+		in, out := &in.ClusterSelectors, &out.ClusterSelectors
+		*out = make([]apiv1alpha1.Selector, len(*in))
+		copy(*out, *in)
+	}
 	if in.ClusterRefs != nil {
 		in, out := &in.ClusterRefs, &out.ClusterRefs
-		*out = make([]corev1.ObjectReference, len(*in))
+		*out = make([]v1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.SetRefs != nil {
@@ -879,6 +1430,18 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.ClusterLimit != nil {
+		in, out := &in.ClusterLimit, &out.ClusterLimit
+		*out = new(ClusterLimit)
+		**out = **in
+	}
+	if in.RequiredClusterLabels != nil {
+		in, out := &in.RequiredClusterLabels, &out.RequiredClusterLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.TemplateResourceRefs != nil {
 		in, out := &in.TemplateResourceRefs, &out.TemplateResourceRefs
 		*out = make([]TemplateResourceRef, len(*in))
@@ -892,6 +1455,18 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 	if in.PolicyRefs != nil {
 		in, out := &in.PolicyRefs, &out.PolicyRefs
 		*out = make([]PolicyRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InlinePolicies != nil {
+		in, out := &in.InlinePolicies, &out.InlinePolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CopyRefs != nil {
+		in, out := &in.CopyRefs, &out.CopyRefs
+		*out = make([]CopyRef, len(*in))
 		copy(*out, *in)
 	}
 	if in.HelmCharts != nil {
@@ -915,6 +1490,57 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PostDeployJobs != nil {
+		in, out := &in.PostDeployJobs, &out.PostDeployJobs
+		*out = make([]PostDeployJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DisabledFeatures != nil {
+		in, out := &in.DisabledFeatures, &out.DisabledFeatures
+		*out = make([]FeatureID, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionPolicies != nil {
+		in, out := &in.DeletionPolicies, &out.DeletionPolicies
+		*out = make([]DeletionPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.KubernetesVersionRequirements != nil {
+		in, out := &in.KubernetesVersionRequirements, &out.KubernetesVersionRequirements
+		*out = make([]KubernetesVersionRequirement, len(*in))
+		copy(*out, *in)
+	}
+	if in.FeaturePriorities != nil {
+		in, out := &in.FeaturePriorities, &out.FeaturePriorities
+		*out = make([]FeaturePriority, len(*in))
+		copy(*out, *in)
+	}
+	if in.Preconditions != nil {
+		in, out := &in.Preconditions, &out.Preconditions
+		*out = make([]Precondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]Patch, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedObjects != nil {
+		in, out := &in.ExcludedObjects, &out.ExcludedObjects
+		*out = make([]ExcludedObject, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExtraLabels != nil {
 		in, out := &in.ExtraLabels, &out.ExtraLabels
 		*out = make(map[string]string, len(*in))
@@ -929,6 +1555,52 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 			(*out)[key] = val
 		}
 	}
+	if in.PriorityClassValue != nil {
+		in, out := &in.PriorityClassValue, &out.PriorityClassValue
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RegistryOverrides != nil {
+		in, out := &in.RegistryOverrides, &out.RegistryOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionWebhook != nil {
+		in, out := &in.CompletionWebhook, &out.CompletionWebhook
+		*out = new(CompletionWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Impersonation != nil {
+		in, out := &in.Impersonation, &out.Impersonation
+		*out = new(Impersonation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSecurity != nil {
+		in, out := &in.PodSecurity, &out.PodSecurity
+		*out = new(PodSecurityConfiguration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Spec.
@@ -946,11 +1618,33 @@ func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
 	if in.MatchingClusterRefs != nil {
 		in, out := &in.MatchingClusterRefs, &out.MatchingClusterRefs
-		*out = make([]corev1.ObjectReference, len(*in))
+		*out = make([]v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedClusterRefs != nil {
+		in, out := &in.ExcludedClusterRefs, &out.ExcludedClusterRefs
+		*out = make([]v1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	in.UpdatingClusters.DeepCopyInto(&out.UpdatingClusters)
 	in.UpdatedClusters.DeepCopyInto(&out.UpdatedClusters)
+	if in.DeploymentSummary != nil {
+		in, out := &in.DeploymentSummary, &out.DeploymentSummary
+		*out = new(DeploymentSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MatchExplanation != nil {
+		in, out := &in.MatchExplanation, &out.MatchExplanation
+		*out = new(MatchExplanation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
@@ -987,6 +1681,11 @@ func (in *ValidateHealth) DeepCopyInto(out *ValidateHealth) {
 		*out = make([]apiv1alpha1.LabelFilter, len(*in))
 		copy(*out, *in)
 	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(HealthCondition)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidateHealth.
@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -30,6 +31,8 @@ import (
 	"time"
 
 	_ "embed"
+	// distroless images ship no time zone database, needed to evaluate maintenance windows
+	_ "time/tzdata"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"github.com/go-logr/logr"
@@ -80,6 +83,41 @@ var (
 	webhookPort          int
 	syncPeriod           time.Duration
 	conflictRetryTime    time.Duration
+	referenceDebounce    time.Duration
+	successRequeueAfter  time.Duration
+	newClustersFirst     bool
+	fairScheduling       bool
+	fairWeights          map[string]int
+	driftCollection      time.Duration
+	singleClusterMode    bool
+	clusterAPIQPS        float32
+	clusterAPIBurst      int
+	clusterMaxInflight   int
+	applyConflictRetries int
+	circuitThreshold     int
+	circuitCooldown      time.Duration
+	hashAlgorithm        string
+	strictHash           bool
+	workloadInventory    bool
+	defaultRefNamespace  string
+	maxObjectsPerRef     int
+	clusterNamespaces    []string
+	auditSink            string
+	auditWebhookURL      string
+	completionURL        string
+	completionSecret     string
+	summaryCleanup       string
+	summaryNaming        string
+	caBundleKind         string
+	caBundleNamespace    string
+	caBundleName         string
+	clusterProxyURL      string
+	clusterNoProxy       string
+	leaderElect          bool
+	leaseDuration        time.Duration
+	renewDeadline        time.Duration
+	retryPeriod          time.Duration
+	drainTimeout         time.Duration
 	version              string
 	healthAddr           string
 	profilerAddress      string
@@ -98,6 +136,9 @@ const (
 // +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
 // +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
+// Add RBAC for leader election.
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+
 func main() {
 	scheme, err := controllers.InitScheme()
 	if err != nil {
@@ -114,6 +155,14 @@ func main() {
 	reportMode = controllers.ReportMode(tmpReportMode)
 
 	ctrl.SetLogger(klog.Background())
+	// Leave time to the manager to stop once in-flight deployments are drained
+	const shutdownMargin = 10 * time.Second
+	gracefulShutdownTimeout := drainTimeout + shutdownMargin
+	if err := validateLeaderElectionFlags(); err != nil {
+		setupLog.Error(err, "invalid leader election configuration")
+		os.Exit(1)
+	}
+
 	ctrlOptions := ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                getDiagnosticsOptions(),
@@ -125,7 +174,14 @@ func main() {
 		Cache: cache.Options{
 			SyncPeriod: &syncPeriod,
 		},
-		PprofBindAddress: profilerAddress,
+		PprofBindAddress:              profilerAddress,
+		LeaderElection:                leaderElect,
+		LeaderElectionID:              getLeaderElectionID(),
+		LeaderElectionReleaseOnCancel: true,
+		LeaseDuration:                 &leaseDuration,
+		RenewDeadline:                 &renewDeadline,
+		RetryPeriod:                   &retryPeriod,
+		GracefulShutdownTimeout:       &gracefulShutdownTimeout,
 	}
 
 	restConfig := ctrl.GetConfigOrDie()
@@ -141,6 +197,46 @@ func main() {
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 	controllers.SetManagementClusterAccess(mgr.GetClient(), mgr.GetConfig())
+	controllers.SetSingleClusterMode(singleClusterMode)
+	controllers.SetClusterNamespaces(clusterNamespaces)
+	controllers.SetClusterRateLimits(clusterAPIQPS, clusterAPIBurst, clusterMaxInflight)
+	controllers.SetApplyConflictRetries(applyConflictRetries)
+	controllers.SetClusterCircuitBreaker(circuitThreshold, circuitCooldown)
+	controllers.SetStrictHash(strictHash)
+	controllers.SetWorkloadInventory(workloadInventory)
+	controllers.SetDefaultReferenceNamespace(defaultRefNamespace)
+	controllers.SetMaxObjectsPerReference(maxObjectsPerRef)
+	if err := controllers.SetHashAlgorithm(controllers.HashAlgorithm(hashAlgorithm)); err != nil {
+		setupLog.Error(err, "invalid hash algorithm")
+		os.Exit(1)
+	}
+	if err := controllers.SetAuditSink(ctx, mgr.GetClient(), controllers.AuditSinkType(auditSink),
+		auditWebhookURL); err != nil {
+		setupLog.Error(err, "invalid audit configuration")
+		os.Exit(1)
+	}
+	if err := controllers.SetClusterCABundle(caBundleKind, caBundleNamespace, caBundleName); err != nil {
+		setupLog.Error(err, "invalid cluster CA bundle configuration")
+		os.Exit(1)
+	}
+	if err := controllers.SetClusterProxy(clusterProxyURL, clusterNoProxy); err != nil {
+		setupLog.Error(err, "invalid cluster proxy configuration")
+		os.Exit(1)
+	}
+	if err := controllers.SetCompletionWebhook(ctx, mgr.GetClient(), completionURL, completionSecret); err != nil {
+		setupLog.Error(err, "invalid completion webhook configuration")
+		os.Exit(1)
+	}
+	if err := controllers.SetClusterSummaryCleanupStrategy(
+		controllers.ClusterSummaryCleanupStrategy(summaryCleanup)); err != nil {
+		setupLog.Error(err, "invalid cluster-summary-cleanup")
+		os.Exit(1)
+	}
+	if err := controllers.SetClusterSummaryNamingScheme(
+		controllers.ClusterSummaryNamingScheme(summaryNaming)); err != nil {
+		setupLog.Error(err, "invalid cluster-summary-naming")
+		os.Exit(1)
+	}
 
 	logs.RegisterForLogSettings(ctx,
 		libsveltosv1alpha1.ComponentAddonManager, ctrl.Log.WithName("log-setter"),
@@ -177,7 +273,8 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&diagnosticsAddress, "diagnostics-address", ":8443",
 		"The address the diagnostics endpoint binds to. Per default metrics are served via https and with"+
 			"authentication/authorization. To serve via http and without authentication/authorization set --insecure-diagnostics."+
-			"If --insecure-diagnostics is not set the diagnostics endpoint also serves pprof endpoints")
+			"If --insecure-diagnostics is not set the diagnostics endpoint also serves pprof endpoints and the "+
+			controllers.RenderPath+" endpoint, which renders resources a ClusterProfile/Profile would deploy")
 
 	fs.BoolVar(&insecureDiagnostics, "insecure-diagnostics", false,
 		"Enable insecure diagnostics serving. For more details see the description of --diagnostics-address.")
@@ -226,13 +323,216 @@ func initFlags(fs *pflag.FlagSet) {
 
 	const defaultSyncPeriod = 10
 	fs.DurationVar(&syncPeriod, "sync-period", defaultSyncPeriod*time.Minute,
-		fmt.Sprintf("The minimum interval at which watched resources are reconciled (e.g. 15m). Default: %d minutes",
+		fmt.Sprintf("The minimum interval at which all watched resources (ClusterProfiles, Profiles, ClusterSummaries, "+
+			"Clusters, ConfigMaps, Secrets, ...) are fully resynced and reconciled again (e.g. 15m). This is a safety net "+
+			"for events missed during API server disruptions: lower values recover faster, but every resync "+
+			"reconciles all objects, which increases the load on the management cluster API server. Default: %d minutes",
 			defaultSyncPeriod))
 
 	const defaultConflictRetryTime = 30
 	fs.DurationVar(&conflictRetryTime, "conflict-retry-time", defaultConflictRetryTime*time.Second,
 		fmt.Sprintf("The minimum interval at which watched ClusterProfile with conflicts are retried. Defaul: %d seconds",
 			defaultConflictRetryTime))
+
+	fs.DurationVar(&successRequeueAfter, "success-requeue-after", 0,
+		"When set, ClusterSummaries in Continuous or ContinuousWithDriftDetection mode are reconciled again this long "+
+			"after a successful deployment (e.g. 30m), even if nothing changed. Lower values detect and fix "+
+			"configuration drifts sooner, but increase the load on API servers. Default: disabled")
+
+	fs.DurationVar(&referenceDebounce, "reference-debounce", 0,
+		"When set, changes to ConfigMaps/Secrets referenced by ClusterProfiles/Profiles are debounced: "+
+			"a cluster is redeployed only once none of the resources it references has changed for this "+
+			"interval (e.g. 5s). Resources which keep changing postpone the deployment. Default: disabled")
+
+	fs.BoolVar(&newClustersFirst, "prioritize-new-clusters", false,
+		"When set, clusters with no add-on deployed yet (for instance clusters which just started matching a "+
+			"ClusterProfile) are reconciled before clusters already provisioned. This reduces time to provision "+
+			"when many clusters are added at once. Default: disabled")
+
+	fs.BoolVar(&fairScheduling, "fair-scheduling", false,
+		"When set, ClusterSummary reconcile workers are shared in round robin across ClusterProfiles/Profiles, "+
+			"so one matching many clusters does not starve the others. Default: disabled")
+
+	fs.StringToIntVar(&fairWeights, "fair-scheduling-weights", nil,
+		"Comma separated list of ClusterProfile/<name>=<weight> and Profile/<namespace>/<name>=<weight>. "+
+			"With fair-scheduling, a ClusterProfile/Profile with weight N gets up to N reconciles in a row before "+
+			"the next one is served. Default weight: 1")
+
+	const defaultDriftCollectionInterval = 10
+	fs.DurationVar(&driftCollection, "drift-collection-interval", defaultDriftCollectionInterval*time.Second,
+		fmt.Sprintf("The interval at which drifts detected in managed clusters (ContinuousWithDriftDetection mode) are "+
+			"collected and fixed. Default: %d seconds", defaultDriftCollectionInterval))
+
+	fs.BoolVar(&singleClusterMode, "single-cluster-mode", false,
+		"When set, add-ons and applications are deployed in the management cluster itself instead of "+
+			"in the matching managed clusters. Meant for testing and simple setups")
+
+	fs.StringSliceVar(&clusterNamespaces, "cluster-namespaces", nil,
+		"Comma separated list of namespaces. When set, only clusters (CAPI Clusters and SveltosClusters) in those "+
+			"namespaces are considered when computing the clusters matching ClusterProfiles/Profiles. This allows "+
+			"different controller instances to own clusters in different namespaces. Default: all namespaces")
+
+	const defaultClusterAPIQPS = 50
+	fs.Float32Var(&clusterAPIQPS, "cluster-api-qps", defaultClusterAPIQPS,
+		fmt.Sprintf("Maximum queries per second sent to each managed cluster API server, shared by all clients "+
+			"accessing that cluster. Set to 0 to disable. Defaults to %d", defaultClusterAPIQPS))
+
+	const defaultClusterAPIBurst = 100
+	fs.IntVar(&clusterAPIBurst, "cluster-api-burst", defaultClusterAPIBurst,
+		fmt.Sprintf("Maximum burst of queries sent to each managed cluster API server. Defaults to %d",
+			defaultClusterAPIBurst))
+
+	const defaultClusterMaxInflight = 25
+	fs.IntVar(&clusterMaxInflight, "cluster-api-max-inflight", defaultClusterMaxInflight,
+		fmt.Sprintf("Maximum number of concurrent requests sent to a single managed cluster API server "+
+			"(watches excluded). The limit applies per cluster, not across all clusters, and is shared by all "+
+			"clients accessing that cluster. Set to 0 to disable. Defaults to %d", defaultClusterMaxInflight))
+
+	const defaultApplyConflictRetries = 4
+	fs.IntVar(&applyConflictRetries, "apply-conflict-retries", defaultApplyConflictRetries,
+		fmt.Sprintf("Number of times applying a resource to a managed cluster is retried when it fails because "+
+			"the resource was concurrently modified (Conflict error). Set to 0 to disable. Defaults to %d",
+			defaultApplyConflictRetries))
+
+	fs.IntVar(&circuitThreshold, "circuit-breaker-failures", 0,
+		"Number of consecutive severe failures (managed cluster API persistently unavailable) after which "+
+			"deployments to that managed cluster are suspended for circuit-breaker-cooldown. After the cooldown a "+
+			"single ClusterSummary is allowed to deploy again. Set to 0 to disable. Default: disabled")
+
+	const defaultCircuitCooldown = 10 * time.Minute
+	fs.DurationVar(&circuitCooldown, "circuit-breaker-cooldown", defaultCircuitCooldown,
+		fmt.Sprintf("How long deployments to a managed cluster are suspended once its circuit breaker trips. "+
+			"Defaults to %s", defaultCircuitCooldown))
+
+	fs.StringVar(&hashAlgorithm, "hash-algorithm", string(controllers.HashAlgorithmSHA256),
+		"Algorithm used to compute the hash of each feature configuration, which detects when a feature "+
+			"needs to be redeployed. Supported values: sha256, sha384, sha512 and fnv128a (faster, not "+
+			"cryptographic). Changing it causes all features to be redeployed once")
+
+	fs.BoolVar(&strictHash, "strict-hash", false,
+		"When set, the hash of each feature configuration includes the UID and resourceVersion of every "+
+			"referenced ConfigMap and Secret. Any change to those resources, including a replacement with "+
+			"identical data, then causes a redeploy. By default only their data is considered")
+
+	fs.BoolVar(&workloadInventory, "workload-inventory", false,
+		"When set, an inventory ConfigMap listing all resources and helm releases deployed by each "+
+			"ClusterSummary is kept in the projectsveltos namespace of every managed cluster")
+
+	fs.StringVar(&defaultRefNamespace, "default-reference-namespace", "",
+		"Management cluster namespace ConfigMaps/Secrets referenced by ClusterProfiles without a namespace "+
+			"(PolicyRefs, CopyRefs, KustomizationRefs and ValuesFrom) are looked up in. Namespace set on "+
+			"the reference always wins. When empty, the matching cluster namespace is used")
+
+	const defaultMaxObjectsPerRef = 5000
+	fs.IntVar(&maxObjectsPerRef, "max-objects-per-policyref", defaultMaxObjectsPerRef,
+		fmt.Sprintf("Maximum number of objects a single referenced ConfigMap/Secret/Source can contain. "+
+			"Deploying a reference with more objects fails with an error and nothing is applied. "+
+			"Set to 0 to disable. Defaults to %d", defaultMaxObjectsPerRef))
+
+	fs.StringVar(&auditSink, "audit-sink", "",
+		"When set, every resource applied to or deleted from a managed cluster is recorded. "+
+			"Supported values: log, configmap (one ConfigMap per cluster, last entries only) and webhook. "+
+			"Default: disabled")
+
+	fs.StringVar(&auditWebhookURL, "audit-webhook-url", "",
+		"The HTTP endpoint audit records are posted to, in JSON format, when audit-sink is webhook")
+
+	fs.StringVar(&completionURL, "completion-webhook-url", "",
+		"When set, a JSON notification (cluster, feature, status and hash) is POSTed to this endpoint every time "+
+			"deploying or removing a feature in a managed cluster completes. A ClusterProfile/Profile can set its own "+
+			"endpoint with spec.completionWebhook. Default: disabled")
+
+	fs.StringVar(&completionSecret, "completion-webhook-secret", "",
+		"Secret, in the form namespace/name, whose \"key\" data is used to sign the notifications sent to "+
+			"completion-webhook-url with HMAC-SHA256. Signature is sent in the X-Sveltos-Signature header")
+
+	fs.StringVar(&summaryCleanup, "cluster-summary-cleanup", string(controllers.ClusterSummaryCleanupFinalizer),
+		"How ClusterSummaries are removed when their ClusterProfile/Profile is deleted. "+
+			"finalizer: the profile controller deletes them and waits for them to be gone, regardless of the "+
+			"deletion propagation policy. ownerreference: ClusterSummaries are deleted by the Kubernetes garbage "+
+			"collector when the profile is deleted with foreground propagation (profile stays until managed "+
+			"clusters are cleaned up); with background propagation it falls back to finalizer")
+
+	fs.StringVar(&summaryNaming, "cluster-summary-naming", string(controllers.ClusterSummaryNamingLegacy),
+		"How the name of new ClusterSummaries is derived. legacy: profile name, cluster type and cluster name "+
+			"joined with dashes; different profile/cluster pairs might collide. hashed: legacy name followed by "+
+			"a hash of profile kind/name and cluster type/name, unique for each pair and truncated to 63 characters "+
+			"so it can be used as label value. Existing ClusterSummaries "+
+			"are found by labels, so changing scheme does not affect them")
+
+	fs.StringVar(&caBundleName, "cluster-ca-bundle-name", "",
+		"Name of the Secret or ConfigMap, in the management cluster, whose ca.crt key contains additional "+
+			"PEM encoded CAs trusted when connecting to managed cluster API servers. Those are merged with the "+
+			"CA contained in the cluster kubeconfig. Needed when API servers present certificates issued by a "+
+			"private CA. Default: disabled")
+
+	fs.StringVar(&caBundleNamespace, "cluster-ca-bundle-namespace", "projectsveltos",
+		"Namespace of the Secret or ConfigMap referenced by cluster-ca-bundle-name")
+
+	fs.StringVar(&caBundleKind, "cluster-ca-bundle-kind", string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+		"Kind of the resource referenced by cluster-ca-bundle-name. Supported values: ConfigMap, Secret")
+
+	fs.StringVar(&clusterProxyURL, "cluster-proxy-url", "",
+		"URL of the HTTP(S) or SOCKS5 proxy used to reach managed cluster API servers. "+
+			"When not set, HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are honored")
+
+	fs.StringVar(&clusterNoProxy, "cluster-no-proxy", "",
+		"Comma separated list of hosts, domains and CIDRs of the managed clusters reached directly, "+
+			"bypassing cluster-proxy-url. Same format as NO_PROXY")
+
+	fs.BoolVar(&leaderElect, "leader-elect", false,
+		"Enable leader election, so that when running multiple replicas only one of them is active. "+
+			"When shard-key is set, each shard elects its own leader")
+
+	const defaultDrainTimeout = 20
+	fs.DurationVar(&drainTimeout, "drain-timeout", defaultDrainTimeout*time.Second,
+		fmt.Sprintf("On shutdown, the maximum time in-flight deployments are given to complete. Deployments "+
+			"still in progress afterwards are canceled and resumed by the next leader. Should be lower than the "+
+			"pod termination grace period. Default: %d seconds", defaultDrainTimeout))
+
+	const defaultLeaseDuration = 15
+	fs.DurationVar(&leaseDuration, "leader-elect-lease-duration", defaultLeaseDuration*time.Second,
+		fmt.Sprintf("The duration non-leader replicas wait before trying to acquire leadership once the leader "+
+			"stops renewing the lease. Only used when leader-elect is set. Default: %d seconds", defaultLeaseDuration))
+
+	const defaultRenewDeadline = 10
+	fs.DurationVar(&renewDeadline, "leader-elect-renew-deadline", defaultRenewDeadline*time.Second,
+		fmt.Sprintf("The duration the leader keeps retrying to renew the lease before giving up leadership. "+
+			"Must be less than leader-elect-lease-duration. Default: %d seconds", defaultRenewDeadline))
+
+	const defaultRetryPeriod = 2
+	fs.DurationVar(&retryPeriod, "leader-elect-retry-period", defaultRetryPeriod*time.Second,
+		fmt.Sprintf("The interval between attempts to acquire or renew the lease. "+
+			"Default: %d seconds", defaultRetryPeriod))
+}
+
+// validateLeaderElectionFlags verifies lease settings are consistent. Those are otherwise only
+// validated once the manager starts competing for leadership.
+func validateLeaderElectionFlags() error {
+	if !leaderElect {
+		return nil
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader-elect-lease-duration (%s) must be greater than leader-elect-renew-deadline (%s)",
+			leaseDuration, renewDeadline)
+	}
+	if retryPeriod <= 0 || renewDeadline <= retryPeriod {
+		return fmt.Errorf("leader-elect-retry-period (%s) must be positive and less than leader-elect-renew-deadline (%s)",
+			retryPeriod, renewDeadline)
+	}
+	return nil
+}
+
+// getLeaderElectionID returns the name of the Lease used for leader election.
+// Each shard has its own Lease, so deployments managing different shards do not compete.
+func getLeaderElectionID() string {
+	const leaderElectionID = "addon-controller.projectsveltos.io"
+	if shardKey == "" {
+		return leaderElectionID
+	}
+	h := fnv.New32a()
+	h.Write([]byte(shardKey))
+	return fmt.Sprintf("%x.%s", h.Sum32(), leaderElectionID)
 }
 
 func setupIndexes(ctx context.Context, mgr ctrl.Manager) {
@@ -389,6 +689,7 @@ func getProfileReconciler(mgr manager.Manager) *controllers.ProfileReconciler {
 		SetMap:               make(map[corev1.ObjectReference]*libsveltosset.Set),
 		ClusterMap:           make(map[corev1.ObjectReference]*libsveltosset.Set),
 		Profiles:             make(map[corev1.ObjectReference]libsveltosv1alpha1.Selector),
+		ProfileSelectors:     make(map[corev1.ObjectReference][]libsveltosv1alpha1.Selector),
 		ClusterLabels:        make(map[corev1.ObjectReference]map[string]string),
 		Mux:                  sync.Mutex{},
 		ConcurrentReconciles: concurrentReconciles,
@@ -398,36 +699,56 @@ func getProfileReconciler(mgr manager.Manager) *controllers.ProfileReconciler {
 
 func getClusterProfileReconciler(mgr manager.Manager) *controllers.ClusterProfileReconciler {
 	return &controllers.ClusterProfileReconciler{
-		Client:               mgr.GetClient(),
-		Scheme:               mgr.GetScheme(),
-		ClusterSetMap:        make(map[corev1.ObjectReference]*libsveltosset.Set),
-		ClusterMap:           make(map[corev1.ObjectReference]*libsveltosset.Set),
-		ClusterProfiles:      make(map[corev1.ObjectReference]libsveltosv1alpha1.Selector),
-		ClusterLabels:        make(map[corev1.ObjectReference]map[string]string),
-		Mux:                  sync.Mutex{},
-		ConcurrentReconciles: concurrentReconciles,
-		Logger:               ctrl.Log.WithName("clusterprofilereconciler"),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		ClusterSetMap:           make(map[corev1.ObjectReference]*libsveltosset.Set),
+		ClusterMap:              make(map[corev1.ObjectReference]*libsveltosset.Set),
+		ClusterProfiles:         make(map[corev1.ObjectReference]libsveltosv1alpha1.Selector),
+		ClusterProfileSelectors: make(map[corev1.ObjectReference][]libsveltosv1alpha1.Selector),
+		ClusterLabels:           make(map[corev1.ObjectReference]map[string]string),
+		Mux:                     sync.Mutex{},
+		ConcurrentReconciles:    concurrentReconciles,
+		Logger:                  ctrl.Log.WithName("clusterprofilereconciler"),
 	}
 }
 
 func getClusterSummaryReconciler(ctx context.Context, mgr manager.Manager) *controllers.ClusterSummaryReconciler {
-	d := deployer.GetClient(ctx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers)
+	// Deployments are not bound to ctx: on shutdown, in-flight deployments are given a grace
+	// period to complete before being canceled.
+	deployerCtx, cancelDeployer := context.WithCancel(context.Background())
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		controllers.DrainDeploys(drainTimeout, cancelDeployer, ctrl.Log.WithName("drain"))
+		return nil
+	})); err != nil {
+		setupLog.Error(err, "unable to add deployment drain")
+		os.Exit(1)
+	}
+
+	d := deployer.GetClient(deployerCtx, ctrl.Log.WithName("deployer"), mgr.GetClient(), workers)
 	controllers.RegisterFeatures(d, setupLog)
 
 	return &controllers.ClusterSummaryReconciler{
-		Config:               mgr.GetConfig(),
-		Client:               mgr.GetClient(),
-		Scheme:               mgr.GetScheme(),
-		ShardKey:             shardKey,
-		ReportMode:           reportMode,
-		AgentInMgmtCluster:   agentInMgmtCluster,
-		Deployer:             d,
-		ClusterMap:           make(map[corev1.ObjectReference]*libsveltosset.Set),
-		ReferenceMap:         make(map[corev1.ObjectReference]*libsveltosset.Set),
-		PolicyMux:            sync.Mutex{},
-		ConcurrentReconciles: concurrentReconciles,
-		ConflictRetryTime:    conflictRetryTime,
-		Logger:               ctrl.Log.WithName("clustersummaryreconciler"),
+		Config:                  mgr.GetConfig(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		ShardKey:                shardKey,
+		ReportMode:              reportMode,
+		AgentInMgmtCluster:      agentInMgmtCluster,
+		Deployer:                d,
+		ClusterMap:              make(map[corev1.ObjectReference]*libsveltosset.Set),
+		ReferenceMap:            make(map[corev1.ObjectReference]*libsveltosset.Set),
+		PolicyMux:               sync.Mutex{},
+		ConcurrentReconciles:    concurrentReconciles,
+		ConflictRetryTime:       conflictRetryTime,
+		ReferenceDebounce:       referenceDebounce,
+		DriftCollectionInterval: driftCollection,
+		SuccessRequeueAfter:     successRequeueAfter,
+		EventRecorder:           mgr.GetEventRecorderFor(controllers.ClusterEventSource),
+		PrioritizeNewClusters:   newClustersFirst,
+		FairScheduling:          fairScheduling,
+		FairSchedulingWeights:   fairWeights,
+		Logger:                  ctrl.Log.WithName("clustersummaryreconciler"),
 	}
 }
 
@@ -459,6 +780,15 @@ func getClusterSetReconciler(mgr manager.Manager) *controllers.ClusterSetReconci
 	}
 }
 
+func getClusterProfilePlanReconciler(mgr manager.Manager) *controllers.ClusterProfilePlanReconciler {
+	return &controllers.ClusterProfilePlanReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		ConcurrentReconciles: concurrentReconciles,
+		Logger:               ctrl.Log.WithName("clusterprofileplanreconciler"),
+	}
+}
+
 // getDiagnosticsOptions returns metrics options which can be used to configure a Manager.
 func getDiagnosticsOptions() metricsserver.Options {
 	// If "--insecure-diagnostics" is set, serve metrics via http
@@ -472,7 +802,8 @@ func getDiagnosticsOptions() metricsserver.Options {
 
 	// If "--insecure-diagnostics" is not set, serve metrics via https
 	// and with authentication/authorization. As the endpoint is protected,
-	// we also serve pprof endpoints and an endpoint to change the log level.
+	// we also serve pprof endpoints, an endpoint to change the log level and
+	// an endpoint to render resources without applying those.
 	return metricsserver.Options{
 		BindAddress:    diagnosticsAddress,
 		SecureServing:  true,
@@ -485,6 +816,8 @@ func getDiagnosticsOptions() metricsserver.Options {
 			"/debug/pprof/symbol":  http.HandlerFunc(pprof.Symbol),
 			"/debug/pprof/trace":   http.HandlerFunc(pprof.Trace),
 			"/debug/pprof/heap":    pprof.Handler("heap"),
+			// Add handler to render resources a ClusterProfile/Profile would deploy in a cluster
+			controllers.RenderPath: controllers.RenderHandler(),
 		},
 	}
 }
@@ -545,6 +878,12 @@ func startControllersAndWatchers(ctx context.Context, mgr manager.Manager) {
 			os.Exit(1)
 		}
 		watchersForCAPI = append(watchersForCAPI, setReconciler)
+
+		err = getClusterProfilePlanReconciler(mgr).SetupWithManager(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", configv1alpha1.ClusterProfilePlanKind)
+			os.Exit(1)
+		}
 	}
 
 	clusterSummaryReconciler := getClusterSummaryReconciler(ctx, mgr)
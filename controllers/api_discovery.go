@@ -0,0 +1,222 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// discoveryCacheTTL is how long the API resources served by a cluster are cached
+	discoveryCacheTTL = 5 * time.Minute
+
+	// managementClusterDiscoveryKey is the discovery cache key used for the management cluster
+	managementClusterDiscoveryKey = "management-cluster"
+)
+
+var (
+	discoveryCacheMux sync.Mutex
+	// discoveryCache contains, per cluster, the API resources served by the cluster
+	discoveryCache = map[string]*discoveryCacheEntry{}
+)
+
+type discoveryCacheEntry struct {
+	supported map[schema.GroupVersionKind]bool
+	expires   time.Time
+}
+
+// getDiscoveryCacheKey returns the discovery cache key for the cluster resources are deployed to.
+// Different clusters might be reachable at the same address (for instance via a proxy), so the
+// cache is keyed by cluster and not by API server address.
+func getDiscoveryCacheKey(deployingToMgmtCluster bool, clusterSummary *configv1alpha1.ClusterSummary) string {
+	if deployingToMgmtCluster {
+		return managementClusterDiscoveryKey
+	}
+	return fmt.Sprintf("%s:%s/%s", clusterSummary.Spec.ClusterType, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName)
+}
+
+// verifyAPISupport verifies the cluster identified by clusterKey, and reachable with restConfig, serves
+// all GroupVersionKinds of resources. GroupVersionKinds defined by CustomResourceDefinitions contained
+// in resources are considered supported.
+// Returns an error listing all unsupported GroupVersionKinds, if any.
+func verifyAPISupport(clusterKey string, restConfig *rest.Config, resources []*unstructured.Unstructured,
+	logger logr.Logger) error {
+
+	unsupported, err := getUnsupportedGroupVersionKinds(clusterKey, restConfig, resources)
+	if err != nil {
+		return err
+	}
+
+	return getAPISupportError(resources, unsupported, logger)
+}
+
+// getUnsupportedGroupVersionKinds returns the GroupVersionKinds of resources which are not served by the
+// cluster identified by clusterKey. GroupVersionKinds defined by CustomResourceDefinitions contained
+// in resources are considered supported.
+// API resources served by each cluster are cached. Cache is refreshed when it is stale or when a
+// GroupVersionKind is not found.
+func getUnsupportedGroupVersionKinds(clusterKey string, restConfig *rest.Config,
+	resources []*unstructured.Unstructured) (map[schema.GroupVersionKind]bool, error) {
+
+	required := make(map[schema.GroupVersionKind]bool)
+	for i := range resources {
+		required[resources[i].GroupVersionKind()] = true
+	}
+	for _, gvk := range getGroupVersionKindsDefinedByCRDs(resources) {
+		delete(required, gvk)
+	}
+
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	supported, err := getSupportedGroupVersionKinds(clusterKey, restConfig, false)
+	if err != nil {
+		return nil, err
+	}
+	missing := getMissingGroupVersionKinds(required, supported)
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	// Cached result might be outdated (CRDs installed since last discovery)
+	supported, err = getSupportedGroupVersionKinds(clusterKey, restConfig, true)
+	if err != nil {
+		return nil, err
+	}
+	return getMissingGroupVersionKinds(required, supported), nil
+}
+
+// getAPISupportError returns an error listing the GroupVersionKinds of resources which are unsupported.
+// Returns nil if resources contains none of those.
+func getAPISupportError(resources []*unstructured.Unstructured, unsupported map[schema.GroupVersionKind]bool,
+	logger logr.Logger) error {
+
+	missing := make(map[string]bool)
+	for i := range resources {
+		gvk := resources[i].GroupVersionKind()
+		if unsupported[gvk] {
+			missing[fmt.Sprintf("%s %s", gvk.GroupVersion().String(), gvk.Kind)] = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	missingList := make([]string, 0, len(missing))
+	for gvk := range missing {
+		missingList = append(missingList, gvk)
+	}
+	sort.Strings(missingList)
+
+	msg := fmt.Sprintf("cluster does not support %s", strings.Join(missingList, ", "))
+	logger.V(logs.LogInfo).Info(msg)
+	return fmt.Errorf("%s", msg)
+}
+
+func getMissingGroupVersionKinds(required, supported map[schema.GroupVersionKind]bool,
+) map[schema.GroupVersionKind]bool {
+
+	missing := make(map[schema.GroupVersionKind]bool)
+	for gvk := range required {
+		if !supported[gvk] {
+			missing[gvk] = true
+		}
+	}
+	return missing
+}
+
+// getSupportedGroupVersionKinds returns the GroupVersionKinds served by the cluster identified by
+// clusterKey and reachable with restConfig. Unless refresh is set, cached result is returned when available.
+func getSupportedGroupVersionKinds(clusterKey string, restConfig *rest.Config, refresh bool,
+) (map[schema.GroupVersionKind]bool, error) {
+
+	discoveryCacheMux.Lock()
+	entry, ok := discoveryCache[clusterKey]
+	discoveryCacheMux.Unlock()
+	if ok && !refresh && time.Now().Before(entry.expires) {
+		return entry.supported, nil
+	}
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partial results are returned when some groups (for instance aggregated APIs) cannot be discovered.
+	_, resourceLists, err := dc.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	supported := make(map[schema.GroupVersionKind]bool)
+	for i := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceLists[i].GroupVersion)
+		if err != nil {
+			continue
+		}
+		for j := range resourceLists[i].APIResources {
+			supported[gv.WithKind(resourceLists[i].APIResources[j].Kind)] = true
+		}
+	}
+
+	discoveryCacheMux.Lock()
+	discoveryCache[clusterKey] = &discoveryCacheEntry{supported: supported, expires: time.Now().Add(discoveryCacheTTL)}
+	discoveryCacheMux.Unlock()
+
+	return supported, nil
+}
+
+// getGroupVersionKindsDefinedByCRDs returns the GroupVersionKinds defined by all
+// CustomResourceDefinitions contained in resources
+func getGroupVersionKindsDefinedByCRDs(resources []*unstructured.Unstructured) []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0)
+	for i := range resources {
+		if !isCustomResourceDefinition(resources[i]) {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(resources[i].Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(resources[i].Object, "spec", "names", "kind")
+		versions, _, _ := unstructured.NestedSlice(resources[i].Object, "spec", "versions")
+		for j := range versions {
+			v, ok := versions[j].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := v["name"].(string); ok {
+				gvks = append(gvks, schema.GroupVersionKind{Group: group, Version: name, Kind: kind})
+			}
+		}
+	}
+	return gvks
+}
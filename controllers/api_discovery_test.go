@@ -0,0 +1,117 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	serviceMonitor = `apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: %s
+  namespace: default
+spec:
+  selector: {}
+  endpoints: []`
+
+	serviceMonitorCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: servicemonitors.monitoring.coreos.com
+spec:
+  group: monitoring.coreos.com
+  names:
+    kind: ServiceMonitor
+    plural: servicemonitors
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true`
+)
+
+var _ = Describe("API discovery", func() {
+	It("verifyAPISupport returns an error listing unsupported GroupVersionKinds", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		clusterKey := randomString()
+
+		clusterRole, err := utils.GetUnstructured([]byte(fmt.Sprintf(viewClusterRole, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.VerifyAPISupport(clusterKey, testEnv.Config, []*unstructured.Unstructured{clusterRole}, logger)).To(Succeed())
+
+		monitor, err := utils.GetUnstructured([]byte(fmt.Sprintf(serviceMonitor, randomString())))
+		Expect(err).To(BeNil())
+		err = controllers.VerifyAPISupport(clusterKey, testEnv.Config, []*unstructured.Unstructured{clusterRole, monitor}, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("cluster does not support monitoring.coreos.com/v1 ServiceMonitor"))
+
+		// GroupVersionKinds defined by CRDs deployed together are considered supported
+		crd, err := utils.GetUnstructured([]byte(serviceMonitorCRD))
+		Expect(err).To(BeNil())
+		Expect(controllers.VerifyAPISupport(clusterKey, testEnv.Config, []*unstructured.Unstructured{crd, monitor}, logger)).To(Succeed())
+	})
+
+	It("getAPISupportError lists only the unsupported GroupVersionKinds of given resources", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		clusterRole, err := utils.GetUnstructured([]byte(fmt.Sprintf(viewClusterRole, randomString())))
+		Expect(err).To(BeNil())
+		monitor, err := utils.GetUnstructured([]byte(fmt.Sprintf(serviceMonitor, randomString())))
+		Expect(err).To(BeNil())
+
+		unsupported := map[schema.GroupVersionKind]bool{monitor.GroupVersionKind(): true}
+
+		Expect(controllers.GetAPISupportError([]*unstructured.Unstructured{clusterRole}, unsupported, logger)).To(Succeed())
+
+		err = controllers.GetAPISupportError([]*unstructured.Unstructured{clusterRole, monitor}, unsupported, logger)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("cluster does not support monitoring.coreos.com/v1 ServiceMonitor"))
+	})
+
+	It("getDiscoveryCacheKey returns a different key per cluster", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+			},
+		}
+		capiKey := controllers.GetDiscoveryCacheKey(false, clusterSummary)
+
+		clusterSummary.Spec.ClusterType = libsveltosv1alpha1.ClusterTypeSveltos
+		sveltosKey := controllers.GetDiscoveryCacheKey(false, clusterSummary)
+		Expect(sveltosKey).ToNot(Equal(capiKey))
+
+		mgmtKey := controllers.GetDiscoveryCacheKey(true, clusterSummary)
+		Expect(mgmtKey).ToNot(Equal(capiKey))
+		Expect(mgmtKey).ToNot(Equal(sveltosKey))
+	})
+})
@@ -0,0 +1,92 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applyOrder lists well-known kinds in the order they are applied when contained in the same
+// referenced ConfigMap/Secret/Source. Objects are applied following these rules:
+// - Namespaces first, so namespaced objects can be created in them;
+// - then cluster and namespace policies (NetworkPolicy, ResourceQuota, LimitRange, PodDisruptionBudget);
+// - then ServiceAccounts, Secrets and ConfigMaps, which workloads mount or reference;
+// - then storage (StorageClass, PersistentVolume, PersistentVolumeClaim);
+// - then CustomResourceDefinitions, so custom resources defined in them can be applied;
+// - then RBAC (ClusterRole, ClusterRoleBinding, Role, RoleBinding);
+// - then Services, workloads and autoscalers, and finally Ingresses and APIServices;
+// - any other kind, custom resources included, comes last.
+// Objects of the same kind, or of kinds not listed, keep their relative order.
+var applyOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+var applyOrderRank = getApplyOrderRank()
+
+func getApplyOrderRank() map[string]int {
+	rank := make(map[string]int, len(applyOrder))
+	for i := range applyOrder {
+		rank[applyOrder[i]] = i
+	}
+	return rank
+}
+
+// getApplyRank returns the position of kind in applyOrder. Kinds not listed come last.
+func getApplyRank(kind string) int {
+	if rank, ok := applyOrderRank[kind]; ok {
+		return rank
+	}
+	return len(applyOrder)
+}
+
+// sortByApplyOrder sorts objects, in place, following applyOrder. This reduces transient
+// errors caused by objects being applied before the ones they depend on.
+func sortByApplyOrder(objects []*unstructured.Unstructured) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return getApplyRank(objects[i].GetKind()) < getApplyRank(objects[j].GetKind())
+	})
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Apply order", func() {
+	It("sortByApplyOrder applies objects others depend on first", func() {
+		getObject := func(kind, name string) *unstructured.Unstructured {
+			u := &unstructured.Unstructured{}
+			u.SetKind(kind)
+			u.SetName(name)
+			return u
+		}
+
+		objects := []*unstructured.Unstructured{
+			getObject("Deployment", "a"),
+			getObject("MyCustomResource", "b"),
+			getObject("ConfigMap", "c"),
+			getObject("ServiceAccount", "d"),
+			getObject("CustomResourceDefinition", "e"),
+			getObject("Namespace", "f"),
+			getObject("Service", "g"),
+			getObject("MyCustomResource", "h"),
+			getObject("ConfigMap", "i"),
+		}
+
+		controllers.SortByApplyOrder(objects)
+
+		names := make([]string, len(objects))
+		for i := range objects {
+			names[i] = objects[i].GetName()
+		}
+		// Objects of the same, or of an unknown, kind keep their relative order
+		Expect(names).To(Equal([]string{"f", "d", "c", "i", "e", "g", "a", "b", "h"}))
+	})
+})
@@ -0,0 +1,42 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"k8s.io/client-go/util/retry"
+)
+
+var (
+	// applyConflictBackoff is the backoff used when applying a resource to a managed cluster
+	// fails because the resource was modified since it was fetched
+	applyConflictBackoff = retry.DefaultRetry
+)
+
+// SetApplyConflictRetries sets how many times applying a resource to a managed cluster is retried
+// when it fails with a Conflict error (resourceVersion changed since the resource was fetched).
+// Zero disables retries.
+func SetApplyConflictRetries(retries int) {
+	backoff := retry.DefaultRetry
+	backoff.Steps = retries + 1
+	applyConflictBackoff = backoff
+}
+
+// retryOnApplyConflict runs apply and, as long as it fails with a Conflict error, runs it again
+// up to the configured number of retries. Each apply attempt must fetch the resource again.
+func retryOnApplyConflict(apply func() error) error {
+	return retry.RetryOnConflict(applyConflictBackoff, apply)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("ApplyRetry", func() {
+	AfterEach(func() {
+		controllers.SetApplyConflictRetries(4)
+	})
+
+	It("retryOnApplyConflict retries apply after a conflict until it succeeds", func() {
+		conflictErr := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+			randomString(), errors.New("the object has been modified"))
+
+		attempts := 0
+		apply := func() error {
+			attempts++
+			if attempts == 1 {
+				return conflictErr
+			}
+			return nil
+		}
+
+		Expect(controllers.RetryOnApplyConflict(apply)).To(Succeed())
+		Expect(attempts).To(Equal(2))
+
+		// Non conflict errors are not retried
+		attempts = 0
+		Expect(controllers.RetryOnApplyConflict(func() error {
+			attempts++
+			return errors.New(randomString())
+		})).ToNot(Succeed())
+		Expect(attempts).To(Equal(1))
+
+		// With retries disabled, conflict is returned
+		controllers.SetApplyConflictRetries(0)
+		attempts = 0
+		err := controllers.RetryOnApplyConflict(apply)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(attempts).To(Equal(1))
+	})
+})
@@ -0,0 +1,299 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update
+
+// AuditSinkType is the destination of audit records
+type AuditSinkType string
+
+const (
+	// AuditSinkNone disables the audit log
+	AuditSinkNone = AuditSinkType("")
+
+	// AuditSinkLog writes audit records to the controller log
+	AuditSinkLog = AuditSinkType("log")
+
+	// AuditSinkConfigMap writes audit records to a ConfigMap, one per managed cluster, in the
+	// cluster namespace of the management cluster
+	AuditSinkConfigMap = AuditSinkType("configmap")
+
+	// AuditSinkWebhook sends audit records, in JSON format, to an HTTP endpoint
+	AuditSinkWebhook = AuditSinkType("webhook")
+)
+
+const (
+	// AuditActionApply is recorded when a resource is created or updated in a managed cluster
+	AuditActionApply = "Apply"
+
+	// AuditActionDelete is recorded when a resource is deleted from a managed cluster
+	AuditActionDelete = "Delete"
+
+	// auditQueueSize is the number of audit records waiting to be written. When the queue is full
+	// new records are dropped, so the audit log never slows down deployments.
+	auditQueueSize = 1000
+
+	// maxAuditConfigMapEntries is the number of audit records kept in each ConfigMap. Older
+	// records are removed first.
+	maxAuditConfigMapEntries = 500
+
+	auditConfigMapSuffix = "-sveltos-audit"
+	auditWebhookTimeout  = 5 * time.Second
+)
+
+// AuditRecord describes a resource applied to or deleted from a managed cluster
+type AuditRecord struct {
+	Timestamp        metav1.Time `json:"timestamp"`
+	Action           string      `json:"action"`
+	ClusterNamespace string      `json:"clusterNamespace"`
+	ClusterName      string      `json:"clusterName"`
+	ClusterType      string      `json:"clusterType"`
+	Group            string      `json:"group,omitempty"`
+	Version          string      `json:"version"`
+	Kind             string      `json:"kind"`
+	Namespace        string      `json:"namespace,omitempty"`
+	Name             string      `json:"name"`
+	ProfileKind      string      `json:"profileKind,omitempty"`
+	ProfileName      string      `json:"profileName,omitempty"`
+	ClusterSummary   string      `json:"clusterSummary"`
+}
+
+var (
+	auditMux    sync.Mutex
+	auditQueue  chan *AuditRecord
+	auditSink   AuditSinkType
+	auditLogger = ctrl.Log.WithName("audit")
+)
+
+// SetAuditSink enables the audit log. Every resource the deploy/undeploy handlers apply to or delete
+// from a managed cluster is recorded in sink:
+// - log: the controller log;
+// - configmap: a ConfigMap per managed cluster in the management cluster. Only the last
+// maxAuditConfigMapEntries records are kept;
+// - webhook: each record is posted, in JSON format, to webhookURL.
+// Records are written asynchronously.
+func SetAuditSink(ctx context.Context, c client.Client, sink AuditSinkType, webhookURL string) error {
+	switch sink {
+	case AuditSinkNone:
+		return nil
+	case AuditSinkLog, AuditSinkConfigMap:
+	case AuditSinkWebhook:
+		if webhookURL == "" {
+			return fmt.Errorf("audit webhook URL must be set when audit sink is %s", AuditSinkWebhook)
+		}
+	default:
+		return fmt.Errorf("unknown audit sink %q", sink)
+	}
+
+	auditMux.Lock()
+	defer auditMux.Unlock()
+
+	auditSink = sink
+	auditQueue = make(chan *AuditRecord, auditQueueSize)
+	go writeAuditRecords(ctx, c, sink, webhookURL, auditQueue)
+
+	return nil
+}
+
+// recordAudit queues an audit record for object. No-op unless an audit sink is configured.
+func recordAudit(clusterSummary *configv1alpha1.ClusterSummary, action string, object client.Object) {
+	auditMux.Lock()
+	queue := auditQueue
+	auditMux.Unlock()
+
+	if queue == nil {
+		return
+	}
+
+	record := newAuditRecord(clusterSummary, action, object)
+	select {
+	case queue <- record:
+	default:
+		auditLogger.V(logs.LogInfo).Info(fmt.Sprintf("audit queue is full. Dropping record for %s %s/%s",
+			record.Kind, record.Namespace, record.Name))
+	}
+}
+
+func newAuditRecord(clusterSummary *configv1alpha1.ClusterSummary, action string,
+	object client.Object) *AuditRecord {
+
+	gvk := object.GetObjectKind().GroupVersionKind()
+	record := &AuditRecord{
+		Timestamp:        metav1.NewTime(time.Now()),
+		Action:           action,
+		ClusterNamespace: clusterSummary.Spec.ClusterNamespace,
+		ClusterName:      clusterSummary.Spec.ClusterName,
+		ClusterType:      string(clusterSummary.Spec.ClusterType),
+		Group:            gvk.Group,
+		Version:          gvk.Version,
+		Kind:             gvk.Kind,
+		Namespace:        object.GetNamespace(),
+		Name:             object.GetName(),
+		ClusterSummary:   clusterSummary.Name,
+	}
+
+	if profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary); err == nil &&
+		profileOwnerRef != nil {
+
+		record.ProfileKind = profileOwnerRef.Kind
+		record.ProfileName = profileOwnerRef.Name
+	}
+
+	return record
+}
+
+func writeAuditRecords(ctx context.Context, c client.Client, sink AuditSinkType, webhookURL string,
+	queue chan *AuditRecord) {
+
+	httpClient := &http.Client{Timeout: auditWebhookTimeout}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-queue:
+			var err error
+			switch sink {
+			case AuditSinkLog:
+				logAuditRecord(auditLogger, record)
+			case AuditSinkConfigMap:
+				err = storeAuditRecord(ctx, c, record)
+			case AuditSinkWebhook:
+				err = postAuditRecord(ctx, httpClient, webhookURL, record)
+			}
+			if err != nil {
+				auditLogger.V(logs.LogInfo).Info(fmt.Sprintf("failed to write audit record for %s %s/%s: %v",
+					record.Kind, record.Namespace, record.Name, err))
+			}
+		}
+	}
+}
+
+func logAuditRecord(logger logr.Logger, record *AuditRecord) {
+	logger.V(logs.LogInfo).Info("audit",
+		"action", record.Action,
+		"cluster", fmt.Sprintf("%s:%s/%s", record.ClusterType, record.ClusterNamespace, record.ClusterName),
+		"gvk", fmt.Sprintf("%s/%s, Kind=%s", record.Group, record.Version, record.Kind),
+		"resource", fmt.Sprintf("%s/%s", record.Namespace, record.Name),
+		"profile", fmt.Sprintf("%s/%s", record.ProfileKind, record.ProfileName),
+		"time", record.Timestamp.UTC().Format(time.RFC3339))
+}
+
+func getAuditConfigMapName(clusterType, clusterName string) string {
+	return strings.ToLower(clusterType) + nameSeparator + clusterName + auditConfigMapSuffix
+}
+
+// storeAuditRecord adds record to the audit ConfigMap of the managed cluster. ConfigMap keys are
+// ordered by time, so when more than maxAuditConfigMapEntries records are present the oldest ones
+// are removed.
+func storeAuditRecord(ctx context.Context, c client.Client, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%020d", record.Timestamp.UnixNano())
+
+	name := getAuditConfigMapName(record.ClusterType, record.ClusterName)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: record.ClusterNamespace, Name: name}, configMap)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: record.ClusterNamespace,
+					Name:      name,
+				},
+				Data: map[string]string{key: string(data)},
+			}
+			return c.Create(ctx, configMap)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[key] = string(data)
+		trimAuditEntries(configMap.Data, maxAuditConfigMapEntries)
+		return c.Update(ctx, configMap)
+	})
+}
+
+// trimAuditEntries removes the oldest entries from data so that at most max are left
+func trimAuditEntries(data map[string]string, max int) {
+	if len(data) <= max {
+		return
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i := 0; i < len(keys)-max; i++ {
+		delete(data, keys[i])
+	}
+}
+
+func postAuditRecord(ctx context.Context, httpClient *http.Client, webhookURL string, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Audit", func() {
+	var clusterSummary *configv1alpha1.ClusterSummary
+	var clusterRole *rbacv1.ClusterRole
+
+	BeforeEach(func() {
+		clusterProfileName := clusterProfileNamePrefix + randomString()
+		clusterSummary = &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1alpha1.ClusterProfileKind,
+						Name:       clusterProfileName,
+						APIVersion: configv1alpha1.GroupVersion.String(),
+					},
+				},
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterName: randomString(),
+				ClusterType: libsveltosv1alpha1.ClusterTypeCapi,
+			},
+		}
+		clusterSummary.Spec.ClusterNamespace = clusterSummary.Namespace
+
+		clusterRole = &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, clusterRole)).To(Succeed())
+	})
+
+	It("SetAuditSink validates configuration", func() {
+		Expect(controllers.SetAuditSink(context.TODO(), nil, controllers.AuditSinkNone, "")).To(Succeed())
+		Expect(controllers.SetAuditSink(context.TODO(), nil, controllers.AuditSinkType(randomString()), "")).ToNot(Succeed())
+		Expect(controllers.SetAuditSink(context.TODO(), nil, controllers.AuditSinkWebhook, "")).ToNot(Succeed())
+	})
+
+	It("newAuditRecord records resource, cluster and ClusterProfile", func() {
+		record := controllers.NewAuditRecord(clusterSummary, controllers.AuditActionApply, clusterRole)
+		Expect(record.Action).To(Equal(controllers.AuditActionApply))
+		Expect(record.ClusterNamespace).To(Equal(clusterSummary.Spec.ClusterNamespace))
+		Expect(record.ClusterName).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(record.ClusterType).To(Equal(string(libsveltosv1alpha1.ClusterTypeCapi)))
+		Expect(record.Group).To(Equal(rbacv1.GroupName))
+		Expect(record.Version).To(Equal("v1"))
+		Expect(record.Kind).To(Equal("ClusterRole"))
+		Expect(record.Name).To(Equal(clusterRole.Name))
+		Expect(record.ProfileKind).To(Equal(configv1alpha1.ClusterProfileKind))
+		Expect(record.ProfileName).To(Equal(clusterSummary.OwnerReferences[0].Name))
+		Expect(record.ClusterSummary).To(Equal(clusterSummary.Name))
+		Expect(record.Timestamp.IsZero()).To(BeFalse())
+	})
+
+	It("storeAuditRecord appends records to the cluster audit ConfigMap", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		record := controllers.NewAuditRecord(clusterSummary, controllers.AuditActionApply, clusterRole)
+		Expect(controllers.StoreAuditRecord(context.TODO(), c, record)).To(Succeed())
+
+		time.Sleep(time.Millisecond)
+		record = controllers.NewAuditRecord(clusterSummary, controllers.AuditActionDelete, clusterRole)
+		Expect(controllers.StoreAuditRecord(context.TODO(), c, record)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name: controllers.GetAuditConfigMapName(string(clusterSummary.Spec.ClusterType),
+					clusterSummary.Spec.ClusterName),
+			}, configMap)).To(Succeed())
+		Expect(configMap.Data).To(HaveLen(2))
+
+		actions := make([]string, 0)
+		for k := range configMap.Data {
+			stored := &controllers.AuditRecord{}
+			Expect(json.Unmarshal([]byte(configMap.Data[k]), stored)).To(Succeed())
+			Expect(stored.Name).To(Equal(clusterRole.Name))
+			actions = append(actions, stored.Action)
+		}
+		Expect(actions).To(ConsistOf(controllers.AuditActionApply, controllers.AuditActionDelete))
+	})
+
+	It("trimAuditEntries removes oldest entries", func() {
+		data := map[string]string{}
+		for i := 0; i < 10; i++ {
+			data[fmt.Sprintf("%020d", i)] = randomString()
+		}
+
+		controllers.TrimAuditEntries(data, 4)
+		Expect(data).To(HaveLen(4))
+		for i := 6; i < 10; i++ {
+			Expect(data).To(HaveKey(fmt.Sprintf("%020d", i)))
+		}
+	})
+
+	It("postAuditRecord sends record to webhook", func() {
+		received := make(chan *controllers.AuditRecord, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			record := &controllers.AuditRecord{}
+			if err := json.NewDecoder(r.Body).Decode(record); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received <- record
+		}))
+		defer server.Close()
+
+		record := controllers.NewAuditRecord(clusterSummary, controllers.AuditActionDelete, clusterRole)
+		Expect(controllers.PostAuditRecord(context.TODO(), server.Client(), server.URL, record)).To(Succeed())
+
+		var got *controllers.AuditRecord
+		Eventually(received).Should(Receive(&got))
+		Expect(got.Action).To(Equal(controllers.AuditActionDelete))
+		Expect(got.Kind).To(Equal("ClusterRole"))
+		Expect(got.Name).To(Equal(clusterRole.Name))
+
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+		Expect(controllers.PostAuditRecord(context.TODO(), failing.Client(), failing.URL, record)).ToNot(Succeed())
+	})
+})
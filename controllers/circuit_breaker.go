@@ -0,0 +1,166 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var (
+	// circuitFailureThreshold is the number of consecutive severe failures after which
+	// deployments to a managed cluster are suspended. Zero disables the circuit breaker.
+	circuitFailureThreshold int
+	// circuitCooldown is how long deployments to a managed cluster stay suspended
+	circuitCooldown time.Duration
+
+	clusterCircuitsMux sync.Mutex
+	clusterCircuits    = map[string]*clusterCircuit{}
+)
+
+// clusterCircuit tracks the severe failures of a managed cluster.
+// Circuit is closed while openUntil is zero. Once open, deployments are suspended until openUntil.
+// Afterwards circuit is half-open: a single ClusterSummary (the probe) is allowed to deploy, for at
+// most one more cooldown. Circuit closes as soon as a deployment succeeds and opens again if the
+// probe fails.
+type clusterCircuit struct {
+	failures    int
+	openUntil   time.Time
+	probeHolder string
+}
+
+// SetClusterCircuitBreaker configures the circuit breaker isolating managed clusters which keep
+// failing: after failureThreshold consecutive severe failures, deployments to the cluster are
+// suspended for cooldown. Zero failureThreshold disables the circuit breaker.
+func SetClusterCircuitBreaker(failureThreshold int, cooldown time.Duration) {
+	clusterCircuitsMux.Lock()
+	defer clusterCircuitsMux.Unlock()
+
+	circuitFailureThreshold = failureThreshold
+	circuitCooldown = cooldown
+	clusterCircuits = map[string]*clusterCircuit{}
+}
+
+func getClusterCircuitKey(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType) string {
+	return fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)
+}
+
+// allowClusterDeploy returns true if applicant can deploy to the managed cluster at now.
+// Otherwise it returns false along with the time left before the cooldown ends.
+func allowClusterDeploy(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType,
+	applicant string, now time.Time) (bool, time.Duration) {
+
+	clusterCircuitsMux.Lock()
+	defer clusterCircuitsMux.Unlock()
+
+	if circuitFailureThreshold <= 0 {
+		return true, 0
+	}
+
+	circuit, ok := clusterCircuits[getClusterCircuitKey(clusterNamespace, clusterName, clusterType)]
+	if !ok || circuit.openUntil.IsZero() {
+		return true, 0
+	}
+
+	if now.Before(circuit.openUntil) {
+		if circuit.probeHolder != "" && circuit.probeHolder == applicant {
+			return true, 0
+		}
+		return false, circuit.openUntil.Sub(now)
+	}
+
+	// Cooldown is over (or previous probe never reported back). Let applicant probe the cluster.
+	circuit.probeHolder = applicant
+	circuit.openUntil = now.Add(circuitCooldown)
+	return true, 0
+}
+
+// recordClusterSevereFailure records a severe failure deploying to the managed cluster.
+// Returns true if the failure opened the circuit.
+func recordClusterSevereFailure(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType,
+	now time.Time) bool {
+
+	clusterCircuitsMux.Lock()
+	defer clusterCircuitsMux.Unlock()
+
+	if circuitFailureThreshold <= 0 {
+		return false
+	}
+
+	key := getClusterCircuitKey(clusterNamespace, clusterName, clusterType)
+	circuit, ok := clusterCircuits[key]
+	if !ok {
+		circuit = &clusterCircuit{}
+		clusterCircuits[key] = circuit
+	}
+
+	circuit.failures++
+	if circuit.openUntil.IsZero() && circuit.failures < circuitFailureThreshold {
+		return false
+	}
+
+	// Either threshold is reached or the probe failed
+	circuit.openUntil = now.Add(circuitCooldown)
+	circuit.probeHolder = ""
+	return true
+}
+
+// recordClusterSuccess records a successful deployment to the managed cluster, closing the circuit
+func recordClusterSuccess(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType) {
+	clusterCircuitsMux.Lock()
+	defer clusterCircuitsMux.Unlock()
+
+	delete(clusterCircuits, getClusterCircuitKey(clusterNamespace, clusterName, clusterType))
+}
+
+// getClusterCircuitFailures returns the number of consecutive severe failures recorded for the managed cluster
+func getClusterCircuitFailures(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType) int {
+	clusterCircuitsMux.Lock()
+	defer clusterCircuitsMux.Unlock()
+
+	if circuit, ok := clusterCircuits[getClusterCircuitKey(clusterNamespace, clusterName, clusterType)]; ok {
+		return circuit.failures
+	}
+	return 0
+}
+
+// updateCircuitOpenCondition sets the CircuitOpen condition when deployments to the managed cluster
+// are suspended and removes it otherwise.
+func updateCircuitOpenCondition(clusterSummary *configv1alpha1.ClusterSummary, open bool, retryAfter time.Duration) {
+	if !open {
+		meta.RemoveStatusCondition(&clusterSummary.Status.Conditions, configv1alpha1.ClusterSummaryCircuitOpenCondition)
+		return
+	}
+
+	failures := getClusterCircuitFailures(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Spec.ClusterType)
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, metav1.Condition{
+		Type:   configv1alpha1.ClusterSummaryCircuitOpenCondition,
+		Status: metav1.ConditionTrue,
+		Reason: configv1alpha1.ClusterSummaryCircuitOpenReason,
+		Message: fmt.Sprintf("deployments to cluster suspended after %d consecutive severe failures. Retrying in %s",
+			failures, retryAfter.Round(time.Second)),
+		ObservedGeneration: clusterSummary.Generation,
+	})
+}
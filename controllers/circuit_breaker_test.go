@@ -0,0 +1,125 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	const (
+		threshold = 3
+		cooldown  = time.Minute
+	)
+
+	var clusterNamespace string
+	var clusterName string
+	clusterType := libsveltosv1alpha1.ClusterTypeSveltos
+
+	BeforeEach(func() {
+		clusterNamespace = randomString()
+		clusterName = randomString()
+		controllers.SetClusterCircuitBreaker(threshold, cooldown)
+	})
+
+	AfterEach(func() {
+		controllers.SetClusterCircuitBreaker(0, 0)
+	})
+
+	It("allowClusterDeploy always allows deployments when circuit breaker is disabled", func() {
+		controllers.SetClusterCircuitBreaker(0, cooldown)
+		now := time.Now()
+		for i := 0; i < 2*threshold; i++ {
+			Expect(controllers.RecordClusterSevereFailure(clusterNamespace, clusterName, clusterType, now)).To(BeFalse())
+		}
+		allowed, _ := controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, randomString(), now)
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("circuit opens after threshold severe failures and is half-open after cooldown", func() {
+		now := time.Now()
+		for i := 0; i < threshold-1; i++ {
+			Expect(controllers.RecordClusterSevereFailure(clusterNamespace, clusterName, clusterType, now)).To(BeFalse())
+		}
+		allowed, _ := controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, randomString(), now)
+		Expect(allowed).To(BeTrue())
+
+		Expect(controllers.RecordClusterSevereFailure(clusterNamespace, clusterName, clusterType, now)).To(BeTrue())
+		allowed, retryAfter := controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType,
+			randomString(), now.Add(time.Second))
+		Expect(allowed).To(BeFalse())
+		Expect(retryAfter).To(Equal(cooldown - time.Second))
+
+		// Other clusters are not affected
+		allowed, _ = controllers.AllowClusterDeploy(randomString(), clusterName, clusterType, randomString(), now)
+		Expect(allowed).To(BeTrue())
+
+		// After cooldown a single applicant probes the cluster
+		probe := randomString()
+		afterCooldown := now.Add(cooldown)
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, probe, afterCooldown)
+		Expect(allowed).To(BeTrue())
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, randomString(),
+			afterCooldown)
+		Expect(allowed).To(BeFalse())
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, probe, afterCooldown)
+		Expect(allowed).To(BeTrue())
+
+		// Probe fails: circuit opens again
+		Expect(controllers.RecordClusterSevereFailure(clusterNamespace, clusterName, clusterType,
+			afterCooldown)).To(BeTrue())
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, probe, afterCooldown)
+		Expect(allowed).To(BeFalse())
+
+		// Probe succeeds: circuit closes
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, probe,
+			afterCooldown.Add(cooldown))
+		Expect(allowed).To(BeTrue())
+		controllers.RecordClusterSuccess(clusterNamespace, clusterName, clusterType)
+		allowed, _ = controllers.AllowClusterDeploy(clusterNamespace, clusterName, clusterType, randomString(),
+			afterCooldown.Add(cooldown))
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("updateCircuitOpenCondition sets and removes the CircuitOpen condition", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: clusterNamespace,
+				ClusterName:      clusterName,
+				ClusterType:      clusterType,
+			},
+		}
+
+		controllers.UpdateCircuitOpenCondition(clusterSummary, true, cooldown)
+		condition := meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1alpha1.ClusterSummaryCircuitOpenCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(configv1alpha1.ClusterSummaryCircuitOpenReason))
+
+		controllers.UpdateCircuitOpenCondition(clusterSummary, false, 0)
+		Expect(meta.FindStatusCondition(clusterSummary.Status.Conditions,
+			configv1alpha1.ClusterSummaryCircuitOpenCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+const (
+	// caBundleKey is the key, in the referenced Secret/ConfigMap, containing the PEM encoded CA bundle
+	caBundleKey = "ca.crt"
+)
+
+var (
+	caBundleMux sync.Mutex
+	// caBundleRef references the Secret/ConfigMap, in the management cluster, containing additional
+	// CAs trusted when connecting to managed clusters. Nil when not set.
+	caBundleRef *corev1.ObjectReference
+)
+
+// SetClusterCABundle configures an additional CA bundle trusted when connecting to managed cluster API
+// servers. Bundle is read from the key ca.crt of the Secret or ConfigMap namespace/name in the management
+// cluster and merged with the CA contained in the cluster kubeconfig.
+// An empty name disables it.
+func SetClusterCABundle(kind, namespace, name string) error {
+	caBundleMux.Lock()
+	defer caBundleMux.Unlock()
+
+	if name == "" {
+		caBundleRef = nil
+		return nil
+	}
+
+	if kind != string(libsveltosv1alpha1.SecretReferencedResourceKind) &&
+		kind != string(libsveltosv1alpha1.ConfigMapReferencedResourceKind) {
+
+		return fmt.Errorf("unsupported CA bundle kind %q. Supported kinds: %s, %s", kind,
+			libsveltosv1alpha1.SecretReferencedResourceKind, libsveltosv1alpha1.ConfigMapReferencedResourceKind)
+	}
+	if namespace == "" {
+		return fmt.Errorf("CA bundle namespace must be set")
+	}
+
+	caBundleRef = &corev1.ObjectReference{Kind: kind, Namespace: namespace, Name: name}
+	return nil
+}
+
+func getClusterCABundleRef() *corev1.ObjectReference {
+	caBundleMux.Lock()
+	defer caBundleMux.Unlock()
+
+	return caBundleRef
+}
+
+// getClusterCABundle returns the additional CA bundle trusted when connecting to managed clusters.
+// Returns nil if none is configured. An error is returned if bundle is missing or does not contain
+// valid PEM encoded certificates.
+func getClusterCABundle(ctx context.Context, c client.Client) ([]byte, error) {
+	ref := getClusterCABundleRef()
+	if ref == nil {
+		return nil, nil
+	}
+
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	var bundle []byte
+	if ref.Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle Secret %s: %w", key, err)
+		}
+		bundle = secret.Data[caBundleKey]
+	} else {
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, key, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get CA bundle ConfigMap %s: %w", key, err)
+		}
+		bundle = []byte(configMap.Data[caBundleKey])
+	}
+
+	if len(bundle) == 0 {
+		return nil, fmt.Errorf("%s %s does not contain key %s", ref.Kind, key, caBundleKey)
+	}
+	if _, err := certutil.ParseCertsPEM(bundle); err != nil {
+		return nil, fmt.Errorf("invalid CA bundle in %s %s: %w", ref.Kind, key, err)
+	}
+
+	return bundle, nil
+}
+
+// applyClusterCABundle adds bundle to the CAs restConfig trusts. CAs contained in the kubeconfig
+// are kept. When restConfig skips TLS verification, it is returned unchanged.
+func applyClusterCABundle(restConfig *rest.Config, bundle []byte) (*rest.Config, error) {
+	if restConfig == nil || len(bundle) == 0 || restConfig.Insecure {
+		return restConfig, nil
+	}
+
+	// Loads CAFile, if any, into CAData
+	if err := rest.LoadTLSFiles(restConfig); err != nil {
+		return nil, err
+	}
+
+	caData := make([]byte, 0, len(restConfig.CAData)+len(bundle)+1)
+	caData = append(caData, restConfig.CAData...)
+	if len(caData) != 0 && caData[len(caData)-1] != '\n' {
+		caData = append(caData, '\n')
+	}
+	restConfig.CAData = append(caData, bundle...)
+	restConfig.CAFile = ""
+
+	return restConfig, nil
+}
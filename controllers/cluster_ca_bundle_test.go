@@ -0,0 +1,120 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Cluster CA bundle", func() {
+	var caBundle []byte
+
+	BeforeEach(func() {
+		var err error
+		caBundle, _, err = certutil.GenerateSelfSignedCertKey(randomString(), nil, nil)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(controllers.SetClusterCABundle("", "", "")).To(Succeed())
+	})
+
+	It("SetClusterCABundle validates the kind", func() {
+		Expect(controllers.SetClusterCABundle("Deployment", randomString(), randomString())).ToNot(Succeed())
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.SecretReferencedResourceKind),
+			"", randomString())).ToNot(Succeed())
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.SecretReferencedResourceKind),
+			randomString(), randomString())).To(Succeed())
+	})
+
+	It("getClusterCABundle returns nil when no bundle is configured", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		bundle, err := controllers.GetClusterCABundle(context.TODO(), c)
+		Expect(err).To(BeNil())
+		Expect(bundle).To(BeNil())
+	})
+
+	It("getClusterCABundle reads the bundle from a ConfigMap or a Secret", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Data:       map[string]string{"ca.crt": string(caBundle)},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Data:       map[string][]byte{"ca.crt": caBundle},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, secret).Build()
+
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			configMap.Namespace, configMap.Name)).To(Succeed())
+		bundle, err := controllers.GetClusterCABundle(context.TODO(), c)
+		Expect(err).To(BeNil())
+		Expect(bundle).To(Equal(caBundle))
+
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.SecretReferencedResourceKind),
+			secret.Namespace, secret.Name)).To(Succeed())
+		bundle, err = controllers.GetClusterCABundle(context.TODO(), c)
+		Expect(err).To(BeNil())
+		Expect(bundle).To(Equal(caBundle))
+	})
+
+	It("getClusterCABundle returns an error when bundle does not parse", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Data:       map[string]string{"ca.crt": randomString()},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			configMap.Namespace, configMap.Name)).To(Succeed())
+		_, err := controllers.GetClusterCABundle(context.TODO(), c)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("applyClusterCABundle merges bundle with kubeconfig CA", func() {
+		kubeconfigCA, _, err := certutil.GenerateSelfSignedCertKey(randomString(), nil, nil)
+		Expect(err).To(BeNil())
+
+		restConfig := &rest.Config{Host: "https://" + randomString()}
+		restConfig.CAData = kubeconfigCA
+		restConfig, err = controllers.ApplyClusterCABundle(restConfig, caBundle)
+		Expect(err).To(BeNil())
+
+		certs, err := certutil.ParseCertsPEM(restConfig.CAData)
+		Expect(err).To(BeNil())
+		Expect(len(certs)).To(Equal(2))
+
+		// Insecure configs are left untouched
+		insecure := &rest.Config{Host: "https://" + randomString()}
+		insecure.Insecure = true
+		insecure, err = controllers.ApplyClusterCABundle(insecure, caBundle)
+		Expect(err).To(BeNil())
+		Expect(insecure.CAData).To(BeNil())
+	})
+})
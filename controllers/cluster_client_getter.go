@@ -0,0 +1,138 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+// ClusterClientGetter gives access to the clusters add-ons and applications are deployed to.
+// c is the management cluster client. When adminName is set, returned restConfig, client and
+// kubeconfig must impersonate the adminNamespace/adminName ServiceAccount.
+type ClusterClientGetter interface {
+	// GetRestConfig returns the restConfig to access the cluster
+	GetRestConfig(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+		adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+	) (*rest.Config, error)
+
+	// GetClient returns the client to access the cluster
+	GetClient(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+		adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+	) (client.Client, error)
+
+	// GetKubeconfig returns the kubeconfig to access the cluster
+	GetKubeconfig(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+		adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+	) ([]byte, error)
+}
+
+var (
+	clusterClientGetterMux sync.RWMutex
+	clusterClientGetter    ClusterClientGetter = &defaultClusterClientGetter{}
+)
+
+// SetClusterClientGetter sets how clusters add-ons and applications are deployed to are accessed.
+// Passing nil restores the default ClusterClientGetter, which accesses CAPI Clusters and SveltosClusters
+// using their kubeconfig Secret (or the management cluster in single cluster mode).
+func SetClusterClientGetter(getter ClusterClientGetter) {
+	clusterClientGetterMux.Lock()
+	defer clusterClientGetterMux.Unlock()
+
+	if getter == nil {
+		getter = &defaultClusterClientGetter{}
+	}
+	clusterClientGetter = getter
+}
+
+func getClusterClientGetter() ClusterClientGetter {
+	clusterClientGetterMux.RLock()
+	defer clusterClientGetterMux.RUnlock()
+
+	return clusterClientGetter
+}
+
+// defaultClusterClientGetter accesses CAPI Clusters and SveltosClusters using the kubeconfig
+// stored in their Secret. In single cluster mode, the management cluster is accessed instead.
+type defaultClusterClientGetter struct{}
+
+// GetRestConfig returns a restConfig subject to the per cluster rate limits. For managed clusters,
+// it trusts the additional CA bundle and goes through the proxy, if any is configured.
+func (g *defaultClusterClientGetter) GetRestConfig(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger) (*rest.Config, error) {
+
+	if !singleClusterMode {
+		restConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
+			adminNamespace, adminName, clusterType, logger)
+		if err != nil {
+			return nil, err
+		}
+		caBundle, err := getClusterCABundle(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		restConfig, err = applyClusterCABundle(restConfig, caBundle)
+		if err != nil {
+			return nil, err
+		}
+		restConfig = applyClusterProxy(restConfig)
+		return applyClusterRateLimits(restConfig, clusterNamespace, clusterName, clusterType), nil
+	}
+
+	restConfig := rest.CopyConfig(getManagementClusterConfig())
+	if adminName != "" {
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", adminNamespace, adminName),
+		}
+	}
+	return applyClusterRateLimits(restConfig, clusterNamespace, clusterName, clusterType), nil
+}
+
+func (g *defaultClusterClientGetter) GetClient(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger) (client.Client, error) {
+
+	restConfig, err := g.GetRestConfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: c.Scheme()})
+}
+
+// GetKubeconfig returns a kubeconfig equivalent to the restConfig returned by GetRestConfig, so that
+// Helm trusts the same CAs as every other client.
+func (g *defaultClusterClientGetter) GetKubeconfig(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, adminNamespace, adminName string,
+	clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger) ([]byte, error) {
+
+	restConfig, err := g.GetRestConfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+	return restConfigToKubeconfig(restConfig)
+}
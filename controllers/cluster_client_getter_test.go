@@ -0,0 +1,153 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+// staticClusterClientGetter gives access to every cluster with same restConfig and client
+type staticClusterClientGetter struct {
+	restConfig *rest.Config
+	client     client.Client
+}
+
+func (g *staticClusterClientGetter) GetRestConfig(_ context.Context, _ client.Client, _, _, _, _ string,
+	_ libsveltosv1alpha1.ClusterType, _ logr.Logger) (*rest.Config, error) {
+
+	return g.restConfig, nil
+}
+
+func (g *staticClusterClientGetter) GetClient(_ context.Context, _ client.Client, _, _, _, _ string,
+	_ libsveltosv1alpha1.ClusterType, _ logr.Logger) (client.Client, error) {
+
+	return g.client, nil
+}
+
+func (g *staticClusterClientGetter) GetKubeconfig(_ context.Context, _ client.Client, _, _, _, _ string,
+	_ libsveltosv1alpha1.ClusterType, _ logr.Logger) ([]byte, error) {
+
+	return nil, nil
+}
+
+var _ = Describe("ClusterClientGetter", func() {
+	AfterEach(func() {
+		controllers.SetClusterClientGetter(nil)
+	})
+
+	It("SetClusterClientGetter replaces how managed clusters are accessed", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		getter := &staticClusterClientGetter{
+			restConfig: &rest.Config{Host: randomString()},
+			client:     fake.NewClientBuilder().WithScheme(scheme).Build(),
+		}
+
+		clusterNamespace := randomString()
+		clusterName := randomString()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// Default getter looks for the cluster kubeconfig, which does not exist
+		_, err := controllers.GetKubernetesClient(context.TODO(), c, clusterNamespace, clusterName, "", "",
+			libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).ToNot(BeNil())
+
+		controllers.SetClusterClientGetter(getter)
+
+		remoteClient, err := controllers.GetKubernetesClient(context.TODO(), c, clusterNamespace, clusterName, "", "",
+			libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(remoteClient).To(Equal(getter.client))
+
+		restConfig, err := controllers.GetKubernetesRestConfig(context.TODO(), c, clusterNamespace, clusterName, "", "",
+			libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(restConfig).To(Equal(getter.restConfig))
+
+		controllers.SetClusterClientGetter(nil)
+		_, err = controllers.GetKubernetesClient(context.TODO(), c, clusterNamespace, clusterName, "", "",
+			libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("default getter returns a kubeconfig trusting the additional CA bundle", func() {
+		kubeconfigCA, _, err := certutil.GenerateSelfSignedCertKey(randomString(), nil, nil)
+		Expect(err).To(BeNil())
+		caBundle, _, err := certutil.GenerateSelfSignedCertKey(randomString(), nil, nil)
+		Expect(err).To(BeNil())
+
+		restConfig := &rest.Config{Host: "https://" + randomString(), BearerToken: randomString()}
+		restConfig.CAData = kubeconfigCA
+		kubeconfig, err := controllers.RestConfigToKubeconfig(restConfig)
+		Expect(err).To(BeNil())
+
+		sveltosCluster := &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+		}
+		kubeconfigSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sveltosCluster.Namespace,
+				Name:      fmt.Sprintf("%s-sveltos-kubeconfig", sveltosCluster.Name),
+			},
+			Data: map[string][]byte{"value": kubeconfig},
+		}
+		caBundleConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Data:       map[string]string{"ca.crt": string(caBundle)},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(sveltosCluster, kubeconfigSecret, caBundleConfigMap).Build()
+
+		Expect(controllers.SetClusterCABundle(string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			caBundleConfigMap.Namespace, caBundleConfigMap.Name)).To(Succeed())
+		defer func() {
+			Expect(controllers.SetClusterCABundle("", "", "")).To(Succeed())
+		}()
+
+		helmKubeconfig, err := controllers.GetKubeconfigContent(context.TODO(), c, sveltosCluster.Namespace,
+			sveltosCluster.Name, "", "", libsveltosv1alpha1.ClusterTypeSveltos, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		helmRestConfig, err := clientcmd.RESTConfigFromKubeConfig(helmKubeconfig)
+		Expect(err).To(BeNil())
+		Expect(helmRestConfig.Host).To(Equal(restConfig.Host))
+		Expect(helmRestConfig.BearerToken).To(Equal(restConfig.BearerToken))
+		kubeconfigCerts, err := certutil.ParseCertsPEM(kubeconfigCA)
+		Expect(err).To(BeNil())
+		caBundleCerts, err := certutil.ParseCertsPEM(caBundle)
+		Expect(err).To(BeNil())
+		certs, err := certutil.ParseCertsPEM(helmRestConfig.CAData)
+		Expect(err).To(BeNil())
+		Expect(len(certs)).To(Equal(len(kubeconfigCerts) + len(caBundleCerts)))
+	})
+})
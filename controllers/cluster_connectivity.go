@@ -0,0 +1,106 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// connectivityProbeInterval is the minimum interval between two connectivity probes
+	// of the same managed cluster
+	connectivityProbeInterval = time.Minute
+
+	// connectivityProbeTimeout bounds how long the connectivity probe waits for the
+	// managed cluster API server
+	connectivityProbeTimeout = 5 * time.Second
+)
+
+// shouldProbeConnectivity returns true if the managed cluster was never probed or
+// was last probed more than connectivityProbeInterval ago
+func shouldProbeConnectivity(clusterSummary *configv1alpha1.ClusterSummary, now time.Time) bool {
+	connectivity := clusterSummary.Status.Connectivity
+	if connectivity == nil {
+		return true
+	}
+	return now.Sub(connectivity.LastProbeTime.Time) >= connectivityProbeInterval
+}
+
+// probeClusterConnectivity verifies the managed cluster API server can be reached using the
+// cluster kubeconfig and records the outcome in the ClusterSummary status.
+// Probe consists of a single request for the server version.
+func (r *ClusterSummaryReconciler) probeClusterConnectivity(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	now := time.Now()
+	if !shouldProbeConnectivity(clusterSummary, now) {
+		return
+	}
+
+	restConfig, err := getKubernetesRestConfig(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, "", "", clusterSummary.Spec.ClusterType, logger)
+	if err == nil {
+		err = probeAPIServer(restConfig)
+	}
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("managed cluster is not reachable: %v", err))
+	}
+
+	setClusterConnectivity(clusterSummary, now, err)
+}
+
+// probeAPIServer requests the server version to the API server reachable with restConfig
+func probeAPIServer(restConfig *rest.Config) error {
+	restConfig = rest.CopyConfig(restConfig)
+	restConfig.Timeout = connectivityProbeTimeout
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = dc.ServerVersion()
+	return err
+}
+
+// setClusterConnectivity records in the ClusterSummary status the outcome of a connectivity
+// probe. A nil probeErr means the managed cluster is reachable.
+func setClusterConnectivity(clusterSummary *configv1alpha1.ClusterSummary, probeTime time.Time, probeErr error) {
+	connectivity := &configv1alpha1.ConnectivityStatus{
+		Status:        configv1alpha1.ClusterReachable,
+		LastProbeTime: metav1.NewTime(probeTime),
+	}
+	if probeErr != nil {
+		message := probeErr.Error()
+		connectivity.Status = configv1alpha1.ClusterUnreachable
+		connectivity.Message = &message
+	}
+
+	clusterSummary.Status.Connectivity = connectivity
+}
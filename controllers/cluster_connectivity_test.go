@@ -0,0 +1,76 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Cluster connectivity", func() {
+	It("probeAPIServer succeeds only when API server answers", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/version" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"major":"1","minor":"30","gitVersion":"v1.30.0"}`)
+		}))
+		defer server.Close()
+
+		Expect(controllers.ProbeAPIServer(&rest.Config{Host: server.URL})).To(Succeed())
+
+		server.Close()
+		Expect(controllers.ProbeAPIServer(&rest.Config{Host: server.URL})).ToNot(Succeed())
+	})
+
+	It("setClusterConnectivity records the probe outcome", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{}
+		now := time.Now()
+
+		controllers.SetClusterConnectivity(clusterSummary, now, fmt.Errorf("connection refused"))
+		Expect(clusterSummary.Status.Connectivity).ToNot(BeNil())
+		Expect(clusterSummary.Status.Connectivity.Status).To(Equal(configv1alpha1.ClusterUnreachable))
+		Expect(clusterSummary.Status.Connectivity.Message).ToNot(BeNil())
+		Expect(*clusterSummary.Status.Connectivity.Message).To(Equal("connection refused"))
+
+		controllers.SetClusterConnectivity(clusterSummary, now, nil)
+		Expect(clusterSummary.Status.Connectivity.Status).To(Equal(configv1alpha1.ClusterReachable))
+		Expect(clusterSummary.Status.Connectivity.Message).To(BeNil())
+	})
+
+	It("shouldProbeConnectivity limits how often a cluster is probed", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{}
+		now := time.Now()
+		Expect(controllers.ShouldProbeConnectivity(clusterSummary, now)).To(BeTrue())
+
+		controllers.SetClusterConnectivity(clusterSummary, now, nil)
+		Expect(controllers.ShouldProbeConnectivity(clusterSummary, now.Add(time.Second))).To(BeFalse())
+		Expect(controllers.ShouldProbeConnectivity(clusterSummary, now.Add(2*time.Minute))).To(BeTrue())
+	})
+})
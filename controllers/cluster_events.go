@@ -0,0 +1,127 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+const (
+	// ClusterEventSource is the source of the Events emitted on Sveltos/CAPI Clusters
+	ClusterEventSource = "sveltos-addon-controller"
+
+	// ClusterEventFeatureProvisioned is the reason of the Event emitted on a Cluster when a feature
+	// has been successfully deployed
+	ClusterEventFeatureProvisioned = "FeatureProvisioned"
+
+	// ClusterEventFeatureFailed is the reason of the Event emitted on a Cluster when deploying a
+	// feature failed
+	ClusterEventFeatureFailed = "FeatureFailed"
+)
+
+// getFeatureStatuses returns, per feature, the status currently reported by the ClusterSummary
+func getFeatureStatuses(clusterSummary *configv1alpha1.ClusterSummary) map[configv1alpha1.FeatureID]configv1alpha1.FeatureStatus {
+	statuses := make(map[configv1alpha1.FeatureID]configv1alpha1.FeatureStatus)
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		statuses[fs.FeatureID] = fs.Status
+	}
+	return statuses
+}
+
+// recordClusterEvents emits an Event on the Sveltos/CAPI Cluster for each feature that moved to
+// Provisioned or Failed since previousStatuses was taken. This lets users looking at the Cluster
+// see deployment results without knowing about ClusterProfiles/ClusterSummaries.
+func recordClusterEvents(ctx context.Context, c client.Client, recorder record.EventRecorder,
+	clusterSummary *configv1alpha1.ClusterSummary,
+	previousStatuses map[configv1alpha1.FeatureID]configv1alpha1.FeatureStatus, logger logr.Logger) {
+
+	if recorder == nil {
+		return
+	}
+
+	var cluster client.Object
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if previousStatuses[fs.FeatureID] == fs.Status {
+			continue
+		}
+
+		eventType, reason := getClusterEventTypeAndReason(fs.Status)
+		if reason == "" {
+			continue
+		}
+
+		if cluster == nil {
+			var err error
+			cluster, err = clusterproxy.GetCluster(ctx, c, clusterSummary.Spec.ClusterNamespace,
+				clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+			if err != nil {
+				logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to get cluster to record events: %v", err))
+				return
+			}
+		}
+
+		recorder.Event(cluster, eventType, reason, getClusterEventMessage(clusterSummary, fs))
+	}
+}
+
+// getClusterEventTypeAndReason returns the Event type and reason for a feature status.
+// An empty reason means no Event is emitted for status.
+func getClusterEventTypeAndReason(status configv1alpha1.FeatureStatus) (eventType, reason string) {
+	switch status {
+	case configv1alpha1.FeatureStatusProvisioned:
+		return corev1.EventTypeNormal, ClusterEventFeatureProvisioned
+	case configv1alpha1.FeatureStatusFailed, configv1alpha1.FeatureStatusFailedNonRetriable:
+		return corev1.EventTypeWarning, ClusterEventFeatureFailed
+	default:
+		return "", ""
+	}
+}
+
+func getClusterEventMessage(clusterSummary *configv1alpha1.ClusterSummary,
+	fs *configv1alpha1.FeatureSummary) string {
+
+	owner := clusterSummary.Name
+	if profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary); err == nil &&
+		profileOwnerRef != nil {
+
+		owner = fmt.Sprintf("%s %s", profileOwnerRef.Kind, profileOwnerRef.Name)
+	}
+
+	if fs.Status == configv1alpha1.FeatureStatusProvisioned {
+		return fmt.Sprintf("%s: %s provisioned", owner, fs.FeatureID)
+	}
+
+	message := fmt.Sprintf("%s: %s failed", owner, fs.FeatureID)
+	if fs.FailureMessage != nil {
+		message += fmt.Sprintf(": %s", *fs.FailureMessage)
+	}
+	return message
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2/textlogger"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Cluster Events", func() {
+	It("recordClusterEvents emits an Event on the Cluster for each feature provisioned or failed", func() {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		failureMessage := randomString()
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{FeatureID: configv1alpha1.FeatureHelm, Status: configv1alpha1.FeatureStatusProvisioning},
+					{FeatureID: configv1alpha1.FeatureResources, Status: configv1alpha1.FeatureStatusFailed},
+				},
+			},
+		}
+
+		initObjects := []client.Object{cluster}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		previousStatuses := controllers.GetFeatureStatuses(clusterSummary)
+		Expect(previousStatuses[configv1alpha1.FeatureHelm]).To(Equal(configv1alpha1.FeatureStatusProvisioning))
+
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{FeatureID: configv1alpha1.FeatureHelm, Status: configv1alpha1.FeatureStatusProvisioned},
+			{FeatureID: configv1alpha1.FeatureResources, Status: configv1alpha1.FeatureStatusFailed},
+			{FeatureID: configv1alpha1.FeatureKustomize, Status: configv1alpha1.FeatureStatusFailed,
+				FailureMessage: &failureMessage},
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		controllers.RecordClusterEvents(context.TODO(), c, recorder, clusterSummary, previousStatuses,
+			textlogger.NewLogger(textlogger.NewConfig()))
+
+		// Resources was already failed, so no Event is emitted for it
+		Expect(len(recorder.Events)).To(Equal(2))
+		event := <-recorder.Events
+		Expect(event).To(ContainSubstring(corev1.EventTypeNormal))
+		Expect(event).To(ContainSubstring(controllers.ClusterEventFeatureProvisioned))
+		Expect(event).To(ContainSubstring(string(configv1alpha1.FeatureHelm)))
+		event = <-recorder.Events
+		Expect(event).To(ContainSubstring(corev1.EventTypeWarning))
+		Expect(event).To(ContainSubstring(controllers.ClusterEventFeatureFailed))
+		Expect(event).To(ContainSubstring(failureMessage))
+
+		// No change, no Events
+		controllers.RecordClusterEvents(context.TODO(), c, recorder, clusterSummary,
+			controllers.GetFeatureStatuses(clusterSummary), textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(len(recorder.Events)).To(Equal(0))
+	})
+})
@@ -0,0 +1,60 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	// clusterNamespaces, when not empty, contains the only namespaces whose clusters are
+	// considered when computing the clusters matching a ClusterProfile/Profile
+	clusterNamespaces map[string]bool
+)
+
+// SetClusterNamespaces restricts the clusters considered by this controller instance to the
+// ones in namespaces. This allows different controller instances to own clusters in different
+// namespaces of the same management cluster. An empty list means all namespaces.
+func SetClusterNamespaces(namespaces []string) {
+	clusterNamespaces = make(map[string]bool, len(namespaces))
+	for i := range namespaces {
+		if namespaces[i] != "" {
+			clusterNamespaces[namespaces[i]] = true
+		}
+	}
+}
+
+// isClusterNamespaceAllowed returns true if clusters in namespace are considered by this
+// controller instance
+func isClusterNamespaceAllowed(namespace string) bool {
+	return len(clusterNamespaces) == 0 || clusterNamespaces[namespace]
+}
+
+// filterByClusterNamespaces returns the clusters in namespaces considered by this controller instance
+func filterByClusterNamespaces(clusters []corev1.ObjectReference) []corev1.ObjectReference {
+	if len(clusterNamespaces) == 0 {
+		return clusters
+	}
+
+	filtered := make([]corev1.ObjectReference, 0, len(clusters))
+	for i := range clusters {
+		if isClusterNamespaceAllowed(clusters[i].Namespace) {
+			filtered = append(filtered, clusters[i])
+		}
+	}
+	return filtered
+}
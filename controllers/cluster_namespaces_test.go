@@ -0,0 +1,49 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("ClusterNamespaces", func() {
+	AfterEach(func() {
+		controllers.SetClusterNamespaces(nil)
+	})
+
+	It("filterByClusterNamespaces returns only clusters in allowed namespaces", func() {
+		clusters := make([]corev1.ObjectReference, 0)
+		for i := 0; i < 3; i++ {
+			clusters = append(clusters, corev1.ObjectReference{
+				Namespace: randomString(), Name: randomString(),
+				Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+			})
+		}
+
+		// No restriction
+		Expect(controllers.FilterByClusterNamespaces(clusters)).To(Equal(clusters))
+
+		controllers.SetClusterNamespaces([]string{clusters[0].Namespace, clusters[2].Namespace})
+		Expect(controllers.FilterByClusterNamespaces(clusters)).To(Equal(
+			[]corev1.ObjectReference{clusters[0], clusters[2]}))
+	})
+})
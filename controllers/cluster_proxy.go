@@ -0,0 +1,89 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	clusterProxyMux sync.Mutex
+	// clusterProxyFunc returns the proxy to use to reach a managed cluster API server.
+	// Nil when no proxy is configured.
+	clusterProxyFunc func(*url.URL) (*url.URL, error)
+)
+
+// SetClusterProxy configures the proxy used to reach managed cluster API servers.
+// noProxy is a comma separated list of hosts, domains and CIDRs (same format as the NO_PROXY
+// environment variable) of the managed clusters reached directly.
+// When proxyURL is empty, proxy is taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+// environment variables.
+func SetClusterProxy(proxyURL, noProxy string) error {
+	clusterProxyMux.Lock()
+	defer clusterProxyMux.Unlock()
+
+	if proxyURL == "" {
+		clusterProxyFunc = nil
+		return nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid cluster proxy URL %q: %w", proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("unsupported cluster proxy URL scheme %q. Supported schemes: http, https, socks5",
+			u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid cluster proxy URL %q: host must be set", proxyURL)
+	}
+
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    noProxy,
+	}
+	clusterProxyFunc = proxyConfig.ProxyFunc()
+	return nil
+}
+
+// applyClusterProxy configures restConfig to reach the API server through the configured proxy.
+// When none is configured, restConfig is returned unchanged and the proxy, if any, is taken from
+// the environment.
+func applyClusterProxy(restConfig *rest.Config) *rest.Config {
+	clusterProxyMux.Lock()
+	proxyFunc := clusterProxyFunc
+	clusterProxyMux.Unlock()
+
+	if restConfig == nil || proxyFunc == nil {
+		return restConfig
+	}
+
+	restConfig.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	return restConfig
+}
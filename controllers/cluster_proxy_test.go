@@ -0,0 +1,119 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Cluster proxy", func() {
+	AfterEach(func() {
+		Expect(controllers.SetClusterProxy("", "")).To(Succeed())
+	})
+
+	It("SetClusterProxy validates the proxy URL", func() {
+		Expect(controllers.SetClusterProxy("ftp://proxy.example.com", "")).ToNot(Succeed())
+		Expect(controllers.SetClusterProxy("http://", "")).ToNot(Succeed())
+		Expect(controllers.SetClusterProxy("http://proxy.example.com:3128", "")).To(Succeed())
+	})
+
+	It("applyClusterProxy leaves restConfig unchanged when no proxy is configured", func() {
+		restConfig := controllers.ApplyClusterProxy(&rest.Config{Host: "https://cluster.example.com:6443"})
+		Expect(restConfig.Proxy).To(BeNil())
+	})
+
+	It("applyClusterProxy honors the no proxy list", func() {
+		Expect(controllers.SetClusterProxy("http://proxy.example.com:3128",
+			"direct.example.com,10.0.0.0/8")).To(Succeed())
+
+		restConfig := controllers.ApplyClusterProxy(&rest.Config{Host: "https://cluster.example.com:6443"})
+		Expect(restConfig.Proxy).ToNot(BeNil())
+
+		getProxy := func(host string) *url.URL {
+			req, err := http.NewRequest(http.MethodGet, "https://"+host+"/api", http.NoBody)
+			Expect(err).To(BeNil())
+			proxyURL, err := restConfig.Proxy(req)
+			Expect(err).To(BeNil())
+			return proxyURL
+		}
+
+		Expect(getProxy("cluster.example.com:6443")).ToNot(BeNil())
+		Expect(getProxy("cluster.example.com:6443").Host).To(Equal("proxy.example.com:3128"))
+		Expect(getProxy("direct.example.com:6443")).To(BeNil())
+		Expect(getProxy("10.1.2.3:6443")).To(BeNil())
+	})
+
+	It("applyClusterProxy configures the transport to go through the proxy", func() {
+		var mux sync.Mutex
+		var proxiedHosts []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			proxiedHosts = append(proxiedHosts, r.URL.Host)
+			mux.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+
+		Expect(controllers.SetClusterProxy(proxy.URL, "")).To(Succeed())
+
+		restConfig := controllers.ApplyClusterProxy(&rest.Config{Host: "http://cluster.example.com:6443"})
+		httpClient, err := rest.HTTPClientFor(restConfig)
+		Expect(err).To(BeNil())
+
+		resp, err := httpClient.Get("http://cluster.example.com:6443/version")
+		Expect(err).To(BeNil())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		mux.Lock()
+		defer mux.Unlock()
+		Expect(proxiedHosts).To(ConsistOf("cluster.example.com:6443"))
+	})
+	It("restConfigToKubeconfig carries the configured proxy so Helm goes through it", func() {
+		Expect(controllers.SetClusterProxy("http://proxy.example.com:3128", "direct.example.com")).To(Succeed())
+
+		restConfig := controllers.ApplyClusterProxy(&rest.Config{Host: "https://cluster.example.com:6443"})
+		kubeconfig, err := controllers.RestConfigToKubeconfig(restConfig)
+		Expect(err).To(BeNil())
+		config, err := clientcmd.Load(kubeconfig)
+		Expect(err).To(BeNil())
+		Expect(config.Clusters).To(HaveLen(1))
+		for _, cluster := range config.Clusters {
+			Expect(cluster.ProxyURL).To(Equal("http://proxy.example.com:3128"))
+		}
+
+		restConfig = controllers.ApplyClusterProxy(&rest.Config{Host: "https://direct.example.com:6443"})
+		kubeconfig, err = controllers.RestConfigToKubeconfig(restConfig)
+		Expect(err).To(BeNil())
+		config, err = clientcmd.Load(kubeconfig)
+		Expect(err).To(BeNil())
+		for _, cluster := range config.Clusters {
+			Expect(cluster.ProxyURL).To(BeEmpty())
+		}
+	})
+})
@@ -0,0 +1,182 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var (
+	// clusterQPS and clusterBurst limit the requests sent to each managed cluster API server.
+	// All clients accessing same managed cluster share same limits. Zero means no shared limit.
+	clusterQPS   float32
+	clusterBurst int
+	// clusterMaxInflight is the maximum number of concurrent requests to each managed cluster
+	// API server. Zero means no limit.
+	clusterMaxInflight int
+
+	clusterLimitersMux sync.Mutex
+	clusterLimiters    = map[string]*clusterLimiter{}
+	// kubeconfigClusters maps the kubeconfig files handed to Helm to the managed cluster they
+	// give access to, so that Helm requests are subject to the limits of that cluster.
+	kubeconfigClusters = map[string]string{}
+)
+
+// clusterLimiter contains the limits shared by all clients accessing a managed cluster
+type clusterLimiter struct {
+	rateLimiter flowcontrol.RateLimiter
+	inflight    chan struct{}
+}
+
+// SetClusterRateLimits sets the limits applied to the requests sent to each managed cluster API server:
+// - qps and burst configure a token bucket rate limiter;
+// - maxInflight caps the number of concurrent requests.
+// Limits are shared by all clients accessing same managed cluster. Zero disables the corresponding limit.
+func SetClusterRateLimits(qps float32, burst, maxInflight int) {
+	clusterLimitersMux.Lock()
+	defer clusterLimitersMux.Unlock()
+
+	clusterQPS = qps
+	clusterBurst = burst
+	clusterMaxInflight = maxInflight
+	clusterLimiters = map[string]*clusterLimiter{}
+}
+
+func getClusterLimiterKey(clusterNamespace, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) string {
+
+	return fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)
+}
+
+func getClusterLimiter(key string) *clusterLimiter {
+	clusterLimitersMux.Lock()
+	defer clusterLimitersMux.Unlock()
+
+	if l, ok := clusterLimiters[key]; ok {
+		return l
+	}
+
+	l := &clusterLimiter{}
+	if clusterQPS > 0 {
+		burst := clusterBurst
+		if burst < 1 {
+			burst = 1
+		}
+		l.rateLimiter = flowcontrol.NewTokenBucketRateLimiter(clusterQPS, burst)
+	}
+	if clusterMaxInflight > 0 {
+		l.inflight = make(chan struct{}, clusterMaxInflight)
+	}
+	clusterLimiters[key] = l
+	return l
+}
+
+// removeClusterLimiter forgets the limits of a managed cluster. Called when cluster is deleted.
+func removeClusterLimiter(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType) {
+	clusterLimitersMux.Lock()
+	defer clusterLimitersMux.Unlock()
+
+	delete(clusterLimiters, getClusterLimiterKey(clusterNamespace, clusterName, clusterType))
+}
+
+// registerKubeconfigCluster records that kubeconfig gives access to the managed cluster.
+// Helm clients created from kubeconfig are then subject to the limits of that cluster.
+func registerKubeconfigCluster(kubeconfig, clusterNamespace, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) {
+
+	clusterLimitersMux.Lock()
+	defer clusterLimitersMux.Unlock()
+
+	kubeconfigClusters[kubeconfig] = getClusterLimiterKey(clusterNamespace, clusterName, clusterType)
+}
+
+// unregisterKubeconfigCluster must be called when kubeconfig is removed.
+func unregisterKubeconfigCluster(kubeconfig string) {
+	clusterLimitersMux.Lock()
+	defer clusterLimitersMux.Unlock()
+
+	delete(kubeconfigClusters, kubeconfig)
+}
+
+// applyKubeconfigRateLimits configures restConfig, built from kubeconfig, so that requests are
+// subject to the limits of the managed cluster kubeconfig gives access to.
+// restConfig is returned unchanged if kubeconfig was never registered.
+func applyKubeconfigRateLimits(restConfig *rest.Config, kubeconfig string) *rest.Config {
+	clusterLimitersMux.Lock()
+	key, ok := kubeconfigClusters[kubeconfig]
+	clusterLimitersMux.Unlock()
+
+	if !ok {
+		return restConfig
+	}
+	return applyLimiter(restConfig, getClusterLimiter(key))
+}
+
+// applyClusterRateLimits configures restConfig so that requests are subject to the limits of
+// the managed cluster.
+func applyClusterRateLimits(restConfig *rest.Config, clusterNamespace, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) *rest.Config {
+
+	return applyLimiter(restConfig,
+		getClusterLimiter(getClusterLimiterKey(clusterNamespace, clusterName, clusterType)))
+}
+
+func applyLimiter(restConfig *rest.Config, l *clusterLimiter) *rest.Config {
+	if restConfig == nil {
+		return nil
+	}
+
+	if l.rateLimiter != nil {
+		restConfig.RateLimiter = l.rateLimiter
+	}
+	if l.inflight != nil {
+		restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &inflightRoundTripper{delegate: rt, inflight: l.inflight}
+		})
+	}
+
+	return restConfig
+}
+
+// inflightRoundTripper caps the number of concurrent requests. Watch requests are long running
+// and are never counted.
+type inflightRoundTripper struct {
+	delegate http.RoundTripper
+	inflight chan struct{}
+}
+
+func (rt *inflightRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("watch") == "true" {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	select {
+	case rt.inflight <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-rt.inflight }()
+
+	return rt.delegate.RoundTrip(req)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Cluster rate limiter", func() {
+	AfterEach(func() {
+		controllers.SetClusterRateLimits(0, 0, 0)
+	})
+
+	It("applyClusterRateLimits shares rate limiter among all clients of same cluster", func() {
+		controllers.SetClusterRateLimits(10, 20, 0)
+
+		clusterNamespace := randomString()
+		clusterName := randomString()
+
+		config1 := controllers.ApplyClusterRateLimits(&rest.Config{}, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeCapi)
+		config2 := controllers.ApplyClusterRateLimits(&rest.Config{}, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeCapi)
+		config3 := controllers.ApplyClusterRateLimits(&rest.Config{}, randomString(), clusterName,
+			libsveltosv1alpha1.ClusterTypeCapi)
+
+		Expect(config1.RateLimiter).ToNot(BeNil())
+		Expect(config1.RateLimiter).To(BeIdenticalTo(config2.RateLimiter))
+		Expect(config1.RateLimiter).ToNot(BeIdenticalTo(config3.RateLimiter))
+	})
+
+	It("applyClusterRateLimits caps concurrent requests to a cluster", func() {
+		const maxInflight = 2
+		controllers.SetClusterRateLimits(0, 0, maxInflight)
+
+		var current, highest int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				h := atomic.LoadInt32(&highest)
+				if n <= h || atomic.CompareAndSwapInt32(&highest, h, n) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		}))
+		defer server.Close()
+
+		restConfig := controllers.ApplyClusterRateLimits(&rest.Config{Host: server.URL}, randomString(),
+			randomString(), libsveltosv1alpha1.ClusterTypeSveltos)
+		httpClient, err := rest.HTTPClientFor(restConfig)
+		Expect(err).To(BeNil())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3*maxInflight; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				resp, err := httpClient.Get(server.URL)
+				Expect(err).To(BeNil())
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+
+		Expect(atomic.LoadInt32(&highest)).To(BeNumerically("<=", maxInflight))
+	})
+	It("applyKubeconfigRateLimits applies the limits of the cluster kubeconfig was registered for", func() {
+		controllers.SetClusterRateLimits(10, 20, 0)
+
+		clusterNamespace := randomString()
+		clusterName := randomString()
+		kubeconfig := randomString()
+
+		Expect(controllers.ApplyKubeconfigRateLimits(&rest.Config{}, kubeconfig).RateLimiter).To(BeNil())
+
+		controllers.RegisterKubeconfigCluster(kubeconfig, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeCapi)
+		helmConfig := controllers.ApplyKubeconfigRateLimits(&rest.Config{}, kubeconfig)
+		clientConfig := controllers.ApplyClusterRateLimits(&rest.Config{}, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeCapi)
+		Expect(helmConfig.RateLimiter).ToNot(BeNil())
+		Expect(helmConfig.RateLimiter).To(BeIdenticalTo(clientConfig.RateLimiter))
+
+		controllers.UnregisterKubeconfigCluster(kubeconfig)
+		Expect(controllers.ApplyKubeconfigRateLimits(&rest.Config{}, kubeconfig).RateLimiter).To(BeNil())
+	})
+
+	It("removeClusterLimiter forgets the limits of a deleted cluster", func() {
+		controllers.SetClusterRateLimits(10, 20, 0)
+
+		clusterNamespace := randomString()
+		clusterName := randomString()
+
+		config1 := controllers.ApplyClusterRateLimits(&rest.Config{}, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeSveltos)
+		controllers.RemoveClusterLimiter(clusterNamespace, clusterName, libsveltosv1alpha1.ClusterTypeSveltos)
+		config2 := controllers.ApplyClusterRateLimits(&rest.Config{}, clusterNamespace, clusterName,
+			libsveltosv1alpha1.ClusterTypeSveltos)
+		Expect(config2.RateLimiter).ToNot(BeIdenticalTo(config1.RateLimiter))
+	})
+})
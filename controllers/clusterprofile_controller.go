@@ -63,6 +63,9 @@ type ClusterProfileReconciler struct {
 	// key: ClusterProfile; value ClusterProfile Selector
 	ClusterProfiles map[corev1.ObjectReference]libsveltosv1alpha1.Selector
 
+	// key: ClusterProfile; value ClusterProfile ClusterSelectors (OR semantics)
+	ClusterProfileSelectors map[corev1.ObjectReference][]libsveltosv1alpha1.Selector
+
 	// For each cluster contains current labels
 	// This is needed in following scenario:
 	// - ClusterProfile is created
@@ -71,6 +74,9 @@ type ClusterProfileReconciler struct {
 	// we need Cluster labels to know which ClusterProfile to reconcile
 	ClusterLabels map[corev1.ObjectReference]map[string]string
 
+	// Clusters changed since MatchingClusterRefs were last computed
+	clusterChanges clusterChanges
+
 	ctrl controller.Controller
 }
 
@@ -160,21 +166,39 @@ func (r *ClusterProfileReconciler) reconcileNormal(
 		}
 	}
 
-	// Get all clusters matching clusterSelector and ClusterRefs
-	matchingCluster, err := getMatchingClusters(ctx, r.Client, "", profileScope.GetSelector(),
-		profileScope.GetSpec().ClusterRefs, logger)
+	// A malformed selector would match no cluster. Report it and keep current
+	// matching clusters until it is fixed.
+	err := validateSelectors(profileScope)
+	profileScope.SetInvalidSelector(err)
 	if err != nil {
-		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		logger.V(logs.LogInfo).Info(err.Error())
+		return reconcile.Result{}
 	}
 
-	// Get all clusters from referenced ClusterSets
-	clusterSetClusters, err := r.getClustersFromClusterSets(ctx, profileScope.GetSpec().SetRefs, logger)
+	// When only some clusters changed since MatchingClusterRefs were last computed, re-evaluate
+	// those clusters only. Anything else (selectors changed, resync, ...) requires a full recompute.
+	var matchingCluster, excludedCluster []corev1.ObjectReference
+	changed, incremental := r.clusterChanges.take(profileScope)
+	if incremental {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("re-evaluating %d changed clusters", len(changed)))
+		matchingCluster, err = updateMatchingClusters(ctx, r.Client, profileScope, "", changed, logger)
+	} else {
+		matchingCluster, excludedCluster, err = r.computeMatchingClusters(ctx, profileScope, logger)
+	}
 	if err != nil {
+		r.clusterChanges.forget(profileScope)
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
-	matchingCluster = append(matchingCluster, clusterSetClusters...)
+	if !incremental {
+		r.clusterChanges.computed(profileScope)
+	}
 
-	profileScope.SetMatchingClusterRefs(removeDuplicates(matchingCluster))
+	profileScope.SetMatchingClusterRefs(matchingCluster)
+	profileScope.SetExcludedClusterRefs(excludedCluster)
+
+	if err := r.setMatchExplanation(ctx, profileScope, logger); err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+	}
 
 	r.updateMaps(profileScope)
 
@@ -186,10 +210,80 @@ func (r *ClusterProfileReconciler) reconcileNormal(
 	return reconcile.Result{}
 }
 
+// computeMatchingClusters lists all clusters and returns the ones matching the ClusterProfile (targeted)
+// and the ones excluded because of ClusterLimit.
+func (r *ClusterProfileReconciler) computeMatchingClusters(ctx context.Context, profileScope *scope.ProfileScope,
+	logger logr.Logger) (matching, excluded []corev1.ObjectReference, err error) {
+
+	// Get all clusters matching clusterSelector and ClusterRefs
+	matchingCluster, err := getMatchingClusters(ctx, r.Client, "", profileScope.GetSelector(),
+		profileScope.GetSpec().ClusterRefs, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get all clusters matching any of ClusterSelectors
+	selectorsClusters, err := getClustersMatchingAnySelector(ctx, r.Client, "", profileScope.GetSelectors(), logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	matchingCluster = append(matchingCluster, selectorsClusters...)
+
+	// Get all clusters from referenced ClusterSets
+	clusterSetClusters, err := r.getClustersFromClusterSets(ctx, profileScope.GetSpec().SetRefs, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	matchingCluster = append(matchingCluster, clusterSetClusters...)
+
+	// Only clusters in namespaces considered by this controller instance are targeted
+	matchingCluster = filterByClusterNamespaces(removeDuplicates(matchingCluster))
+
+	// Only clusters having all RequiredClusterLabels, if any, are targeted
+	matchingCluster, err = filterByRequiredClusterLabels(ctx, r.Client, matchingCluster,
+		profileScope.GetSpec().RequiredClusterLabels, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only clusters within ClusterLimit, if any, are targeted
+	return limitMatchingClusters(ctx, r.Client, matchingCluster,
+		profileScope.GetSpec().ClusterLimit, logger)
+}
+
+// setMatchExplanation, when the explain annotation is set, reports in status why each cluster
+// is, or is not, matching. Otherwise any previous explanation is removed.
+func (r *ClusterProfileReconciler) setMatchExplanation(ctx context.Context, profileScope *scope.ProfileScope,
+	logger logr.Logger) error {
+
+	if !isExplainRequested(profileScope.Profile) {
+		profileScope.SetMatchExplanation(nil)
+		return nil
+	}
+
+	setClusters, err := r.getClustersFromClusterSets(ctx, profileScope.GetSpec().SetRefs, logger)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := explainMatchingClusters(ctx, r.Client, profileScope, "", setClusters, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to explain matching clusters: %v", err))
+		return err
+	}
+
+	profileScope.SetMatchExplanation(explanation)
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&configv1alpha1.ClusterProfile{}).
+		For(&configv1alpha1.ClusterProfile{},
+			builder.WithPredicates(
+				ProfilePredicates(mgr.GetLogger().WithValues("predicate", "clusterprofilepredicate")),
+			),
+		).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.ConcurrentReconciles,
 		}).
@@ -205,6 +299,10 @@ func (r *ClusterProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SveltosClusterPredicates(mgr.GetLogger().WithValues("predicate", "sveltosclusterpredicate")),
 			),
 		).
+		Watches(&configv1alpha1.ClusterSummary{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueClusterProfileForClusterSummary),
+			builder.WithPredicates(ClusterSummaryDeploymentPredicates()),
+		).
 		Build(r)
 	if err != nil {
 		return errors.Wrap(err, "error creating controller")
@@ -254,7 +352,10 @@ func (r *ClusterProfileReconciler) cleanMaps(profileScope *scope.ProfileScope) {
 
 	clusterProfileInfo := getKeyFromObject(r.Scheme, profileScope.Profile)
 
+	r.clusterChanges.forget(profileScope)
+
 	delete(r.ClusterProfiles, *clusterProfileInfo)
+	delete(r.ClusterProfileSelectors, *clusterProfileInfo)
 
 	// ClusterMap contains for each cluster, list of ClusterProfiles matching
 	// such cluster. Remove ClusterProfile from this map
@@ -308,6 +409,10 @@ func (r *ClusterProfileReconciler) updateMaps(profileScope *scope.ProfileScope)
 	}
 
 	r.ClusterProfiles[*clusterProfileInfo] = profileScope.GetSpec().ClusterSelector
+	if r.ClusterProfileSelectors == nil {
+		r.ClusterProfileSelectors = make(map[corev1.ObjectReference][]libsveltosv1alpha1.Selector)
+	}
+	r.ClusterProfileSelectors[*clusterProfileInfo] = profileScope.GetSpec().ClusterSelectors
 }
 
 func (r *ClusterProfileReconciler) GetController() controller.Controller {
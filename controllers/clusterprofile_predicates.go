@@ -98,6 +98,13 @@ func (p ClusterPredicate) Update(obj event.TypedUpdateEvent[*clusterv1.Cluster])
 		return true
 	}
 
+	// return true if Cluster.Status.InfrastructureReady has changed
+	if oldCluster.Status.InfrastructureReady != newCluster.Status.InfrastructureReady {
+		log.V(logs.LogVerbose).Info(
+			"Cluster InfrastructureReady changed. Will attempt to reconcile associated (Cluster)Profiles/(Cluster)Set.")
+		return true
+	}
+
 	if oldCluster.Status.Phase != string(clusterv1.ClusterPhaseDeleting) &&
 		newCluster.Status.Phase == string(clusterv1.ClusterPhaseDeleting) {
 
@@ -365,3 +372,42 @@ func SetPredicates(logger logr.Logger) predicate.Funcs {
 		},
 	}
 }
+
+// ProfilePredicates predicates for ClusterProfile and Profile. Their reconcilers reconcile a profile
+// only when its spec or metadata changes. Updates only touching status, like the ones the reconcilers
+// themselves make, are ignored.
+func ProfilePredicates(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "updateEvent",
+				"profile", e.ObjectNew.GetName(),
+			)
+
+			if e.ObjectOld == nil {
+				log.V(logs.LogVerbose).Info("Old profile is nil. Reconcile profile.")
+				return true
+			}
+
+			if hasMetadataChanged(e.ObjectOld, e.ObjectNew) {
+				log.V(logs.LogVerbose).Info(
+					"Profile spec or metadata changed. Will attempt to reconcile profile.",
+				)
+				return true
+			}
+
+			// otherwise, return false
+			log.V(logs.LogVerbose).Info(
+				"Profile status only changed. Will not attempt to reconcile profile.")
+			return false
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return CreateFuncTrue(e, logger)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return DeleteFuncTrue(e, logger)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return true
+		},
+	}
+}
@@ -21,11 +21,13 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	"github.com/projectsveltos/addon-controller/controllers"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
 	"github.com/projectsveltos/libsveltos/lib/sharding"
@@ -417,3 +419,50 @@ var _ = Describe("ClusterProfile Predicates: MachinePredicates", func() {
 		Expect(result).To(BeFalse())
 	})
 })
+
+var _ = Describe("ClusterProfile Predicates: ProfilePredicates", func() {
+	var logger logr.Logger
+	var clusterProfile *configv1alpha1.ClusterProfile
+
+	BeforeEach(func() {
+		logger = textlogger.NewLogger(textlogger.NewConfig())
+		clusterProfile = &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       clusterProfileNamePrefix + randomString(),
+				Generation: 1,
+			},
+		}
+	})
+
+	It("Update reprocesses when spec changes", func() {
+		profilePredicate := controllers.ProfilePredicates(logger)
+
+		oldClusterProfile := clusterProfile.DeepCopy()
+		clusterProfile.Generation = 2
+
+		result := profilePredicate.Update(event.UpdateEvent{ObjectNew: clusterProfile, ObjectOld: oldClusterProfile})
+		Expect(result).To(BeTrue())
+	})
+
+	It("Update reprocesses when annotations change", func() {
+		profilePredicate := controllers.ProfilePredicates(logger)
+
+		oldClusterProfile := clusterProfile.DeepCopy()
+		clusterProfile.Annotations = map[string]string{randomString(): randomString()}
+
+		result := profilePredicate.Update(event.UpdateEvent{ObjectNew: clusterProfile, ObjectOld: oldClusterProfile})
+		Expect(result).To(BeTrue())
+	})
+
+	It("Update does not reprocess when only status changes", func() {
+		profilePredicate := controllers.ProfilePredicates(logger)
+
+		oldClusterProfile := clusterProfile.DeepCopy()
+		clusterProfile.Status.MatchingClusterRefs = []corev1.ObjectReference{
+			{Namespace: randomString(), Name: randomString()},
+		}
+
+		result := profilePredicate.Update(event.UpdateEvent{ObjectNew: clusterProfile, ObjectOld: oldClusterProfile})
+		Expect(result).To(BeFalse())
+	})
+})
@@ -49,7 +49,10 @@ func (r *ClusterProfileReconciler) requeueClusterProfileForSveltosCluster(
 
 	addTypeInformationToObject(r.Scheme, o)
 
-	return requeueForCluster(o, r.ClusterProfiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	requests := requeueForCluster(o, r.ClusterProfiles, r.ClusterProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForCluster(o))
+
+	return requests
 }
 
 func (r *ClusterProfileReconciler) requeueClusterProfileForCluster(
@@ -61,7 +64,10 @@ func (r *ClusterProfileReconciler) requeueClusterProfileForCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.ClusterProfiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	requests := requeueForCluster(cluster, r.ClusterProfiles, r.ClusterProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForCluster(cluster))
+
+	return requests
 }
 
 func (r *ClusterProfileReconciler) requeueClusterProfileForMachine(
@@ -73,5 +79,15 @@ func (r *ClusterProfileReconciler) requeueClusterProfileForMachine(
 	r.Mux.Lock()
 	defer r.Mux.Unlock()
 
-	return requeueForMachine(machine, r.ClusterProfiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	requests := requeueForMachine(machine, r.ClusterProfiles, r.ClusterProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ClusterProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForMachine(machine))
+
+	return requests
+}
+
+func (r *ClusterProfileReconciler) requeueClusterProfileForClusterSummary(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForClusterSummary(o, configv1alpha1.ClusterProfileKind)
 }
@@ -138,6 +138,64 @@ var _ = Describe("ClusterProfileReconciler map functions", func() {
 		Expect(requests).To(HaveLen(0))
 	})
 
+	It("requeueClusterProfileForCluster returns ClusterProfiles with any ClusterSelectors matching", func() {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upstreamClusterNamePrefix + randomString(),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"tier": "critical",
+				},
+			},
+		}
+
+		matchingClusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+			Spec: configv1alpha1.Spec{
+				ClusterSelectors: []libsveltosv1alpha1.Selector{"env=prod", "tier=critical"},
+			},
+		}
+
+		nonMatchingClusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+			Spec: configv1alpha1.Spec{
+				ClusterSelectors: []libsveltosv1alpha1.Selector{"env=prod", "tier=low"},
+			},
+		}
+
+		initObjects := []client.Object{
+			matchingClusterProfile,
+			nonMatchingClusterProfile,
+			cluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := &controllers.ClusterProfileReconciler{
+			Client:                  c,
+			Scheme:                  scheme,
+			ClusterMap:              make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ClusterProfiles:         make(map[corev1.ObjectReference]libsveltosv1alpha1.Selector),
+			ClusterProfileSelectors: make(map[corev1.ObjectReference][]libsveltosv1alpha1.Selector),
+			ClusterLabels:           make(map[corev1.ObjectReference]map[string]string),
+			Mux:                     sync.Mutex{},
+		}
+
+		matchingInfo := corev1.ObjectReference{Kind: configv1alpha1.ClusterProfileKind, Name: matchingClusterProfile.Name}
+		reconciler.ClusterProfileSelectors[matchingInfo] = matchingClusterProfile.Spec.ClusterSelectors
+		nonMatchingInfo := corev1.ObjectReference{Kind: configv1alpha1.ClusterProfileKind, Name: nonMatchingClusterProfile.Name}
+		reconciler.ClusterProfileSelectors[nonMatchingInfo] = nonMatchingClusterProfile.Spec.ClusterSelectors
+
+		requests := controllers.RequeueClusterProfileForCluster(reconciler, context.TODO(), cluster)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests).To(ContainElement(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: matchingClusterProfile.Name}}))
+	})
+
 	It("RequeueClusterProfileForMachine returns correct ClusterProfiles for a CAPI machine", func() {
 		cluster := &clusterv1.Cluster{
 			ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,288 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// ClusterProfilePlanReconciler reconciles a ClusterProfilePlan object
+type ClusterProfilePlanReconciler struct {
+	client.Client
+	Scheme               *runtime.Scheme
+	ConcurrentReconciles int
+	Logger               logr.Logger
+}
+
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterprofileplans,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clusterprofileplans/status,verbs=get;update;patch
+
+func (r *ClusterProfilePlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(logs.LogInfo).Info("Reconciling")
+
+	// Fetch the ClusterProfilePlan instance
+	plan := &configv1alpha1.ClusterProfilePlan{}
+	if err := r.Get(ctx, req.NamespacedName, plan); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		logger.Error(err, "Failed to fetch ClusterProfilePlan")
+		return reconcile.Result{}, errors.Wrapf(err,
+			"Failed to fetch ClusterProfilePlan %s", req.NamespacedName)
+	}
+
+	if !plan.DeletionTimestamp.IsZero() || plan.Status.Phase == configv1alpha1.PlanPhaseApplied {
+		// An applied plan is never reevaluated
+		return reconcile.Result{}, nil
+	}
+
+	logger = logger.WithValues("clusterprofile", plan.Spec.ClusterProfileName)
+	status, err := r.evaluatePlan(ctx, plan, logger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(plan.Status, *status) {
+		plan.Status = *status
+		if err := r.Status().Update(ctx, plan); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("Reconcile success. Plan is %s", status.Phase))
+	return reconcile.Result{}, nil
+}
+
+// evaluatePlan returns the plan status. When plan is approved and ready, ClusterProfile is moved out
+// of DryRun mode.
+func (r *ClusterProfilePlanReconciler) evaluatePlan(ctx context.Context, plan *configv1alpha1.ClusterProfilePlan,
+	logger logr.Logger) (*configv1alpha1.ClusterProfilePlanStatus, error) {
+
+	clusterProfile := &configv1alpha1.ClusterProfile{}
+	err := r.Get(ctx, types.NamespacedName{Name: plan.Spec.ClusterProfileName}, clusterProfile)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &configv1alpha1.ClusterProfilePlanStatus{
+				Phase:   configv1alpha1.PlanPhasePending,
+				Message: "ClusterProfile not found",
+			}, nil
+		}
+		return nil, err
+	}
+
+	approvalReset := false
+	if plan.Spec.Approved && plan.Spec.ApprovedGeneration != clusterProfile.Generation {
+		// ClusterProfile changed since plan was approved, or approval does not reference
+		// the reviewed generation. Plan must be reviewed and approved again.
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("approval references ClusterProfile generation %d (current %d). Reset it",
+			plan.Spec.ApprovedGeneration, clusterProfile.Generation))
+		plan.Spec.Approved = false
+		if err := r.Update(ctx, plan); err != nil {
+			return nil, err
+		}
+		approvalReset = true
+	}
+
+	if clusterProfile.Spec.SyncMode != configv1alpha1.SyncModeDryRun {
+		return &configv1alpha1.ClusterProfilePlanStatus{
+			Phase:   configv1alpha1.PlanPhasePending,
+			Message: "ClusterProfile is not in DryRun mode",
+		}, nil
+	}
+
+	status := &configv1alpha1.ClusterProfilePlanStatus{
+		Phase:              configv1alpha1.PlanPhaseReady,
+		ReviewedGeneration: clusterProfile.Generation,
+		Clusters:           make([]configv1alpha1.ClusterPlan, len(clusterProfile.Status.MatchingClusterRefs)),
+	}
+
+	pending := 0
+	for i := range clusterProfile.Status.MatchingClusterRefs {
+		clusterPlan, err := getClusterPlan(ctx, r.Client, clusterProfile, &clusterProfile.Status.MatchingClusterRefs[i])
+		if err != nil {
+			return nil, err
+		}
+		status.Clusters[i] = *clusterPlan
+		if !clusterPlan.Ready {
+			pending++
+		}
+	}
+
+	if pending != 0 {
+		status.Phase = configv1alpha1.PlanPhasePending
+		status.Message = fmt.Sprintf("dry run in progress in %d clusters", pending)
+		return status, nil
+	}
+
+	if approvalReset {
+		status.Message = fmt.Sprintf("approval did not reference ClusterProfile generation %d. Approve plan again",
+			clusterProfile.Generation)
+		return status, nil
+	}
+
+	if plan.Spec.Approved {
+		syncMode := plan.Spec.ApprovedSyncMode
+		if syncMode == "" {
+			syncMode = configv1alpha1.SyncModeContinuous
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("plan approved. Setting ClusterProfile sync mode to %s", syncMode))
+		clusterProfile.Spec.SyncMode = syncMode
+		if err := r.Update(ctx, clusterProfile); err != nil {
+			return nil, err
+		}
+		status.Phase = configv1alpha1.PlanPhaseApplied
+	}
+
+	return status, nil
+}
+
+// getClusterPlan returns the changes ClusterProfile would apply to cluster. Cluster plan is ready
+// once the ClusterSummary has the current ClusterProfile spec and all its features completed the
+// dry run.
+func getClusterPlan(ctx context.Context, c client.Client, clusterProfile *configv1alpha1.ClusterProfile,
+	cluster *corev1.ObjectReference) (*configv1alpha1.ClusterPlan, error) {
+
+	clusterPlan := &configv1alpha1.ClusterPlan{ClusterRef: *cluster}
+	clusterType := clusterproxy.GetClusterType(cluster)
+
+	clusterSummary, err := getClusterSummary(ctx, c, configv1alpha1.ClusterProfileKind, clusterProfile.Name,
+		cluster.Namespace, cluster.Name, clusterType)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return clusterPlan, nil
+		}
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(clusterSummary.Spec.ClusterProfileSpec, clusterProfile.Spec) ||
+		!isCluterSummaryProvisioned(clusterSummary) {
+
+		return clusterPlan, nil
+	}
+
+	clusterReport := &configv1alpha1.ClusterReport{}
+	err = c.Get(ctx, types.NamespacedName{
+		Namespace: cluster.Namespace,
+		Name:      getClusterReportName(configv1alpha1.ClusterProfileKind, clusterProfile.Name, cluster.Name, clusterType),
+	}, clusterReport)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return clusterPlan, nil
+		}
+		return nil, err
+	}
+
+	clusterPlan.Ready = true
+	for i := range clusterReport.Status.ReleaseReports {
+		if clusterReport.Status.ReleaseReports[i].Action != string(configv1alpha1.NoHelmAction) {
+			clusterPlan.ReleaseReports = append(clusterPlan.ReleaseReports, clusterReport.Status.ReleaseReports[i])
+		}
+	}
+	clusterPlan.ResourceReports = getResourceReportsWithChanges(clusterReport.Status.ResourceReports)
+	clusterPlan.KustomizeResourceReports = getResourceReportsWithChanges(clusterReport.Status.KustomizeResourceReports)
+
+	return clusterPlan, nil
+}
+
+// getResourceReportsWithChanges returns the reports for resources which would be created, updated
+// or deleted (or are in conflict)
+func getResourceReportsWithChanges(reports []configv1alpha1.ResourceReport) []configv1alpha1.ResourceReport {
+	var result []configv1alpha1.ResourceReport
+	for i := range reports {
+		if reports[i].Action != string(configv1alpha1.NoResourceAction) {
+			result = append(result, reports[i])
+		}
+	}
+	return result
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterProfilePlanReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&configv1alpha1.ClusterProfilePlan{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.ConcurrentReconciles,
+		}).
+		Watches(&configv1alpha1.ClusterProfile{},
+			handler.EnqueueRequestsFromMapFunc(r.requeuePlansForClusterProfile),
+		).
+		Watches(&configv1alpha1.ClusterSummary{},
+			handler.EnqueueRequestsFromMapFunc(r.requeuePlansForProfileLabel),
+		).
+		Watches(&configv1alpha1.ClusterReport{},
+			handler.EnqueueRequestsFromMapFunc(r.requeuePlansForProfileLabel),
+		).
+		Complete(r)
+}
+
+// requeuePlansForClusterProfile returns all ClusterProfilePlans for the ClusterProfile
+func (r *ClusterProfilePlanReconciler) requeuePlansForClusterProfile(
+	ctx context.Context, o client.Object) []reconcile.Request {
+
+	return r.getPlansForClusterProfile(ctx, o.GetName())
+}
+
+// requeuePlansForProfileLabel returns all ClusterProfilePlans for the ClusterProfile which created o
+// (a ClusterSummary or ClusterReport)
+func (r *ClusterProfilePlanReconciler) requeuePlansForProfileLabel(
+	ctx context.Context, o client.Object) []reconcile.Request {
+
+	clusterProfileName, ok := o.GetLabels()[ClusterProfileLabelName]
+	if !ok {
+		return nil
+	}
+	return r.getPlansForClusterProfile(ctx, clusterProfileName)
+}
+
+func (r *ClusterProfilePlanReconciler) getPlansForClusterProfile(ctx context.Context,
+	clusterProfileName string) []reconcile.Request {
+
+	plans := &configv1alpha1.ClusterProfilePlanList{}
+	if err := r.List(ctx, plans); err != nil {
+		r.Logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to list ClusterProfilePlans: %v", err))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for i := range plans.Items {
+		if plans.Items[i].Spec.ClusterProfileName == clusterProfileName {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: plans.Items[i].Name},
+			})
+		}
+	}
+	return requests
+}
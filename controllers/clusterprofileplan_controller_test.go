@@ -0,0 +1,234 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("ClusterProfilePlan: Reconciler", func() {
+	var clusterProfile *configv1alpha1.ClusterProfile
+	var plan *configv1alpha1.ClusterProfilePlan
+	var cluster *corev1.ObjectReference
+
+	BeforeEach(func() {
+		cluster = &corev1.ObjectReference{
+			Namespace:  randomString(),
+			Name:       randomString(),
+			Kind:       libsveltosv1alpha1.SveltosClusterKind,
+			APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+		}
+
+		clusterProfile = &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       clusterProfileNamePrefix + randomString(),
+				Generation: 2,
+			},
+			Spec: configv1alpha1.Spec{
+				SyncMode: configv1alpha1.SyncModeDryRun,
+				PolicyRefs: []configv1alpha1.PolicyRef{
+					{Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)},
+				},
+			},
+			Status: configv1alpha1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{*cluster},
+			},
+		}
+
+		plan = &configv1alpha1.ClusterProfilePlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Spec: configv1alpha1.ClusterProfilePlanSpec{
+				ClusterProfileName: clusterProfile.Name,
+			},
+		}
+	})
+
+	getClusterSummary := func(featureStatus configv1alpha1.FeatureStatus) *configv1alpha1.ClusterSummary {
+		return &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name: controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind, clusterProfile.Name,
+					cluster.Name, true),
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: clusterProfile.Name,
+					configv1alpha1.ClusterNameLabel:     cluster.Name,
+					configv1alpha1.ClusterTypeLabel:     string(libsveltosv1alpha1.ClusterTypeSveltos),
+				},
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace:   cluster.Namespace,
+				ClusterName:        cluster.Name,
+				ClusterType:        libsveltosv1alpha1.ClusterTypeSveltos,
+				ClusterProfileSpec: clusterProfile.Spec,
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{FeatureID: configv1alpha1.FeatureResources, Status: featureStatus},
+				},
+			},
+		}
+	}
+
+	getClusterReport := func() *configv1alpha1.ClusterReport {
+		return &configv1alpha1.ClusterReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name: controllers.GetClusterReportName(configv1alpha1.ClusterProfileKind, clusterProfile.Name,
+					cluster.Name, libsveltosv1alpha1.ClusterTypeSveltos),
+			},
+			Status: configv1alpha1.ClusterReportStatus{
+				ResourceReports: []configv1alpha1.ResourceReport{
+					{
+						Resource: configv1alpha1.Resource{Name: randomString(), Kind: "ConfigMap", Version: "v1"},
+						Action:   string(configv1alpha1.NoResourceAction),
+					},
+					{
+						Resource: configv1alpha1.Resource{Name: randomString(), Kind: "Secret", Version: "v1"},
+						Action:   string(configv1alpha1.CreateResourceAction),
+					},
+				},
+			},
+		}
+	}
+
+	reconcilePlan := func(c client.Client) *configv1alpha1.ClusterProfilePlan {
+		reconciler := &controllers.ClusterProfilePlanReconciler{
+			Client: c,
+			Scheme: scheme,
+		}
+
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: plan.Name},
+		})
+		Expect(err).To(BeNil())
+
+		currentPlan := &configv1alpha1.ClusterProfilePlan{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: plan.Name}, currentPlan)).To(Succeed())
+		return currentPlan
+	}
+
+	It("Reconcile leaves plan Pending when ClusterProfile is not in DryRun mode", func() {
+		clusterProfile.Spec.SyncMode = configv1alpha1.SyncModeContinuous
+
+		initObjects := []client.Object{clusterProfile, plan}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		currentPlan := reconcilePlan(c)
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhasePending))
+		Expect(currentPlan.Status.Clusters).To(BeEmpty())
+	})
+
+	It("Reconcile leaves plan Pending while dry run is in progress", func() {
+		initObjects := []client.Object{clusterProfile, plan,
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioning)}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		currentPlan := reconcilePlan(c)
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhasePending))
+		Expect(len(currentPlan.Status.Clusters)).To(Equal(1))
+		Expect(currentPlan.Status.Clusters[0].Ready).To(BeFalse())
+	})
+
+	It("Reconcile lists only changes and applies approved plan", func() {
+		clusterReport := getClusterReport()
+		initObjects := []client.Object{clusterProfile, plan, clusterReport,
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioned)}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		currentPlan := reconcilePlan(c)
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhaseReady))
+		Expect(len(currentPlan.Status.Clusters)).To(Equal(1))
+		Expect(currentPlan.Status.Clusters[0].Ready).To(BeTrue())
+		Expect(currentPlan.Status.Clusters[0].ResourceReports).To(ConsistOf(clusterReport.Status.ResourceReports[1]))
+		Expect(currentPlan.Status.ReviewedGeneration).To(Equal(clusterProfile.Generation))
+
+		currentPlan.Spec.Approved = true
+		currentPlan.Spec.ApprovedGeneration = currentPlan.Status.ReviewedGeneration
+		Expect(c.Update(context.TODO(), currentPlan)).To(Succeed())
+
+		currentPlan = reconcilePlan(c)
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhaseApplied))
+
+		currentClusterProfile := &configv1alpha1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		Expect(currentClusterProfile.Spec.SyncMode).To(Equal(configv1alpha1.SyncModeContinuous))
+	})
+
+	It("Reconcile resets approval not referencing the reviewed ClusterProfile generation", func() {
+		plan.Spec.Approved = true
+		initObjects := []client.Object{clusterProfile, plan, getClusterReport(),
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioned)}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		currentPlan := reconcilePlan(c)
+		Expect(currentPlan.Spec.Approved).To(BeFalse())
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhaseReady))
+		Expect(currentPlan.Status.Message).ToNot(BeEmpty())
+
+		currentClusterProfile := &configv1alpha1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		Expect(currentClusterProfile.Spec.SyncMode).To(Equal(configv1alpha1.SyncModeDryRun))
+	})
+
+	It("Reconcile resets approval when ClusterProfile changes after review", func() {
+		initObjects := []client.Object{clusterProfile, plan, getClusterReport(),
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioned)}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		currentPlan := reconcilePlan(c)
+		Expect(currentPlan.Status.Phase).To(Equal(configv1alpha1.PlanPhaseReady))
+
+		currentPlan.Spec.Approved = true
+		currentPlan.Spec.ApprovedGeneration = currentPlan.Status.ReviewedGeneration
+		Expect(c.Update(context.TODO(), currentPlan)).To(Succeed())
+
+		// ClusterProfile changes before plan is reconciled
+		currentClusterProfile := &configv1alpha1.ClusterProfile{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		currentClusterProfile.Generation++
+		Expect(c.Update(context.TODO(), currentClusterProfile)).To(Succeed())
+
+		currentPlan = reconcilePlan(c)
+		Expect(currentPlan.Spec.Approved).To(BeFalse())
+		Expect(currentPlan.Status.Phase).ToNot(Equal(configv1alpha1.PlanPhaseApplied))
+		Expect(currentPlan.Status.ReviewedGeneration).To(Equal(currentClusterProfile.Generation))
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name}, currentClusterProfile)).To(Succeed())
+		Expect(currentClusterProfile.Spec.SyncMode).To(Equal(configv1alpha1.SyncModeDryRun))
+	})
+})
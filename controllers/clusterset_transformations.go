@@ -37,7 +37,7 @@ func (r *ClusterSetReconciler) requeueClusterSetForSveltosCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.ClusterSets, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.ClusterSetKind, r.Logger)
+	return requeueForCluster(cluster, r.ClusterSets, nil, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.ClusterSetKind, r.Logger)
 }
 
 func (r *ClusterSetReconciler) requeueClusterSetForCluster(
@@ -49,5 +49,5 @@ func (r *ClusterSetReconciler) requeueClusterSetForCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.ClusterSets, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.ClusterSetKind, r.Logger)
+	return requeueForCluster(cluster, r.ClusterSets, nil, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.ClusterSetKind, r.Logger)
 }
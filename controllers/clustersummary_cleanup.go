@@ -0,0 +1,95 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+)
+
+// ClusterSummaryCleanupStrategy defines who deletes ClusterSummaries when the
+// owning ClusterProfile/Profile is deleted.
+type ClusterSummaryCleanupStrategy string
+
+const (
+	// ClusterSummaryCleanupFinalizer: the ClusterProfile/Profile controller explicitly deletes
+	// all of its ClusterSummaries and keeps its finalizer until all of them are gone.
+	// Works regardless of the propagation policy used to delete the profile.
+	ClusterSummaryCleanupFinalizer = ClusterSummaryCleanupStrategy("finalizer")
+
+	// ClusterSummaryCleanupOwnerReference: ClusterSummaries are deleted by the Kubernetes garbage
+	// collector. The ClusterSummary OwnerReference is marked as controller and blocks owner deletion.
+	// Garbage collection can only be relied upon when the profile is deleted with foreground
+	// propagation: a ClusterSummary needs its profile to clean managed clusters up, and with
+	// background propagation the garbage collector would remove the profile first. So when the
+	// profile is not being deleted in foreground, ClusterSummaries are still explicitly deleted.
+	// Kubernetes does not allow a cluster-scoped object to be owned by a namespaced one, and a
+	// namespaced owner must be in the same namespace of its dependents. Both constraints hold for
+	// ClusterProfile (cluster-scoped) and Profile (same namespace of the matching clusters).
+	ClusterSummaryCleanupOwnerReference = ClusterSummaryCleanupStrategy("ownerreference")
+)
+
+var (
+	clusterSummaryCleanupStrategy = ClusterSummaryCleanupFinalizer
+)
+
+// SetClusterSummaryCleanupStrategy sets how ClusterSummaries are removed when the owning
+// ClusterProfile/Profile is deleted.
+func SetClusterSummaryCleanupStrategy(strategy ClusterSummaryCleanupStrategy) error {
+	switch strategy {
+	case ClusterSummaryCleanupFinalizer, ClusterSummaryCleanupOwnerReference:
+		clusterSummaryCleanupStrategy = strategy
+		return nil
+	default:
+		return fmt.Errorf("unknown ClusterSummary cleanup strategy %q", strategy)
+	}
+}
+
+func getClusterSummaryCleanupStrategy() ClusterSummaryCleanupStrategy {
+	return clusterSummaryCleanupStrategy
+}
+
+// getClusterSummaryOwnerReference returns the OwnerReference set on a ClusterSummary
+// created because of profile
+func getClusterSummaryOwnerReference(profile client.Object) metav1.OwnerReference {
+	ownerRef := metav1.OwnerReference{
+		APIVersion: configv1alpha1.GroupVersion.String(),
+		Kind:       profile.GetObjectKind().GroupVersionKind().Kind,
+		Name:       profile.GetName(),
+		UID:        profile.GetUID(),
+	}
+
+	if getClusterSummaryCleanupStrategy() == ClusterSummaryCleanupOwnerReference {
+		ownerRef.Controller = ptr.To(true)
+		ownerRef.BlockOwnerDeletion = ptr.To(true)
+	}
+
+	return ownerRef
+}
+
+// areClusterSummariesGarbageCollected returns true if ClusterSummaries created because of profile
+// are going to be removed by the Kubernetes garbage collector
+func areClusterSummariesGarbageCollected(profile client.Object) bool {
+	return getClusterSummaryCleanupStrategy() == ClusterSummaryCleanupOwnerReference &&
+		controllerutil.ContainsFinalizer(profile, metav1.FinalizerDeleteDependents)
+}
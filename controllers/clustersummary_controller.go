@@ -32,7 +32,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -63,6 +65,19 @@ const (
 	// normalRequeueAfter is how long to wait before checking again to see if the cluster can be moved
 	// to ready after or workload features (for instance ingress or reporter) have failed
 	normalRequeueAfter = 10 * time.Second
+
+	// orphanGracePeriod is how long a ClusterSummary must exist before it is considered an orphan
+	// when its cluster is not found. This prevents deleting ClusterSummary instances just created,
+	// when the cluster might not be in the cache yet.
+	orphanGracePeriod = 2 * time.Minute
+)
+
+var (
+	// resourceSummaryRemoved contains ClusterSummaries whose ResourceSummary was successfully
+	// removed from the managed cluster. It is only consulted when sync mode is not
+	// ContinuousWithDriftDetection, to avoid contacting the managed cluster at every reconciliation.
+	resourceSummaryRemovedMux sync.Mutex
+	resourceSummaryRemoved    = map[types.NamespacedName]bool{}
 )
 
 type ReportMode int
@@ -94,7 +109,31 @@ type ClusterSummaryReconciler struct {
 	ClusterMap           map[corev1.ObjectReference]*libsveltosset.Set // key: Sveltos/Cluster; value: set of all ClusterSummaries for that Cluster
 
 	ConflictRetryTime time.Duration
-	ctrl              controller.Controller
+	ReferenceDebounce time.Duration // when set, ClusterSummaries are requeued only once referenced ConfigMaps/Secrets have not changed for this long
+	// DriftCollectionInterval is how often ResourceSummaries are collected from managed clusters.
+	// It bounds how quickly a drift (including deletion of a deployed resource) is fixed.
+	DriftCollectionInterval time.Duration
+	// SuccessRequeueAfter, when set, is how long to wait before reconciling again a ClusterSummary in
+	// Continuous/ContinuousWithDriftDetection mode after all its features were successfully deployed.
+	// When not set, ClusterSummaries are reconciled again only when watched resources change.
+	SuccessRequeueAfter time.Duration
+	// PrioritizeNewClusters, when set, makes ClusterSummaries with no feature provisioned yet
+	// (for instance clusters which just started matching a ClusterProfile) be reconciled before
+	// any other ClusterSummary
+	PrioritizeNewClusters bool
+	// FairScheduling, when set, shares reconcile workers across ClusterProfiles/Profiles so
+	// that one matching many clusters does not starve the others
+	FairScheduling bool
+	// FairSchedulingWeights contains, for ClusterProfiles (ClusterProfile/<name>) and
+	// Profiles (Profile/<namespace>/<name>), the relative share of reconcile workers. Default is 1.
+	FairSchedulingWeights map[string]int
+	// EventRecorder, when set, is used to emit Events on Sveltos/CAPI Clusters as features
+	// are provisioned or fail
+	EventRecorder record.EventRecorder
+	// ClusterClientGetter, when set, replaces how clusters add-ons and applications are deployed to
+	// are accessed, both by the reconciler and by the feature handlers
+	ClusterClientGetter ClusterClientGetter
+	ctrl                controller.Controller
 }
 
 //+kubebuilder:rbac:groups=config.projectsveltos.io,resources=clustersummaries,verbs=get;list;watch;create;update;patch;delete
@@ -182,9 +221,12 @@ func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
+	previousStatuses := getFeatureStatuses(clusterSummary)
+
 	// Always close the scope when exiting this function so we can persist any ClusterSummary
 	// changes.
 	defer func() {
+		recordClusterEvents(ctx, r.Client, r.EventRecorder, clusterSummary, previousStatuses, logger)
 		if err = clusterSummaryScope.Close(ctx); err != nil {
 			reterr = err
 		}
@@ -195,6 +237,20 @@ func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return r.reconcileDelete(ctx, clusterSummaryScope, logger)
 	}
 
+	isOrphan, err := r.isOrphan(ctx, clusterSummaryScope)
+	if err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if isOrphan {
+		// Cluster does not exist anymore. Delete ClusterSummary. reconcileDelete will then take
+		// care of cleaning up.
+		logger.V(logs.LogInfo).Info("cluster does not exist anymore. Deleting orphaned ClusterSummary.")
+		if err := r.Delete(ctx, clusterSummary); err != nil && !apierrors.IsNotFound(err) {
+			return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+		}
+		return reconcile.Result{}, nil
+	}
+
 	isReady, err := r.isReady(ctx, clusterSummary, logger)
 	if err != nil {
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
@@ -202,11 +258,13 @@ func (r *ClusterSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if !isReady {
 		logger.V(logs.LogInfo).Info("cluster is not ready.")
 		r.setFailureMessage(clusterSummaryScope, "cluster is not ready")
-		r.resetFeatureStatus(clusterSummaryScope, configv1alpha1.FeatureStatusFailed)
-		// if cluster is not ready, do nothing and don't queue for reconciliation.
-		// When cluster becomes ready, all matching clusterSummaries will be requeued for reconciliation
+		r.resetFeatureStatus(clusterSummaryScope, configv1alpha1.FeatureStatusWaitingForCluster)
+		// if cluster is not ready, do not deploy anything. Deploying while control plane or infrastructure
+		// is still being provisioned only produces confusing errors.
+		// When cluster becomes ready, all matching clusterSummaries will be requeued for reconciliation.
+		// Requeue anyway in case that event is missed.
 		r.updateMaps(clusterSummaryScope, logger)
-		return reconcile.Result{}, nil
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
 	// Handle non-deleted clusterSummary
@@ -273,8 +331,12 @@ func (r *ClusterSummaryReconciler) reconcileDelete(
 				fmt.Sprintf("failed to remove drift-detection-manager resources from management cluster: %v", err))
 			return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
 		}
+		removeClusterLimiter(cs.Spec.ClusterNamespace, cs.Spec.ClusterName, cs.Spec.ClusterType)
 	}
 
+	forgetResourceSummaryRemoved(clusterSummaryScope.ClusterSummary)
+	removePendingDeployments(clusterSummaryScope.ClusterSummary)
+
 	// Cluster is not present anymore or cleanup succeeded
 	logger.V(logs.LogInfo).Info("Removing finalizer")
 	if controllerutil.ContainsFinalizer(clusterSummaryScope.ClusterSummary, configv1alpha1.ClusterSummaryFinalizer) {
@@ -313,9 +375,22 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		}
 	}
 
+	inMaintenance, maintenanceRequeueAfter, err := r.reconcileMaintenanceWindows(ctx,
+		clusterSummaryScope.ClusterSummary, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to evaluate maintenance windows")
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
+	}
+	if inMaintenance {
+		logger.V(logs.LogInfo).Info("maintenance window is active. Do nothing.")
+		return reconcile.Result{RequeueAfter: maintenanceRequeueAfter}, nil
+	}
+
+	r.handleReconcileNowRequest(clusterSummaryScope, logger)
+
 	if !r.shouldReconcile(clusterSummaryScope, logger) {
 		logger.V(logs.LogInfo).Info("ClusterSummary does not need a reconciliation")
-		return reconcile.Result{}, nil
+		return reconcile.Result{RequeueAfter: maintenanceRequeueAfter}, nil
 	}
 
 	r.updateMaps(clusterSummaryScope, logger)
@@ -329,6 +404,19 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 		return reconcile.Result{}, nil
 	}
 
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	allowed, circuitRetryAfter := allowClusterDeploy(clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType,
+		fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name), time.Now())
+	updateCircuitOpenCondition(clusterSummary, !allowed, circuitRetryAfter)
+	if !allowed {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("circuit breaker is open for cluster. Retrying in %s",
+			circuitRetryAfter))
+		return reconcile.Result{RequeueAfter: circuitRetryAfter}, nil
+	}
+
+	r.probeClusterConnectivity(ctx, clusterSummaryScope, logger)
+
 	err = r.startWatcherForTemplateResourceRefs(ctx, clusterSummaryScope.ClusterSummary)
 	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to start watcher on resources referenced in TemplateResourceRefs.")
@@ -355,6 +443,8 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 			logger.V(logs.LogInfo).Error(err, "failed to remove ResourceSummary.")
 			return reconcile.Result{Requeue: true, RequeueAfter: deleteRequeueAfter}, nil
 		}
+	} else {
+		forgetResourceSummaryRemoved(clusterSummaryScope.ClusterSummary)
 	}
 
 	err = r.deploy(ctx, clusterSummaryScope, logger)
@@ -365,21 +455,86 @@ func (r *ClusterSummaryReconciler) reconcileNormal(
 			logger.V(logs.LogInfo).Error(err, "failed to deploy because of conflict")
 			return reconcile.Result{Requeue: true, RequeueAfter: r.ConflictRetryTime}, nil
 		}
+		var transientErr *TransientError
+		if errors.As(err, &transientErr) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to deploy: %v", err))
+			return reconcile.Result{Requeue: true, RequeueAfter: transientErr.RetryAfter}, nil
+		}
 		logger.V(logs.LogInfo).Error(err, "failed to deploy")
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}, nil
 	}
 
 	logger.V(logs.LogInfo).Info("Reconciling ClusterSummary success")
-	return reconcile.Result{}, nil
+	return reconcile.Result{RequeueAfter: r.getSuccessRequeueAfter(clusterSummaryScope, maintenanceRequeueAfter)}, nil
+}
+
+// handleReconcileNowRequest processes, if present, the ReconcileNowAnnotation: hash of every feature is
+// reset, so all features are deployed again, and the annotation is removed. Both changes are persisted
+// when the scope is closed.
+func (r *ClusterSummaryReconciler) handleReconcileNowRequest(clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	if _, ok := clusterSummary.Annotations[configv1alpha1.ReconcileNowAnnotation]; !ok {
+		return
+	}
+
+	logger.V(logs.LogInfo).Info("reconcile now requested. All features will be deployed again.")
+	for i := range clusterSummary.Status.FeatureSummaries {
+		clusterSummaryScope.SetFeatureStatus(clusterSummary.Status.FeatureSummaries[i].FeatureID,
+			configv1alpha1.FeatureStatusProvisioning, nil)
+	}
+
+	delete(clusterSummary.Annotations, configv1alpha1.ReconcileNowAnnotation)
+}
+
+// getSuccessRequeueAfter returns how long to wait before reconciling again a successfully deployed
+// ClusterSummary: the smallest between next maintenance window change (if any) and, in
+// Continuous/ContinuousWithDriftDetection mode, SuccessRequeueAfter (if set).
+func (r *ClusterSummaryReconciler) getSuccessRequeueAfter(clusterSummaryScope *scope.ClusterSummaryScope,
+	maintenanceRequeueAfter time.Duration) time.Duration {
+
+	syncMode := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.SyncMode
+	if r.SuccessRequeueAfter <= 0 ||
+		(syncMode != configv1alpha1.SyncModeContinuous && syncMode != configv1alpha1.SyncModeContinuousWithDriftDetection) {
+
+		return maintenanceRequeueAfter
+	}
+
+	if maintenanceRequeueAfter != 0 && maintenanceRequeueAfter < r.SuccessRequeueAfter {
+		return maintenanceRequeueAfter
+	}
+	return r.SuccessRequeueAfter
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if r.ClusterClientGetter != nil {
+		SetClusterClientGetter(r.ClusterClientGetter)
+	}
+
+	options := controller.Options{
+		MaxConcurrentReconciles: r.ConcurrentReconciles,
+	}
+	if r.FairScheduling || r.PrioritizeNewClusters {
+		queueOptions := priorityQueueOptions{}
+		if r.PrioritizeNewClusters {
+			queueOptions.IsHighPriority = r.isNeverProvisioned
+		}
+		if r.FairScheduling {
+			queueOptions.GroupOf = r.getProfileGroup
+			queueOptions.Weights = r.FairSchedulingWeights
+		}
+		options.NewQueue = newPriorityRateLimitingQueue(queueOptions)
+	}
+
 	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&configv1alpha1.ClusterSummary{}).
-		WithOptions(controller.Options{
-			MaxConcurrentReconciles: r.ConcurrentReconciles,
-		}).
+		For(&configv1alpha1.ClusterSummary{},
+			builder.WithPredicates(
+				ClusterSummaryPredicates(mgr.GetLogger().WithValues("predicate", "clustersummarypredicate")),
+			),
+		).
+		WithOptions(options).
 		Watches(&libsveltosv1alpha1.SveltosCluster{},
 			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForSveltosCluster),
 			builder.WithPredicates(
@@ -387,13 +542,13 @@ func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctr
 			),
 		).
 		Watches(&corev1.ConfigMap{},
-			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForReference),
+			r.enqueueClusterSummaryForReference(),
 			builder.WithPredicates(
 				ConfigMapPredicates(mgr.GetLogger().WithValues("predicate", "configmappredicate")),
 			),
 		).
 		Watches(&corev1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForReference),
+			r.enqueueClusterSummaryForReference(),
 			builder.WithPredicates(
 				SecretPredicates(mgr.GetLogger().WithValues("predicate", "secretpredicate")),
 			),
@@ -407,7 +562,8 @@ func (r *ClusterSummaryReconciler) SetupWithManager(ctx context.Context, mgr ctr
 	// Later on, in main, we detect that and if CAPI is present WatchForCAPI will be invoked.
 
 	if r.ReportMode == CollectFromManagementCluster {
-		go collectAndProcessResourceSummaries(ctx, mgr.GetClient(), r.ShardKey, mgr.GetLogger())
+		go collectAndProcessResourceSummaries(ctx, mgr.GetClient(), r.ShardKey, r.DriftCollectionInterval,
+			mgr.GetLogger())
 	}
 
 	initializeManager(ctrl.Log.WithName("watchers"), mgr.GetConfig(), mgr.GetClient())
@@ -490,28 +646,42 @@ func (r *ClusterSummaryReconciler) deploy(ctx context.Context, clusterSummarySco
 	clusterSummary := clusterSummaryScope.ClusterSummary
 	logger = logger.WithValues("clusternamespace", clusterSummary.Spec.ClusterNamespace, "clustername", clusterSummary.Spec.ClusterName)
 
-	resourceErr := r.deployResources(ctx, clusterSummaryScope, logger)
-
-	helmErr := r.deployHelm(ctx, clusterSummaryScope, logger)
-
-	kustomizeError := r.deployKustomizeRefs(ctx, clusterSummaryScope, logger)
-
-	if resourceErr != nil {
-		return resourceErr
-	}
-
-	if helmErr != nil {
-		return helmErr
-	}
+	features := []struct {
+		id     configv1alpha1.FeatureID
+		deploy func(context.Context, *scope.ClusterSummaryScope, logr.Logger) error
+	}{
+		{configv1alpha1.FeatureResources, r.deployResources},
+		{configv1alpha1.FeatureHelm, r.deployHelm},
+		{configv1alpha1.FeatureKustomize, r.deployKustomizeRefs},
+		{configv1alpha1.FeaturePodSecurity, r.deployPodSecurity},
+	}
+
+	var deployErr error
+	for i := range features {
+		var err error
+		// Per FeaturePriorities, a feature is deployed only once features with a lower priority are provisioned
+		pending := getPendingLowerPriorityFeature(clusterSummary, features[i].id)
+		if pending != nil && isFeatureConfigured(clusterSummary, features[i].id) {
+			msg := fmt.Sprintf("feature %s waits for feature %s to be provisioned", features[i].id, *pending)
+			logger.V(logs.LogDebug).Info(msg)
+			err = errors.New(msg)
+		} else {
+			err = features[i].deploy(ctx, clusterSummaryScope, logger)
+		}
 
-	if kustomizeError != nil {
-		return kustomizeError
+		if deployErr == nil {
+			deployErr = err
+		}
 	}
 
-	return nil
+	return deployErr
 }
 
 func (r *ClusterSummaryReconciler) deployKustomizeRefs(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if isFeatureDisabled(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureKustomize) {
+		return r.disableFeature(ctx, clusterSummaryScope, getHandlersForFeature(configv1alpha1.FeatureKustomize), logger)
+	}
+
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs == nil {
 		logger.V(logs.LogDebug).Info("no kustomize policy configuration")
 		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureKustomize) {
@@ -525,7 +695,29 @@ func (r *ClusterSummaryReconciler) deployKustomizeRefs(ctx context.Context, clus
 	return r.deployFeature(ctx, clusterSummaryScope, f, logger)
 }
 
+func (r *ClusterSummaryReconciler) deployPodSecurity(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if isFeatureDisabled(clusterSummaryScope.ClusterSummary, configv1alpha1.FeaturePodSecurity) {
+		return r.disableFeature(ctx, clusterSummaryScope, getHandlersForFeature(configv1alpha1.FeaturePodSecurity), logger)
+	}
+
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PodSecurity == nil {
+		logger.V(logs.LogDebug).Info("no pod security configuration")
+		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1alpha1.FeaturePodSecurity) {
+			logger.V(logs.LogDebug).Info("no pod security status. Do not reconcile this")
+			return nil
+		}
+	}
+
+	f := getHandlersForFeature(configv1alpha1.FeaturePodSecurity)
+
+	return r.deployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
 func (r *ClusterSummaryReconciler) deployResources(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if isFeatureDisabled(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureResources) {
+		return r.disableFeature(ctx, clusterSummaryScope, getHandlersForFeature(configv1alpha1.FeatureResources), logger)
+	}
+
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs == nil {
 		logger.V(logs.LogDebug).Info("no policy configuration")
 		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureResources) {
@@ -540,6 +732,10 @@ func (r *ClusterSummaryReconciler) deployResources(ctx context.Context, clusterS
 }
 
 func (r *ClusterSummaryReconciler) deployHelm(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	if isFeatureDisabled(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureHelm) {
+		return r.disableFeature(ctx, clusterSummaryScope, getHandlersForFeature(configv1alpha1.FeatureHelm), logger)
+	}
+
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.HelmCharts == nil {
 		logger.V(logs.LogDebug).Info("no helm configuration")
 		if !r.isFeatureStatusPresent(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureHelm) {
@@ -564,9 +760,30 @@ func (r *ClusterSummaryReconciler) isClusterPresent(ctx context.Context,
 		if apierrors.IsNotFound(err) {
 			return false, false, nil
 		}
+		return true, false, err
 	}
 
-	return true, !cluster.GetDeletionTimestamp().IsZero(), err
+	return true, !cluster.GetDeletionTimestamp().IsZero(), nil
+}
+
+// isOrphan returns true if the Sveltos/Cluster referenced by ClusterSummary does not exist anymore.
+// Only the presence of the cluster object in the management cluster is considered, so a cluster
+// which is temporarily unreachable is never considered gone.
+// A ClusterSummary created less than orphanGracePeriod ago is never considered an orphan.
+func (r *ClusterSummaryReconciler) isOrphan(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope) (bool, error) {
+
+	creationTime := clusterSummaryScope.ClusterSummary.CreationTimestamp
+	if time.Since(creationTime.Time) < orphanGracePeriod {
+		return false, nil
+	}
+
+	isPresent, _, err := r.isClusterPresent(ctx, clusterSummaryScope)
+	if err != nil {
+		return false, err
+	}
+
+	return !isPresent, nil
 }
 
 func (r *ClusterSummaryReconciler) undeploy(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
@@ -578,6 +795,8 @@ func (r *ClusterSummaryReconciler) undeploy(ctx context.Context, clusterSummaryS
 
 	helmErr := r.undeployHelm(ctx, clusterSummaryScope, logger)
 
+	podSecurityErr := r.undeployPodSecurity(ctx, clusterSummaryScope, logger)
+
 	if resourceErr != nil {
 		return resourceErr
 	}
@@ -590,6 +809,10 @@ func (r *ClusterSummaryReconciler) undeploy(ctx context.Context, clusterSummaryS
 		return helmErr
 	}
 
+	if podSecurityErr != nil {
+		return podSecurityErr
+	}
+
 	return nil
 }
 
@@ -608,6 +831,11 @@ func (r *ClusterSummaryReconciler) undeployHelm(ctx context.Context, clusterSumm
 	return r.undeployFeature(ctx, clusterSummaryScope, f, logger)
 }
 
+func (r *ClusterSummaryReconciler) undeployPodSecurity(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
+	f := getHandlersForFeature(configv1alpha1.FeaturePodSecurity)
+	return r.undeployFeature(ctx, clusterSummaryScope, f, logger)
+}
+
 func (r *ClusterSummaryReconciler) updateChartMap(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
 	logger logr.Logger) error {
 
@@ -755,7 +983,10 @@ func (r *ClusterSummaryReconciler) shouldReconcile(clusterSummaryScope *scope.Cl
 		return true
 	}
 
-	if len(clusterSummary.Spec.ClusterProfileSpec.PolicyRefs) != 0 {
+	if len(clusterSummary.Spec.ClusterProfileSpec.PolicyRefs) != 0 ||
+		len(clusterSummary.Spec.ClusterProfileSpec.InlinePolicies) != 0 ||
+		len(clusterSummary.Spec.ClusterProfileSpec.CopyRefs) != 0 {
+
 		if !r.isFeatureDeployed(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureResources) {
 			logger.V(logs.LogDebug).Info("Mode set to one time. Resources not deployed yet. Reconciliation is needed.")
 			return true
@@ -776,9 +1007,68 @@ func (r *ClusterSummaryReconciler) shouldReconcile(clusterSummaryScope *scope.Cl
 		}
 	}
 
+	if clusterSummary.Spec.ClusterProfileSpec.PodSecurity != nil {
+		if !r.isFeatureDeployed(clusterSummaryScope.ClusterSummary, configv1alpha1.FeaturePodSecurity) {
+			logger.V(logs.LogDebug).Info("Mode set to one time. Pod security labels not deployed yet. Reconciliation is needed.")
+			return true
+		}
+	}
+
 	return false
 }
 
+// isNeverProvisioned returns true if item is a request for a ClusterSummary with no feature
+// provisioned yet. Such ClusterSummaries are reconciled first when PrioritizeNewClusters is set.
+func (r *ClusterSummaryReconciler) isNeverProvisioned(item interface{}) bool {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		return false
+	}
+
+	// Client reads from the cache, so this does not reach the API server
+	clusterSummary := &configv1alpha1.ClusterSummary{}
+	if err := r.Client.Get(context.TODO(), req.NamespacedName, clusterSummary); err != nil {
+		return false
+	}
+
+	if !clusterSummary.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	for i := range clusterSummary.Status.FeatureSummaries {
+		if clusterSummary.Status.FeatureSummaries[i].Status == configv1alpha1.FeatureStatusProvisioned {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getProfileGroup returns, for a request for a ClusterSummary, the ClusterProfile/Profile which
+// created it, in the form ClusterProfile/<name> or Profile/<namespace>/<name>.
+// ClusterSummaries share reconcile workers fairly across ClusterProfiles/Profiles using it.
+func (r *ClusterSummaryReconciler) getProfileGroup(item interface{}) string {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		return ""
+	}
+
+	// Client reads from the cache, so this does not reach the API server
+	clusterSummary := &configv1alpha1.ClusterSummary{}
+	if err := r.Client.Get(context.TODO(), req.NamespacedName, clusterSummary); err != nil {
+		return ""
+	}
+
+	labels := clusterSummary.GetLabels()
+	if name, ok := labels[ClusterProfileLabelName]; ok {
+		return fmt.Sprintf("%s/%s", configv1alpha1.ClusterProfileKind, name)
+	}
+	if name, ok := labels[ProfileLabelName]; ok {
+		return fmt.Sprintf("%s/%s/%s", configv1alpha1.ProfileKind, clusterSummary.Namespace, name)
+	}
+	return ""
+}
+
 func (r *ClusterSummaryReconciler) getCurrentReferences(clusterSummaryScope *scope.ClusterSummaryScope) *libsveltosset.Set {
 	currentReferences := r.getPolicyRefReferences(clusterSummaryScope)
 	currentReferences.Append(r.getKustomizationRefReferences(clusterSummaryScope))
@@ -802,6 +1092,16 @@ func (r *ClusterSummaryReconciler) getPolicyRefReferences(clusterSummaryScope *s
 			Name:       referencedName,
 		})
 	}
+
+	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.CopyRefs {
+		copyRef := &clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.CopyRefs[i]
+		currentReferences.Insert(&corev1.ObjectReference{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       copyRef.Kind,
+			Namespace:  getReferenceResourceNamespace(clusterSummaryScope.Namespace(), copyRef.Namespace),
+			Name:       copyRef.Name,
+		})
+	}
 	return currentReferences
 }
 
@@ -904,7 +1204,8 @@ func (r *ClusterSummaryReconciler) getReferenceMapForEntry(entry *corev1.ObjectR
 	return s
 }
 
-// isReady returns true if Sveltos/Cluster is ready
+// isReady returns true if Sveltos/Cluster is ready. A CAPI Cluster is ready when both its control plane
+// and its infrastructure are ready.
 func (r *ClusterSummaryReconciler) isReady(ctx context.Context,
 	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) (bool, error) {
 
@@ -929,7 +1230,20 @@ func (r *ClusterSummaryReconciler) isReady(ctx context.Context,
 		return false, err
 	}
 
-	return isClusterReady, nil
+	if !isClusterReady || clusterSummary.Spec.ClusterType == libsveltosv1alpha1.ClusterTypeSveltos {
+		return isClusterReady, nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: clusterRef.Namespace, Name: clusterRef.Name}, cluster)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return cluster.Status.InfrastructureReady, nil
 }
 
 // isPaused returns true if Sveltos/Cluster is paused or ClusterSummary has paused annotation.
@@ -1015,10 +1329,16 @@ func (r *ClusterSummaryReconciler) canRemoveFinalizer(ctx context.Context,
 func (r *ClusterSummaryReconciler) removeResourceSummary(ctx context.Context,
 	clusterSummaryScope *scope.ClusterSummaryScope, logger logr.Logger) error {
 
+	// Once removed, there is no need to contact the managed cluster again till sync mode
+	// goes back to ContinuousWithDriftDetection
+	cs := clusterSummaryScope.ClusterSummary
+	if isResourceSummaryRemoved(cs) {
+		return nil
+	}
+
 	// ResourceSummary is a Sveltos resource deployed in managed clusters.
 	// Such resources are always created, removed using cluster-admin roles.
-	cs := clusterSummaryScope.ClusterSummary
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, r.Client, cs.Spec.ClusterNamespace,
+	remoteClient, err := getKubernetesClient(ctx, r.Client, cs.Spec.ClusterNamespace,
 		cs.Spec.ClusterName, "", "", cs.Spec.ClusterType, logger)
 	if err != nil {
 		return err
@@ -1026,16 +1346,41 @@ func (r *ClusterSummaryReconciler) removeResourceSummary(ctx context.Context,
 
 	err = unDeployResourceSummaryInstance(ctx, remoteClient, cs.Spec.ClusterNamespace,
 		cs.Name, logger)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil
-		}
-		// ResourceSummaries are only installed when in ContinuousWithDriftDetection mode
-		if meta.IsNoMatchError(err) {
-			return nil
-		}
+	// ResourceSummaries are only installed when in ContinuousWithDriftDetection mode
+	if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return err
 	}
-	return err
+
+	setResourceSummaryRemoved(cs)
+	return nil
+}
+
+// isResourceSummaryRemoved returns true if ResourceSummary for this ClusterSummary was
+// already successfully removed from the managed cluster
+func isResourceSummaryRemoved(clusterSummary *configv1alpha1.ClusterSummary) bool {
+	resourceSummaryRemovedMux.Lock()
+	defer resourceSummaryRemovedMux.Unlock()
+
+	_, ok := resourceSummaryRemoved[types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}]
+	return ok
+}
+
+// setResourceSummaryRemoved records ResourceSummary for this ClusterSummary was removed
+// from the managed cluster
+func setResourceSummaryRemoved(clusterSummary *configv1alpha1.ClusterSummary) {
+	resourceSummaryRemovedMux.Lock()
+	defer resourceSummaryRemovedMux.Unlock()
+
+	resourceSummaryRemoved[types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}] = true
+}
+
+// forgetResourceSummaryRemoved must be called any time a ResourceSummary might be deployed
+// again (sync mode is ContinuousWithDriftDetection) or ClusterSummary is gone
+func forgetResourceSummaryRemoved(clusterSummary *configv1alpha1.ClusterSummary) {
+	resourceSummaryRemovedMux.Lock()
+	defer resourceSummaryRemovedMux.Unlock()
+
+	delete(resourceSummaryRemoved, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name})
 }
 
 func (r *ClusterSummaryReconciler) updateClusterShardPair(ctx context.Context,
@@ -1179,6 +1524,9 @@ func (r *ClusterSummaryReconciler) setFailureMessage(clusterSummaryScope *scope.
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs != nil {
 		clusterSummaryScope.SetFailureMessage(configv1alpha1.FeatureKustomize, &failureMessage)
 	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PodSecurity != nil {
+		clusterSummaryScope.SetFailureMessage(configv1alpha1.FeaturePodSecurity, &failureMessage)
+	}
 }
 
 func (r *ClusterSummaryReconciler) resetFeatureStatus(clusterSummaryScope *scope.ClusterSummaryScope, status configv1alpha1.FeatureStatus) {
@@ -1191,6 +1539,9 @@ func (r *ClusterSummaryReconciler) resetFeatureStatus(clusterSummaryScope *scope
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs != nil {
 		clusterSummaryScope.SetFeatureStatus(configv1alpha1.FeatureKustomize, status, nil)
 	}
+	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PodSecurity != nil {
+		clusterSummaryScope.SetFeatureStatus(configv1alpha1.FeaturePodSecurity, status, nil)
+	}
 }
 
 func (r *ClusterSummaryReconciler) GetController() controller.Controller {
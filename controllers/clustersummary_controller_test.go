@@ -67,7 +67,8 @@ var _ = Describe("ClustersummaryController", func() {
 				},
 			},
 			Status: clusterv1.ClusterStatus{
-				ControlPlaneReady: true,
+				ControlPlaneReady:   true,
+				InfrastructureReady: true,
 			},
 		}
 
@@ -97,14 +98,16 @@ var _ = Describe("ClustersummaryController", func() {
 		prepareForDeployment(clusterProfile, clusterSummary, cluster)
 
 		cluster.Status.ControlPlaneReady = true
+		cluster.Status.InfrastructureReady = true
 
 		// Get ClusterSummary so OwnerReference is set
 		Expect(testEnv.Get(context.TODO(),
 			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name}, clusterSummary)).To(Succeed())
 	})
 
-	It("isReady returns true if CAPI Cluster has Status.ControlPlaneReady set to true", func() {
+	It("isReady returns true if CAPI Cluster has Status.ControlPlaneReady and Status.InfrastructureReady set to true", func() {
 		cluster.Status.ControlPlaneReady = true
+		cluster.Status.InfrastructureReady = true
 
 		initObjects := []client.Object{
 			clusterProfile,
@@ -125,12 +128,43 @@ var _ = Describe("ClustersummaryController", func() {
 
 		Expect(controllers.IsReady(reconciler, context.TODO(), clusterSummary, logr.Logger{})).To(BeTrue())
 
+		cluster.Status.InfrastructureReady = false
+		Expect(c.Status().Update(context.TODO(), cluster)).To(Succeed())
+
+		Expect(controllers.IsReady(reconciler, context.TODO(), clusterSummary, logr.Logger{})).To(BeFalse())
+
+		cluster.Status.InfrastructureReady = true
 		cluster.Status.ControlPlaneReady = false
 		Expect(c.Status().Update(context.TODO(), cluster)).To(Succeed())
 
 		Expect(controllers.IsReady(reconciler, context.TODO(), clusterSummary, logr.Logger{})).To(BeFalse())
 	})
 
+	It("isOrphan returns true only when Cluster does not exist and grace period has expired", func() {
+		initObjects := []client.Object{
+			clusterProfile,
+			clusterSummary,
+			cluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+
+		clusterSummary.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		clusterSummaryScope := getClusterSummaryScope(c, textlogger.NewLogger(textlogger.NewConfig()),
+			clusterProfile, clusterSummary)
+
+		Expect(controllers.IsOrphan(reconciler, context.TODO(), clusterSummaryScope)).To(BeFalse())
+
+		Expect(c.Delete(context.TODO(), cluster)).To(Succeed())
+		Expect(controllers.IsOrphan(reconciler, context.TODO(), clusterSummaryScope)).To(BeTrue())
+
+		// ClusterSummary just created is not an orphan yet
+		clusterSummary.CreationTimestamp = metav1.NewTime(time.Now())
+		Expect(controllers.IsOrphan(reconciler, context.TODO(), clusterSummaryScope)).To(BeFalse())
+	})
+
 	It("isPaused returns true if CAPI Cluster has Spec.Paused set", func() {
 		initObjects := []client.Object{
 			clusterProfile,
@@ -181,6 +215,37 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(controllers.IsPaused(reconciler, context.TODO(), clusterSummary)).To(BeFalse())
 	})
 
+	It("removeResourceSummary does not contact managed cluster once ResourceSummary has been removed", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		initObjects := []client.Object{
+			clusterProfile,
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:       c,
+			Scheme:       scheme,
+			Deployer:     nil,
+			ClusterMap:   make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ReferenceMap: make(map[corev1.ObjectReference]*libsveltosset.Set),
+			PolicyMux:    sync.Mutex{},
+		}
+
+		clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+		// Cluster does not exist, so any attempt to reach the managed cluster fails
+		Expect(controllers.RemoveResourceSummary(reconciler, context.TODO(), clusterSummaryScope, logger)).ToNot(BeNil())
+
+		controllers.SetResourceSummaryRemoved(clusterSummary)
+		Expect(controllers.RemoveResourceSummary(reconciler, context.TODO(), clusterSummaryScope, logger)).To(BeNil())
+
+		controllers.ForgetResourceSummaryRemoved(clusterSummary)
+		Expect(controllers.RemoveResourceSummary(reconciler, context.TODO(), clusterSummaryScope, logger)).ToNot(BeNil())
+	})
+
 	It("shouldReconcile returns true when mode is Continuous", func() {
 		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeContinuous
 
@@ -213,6 +278,73 @@ var _ = Describe("ClustersummaryController", func() {
 			textlogger.NewLogger(textlogger.NewConfig()))).To(BeTrue())
 	})
 
+	It("getSuccessRequeueAfter returns SuccessRequeueAfter only in Continuous modes", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeContinuous
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client: c,
+			Scheme: scheme,
+		}
+
+		// Not set: only maintenance windows cause a requeue
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, 0)).To(Equal(time.Duration(0)))
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, time.Minute)).To(Equal(time.Minute))
+
+		reconciler.SuccessRequeueAfter = 30 * time.Minute
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, 0)).To(Equal(30 * time.Minute))
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, time.Minute)).To(Equal(time.Minute))
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, time.Hour)).To(Equal(30 * time.Minute))
+
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeOneTime
+		Expect(controllers.GetSuccessRequeueAfter(reconciler, clusterSummaryScope, 0)).To(Equal(time.Duration(0)))
+	})
+
+	It("handleReconcileNowRequest resets all feature hashes and removes the annotation", func() {
+		clusterSummary.Annotations = map[string]string{
+			configv1alpha1.ReconcileNowAnnotation: "",
+			randomString():                        randomString(),
+		}
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{FeatureID: configv1alpha1.FeatureResources, Status: configv1alpha1.FeatureStatusProvisioned, Hash: []byte(randomString())},
+			{FeatureID: configv1alpha1.FeatureHelm, Status: configv1alpha1.FeatureStatusFailed, Hash: []byte(randomString())},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client: c,
+			Scheme: scheme,
+		}
+
+		controllers.HandleReconcileNowRequest(reconciler, clusterSummaryScope, textlogger.NewLogger(textlogger.NewConfig()))
+
+		Expect(clusterSummary.Annotations).ToNot(HaveKey(configv1alpha1.ReconcileNowAnnotation))
+		Expect(len(clusterSummary.Annotations)).To(Equal(1))
+		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(2))
+		for i := range clusterSummary.Status.FeatureSummaries {
+			Expect(clusterSummary.Status.FeatureSummaries[i].Hash).To(BeNil())
+			Expect(clusterSummary.Status.FeatureSummaries[i].Status).To(Equal(configv1alpha1.FeatureStatusProvisioning))
+		}
+	})
+
 	It("updateChartMap updates chartMap always but in DryRun mode", func() {
 		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeContinuous
 		clusterSummary.Spec.ClusterProfileSpec.HelmCharts = []configv1alpha1.HelmChart{
@@ -1064,6 +1196,81 @@ var _ = Describe("ClustersummaryController", func() {
 		Expect(err).To(BeNil())
 		Expect(deployed).To(BeTrue())
 	})
+
+	It("areDependenciesDeployed returns true when a dependency has a disabled feature", func() {
+		clusterProfileAName := randomString()
+		clusterSummaryAName := controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind,
+			clusterProfileAName, clusterName, false)
+		clusterSummaryA := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterSummaryAName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					controllers.ClusterProfileLabelName: clusterProfileAName,
+					configv1alpha1.ClusterNameLabel:     clusterName,
+					configv1alpha1.ClusterTypeLabel:     string(libsveltosv1alpha1.ClusterTypeCapi),
+				},
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: cluster.Namespace,
+				ClusterName:      cluster.Name,
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					HelmCharts: []configv1alpha1.HelmChart{
+						{
+							RepositoryURL: randomString(), ChartName: randomString(), ChartVersion: randomString(),
+							ReleaseName: randomString(), ReleaseNamespace: randomString(), RepositoryName: randomString(),
+						},
+					},
+					PolicyRefs: []configv1alpha1.PolicyRef{
+						{
+							Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+						},
+					},
+					DisabledFeatures: []configv1alpha1.FeatureID{configv1alpha1.FeatureHelm},
+				},
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{
+						FeatureID: configv1alpha1.FeatureHelm,
+						Status:    configv1alpha1.FeatureStatusDisabled,
+					},
+					{
+						FeatureID: configv1alpha1.FeatureResources,
+						Status:    configv1alpha1.FeatureStatusProvisioned,
+					},
+				},
+			},
+		}
+
+		clusterSummary.Spec.ClusterProfileSpec.DependsOn = []string{clusterProfileAName}
+
+		initObjects := []client.Object{
+			clusterSummaryA,
+			clusterSummary,
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		addOwnerReference(context.TODO(), c, clusterSummary, clusterProfile)
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		deployed, _, err := controllers.AreDependenciesDeployed(reconciler, context.TODO(), clusterSummaryScope,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(deployed).To(BeTrue())
+	})
 })
 
 var _ = Describe("ClusterSummaryReconciler: requeue methods", func() {
@@ -21,11 +21,15 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
@@ -38,6 +42,22 @@ import (
 
 const (
 	driftDetectionInMgtmCluster = "driftDetectionInMgtmCluster"
+
+	// maxTransientFailures is the number of consecutive deployments failing because of transient
+	// errors after which a feature is marked as failed and the ClusterSummary as stalled
+	maxTransientFailures = 5
+
+	// maxTransientRetryAfter caps the backoff used to retry deployments failing because of
+	// transient errors
+	maxTransientRetryAfter = 5 * time.Minute
+)
+
+var (
+	// pendingDeployments contains, per ClusterSummary and feature, the hash of the configuration a
+	// deployment was queued for. Feature status and hash in the ClusterSummary Status are only updated
+	// once the outcome of such deployment is available.
+	pendingDeploymentsMux sync.Mutex
+	pendingDeployments    = map[string][]byte{}
 )
 
 func startDriftDetectionInMgmtCluster(o deployer.Options) bool {
@@ -77,6 +97,11 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 		"feature", string(f.id))
 	logger.V(logs.LogDebug).Info("request to deploy")
 
+	if r.isOneTimeProvisioned(ctx, clusterSummaryScope, f, logger) {
+		logger.V(logs.LogDebug).Info("sync mode is one time and feature is provisioned. Nothing to do.")
+		return nil
+	}
+
 	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName, clusterSummary.Name,
 		string(f.id), clusterSummary.Spec.ClusterType, true)
 
@@ -90,18 +115,36 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 		return fmt.Errorf("cleanup of %s still in progress. Wait before redeploying", string(f.id))
 	}
 
+	if err := r.checkKubernetesVersion(ctx, clusterSummaryScope, f.id, logger); err != nil {
+		return err
+	}
+
+	if err := r.checkPreconditions(ctx, clusterSummaryScope, f.id, logger); err != nil {
+		return err
+	}
+
 	// Get hash of current configuration (at this very precise moment)
 	currentHash, err := f.currentHash(ctx, r.Client, clusterSummaryScope, logger)
 	if err != nil {
 		return err
 	}
 
+	if err := r.updateReferencesStatus(ctx, clusterSummaryScope, f.id); err != nil {
+		return err
+	}
+
 	hash := r.getHash(clusterSummaryScope, f.id)
 
-	isConfigSame := reflect.DeepEqual(hash, currentHash)
+	// Hash computed with a different algorithm means configured algorithm was changed. Redeploy.
+	isConfigSame := reflect.DeepEqual(hash, currentHash) &&
+		isHashAlgorithmCurrent(clusterSummaryScope.ClusterSummary, f.id)
 	if !isConfigSame {
 		logger.V(logs.LogDebug).Info(fmt.Sprintf("configuration has changed. Current hash %x. Previous hash %x",
 			currentHash, hash))
+		// A change of the hash algorithm alone is not a configuration change
+		if isHashAlgorithmCurrent(clusterSummary, f.id) {
+			recordFeatureHashChange(clusterSummary, f.id, hash, currentHash, logger)
+		}
 	}
 
 	if !r.shouldRedeploy(clusterSummaryScope, f, isConfigSame, logger) {
@@ -112,8 +155,9 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 	var status *configv1alpha1.FeatureStatus
 	var resultError error
 
-	// Feature is not deployed yet
-	if isConfigSame {
+	// Feature is not deployed yet or a deployment of current configuration was already queued
+	isDeploymentPending := reflect.DeepEqual(getPendingDeployment(clusterSummary, f.id), currentHash)
+	if isConfigSame || isDeploymentPending {
 		logger.V(logs.LogDebug).Info("hash has not changed")
 		result := r.Deployer.GetResult(ctx, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
 			clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, false)
@@ -123,8 +167,33 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 
 	if status != nil {
 		logger.V(logs.LogDebug).Info("result is available. updating status.")
+		if clusterSummaryScope.IsDryRunSync() {
+			r.updateDryRunDiff(ctx, clusterSummaryScope, f.id, logger)
+		}
+		if *status == configv1alpha1.FeatureStatusProvisioning && !isConfigSame {
+			// Previous status and hash are kept till the outcome of the deployment is available
+			return fmt.Errorf("feature is still being provisioned")
+		}
+		if *status == configv1alpha1.FeatureStatusFailed && isTransientError(resultError) {
+			return r.handleTransientFailure(clusterSummaryScope, f.id, currentHash, resultError, logger)
+		}
+		var provisioningError *ProvisioningError
+		if *status == configv1alpha1.FeatureStatusFailed && errors.As(resultError, &provisioningError) {
+			// Content is deployed but feature is not ready yet. Drop the result so deployment, and
+			// so readiness checks, are queued again at next reconciliation.
+			r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+				clusterSummary.Name, string(f.id), clusterSummary.Spec.ClusterType, false)
+			removePendingDeployment(clusterSummary, f.id)
+			provisioning := configv1alpha1.FeatureStatusProvisioning
+			r.updateFeatureStatus(clusterSummaryScope, f.id, &provisioning, currentHash, nil, logger)
+			return fmt.Errorf("feature is still being provisioned: %w", resultError)
+		}
+		removePendingDeployment(clusterSummary, f.id)
 		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, resultError, logger)
 		if *status == configv1alpha1.FeatureStatusProvisioned {
+			recordClusterSuccess(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+				clusterSummary.Spec.ClusterType)
+			notifyCompletion(clusterSummary, f.id, CompletionActionDeploy, *status, currentHash, nil)
 			return nil
 		}
 		if resultError != nil {
@@ -133,17 +202,25 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 			if errors.As(resultError, &nonRetriableError) {
 				nonRetriableStatus := configv1alpha1.FeatureStatusFailedNonRetriable
 				r.updateFeatureStatus(clusterSummaryScope, f.id, &nonRetriableStatus, currentHash, resultError, logger)
+				notifyCompletion(clusterSummary, f.id, CompletionActionDeploy, nonRetriableStatus, currentHash, resultError)
 				return nil
 			}
 		}
 		if *status == configv1alpha1.FeatureStatusProvisioning {
 			return fmt.Errorf("feature is still being provisioned")
 		}
+		notifyCompletion(clusterSummary, f.id, CompletionActionDeploy, *status, currentHash, resultError)
 	} else {
-		logger.V(logs.LogDebug).Info("no result is available. mark status as provisioning")
-		s := configv1alpha1.FeatureStatusProvisioning
-		status = &s
-		r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		// Status and hash are only updated once the outcome of the deployment is available. Till then
+		// previous status is kept (for instance a Provisioned feature stays Provisioned while the managed
+		// cluster API is temporarily unavailable). Only a feature never deployed before is marked as provisioning.
+		if fs := getFeatureSummaryForFeatureID(clusterSummary, f.id); fs == nil || fs.Status == "" {
+			logger.V(logs.LogDebug).Info("no result is available. mark status as provisioning")
+			s := configv1alpha1.FeatureStatusProvisioning
+			status = &s
+			r.updateFeatureStatus(clusterSummaryScope, f.id, status, currentHash, nil, logger)
+		}
+		setPendingDeployment(clusterSummary, f.id, currentHash)
 	}
 
 	// Getting here means either feature failed to be deployed or configuration has changed.
@@ -164,6 +241,66 @@ func (r *ClusterSummaryReconciler) deployFeature(ctx context.Context, clusterSum
 	return fmt.Errorf("request is queued")
 }
 
+// updateReferencesStatus reports, for featureID, the referenced resources which were found and the
+// ones which do not exist (yet). Only PolicyRefs (Resources feature) are reported.
+func (r *ClusterSummaryReconciler) updateReferencesStatus(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1alpha1.FeatureID) error {
+
+	if featureID != configv1alpha1.FeatureResources {
+		return nil
+	}
+
+	resolved, missing, err := getPolicyRefsStatus(ctx, r.Client, clusterSummaryScope.ClusterSummary)
+	if err != nil {
+		return err
+	}
+	clusterSummaryScope.SetReferencesStatus(featureID, resolved, missing)
+	return nil
+}
+
+// handleTransientFailure is invoked when deploying a feature failed because the managed cluster
+// API was temporarily unavailable. A new deployment is scheduled with exponential backoff and a
+// TransientError is returned. Until this happened maxTransientFailures consecutive times, feature
+// status and hash are left untouched (so a Provisioned feature stays Provisioned) and only the
+// failure message is reported. Afterwards feature
+// is marked as failed and the ClusterSummary as stalled, while deployment keeps being retried, and
+// each further failure counts as a severe failure for the cluster circuit breaker.
+func (r *ClusterSummaryReconciler) handleTransientFailure(clusterSummaryScope *scope.ClusterSummaryScope,
+	featureID configv1alpha1.FeatureID, hash []byte, deployErr error, logger logr.Logger) error {
+
+	failures := clusterSummaryScope.IncrementConsecutiveFailures(featureID)
+
+	// Drop the failed result so feature is deployed again at next reconciliation
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	r.Deployer.CleanupEntries(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Name, string(featureID), clusterSummary.Spec.ClusterType, false)
+
+	if failures >= maxTransientFailures {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("deployment failed %d consecutive times because of transient errors",
+			failures))
+		status := configv1alpha1.FeatureStatusFailed
+		removePendingDeployment(clusterSummary, featureID)
+		r.updateFeatureStatus(clusterSummaryScope, featureID, &status, hash, deployErr, logger)
+		// Managed cluster keeps being unavailable. This counts as a severe failure for the circuit breaker.
+		if recordClusterSevereFailure(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Spec.ClusterType, time.Now()) {
+
+			logger.V(logs.LogInfo).Info("circuit breaker open. Suspending deployments to cluster")
+		}
+	}
+	failureMessage := deployErr.Error()
+	clusterSummaryScope.SetFailureMessage(featureID, &failureMessage)
+	updateStalledCondition(clusterSummary)
+
+	retryAfter := getTransientRetryAfter(failures)
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("transient error (%d consecutive failures). Retrying in %s",
+		failures, retryAfter))
+	return &TransientError{
+		Message:    fmt.Sprintf("managed cluster temporarily unavailable: %v", deployErr),
+		RetryAfter: retryAfter,
+	}
+}
+
 func genericDeploy(ctx context.Context, c client.Client,
 	clusterNamespace, clusterName, applicant, featureID string,
 	clusterType libsveltosv1alpha1.ClusterType,
@@ -174,10 +311,18 @@ func genericDeploy(ctx context.Context, c client.Client,
 	// Code common to all features
 
 	// Before any per feature specific code
+	inflight := newInflightDeploy(clusterNamespace, clusterName, applicant,
+		configv1alpha1.FeatureID(featureID), clusterType, false)
+	if err := startDeploy(inflight); err != nil {
+		return err
+	}
+	defer endDeploy(inflight)
 
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1alpha1.FeatureID(featureID))
-	err := featureHandler.deploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	err := runRecoveringPanic(clusterNamespace, clusterName, clusterType, featureID, logger, func() error {
+		return featureHandler.deploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	})
 	if err != nil {
 		return err
 	}
@@ -235,10 +380,12 @@ func (r *ClusterSummaryReconciler) undeployFeature(ctx context.Context, clusterS
 			tmpStatus := configv1alpha1.FeatureStatusRemoved
 			status = &tmpStatus
 			r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, result.Err, logger)
+			notifyCompletion(clusterSummary, f.id, CompletionActionUndeploy, *status, nil, nil)
 			return nil
 		}
 
 		r.updateFeatureStatus(clusterSummaryScope, f.id, status, nil, result.Err, logger)
+		notifyCompletion(clusterSummary, f.id, CompletionActionUndeploy, *status, nil, result.Err)
 		if *status == configv1alpha1.FeatureStatusRemoved {
 			return nil
 		}
@@ -259,6 +406,28 @@ func (r *ClusterSummaryReconciler) undeployFeature(ctx context.Context, clusterS
 	return fmt.Errorf("cleanup request is queued")
 }
 
+// disableFeature removes from the managed cluster all resources deployed because of feature f.
+// Once all resources are removed, feature status is set to Disabled.
+func (r *ClusterSummaryReconciler) disableFeature(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
+	f feature, logger logr.Logger) error {
+
+	fs := getFeatureSummaryForFeatureID(clusterSummaryScope.ClusterSummary, f.id)
+	if fs != nil && fs.Status == configv1alpha1.FeatureStatusDisabled {
+		return nil
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("feature %s is disabled", f.id))
+	if fs != nil {
+		err := r.undeployFeature(ctx, clusterSummaryScope, f, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	clusterSummaryScope.SetFeatureStatus(f.id, configv1alpha1.FeatureStatusDisabled, nil)
+	return nil
+}
+
 func genericUndeploy(ctx context.Context, c client.Client,
 	clusterNamespace, clusterName, applicant, featureID string,
 	clusterType libsveltosv1alpha1.ClusterType, o deployer.Options, logger logr.Logger) error {
@@ -268,6 +437,12 @@ func genericUndeploy(ctx context.Context, c client.Client,
 	// Code common to all features
 
 	// Before any per feature specific code
+	inflight := newInflightDeploy(clusterNamespace, clusterName, applicant,
+		configv1alpha1.FeatureID(featureID), clusterType, true)
+	if err := startDeploy(inflight); err != nil {
+		return err
+	}
+	defer endDeploy(inflight)
 
 	var err error
 	_, err = clusterproxy.GetCluster(ctx, c, clusterNamespace, clusterName, clusterType)
@@ -282,7 +457,10 @@ func genericUndeploy(ctx context.Context, c client.Client,
 
 	// Invoking per feature specific code
 	featureHandler := getHandlersForFeature(configv1alpha1.FeatureID(featureID))
-	if err := featureHandler.undeploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger); err != nil {
+	err = runRecoveringPanic(clusterNamespace, clusterName, clusterType, featureID, logger, func() error {
+		return featureHandler.undeploy(ctx, c, clusterNamespace, clusterName, applicant, featureID, clusterType, o, logger)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -291,6 +469,52 @@ func genericUndeploy(ctx context.Context, c client.Client,
 	return nil
 }
 
+// getTransientRetryAfter returns the backoff after failures consecutive transient failures
+func getTransientRetryAfter(failures int32) time.Duration {
+	retryAfter := normalRequeueAfter
+	for i := int32(1); i < failures && retryAfter < maxTransientRetryAfter; i++ {
+		retryAfter *= 2
+	}
+	if retryAfter > maxTransientRetryAfter {
+		retryAfter = maxTransientRetryAfter
+	}
+	return retryAfter
+}
+
+// updateStalledCondition sets the Stalled condition on clusterSummary if deploying any feature failed
+// at least maxTransientFailures consecutive times because of transient errors. The condition reports,
+// for each such feature, the number of consecutive failures and the last error.
+// Condition is removed otherwise.
+func updateStalledCondition(clusterSummary *configv1alpha1.ClusterSummary) {
+	message := ""
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.ConsecutiveFailures < maxTransientFailures {
+			continue
+		}
+		if message != "" {
+			message += "; "
+		}
+		message += fmt.Sprintf("%s: %d consecutive failures", fs.FeatureID, fs.ConsecutiveFailures)
+		if fs.FailureMessage != nil {
+			message += fmt.Sprintf(". Last error: %s", *fs.FailureMessage)
+		}
+	}
+
+	if message == "" {
+		meta.RemoveStatusCondition(&clusterSummary.Status.Conditions, configv1alpha1.ClusterSummaryStalledCondition)
+		return
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, metav1.Condition{
+		Type:               configv1alpha1.ClusterSummaryStalledCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             configv1alpha1.ClusterSummaryStalledReason,
+		Message:            message,
+		ObservedGeneration: clusterSummary.Generation,
+	})
+}
+
 // isFeatureStatusPresent returns true if feature status is set.
 // That means feature was deployed/being deployed
 func (r *ClusterSummaryReconciler) isFeatureStatusPresent(clusterSummary *configv1alpha1.ClusterSummary,
@@ -370,6 +594,9 @@ func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scop
 	case configv1alpha1.FeatureStatusProvisioned:
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1alpha1.FeatureStatusProvisioned, hash)
 		clusterSummaryScope.SetFailureMessage(featureID, nil)
+		clusterSummaryScope.ResetConsecutiveFailures(featureID)
+		updateStalledCondition(clusterSummaryScope.ClusterSummary)
+		clusterSummaryScope.SetDryRunDiff(featureID, nil)
 	case configv1alpha1.FeatureStatusRemoved:
 		clusterSummaryScope.SetFeatureStatus(featureID, configv1alpha1.FeatureStatusRemoved, hash)
 		clusterSummaryScope.SetFailureMessage(featureID, nil)
@@ -383,9 +610,80 @@ func (r *ClusterSummaryReconciler) updateFeatureStatus(clusterSummaryScope *scop
 		clusterSummaryScope.SetFailureMessage(featureID, &err)
 	}
 
+	if hash != nil {
+		clusterSummaryScope.SetHashAlgorithm(featureID, string(getHashAlgorithm()))
+	}
 	clusterSummaryScope.SetLastAppliedTime(featureID, &now)
 }
 
+// updateDryRunDiff sets, for featureID, the resources which would be added, changed or removed
+// in the managed cluster. Those are the outcome of the last dry run deployment, which is reported
+// in the ClusterReport.
+// Only resources deployed because of PolicyRefs and KustomizationRefs are reported.
+func (r *ClusterSummaryReconciler) updateDryRunDiff(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1alpha1.FeatureID, logger logr.Logger) {
+
+	if featureID != configv1alpha1.FeatureResources && featureID != configv1alpha1.FeatureKustomize {
+		return
+	}
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || profileOwnerRef == nil {
+		return
+	}
+
+	clusterReport := &configv1alpha1.ClusterReport{}
+	err = r.Client.Get(ctx,
+		types.NamespacedName{
+			Namespace: clusterSummary.Spec.ClusterNamespace,
+			Name: getClusterReportName(profileOwnerRef.Kind, profileOwnerRef.Name,
+				clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType),
+		}, clusterReport)
+	if err != nil {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("failed to get ClusterReport: %v", err))
+		return
+	}
+
+	resourceReports := clusterReport.Status.ResourceReports
+	if featureID == configv1alpha1.FeatureKustomize {
+		resourceReports = clusterReport.Status.KustomizeResourceReports
+	}
+
+	clusterSummaryScope.SetDryRunDiff(featureID, getDryRunDiff(resourceReports))
+}
+
+// getDryRunDiff converts resourceReports into the list of resources which would be added, changed
+// or removed. Resources with no action or in conflict are skipped.
+func getDryRunDiff(resourceReports []configv1alpha1.ResourceReport) []configv1alpha1.DryRunDiffEntry {
+	var diff []configv1alpha1.DryRunDiffEntry
+	for i := range resourceReports {
+		var change configv1alpha1.DryRunChange
+		switch configv1alpha1.ResourceAction(resourceReports[i].Action) {
+		case configv1alpha1.CreateResourceAction:
+			change = configv1alpha1.DryRunChangeAdded
+		case configv1alpha1.UpdateResourceAction:
+			change = configv1alpha1.DryRunChangeChanged
+		case configv1alpha1.DeleteResourceAction:
+			change = configv1alpha1.DryRunChangeRemoved
+		default:
+			continue
+		}
+
+		resource := &resourceReports[i].Resource
+		diff = append(diff, configv1alpha1.DryRunDiffEntry{
+			Group:     resource.Group,
+			Version:   resource.Version,
+			Kind:      resource.Kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+			Change:    change,
+		})
+	}
+
+	return diff
+}
+
 func (r *ClusterSummaryReconciler) convertResultStatus(result deployer.Result) *configv1alpha1.FeatureStatus {
 	switch result.ResultStatus {
 	case deployer.Deployed:
@@ -407,6 +705,34 @@ func (r *ClusterSummaryReconciler) convertResultStatus(result deployer.Result) *
 	return nil
 }
 
+// isOneTimeProvisioned returns true if sync mode is OneTime, feature is provisioned and its
+// configuration has not changed since. Such a feature never needs to be redeployed, so callers
+// can skip any further work, including contacting the managed cluster.
+func (r *ClusterSummaryReconciler) isOneTimeProvisioned(ctx context.Context, clusterSummaryScope *scope.ClusterSummaryScope,
+	f feature, logger logr.Logger) bool {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode != configv1alpha1.SyncModeOneTime {
+		return false
+	}
+
+	if !r.isFeatureDeployed(clusterSummary, f.id) {
+		return false
+	}
+
+	hash := r.getHash(clusterSummaryScope, f.id)
+	if hash == nil {
+		return false
+	}
+
+	currentHash, err := f.currentHash(ctx, r.Client, clusterSummaryScope, logger)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(hash, currentHash)
+}
+
 // shouldRedeploy returns true if this feature requires to be redeployed.
 func (r *ClusterSummaryReconciler) shouldRedeploy(clusterSummaryScope *scope.ClusterSummaryScope, f feature,
 	isConfigSame bool, logger logr.Logger) bool {
@@ -436,3 +762,46 @@ func (r *ClusterSummaryReconciler) shouldRedeploy(clusterSummaryScope *scope.Clu
 
 	return true
 }
+
+// getPendingDeploymentKey returns the key used to track the deployment queued for featureID
+func getPendingDeploymentKey(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) string {
+	return fmt.Sprintf("%s/%s/%s", clusterSummary.Namespace, clusterSummary.Name, featureID)
+}
+
+// getPendingDeployment returns the hash of the configuration a deployment was queued for, and whose
+// outcome has not been reported in the ClusterSummary Status yet. Returns nil if there is none.
+func getPendingDeployment(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) []byte {
+	pendingDeploymentsMux.Lock()
+	defer pendingDeploymentsMux.Unlock()
+
+	return pendingDeployments[getPendingDeploymentKey(clusterSummary, featureID)]
+}
+
+func setPendingDeployment(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID,
+	hash []byte) {
+
+	pendingDeploymentsMux.Lock()
+	defer pendingDeploymentsMux.Unlock()
+
+	pendingDeployments[getPendingDeploymentKey(clusterSummary, featureID)] = hash
+}
+
+func removePendingDeployment(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) {
+	pendingDeploymentsMux.Lock()
+	defer pendingDeploymentsMux.Unlock()
+
+	delete(pendingDeployments, getPendingDeploymentKey(clusterSummary, featureID))
+}
+
+// removePendingDeployments forgets the deployments queued for any feature of clusterSummary
+func removePendingDeployments(clusterSummary *configv1alpha1.ClusterSummary) {
+	pendingDeploymentsMux.Lock()
+	defer pendingDeploymentsMux.Unlock()
+
+	prefix := getPendingDeploymentKey(clusterSummary, "")
+	for k := range pendingDeployments {
+		if strings.HasPrefix(k, prefix) {
+			delete(pendingDeployments, k)
+		}
+	}
+}
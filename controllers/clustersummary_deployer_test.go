@@ -18,8 +18,10 @@ package controllers_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/gdexlab/go-render/render"
 	"github.com/go-logr/logr"
@@ -27,7 +29,10 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -230,6 +235,48 @@ var _ = Describe("ClustersummaryDeployer", func() {
 		Expect(reflect.DeepEqual(currentHash, hash)).To(BeTrue())
 	})
 
+	It("isOneTimeProvisioned returns true only for OneTime provisioned features whose hash has not changed", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeOneTime
+
+		initObjects := []client.Object{
+			clusterSummary,
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+
+		clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+		currentHash, err := controllers.ResourcesHash(context.TODO(), c, clusterSummaryScope, logger)
+		Expect(err).To(BeNil())
+
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{
+				FeatureID: configv1alpha1.FeatureResources,
+				Status:    configv1alpha1.FeatureStatusProvisioned,
+				Hash:      currentHash,
+			},
+		}
+		Expect(controllers.IsOneTimeProvisioned(reconciler, context.TODO(), clusterSummaryScope, f, logger)).To(BeTrue())
+
+		// Configuration has changed
+		clusterSummary.Status.FeatureSummaries[0].Hash = []byte(randomString())
+		Expect(controllers.IsOneTimeProvisioned(reconciler, context.TODO(), clusterSummaryScope, f, logger)).To(BeFalse())
+
+		// Feature is not provisioned yet
+		clusterSummary.Status.FeatureSummaries[0].Hash = currentHash
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1alpha1.FeatureStatusProvisioning
+		Expect(controllers.IsOneTimeProvisioned(reconciler, context.TODO(), clusterSummaryScope, f, logger)).To(BeFalse())
+
+		// Sync mode is not OneTime
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1alpha1.FeatureStatusProvisioned
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeContinuous
+		Expect(controllers.IsOneTimeProvisioned(reconciler, context.TODO(), clusterSummaryScope, f, logger)).To(BeFalse())
+	})
+
 	It("updateFeatureStatus updates ClusterSummary Status FeatureSummary", func() {
 		initObjects := []client.Object{
 			clusterSummary,
@@ -523,6 +570,61 @@ var _ = Describe("ClustersummaryDeployer", func() {
 		Expect(dep.IsKeyInProgress(key)).To(BeTrue())
 	})
 
+	It("disableFeature removes deployed resources then marks feature as disabled", func() {
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{
+			{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Kind:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			},
+		}
+		clusterSummary.Spec.ClusterProfileSpec.DisabledFeatures = []configv1alpha1.FeatureID{
+			configv1alpha1.FeatureResources,
+		}
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{
+				FeatureID: configv1alpha1.FeatureResources,
+				Status:    configv1alpha1.FeatureStatusProvisioned,
+				Hash:      []byte(randomString()),
+			},
+		}
+
+		initObjects := []client.Object{
+			clusterSummary,
+			clusterProfile,
+			cluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+		dep := fakedeployer.GetClient(context.TODO(), textlogger.NewLogger(textlogger.NewConfig()), c)
+
+		reconciler := getClusterSummaryReconciler(c, dep)
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+
+		// Feature is deployed. Undeploy request is queued
+		err := controllers.DisableFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+
+		key := deployer.GetKey(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi, true)
+		Expect(dep.IsKeyInProgress(key)).To(BeTrue())
+
+		// Once feature is removed, it is marked as disabled. Configuration is preserved.
+		clusterSummaryScope.SetFeatureStatus(configv1alpha1.FeatureResources, configv1alpha1.FeatureStatusRemoved, nil)
+		Expect(controllers.DisableFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+		Expect(clusterSummaryScope.ClusterSummary.Status.FeatureSummaries).To(HaveLen(1))
+		Expect(clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0].Status).To(
+			Equal(configv1alpha1.FeatureStatusDisabled))
+		Expect(clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0].Hash).To(BeNil())
+		Expect(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PolicyRefs).To(HaveLen(1))
+	})
+
 	//nolint: dupl // better readibility of test
 	It("deployFeature return an error if cleaning up is in progress", func() {
 		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{
@@ -558,7 +660,247 @@ var _ = Describe("ClustersummaryDeployer", func() {
 		Expect(err.Error()).To(Equal("cleanup of Resources still in progress. Wait before redeploying"))
 	})
 
+	It("deployFeature keeps feature provisioning on transient errors", func() {
+		deployErr := fmt.Errorf("dial tcp 10.0.0.1:6443: connect: connection refused")
+		reconciler, clusterSummaryScope, dep := prepareFailedDeployment(clusterSummary, clusterProfile, deployErr, 0)
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+		err := controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		var transientErr *controllers.TransientError
+		Expect(errors.As(err, &transientErr)).To(BeTrue())
+		Expect(transientErr.RetryAfter).To(Equal(10 * time.Second))
+
+		fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+		Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusProvisioning))
+		Expect(fs.ConsecutiveFailures).To(Equal(int32(1)))
+		Expect(fs.FailureMessage).ToNot(BeNil())
+		Expect(*fs.FailureMessage).To(Equal(deployErr.Error()))
+
+		// Failed result is dropped so deployment is retried
+		result := dep.GetResult(context.TODO(), clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.Unavailable))
+	})
+
+	It("deployFeature keeps feature provisioned, and its hash, for every class of transient error", func() {
+		gr := schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+		transientErrors := []error{
+			fmt.Errorf("dial tcp 10.0.0.1:6443: connect: connection refused"),
+			apierrors.NewServiceUnavailable("unavailable"),
+			apierrors.NewTimeoutError("timeout", 1),
+			apierrors.NewServerTimeout(gr, "list", 1),
+			apierrors.NewTooManyRequests("too many requests", 1),
+			context.DeadlineExceeded,
+		}
+
+		for i := range transientErrors {
+			deployErr := transientErrors[i]
+			Expect(controllers.IsTransientError(deployErr)).To(BeTrue())
+
+			currentClusterSummary := clusterSummary.DeepCopy()
+			currentClusterSummary.Name = randomString()
+			reconciler, clusterSummaryScope, dep := prepareFailedDeployment(currentClusterSummary, clusterProfile,
+				deployErr, 0)
+			// Feature was provisioned with a previous configuration
+			provisionedHash := []byte(randomString())
+			clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0].Status = configv1alpha1.FeatureStatusProvisioned
+			clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0].Hash = provisionedHash
+			storer, ok := dep.(resultStorer)
+			Expect(ok).To(BeTrue())
+			dep.CleanupEntries(currentClusterSummary.Spec.ClusterNamespace, currentClusterSummary.Spec.ClusterName,
+				currentClusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi, false)
+
+			f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+
+			// Configuration has changed. Deployment is queued while status and hash are left untouched
+			err := controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+				textlogger.NewLogger(textlogger.NewConfig()))
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal("request is queued"))
+			fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+			Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusProvisioned))
+			Expect(fs.Hash).To(Equal(provisionedHash))
+
+			// Deployment fails because managed cluster API is temporarily unavailable
+			storer.StoreResult(currentClusterSummary.Spec.ClusterNamespace, currentClusterSummary.Spec.ClusterName,
+				currentClusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi,
+				false, deployErr)
+			err = controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+				textlogger.NewLogger(textlogger.NewConfig()))
+			var transientErr *controllers.TransientError
+			Expect(errors.As(err, &transientErr)).To(BeTrue())
+
+			fs = clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+			Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusProvisioned))
+			Expect(fs.Hash).To(Equal(provisionedHash))
+			Expect(fs.ConsecutiveFailures).To(Equal(int32(1)))
+			Expect(fs.FailureMessage).ToNot(BeNil())
+			Expect(*fs.FailureMessage).To(Equal(deployErr.Error()))
+
+			// Once deployment succeeds, status and hash are updated
+			storer.StoreResult(currentClusterSummary.Spec.ClusterNamespace, currentClusterSummary.Spec.ClusterName,
+				currentClusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi,
+				false, nil)
+			Expect(controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+				textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+			fs = clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+			Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusProvisioned))
+			Expect(fs.Hash).ToNot(Equal(provisionedHash))
+			Expect(fs.ConsecutiveFailures).To(BeZero())
+		}
+	})
+
+	It("deployFeature reports feature as provisioning while it is not ready yet", func() {
+		deployErr := &controllers.ProvisioningError{Message: "post deploy job has not completed yet"}
+		reconciler, clusterSummaryScope, dep := prepareFailedDeployment(clusterSummary, clusterProfile, deployErr, 0)
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+		err := controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(errors.Is(err, deployErr)).To(BeTrue())
+
+		fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+		Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusProvisioning))
+		Expect(fs.FailureMessage).To(BeNil())
+
+		// Result is dropped so deployment is queued again
+		result := dep.GetResult(context.TODO(), clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi, false)
+		Expect(result.ResultStatus).To(Equal(deployer.Unavailable))
+
+		err = controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("request is queued"))
+	})
+
+	It("deployFeature marks feature as failed after too many consecutive transient errors", func() {
+		deployErr := apierrors.NewServiceUnavailable("unavailable")
+		reconciler, clusterSummaryScope, _ := prepareFailedDeployment(clusterSummary, clusterProfile, deployErr,
+			int32(controllers.MaxTransientFailures-1))
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+		err := controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		// Deployment keeps being retried
+		var transientErr *controllers.TransientError
+		Expect(errors.As(err, &transientErr)).To(BeTrue())
+		Expect(transientErr.RetryAfter).To(Equal(controllers.GetTransientRetryAfter(int32(controllers.MaxTransientFailures))))
+
+		fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+		Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusFailed))
+		Expect(fs.ConsecutiveFailures).To(Equal(int32(controllers.MaxTransientFailures)))
+
+		condition := meta.FindStatusCondition(clusterSummaryScope.ClusterSummary.Status.Conditions,
+			configv1alpha1.ClusterSummaryStalledCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(configv1alpha1.ClusterSummaryStalledReason))
+		Expect(condition.Message).To(ContainSubstring(fmt.Sprintf("%d consecutive failures", controllers.MaxTransientFailures)))
+		Expect(condition.Message).To(ContainSubstring(deployErr.Error()))
+
+		// Stalled condition is removed once feature is provisioned
+		status := configv1alpha1.FeatureStatusProvisioned
+		controllers.UpdateFeatureStatus(reconciler, clusterSummaryScope, configv1alpha1.FeatureResources, &status,
+			fs.Hash, nil, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(meta.FindStatusCondition(clusterSummaryScope.ClusterSummary.Status.Conditions,
+			configv1alpha1.ClusterSummaryStalledCondition)).To(BeNil())
+	})
+
+	It("getTransientRetryAfter backs off exponentially up to a maximum", func() {
+		Expect(controllers.GetTransientRetryAfter(1)).To(Equal(10 * time.Second))
+		Expect(controllers.GetTransientRetryAfter(2)).To(Equal(20 * time.Second))
+		Expect(controllers.GetTransientRetryAfter(4)).To(Equal(80 * time.Second))
+		Expect(controllers.GetTransientRetryAfter(100)).To(Equal(5 * time.Minute))
+	})
+
+	It("deployFeature marks feature as failed on permanent errors", func() {
+		gr := schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+		deployErr := apierrors.NewForbidden(gr, randomString(), fmt.Errorf("denied"))
+		reconciler, clusterSummaryScope, _ := prepareFailedDeployment(clusterSummary, clusterProfile, deployErr, 0)
+
+		f := controllers.GetHandlersForFeature(configv1alpha1.FeatureResources)
+		err := controllers.DeployFeature(reconciler, context.TODO(), clusterSummaryScope, f, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		var transientErr *controllers.TransientError
+		Expect(errors.As(err, &transientErr)).To(BeFalse())
+
+		fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0]
+		Expect(fs.Status).To(Equal(configv1alpha1.FeatureStatusFailed))
+		Expect(fs.ConsecutiveFailures).To(BeZero())
+	})
+
 	//nolint: dupl // better readibility of test
+	It("updateDryRunDiff sets resources which would be added, changed or removed", func() {
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeDryRun
+		clusterSummary.OwnerReferences = []metav1.OwnerReference{
+			{
+				Kind:       configv1alpha1.ClusterProfileKind,
+				Name:       clusterProfile.Name,
+				APIVersion: configv1alpha1.GroupVersion.String(),
+			},
+		}
+
+		added := configv1alpha1.Resource{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Version: "v1",
+			Name: randomString()}
+		changed := configv1alpha1.Resource{Kind: "Deployment", Group: "apps", Version: "v1",
+			Namespace: randomString(), Name: randomString()}
+		removed := configv1alpha1.Resource{Kind: "ConfigMap", Version: "v1",
+			Namespace: randomString(), Name: randomString()}
+		unchanged := configv1alpha1.Resource{Kind: "Secret", Version: "v1",
+			Namespace: randomString(), Name: randomString()}
+
+		clusterReport := &configv1alpha1.ClusterReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name: controllers.GetClusterReportName(configv1alpha1.ClusterProfileKind, clusterProfile.Name,
+					clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType),
+			},
+			Status: configv1alpha1.ClusterReportStatus{
+				ResourceReports: []configv1alpha1.ResourceReport{
+					{Resource: added, Action: string(configv1alpha1.CreateResourceAction)},
+					{Resource: changed, Action: string(configv1alpha1.UpdateResourceAction)},
+					{Resource: removed, Action: string(configv1alpha1.DeleteResourceAction)},
+					{Resource: unchanged, Action: string(configv1alpha1.NoResourceAction)},
+				},
+			},
+		}
+
+		initObjects := []client.Object{
+			clusterSummary,
+			clusterProfile,
+			clusterReport,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+		controllers.UpdateDryRunDiff(reconciler, context.TODO(), clusterSummaryScope,
+			configv1alpha1.FeatureResources, logger)
+
+		fs := clusterSummaryScope.ClusterSummary.Status.FeatureSummaries
+		Expect(fs).To(HaveLen(1))
+		Expect(fs[0].FeatureID).To(Equal(configv1alpha1.FeatureResources))
+		Expect(fs[0].DryRunDiff).To(ConsistOf(
+			configv1alpha1.DryRunDiffEntry{Kind: added.Kind, Group: added.Group, Version: added.Version,
+				Name: added.Name, Change: configv1alpha1.DryRunChangeAdded},
+			configv1alpha1.DryRunDiffEntry{Kind: changed.Kind, Group: changed.Group, Version: changed.Version,
+				Namespace: changed.Namespace, Name: changed.Name, Change: configv1alpha1.DryRunChangeChanged},
+			configv1alpha1.DryRunDiffEntry{Kind: removed.Kind, Version: removed.Version,
+				Namespace: removed.Namespace, Name: removed.Name, Change: configv1alpha1.DryRunChangeRemoved},
+		))
+
+		// Once feature is provisioned, diff is reset
+		status := configv1alpha1.FeatureStatusProvisioned
+		controllers.UpdateFeatureStatus(reconciler, clusterSummaryScope, configv1alpha1.FeatureResources, &status,
+			[]byte(randomString()), nil, logger)
+		Expect(clusterSummaryScope.ClusterSummary.Status.FeatureSummaries[0].DryRunDiff).To(BeNil())
+	})
+
 	It("undeployFeatures returns an error if deploying is in progress", func() {
 		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{
 			{
@@ -640,3 +982,54 @@ func getClusterSummaryScope(c client.Client, logger logr.Logger,
 	Expect(err).To(BeNil())
 	return clusterSummaryScope
 }
+
+// resultStorer is implemented by the fake deployer and allows tests to pretend a deployment result is available
+type resultStorer interface {
+	StoreResult(clusterNamespace, clusterName, applicant, featureID string,
+		clusterType libsveltosv1alpha1.ClusterType, cleanup bool, err error)
+}
+
+// prepareFailedDeployment returns a reconciler whose deployer reports deploying the Resources feature
+// for clusterSummary failed with deployErr.
+func prepareFailedDeployment(clusterSummary *configv1alpha1.ClusterSummary, clusterProfile *configv1alpha1.ClusterProfile,
+	deployErr error, consecutiveFailures int32) (*controllers.ClusterSummaryReconciler, *scope.ClusterSummaryScope,
+	deployer.DeployerInterface) {
+
+	configMap := createConfigMapWithPolicy("default", randomString(), fmt.Sprintf(viewClusterRole, randomString()))
+	clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{
+		{
+			Namespace: configMap.Namespace,
+			Name:      configMap.Name,
+			Kind:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+		},
+	}
+
+	initObjects := []client.Object{
+		configMap,
+		clusterSummary,
+		clusterProfile,
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+	logger := textlogger.NewLogger(textlogger.NewConfig())
+	clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+	hash, err := controllers.ResourcesHash(context.TODO(), c, clusterSummaryScope, logger)
+	Expect(err).To(BeNil())
+
+	clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+		{
+			FeatureID:           configv1alpha1.FeatureResources,
+			Hash:                hash,
+			Status:              configv1alpha1.FeatureStatusProvisioning,
+			ConsecutiveFailures: consecutiveFailures,
+		},
+	}
+
+	dep := fakedeployer.GetClient(context.TODO(), logger, c)
+	dep.StoreResult(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Name, string(configv1alpha1.FeatureResources), libsveltosv1alpha1.ClusterTypeCapi, false, deployErr)
+
+	return getClusterSummaryReconciler(c, dep), clusterSummaryScope, dep
+}
@@ -48,6 +48,12 @@ func RegisterFeatures(d deployer.DeployerInterface, setupLog logr.Logger) {
 		os.Exit(1)
 	}
 
+	err = d.RegisterFeatureID(string(configv1alpha1.FeaturePodSecurity))
+	if err != nil {
+		setupLog.Error(err, "failed to register feature FeaturePodSecurity")
+		os.Exit(1)
+	}
+
 	creatFeatureHandlerMaps()
 }
 
@@ -62,6 +68,9 @@ func creatFeatureHandlerMaps() {
 
 	featuresHandlers[configv1alpha1.FeatureKustomize] = feature{id: configv1alpha1.FeatureKustomize, currentHash: kustomizationHash,
 		deploy: deployKustomizeRefs, undeploy: undeployKustomizeRefs, getRefs: getKustomizationRefs}
+
+	featuresHandlers[configv1alpha1.FeaturePodSecurity] = feature{id: configv1alpha1.FeaturePodSecurity, currentHash: podSecurityHash,
+		deploy: deployPodSecurity, undeploy: undeployPodSecurity, getRefs: getPodSecurityRefs}
 }
 
 func getHandlersForFeature(featureID configv1alpha1.FeatureID) feature {
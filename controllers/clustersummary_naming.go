@@ -0,0 +1,95 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+// ClusterSummaryNamingScheme defines how the name of a new ClusterSummary is derived.
+// ClusterSummaries are always looked up by labels, so existing ClusterSummaries keep
+// being found when the scheme changes.
+type ClusterSummaryNamingScheme string
+
+const (
+	// ClusterSummaryNamingLegacy names a ClusterSummary joining profile name, cluster type and
+	// cluster name with dashes. Since dashes can be part of names, two different profile/cluster
+	// pairs might end up with the same name.
+	ClusterSummaryNamingLegacy = ClusterSummaryNamingScheme("legacy")
+
+	// ClusterSummaryNamingHashed appends to the legacy name a hash of profile kind/name and
+	// cluster type/name, so that the name is unique for each profile/cluster pair. The legacy
+	// part is truncated if needed to keep the name within the label value length limit.
+	ClusterSummaryNamingHashed = ClusterSummaryNamingScheme("hashed")
+)
+
+const (
+	// clusterSummaryNameHashLength is the number of hex characters of the hash
+	// appended to ClusterSummary names by the hashed naming scheme
+	clusterSummaryNameHashLength = 16
+
+	// maxClusterSummaryNameLength is the maximum length of a label value. ClusterSummary name
+	// is used as label value, for instance on ResourceSummaries and PostDeployJobs.
+	maxClusterSummaryNameLength = validation.LabelValueMaxLength
+)
+
+var (
+	clusterSummaryNaming = ClusterSummaryNamingLegacy
+)
+
+// SetClusterSummaryNamingScheme sets how the name of new ClusterSummaries is derived
+func SetClusterSummaryNamingScheme(naming ClusterSummaryNamingScheme) error {
+	switch naming {
+	case ClusterSummaryNamingLegacy, ClusterSummaryNamingHashed:
+		clusterSummaryNaming = naming
+		return nil
+	default:
+		return fmt.Errorf("unknown ClusterSummary naming scheme %q", naming)
+	}
+}
+
+func getClusterSummaryNamingScheme() ClusterSummaryNamingScheme {
+	return clusterSummaryNaming
+}
+
+// getClusterSummaryNameHash returns a hash identifying a profile/cluster pair. Fields are
+// separated by a character which cannot be part of a Kubernetes name.
+func getClusterSummaryNameHash(profileKind, profileName, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) string {
+
+	h := sha256.Sum256([]byte(strings.Join([]string{profileKind, profileName, string(clusterType), clusterName}, "/")))
+	return hex.EncodeToString(h[:])[:clusterSummaryNameHashLength]
+}
+
+// addClusterSummaryNameHash appends the profile/cluster pair hash to name, truncating name
+// if needed
+func addClusterSummaryNameHash(name, profileKind, profileName, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) string {
+
+	maxLength := maxClusterSummaryNameLength - clusterSummaryNameHashLength - 1
+	if len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-.")
+	}
+	return fmt.Sprintf("%s-%s", name, getClusterSummaryNameHash(profileKind, profileName, clusterName, clusterType))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("ClusterSummary naming", func() {
+	AfterEach(func() {
+		Expect(controllers.SetClusterSummaryNamingScheme(controllers.ClusterSummaryNamingLegacy)).To(Succeed())
+	})
+
+	It("SetClusterSummaryNamingScheme rejects unknown schemes", func() {
+		Expect(controllers.SetClusterSummaryNamingScheme(controllers.ClusterSummaryNamingScheme(randomString()))).
+			ToNot(Succeed())
+	})
+
+	It("GetClusterSummaryName with legacy scheme preserves existing names", func() {
+		Expect(controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind, "profile", "cluster", false)).
+			To(Equal("profile-capi-cluster"))
+		Expect(controllers.GetClusterSummaryName(configv1alpha1.ProfileKind, "profile", "cluster", true)).
+			To(Equal("p--profile-sveltos-cluster"))
+	})
+
+	It("GetClusterSummaryName with hashed scheme returns unique names", func() {
+		// With the legacy scheme those profile/cluster pairs collide
+		Expect(controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind, "a", "capi-b", false)).
+			To(Equal(controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind, "a-capi", "b", false)))
+
+		Expect(controllers.SetClusterSummaryNamingScheme(controllers.ClusterSummaryNamingHashed)).To(Succeed())
+
+		names := map[string]bool{}
+		for _, profileKind := range []string{configv1alpha1.ClusterProfileKind, configv1alpha1.ProfileKind} {
+			for _, isSveltos := range []bool{true, false} {
+				for _, pair := range [][2]string{{"a", "capi-b"}, {"a-capi", "b"}, {"a", "sveltos-b"}, {"a-sveltos", "b"}} {
+					name := controllers.GetClusterSummaryName(profileKind, pair[0], pair[1], isSveltos)
+					Expect(names).ToNot(HaveKey(name))
+					names[name] = true
+
+					// Name derivation is deterministic
+					Expect(controllers.GetClusterSummaryName(profileKind, pair[0], pair[1], isSveltos)).To(Equal(name))
+				}
+			}
+		}
+	})
+
+	It("GetClusterSummaryName with hashed scheme keeps names within label value length limit", func() {
+		Expect(controllers.SetClusterSummaryNamingScheme(controllers.ClusterSummaryNamingHashed)).To(Succeed())
+
+		profileName := strings.Repeat("p", 200)
+		clusterName := strings.Repeat("c", 200)
+		name := controllers.GetClusterSummaryName(configv1alpha1.ProfileKind, profileName, clusterName, true)
+		Expect(len(name)).To(BeNumerically("<=", validation.LabelValueMaxLength))
+		Expect(validation.IsValidLabelValue(name)).To(BeEmpty())
+		Expect(name).To(HavePrefix("p--ppp"))
+
+		otherName := controllers.GetClusterSummaryName(configv1alpha1.ProfileKind, profileName, clusterName+"c", true)
+		Expect(otherName).ToNot(Equal(name))
+	})
+
+	It("GetClusterSummaryName with hashed scheme returns names usable as label values", func() {
+		Expect(controllers.SetClusterSummaryNamingScheme(controllers.ClusterSummaryNamingHashed)).To(Succeed())
+
+		// Truncation point falls right after a separator
+		profileName := strings.Repeat("a", 45) + "-" + strings.Repeat("b", 10)
+		clusterName := "cluster-" + randomString()
+		for _, profileKind := range []string{configv1alpha1.ClusterProfileKind, configv1alpha1.ProfileKind} {
+			for _, isSveltos := range []bool{true, false} {
+				name := controllers.GetClusterSummaryName(profileKind, profileName, clusterName, isSveltos)
+				Expect(validation.IsValidLabelValue(name)).To(BeEmpty())
+				Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+			}
+		}
+	})
+})
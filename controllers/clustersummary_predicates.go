@@ -28,9 +28,93 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
+// ClusterSummaryPredicates predicates for ClusterSummary. ClusterSummaryReconciler reconciles a
+// ClusterSummary only when its spec or metadata changes, or when the hash of any of its features is
+// reset (drift detection or a referenced resource change, which require features to be redeployed).
+// Updates only touching status, like the ones the reconciler itself makes, are ignored.
+func ClusterSummaryPredicates(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			newClusterSummary := e.ObjectNew.(*configv1alpha1.ClusterSummary)
+			oldClusterSummary := e.ObjectOld.(*configv1alpha1.ClusterSummary)
+			log := logger.WithValues("predicate", "updateEvent",
+				"clustersummary", newClusterSummary.Name,
+			)
+
+			if oldClusterSummary == nil {
+				log.V(logs.LogVerbose).Info("Old ClusterSummary is nil. Reconcile ClusterSummary.")
+				return true
+			}
+
+			if hasMetadataChanged(oldClusterSummary, newClusterSummary) {
+				log.V(logs.LogVerbose).Info(
+					"ClusterSummary spec or metadata changed. Will attempt to reconcile ClusterSummary.",
+				)
+				return true
+			}
+
+			if isAnyFeatureHashReset(oldClusterSummary, newClusterSummary) {
+				log.V(logs.LogVerbose).Info(
+					"ClusterSummary feature hash was reset. Will attempt to reconcile ClusterSummary.",
+				)
+				return true
+			}
+
+			// otherwise, return false
+			log.V(logs.LogVerbose).Info(
+				"ClusterSummary status only changed. Will not attempt to reconcile ClusterSummary.")
+			return false
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return CreateFuncTrue(e, logger)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return DeleteFuncTrue(e, logger)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return true
+		},
+	}
+}
+
+// hasMetadataChanged returns true if spec (generation), labels, annotations or deletion
+// timestamp are different
+func hasMetadataChanged(oldObj, newObj client.Object) bool {
+	if oldObj.GetGeneration() != newObj.GetGeneration() {
+		return true
+	}
+
+	if !reflect.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) {
+		return true
+	}
+
+	if !reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) {
+		return true
+	}
+
+	return !reflect.DeepEqual(oldObj.GetDeletionTimestamp(), newObj.GetDeletionTimestamp())
+}
+
+// isAnyFeatureHashReset returns true if a feature with a hash in oldClusterSummary has none
+// in newClusterSummary. Hashes are reset whenever features need to be redeployed.
+func isAnyFeatureHashReset(oldClusterSummary, newClusterSummary *configv1alpha1.ClusterSummary) bool {
+	for i := range oldClusterSummary.Status.FeatureSummaries {
+		oldFs := &oldClusterSummary.Status.FeatureSummaries[i]
+		if oldFs.Hash == nil {
+			continue
+		}
+		newFs := getFeatureSummaryForFeatureID(newClusterSummary, oldFs.FeatureID)
+		if newFs == nil || newFs.Hash == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // ConfigMapPredicates predicates for ConfigMaps. ClusterSummaryReconciler watches ConfigMap events
 // and react to those by reconciling itself based on following predicates
 func ConfigMapPredicates(logger logr.Logger) predicate.Funcs {
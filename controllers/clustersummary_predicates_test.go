@@ -30,6 +30,7 @@ import (
 	"k8s.io/klog/v2/textlogger"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	"github.com/projectsveltos/addon-controller/controllers"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
 )
@@ -337,3 +338,62 @@ var _ = Describe("ClusterProfile Predicates: FluxSourcePredicates", func() {
 		Expect(result).To(BeFalse())
 	})
 })
+
+var _ = Describe("Clustersummary Predicates: ClusterSummaryPredicates", func() {
+	var logger logr.Logger
+	var clusterSummary *configv1alpha1.ClusterSummary
+
+	BeforeEach(func() {
+		logger = textlogger.NewLogger(textlogger.NewConfig())
+		clusterSummary = &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  randomString(),
+				Name:       randomString(),
+				Generation: 1,
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{
+						FeatureID: configv1alpha1.FeatureResources,
+						Status:    configv1alpha1.FeatureStatusProvisioned,
+						Hash:      []byte(randomString()),
+					},
+				},
+			},
+		}
+	})
+
+	It("Update returns true when spec changes", func() {
+		clusterSummaryPredicate := controllers.ClusterSummaryPredicates(logger)
+
+		oldClusterSummary := clusterSummary.DeepCopy()
+		clusterSummary.Generation = 2
+
+		result := clusterSummaryPredicate.Update(event.UpdateEvent{ObjectNew: clusterSummary, ObjectOld: oldClusterSummary})
+		Expect(result).To(BeTrue())
+	})
+
+	It("Update returns true when a feature hash is reset", func() {
+		clusterSummaryPredicate := controllers.ClusterSummaryPredicates(logger)
+
+		oldClusterSummary := clusterSummary.DeepCopy()
+		clusterSummary.Status.FeatureSummaries[0].Hash = nil
+		clusterSummary.Status.FeatureSummaries[0].Status = configv1alpha1.FeatureStatusProvisioning
+
+		result := clusterSummaryPredicate.Update(event.UpdateEvent{ObjectNew: clusterSummary, ObjectOld: oldClusterSummary})
+		Expect(result).To(BeTrue())
+	})
+
+	It("Update returns false when only status changes", func() {
+		clusterSummaryPredicate := controllers.ClusterSummaryPredicates(logger)
+
+		oldClusterSummary := clusterSummary.DeepCopy()
+		oldClusterSummary.Status.FeatureSummaries[0].Status = configv1alpha1.FeatureStatusProvisioning
+		oldClusterSummary.Status.FeatureSummaries[0].Hash = nil
+		failureMessage := randomString()
+		clusterSummary.Status.FeatureSummaries[0].FailureMessage = &failureMessage
+
+		result := clusterSummaryPredicate.Update(event.UpdateEvent{ObjectNew: clusterSummary, ObjectOld: oldClusterSummary})
+		Expect(result).To(BeFalse())
+	})
+})
@@ -21,9 +21,12 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
@@ -121,6 +124,40 @@ func (r *ClusterSummaryReconciler) requeueClusterSummaryForFluxSource(
 	return requests
 }
 
+// enqueueClusterSummaryForReference returns the handler used to react to ConfigMap/Secret changes.
+// When ReferenceDebounce is set, changes are debounced per ClusterSummary: a ClusterSummary is
+// requeued only once none of the resources it references has changed for ReferenceDebounce.
+// So rapid successive changes to referenced resources result in a single reconciliation, which
+// will use the latest content of the referenced resources.
+func (r *ClusterSummaryReconciler) enqueueClusterSummaryForReference() handler.EventHandler {
+	if r.ReferenceDebounce <= 0 {
+		return handler.EnqueueRequestsFromMapFunc(r.requeueClusterSummaryForReference)
+	}
+
+	debouncer := newReferenceDebouncer(r.ReferenceDebounce)
+	enqueue := func(ctx context.Context, o client.Object, q workqueue.RateLimitingInterface) {
+		requests := r.requeueClusterSummaryForReference(ctx, o)
+		for i := range requests {
+			debouncer.add(requests[i], q)
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.ObjectNew, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, q)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, e.Object, q)
+		},
+	}
+}
+
 func (r *ClusterSummaryReconciler) requeueClusterSummaryForReference(
 	ctx context.Context, o client.Object,
 ) []reconcile.Request {
@@ -19,6 +19,7 @@ package controllers_test
 import (
 	"context"
 	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,8 +27,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
@@ -130,4 +133,97 @@ var _ = Describe("ClusterSummaryTransformations map functions", func() {
 		Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterSummary0.Name}}))
 		Expect(requests).To(ContainElement(reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterSummary1.Name}}))
 	})
+
+	It("enqueueClusterSummaryForReference coalesces rapid changes when ReferenceDebounce is set", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+		}
+
+		Expect(addTypeInformationToObject(scheme, configMap)).To(Succeed())
+
+		clusterSummaryName := randomString()
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:            fake.NewClientBuilder().WithScheme(scheme).Build(),
+			Scheme:            scheme,
+			ClusterMap:        make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ReferenceMap:      make(map[corev1.ObjectReference]*libsveltosset.Set),
+			PolicyMux:         sync.Mutex{},
+			ReferenceDebounce: time.Second,
+		}
+
+		set := libsveltosset.Set{}
+		key := corev1.ObjectReference{APIVersion: configMap.APIVersion,
+			Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind), Namespace: configMap.Namespace, Name: configMap.Name}
+		set.Insert(&corev1.ObjectReference{APIVersion: configv1alpha1.GroupVersion.String(),
+			Kind: configv1alpha1.ClusterSummaryKind, Name: clusterSummaryName})
+		reconciler.ReferenceMap[key] = &set
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h := controllers.EnqueueClusterSummaryForReference(reconciler)
+		const updates = 5
+		for i := 0; i < updates; i++ {
+			h.Update(context.TODO(), event.UpdateEvent{ObjectOld: configMap, ObjectNew: configMap}, q)
+		}
+
+		// Nothing is queued before debounce window is over
+		Expect(q.Len()).To(Equal(0))
+
+		// All changes result in one single request
+		Eventually(q.Len, 5*time.Second, 100*time.Millisecond).Should(Equal(1))
+		Consistently(q.Len, 2*time.Second, 100*time.Millisecond).Should(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterSummaryName}}))
+	})
+
+	It("enqueueClusterSummaryForReference postpones requeue while referenced resources keep changing", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+		}
+
+		Expect(addTypeInformationToObject(scheme, configMap)).To(Succeed())
+
+		clusterSummaryName := randomString()
+
+		reconciler := &controllers.ClusterSummaryReconciler{
+			Client:            fake.NewClientBuilder().WithScheme(scheme).Build(),
+			Scheme:            scheme,
+			ClusterMap:        make(map[corev1.ObjectReference]*libsveltosset.Set),
+			ReferenceMap:      make(map[corev1.ObjectReference]*libsveltosset.Set),
+			PolicyMux:         sync.Mutex{},
+			ReferenceDebounce: time.Second,
+		}
+
+		set := libsveltosset.Set{}
+		key := corev1.ObjectReference{APIVersion: configMap.APIVersion,
+			Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind), Namespace: configMap.Namespace, Name: configMap.Name}
+		set.Insert(&corev1.ObjectReference{APIVersion: configv1alpha1.GroupVersion.String(),
+			Kind: configv1alpha1.ClusterSummaryKind, Name: clusterSummaryName})
+		reconciler.ReferenceMap[key] = &set
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h := controllers.EnqueueClusterSummaryForReference(reconciler)
+
+		// Changes are 500ms apart, so the quiet period is never over while they keep coming
+		// even if overall they span more than ReferenceDebounce.
+		const updates = 4
+		for i := 0; i < updates; i++ {
+			h.Update(context.TODO(), event.UpdateEvent{ObjectOld: configMap, ObjectNew: configMap}, q)
+			time.Sleep(500 * time.Millisecond)
+			Expect(q.Len()).To(Equal(0))
+		}
+
+		Eventually(q.Len, 5*time.Second, 100*time.Millisecond).Should(Equal(1))
+		Consistently(q.Len, 2*time.Second, 100*time.Millisecond).Should(Equal(1))
+	})
 })
@@ -0,0 +1,280 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// CompletionActionDeploy is reported when deploying a feature completes
+	CompletionActionDeploy = "Deploy"
+
+	// CompletionActionUndeploy is reported when removing a feature completes
+	CompletionActionUndeploy = "Undeploy"
+
+	// CompletionSignatureHeader contains the HMAC-SHA256 signature of the notification body,
+	// in the form sha256=<hex digest>. Only set when a signing secret is configured.
+	CompletionSignatureHeader = "X-Sveltos-Signature"
+
+	// completionSecretKey is the key, in the signing Secret, containing the HMAC key
+	completionSecretKey = "key"
+
+	// completionQueueSize is the number of notifications waiting to be sent. When the queue is
+	// full new notifications are dropped, so notifications never slow down deployments.
+	completionQueueSize = 1000
+
+	completionWebhookTimeout = 5 * time.Second
+	completionMaxAttempts    = 4
+)
+
+// CompletionNotification is sent to the completion webhook when deploying or removing a
+// feature in a managed cluster completes
+type CompletionNotification struct {
+	Timestamp        metav1.Time `json:"timestamp"`
+	Action           string      `json:"action"`
+	ClusterNamespace string      `json:"clusterNamespace"`
+	ClusterName      string      `json:"clusterName"`
+	ClusterType      string      `json:"clusterType"`
+	ProfileKind      string      `json:"profileKind,omitempty"`
+	ProfileName      string      `json:"profileName,omitempty"`
+	ClusterSummary   string      `json:"clusterSummary"`
+	FeatureID        string      `json:"featureID"`
+	Status           string      `json:"status"`
+	Hash             string      `json:"hash,omitempty"`
+	FailureMessage   string      `json:"failureMessage,omitempty"`
+}
+
+// completionRequest is a notification along with the webhook it needs to be sent to
+type completionRequest struct {
+	webhook      configv1alpha1.CompletionWebhook
+	notification *CompletionNotification
+}
+
+var (
+	completionMux         sync.Mutex
+	completionQueue       chan *completionRequest
+	defaultCompletionHook *configv1alpha1.CompletionWebhook
+	completionLogger      = ctrl.Log.WithName("completion-webhook")
+	// completionRetryInterval is the delay before the first retry. It doubles at each attempt.
+	completionRetryInterval = time.Second
+)
+
+// SetCompletionWebhook starts sending completion notifications. Notifications are sent to the
+// CompletionWebhook of the ClusterProfile/Profile when set, to webhookURL otherwise.
+// When secret (in the form namespace/name) is set, notifications sent to webhookURL are signed
+// using the key contained in that Secret.
+// Notifications are sent asynchronously and retried on failure.
+func SetCompletionWebhook(ctx context.Context, c client.Client, webhookURL, secret string) error {
+	var hook *configv1alpha1.CompletionWebhook
+	if webhookURL != "" {
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid completion webhook URL %q", webhookURL)
+		}
+		hook = &configv1alpha1.CompletionWebhook{URL: webhookURL}
+		if secret != "" {
+			namespace, name, found := strings.Cut(secret, "/")
+			if !found || namespace == "" || name == "" {
+				return fmt.Errorf("completion webhook secret must be in the form namespace/name")
+			}
+			hook.SecretRef = &corev1.SecretReference{Namespace: namespace, Name: name}
+		}
+	} else if secret != "" {
+		return fmt.Errorf("completion webhook secret is set but webhook URL is not")
+	}
+
+	completionMux.Lock()
+	defer completionMux.Unlock()
+
+	defaultCompletionHook = hook
+	completionQueue = make(chan *completionRequest, completionQueueSize)
+	go sendCompletionNotifications(ctx, c, completionQueue)
+
+	return nil
+}
+
+// notifyCompletion queues a notification reporting that deploying (or removing) featureID
+// in the cluster completed with status. No-op unless a completion webhook is configured.
+func notifyCompletion(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID,
+	action string, status configv1alpha1.FeatureStatus, hash []byte, failure error) {
+
+	completionMux.Lock()
+	queue := completionQueue
+	hook := defaultCompletionHook
+	completionMux.Unlock()
+
+	if clusterSummary.Spec.ClusterProfileSpec.CompletionWebhook != nil {
+		hook = clusterSummary.Spec.ClusterProfileSpec.CompletionWebhook
+	}
+	if queue == nil || hook == nil {
+		return
+	}
+
+	request := &completionRequest{
+		webhook:      *hook,
+		notification: newCompletionNotification(clusterSummary, featureID, action, status, hash, failure),
+	}
+	select {
+	case queue <- request:
+	default:
+		completionLogger.V(logs.LogInfo).Info(fmt.Sprintf("completion queue is full. Dropping notification for %s %s",
+			clusterSummary.Name, featureID))
+	}
+}
+
+func newCompletionNotification(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID,
+	action string, status configv1alpha1.FeatureStatus, hash []byte, failure error) *CompletionNotification {
+
+	notification := &CompletionNotification{
+		Timestamp:        metav1.NewTime(time.Now()),
+		Action:           action,
+		ClusterNamespace: clusterSummary.Spec.ClusterNamespace,
+		ClusterName:      clusterSummary.Spec.ClusterName,
+		ClusterType:      string(clusterSummary.Spec.ClusterType),
+		ClusterSummary:   clusterSummary.Name,
+		FeatureID:        string(featureID),
+		Status:           string(status),
+	}
+	if len(hash) != 0 {
+		notification.Hash = fmt.Sprintf("%x", hash)
+	}
+	if failure != nil {
+		notification.FailureMessage = failure.Error()
+	}
+
+	if profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary); err == nil &&
+		profileOwnerRef != nil {
+
+		notification.ProfileKind = profileOwnerRef.Kind
+		notification.ProfileName = profileOwnerRef.Name
+	}
+
+	return notification
+}
+
+func sendCompletionNotifications(ctx context.Context, c client.Client, queue chan *completionRequest) {
+	httpClient := &http.Client{Timeout: completionWebhookTimeout}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case request := <-queue:
+			if err := postCompletionNotification(ctx, c, httpClient, request); err != nil {
+				completionLogger.V(logs.LogInfo).Info(fmt.Sprintf("failed to send completion notification for %s %s: %v",
+					request.notification.ClusterSummary, request.notification.FeatureID, err))
+			}
+		}
+	}
+}
+
+// postCompletionNotification sends the notification, retrying with exponential backoff on failure
+func postCompletionNotification(ctx context.Context, c client.Client, httpClient *http.Client,
+	request *completionRequest) error {
+
+	data, err := json.Marshal(request.notification)
+	if err != nil {
+		return err
+	}
+
+	var signature string
+	if request.webhook.SecretRef != nil {
+		key, err := getCompletionSigningKey(ctx, c, request.webhook.SecretRef)
+		if err != nil {
+			return err
+		}
+		signature = signCompletionNotification(key, data)
+	}
+
+	retryAfter := completionRetryInterval
+	for attempt := 1; ; attempt++ {
+		err = doPostCompletionNotification(ctx, httpClient, request.webhook.URL, signature, data)
+		if err == nil || attempt == completionMaxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryAfter):
+		}
+		retryAfter *= 2
+	}
+}
+
+func doPostCompletionNotification(ctx context.Context, httpClient *http.Client, webhookURL, signature string,
+	data []byte) error {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(CompletionSignatureHeader, signature)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("completion webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func getCompletionSigningKey(ctx context.Context, c client.Client, ref *corev1.SecretReference) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get completion webhook Secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	key := secret.Data[completionSecretKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("completion webhook Secret %s/%s does not contain key %s",
+			ref.Namespace, ref.Name, completionSecretKey)
+	}
+	return key, nil
+}
+
+// signCompletionNotification returns the HMAC-SHA256 signature of data
+func signCompletionNotification(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Completion webhook", func() {
+	It("SetCompletionWebhook validates the configuration", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		Expect(controllers.SetCompletionWebhook(ctx, c, "ftp://"+randomString(), "")).ToNot(Succeed())
+		Expect(controllers.SetCompletionWebhook(ctx, c, "https://"+randomString(), randomString())).ToNot(Succeed())
+		Expect(controllers.SetCompletionWebhook(ctx, c, "", randomString()+"/"+randomString())).ToNot(Succeed())
+		Expect(controllers.SetCompletionWebhook(ctx, c, "", "")).To(Succeed())
+	})
+
+	It("notifyCompletion posts a signed notification and retries on failure", func() {
+		key := []byte(randomString())
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Data:       map[string][]byte{"key": key},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+		var mux sync.Mutex
+		attempts := 0
+		var body []byte
+		var signature string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			defer mux.Unlock()
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ = io.ReadAll(r.Body)
+			signature = r.Header.Get(controllers.CompletionSignatureHeader)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		controllers.SetCompletionRetryInterval(10 * time.Millisecond)
+		Expect(controllers.SetCompletionWebhook(ctx, c, server.URL,
+			secret.Namespace+"/"+secret.Name)).To(Succeed())
+		defer func() {
+			Expect(controllers.SetCompletionWebhook(ctx, c, "", "")).To(Succeed())
+		}()
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: randomString(), Name: randomString()},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeSveltos,
+			},
+		}
+		hash := []byte(randomString())
+		controllers.NotifyCompletion(clusterSummary, configv1alpha1.FeatureHelm, controllers.CompletionActionDeploy,
+			configv1alpha1.FeatureStatusProvisioned, hash, nil)
+
+		Eventually(func() bool {
+			mux.Lock()
+			defer mux.Unlock()
+			return body != nil
+		}, time.Minute, 10*time.Millisecond).Should(BeTrue())
+
+		mux.Lock()
+		defer mux.Unlock()
+		Expect(attempts).To(Equal(2))
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		Expect(signature).To(Equal("sha256=" + hex.EncodeToString(mac.Sum(nil))))
+
+		notification := &controllers.CompletionNotification{}
+		Expect(json.Unmarshal(body, notification)).To(Succeed())
+		Expect(notification.Action).To(Equal(controllers.CompletionActionDeploy))
+		Expect(notification.ClusterNamespace).To(Equal(clusterSummary.Spec.ClusterNamespace))
+		Expect(notification.ClusterName).To(Equal(clusterSummary.Spec.ClusterName))
+		Expect(notification.FeatureID).To(Equal(string(configv1alpha1.FeatureHelm)))
+		Expect(notification.Status).To(Equal(string(configv1alpha1.FeatureStatusProvisioned)))
+		Expect(notification.Hash).To(Equal(hex.EncodeToString(hash)))
+	})
+})
@@ -0,0 +1,195 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// deployCopyRefs copies, in the managed cluster, all ConfigMaps/Secrets referenced by CopyRefs.
+// Copies are deployed, and so tracked for conflicts and cleanup, like any other resource
+// deployed by the Resources feature.
+func deployCopyRefs(ctx context.Context, c client.Client, remoteConfig *rest.Config,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) ([]configv1alpha1.ResourceReport, error) {
+
+	copyRefs := clusterSummary.Spec.ClusterProfileSpec.CopyRefs
+	if len(copyRefs) == 0 {
+		return nil, nil
+	}
+
+	remoteClient, err := client.New(remoteConfig, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []configv1alpha1.ResourceReport
+	for i := range copyRefs {
+		copyRef := &copyRefs[i]
+
+		object, err := getCopyRefObject(ctx, c, clusterSummary, copyRef)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				msg := getMissingReferenceMessage(copyRef.Kind, copyRef.Namespace, copyRef.Name)
+				logger.V(logs.LogInfo).Info(msg)
+				return reports, &NonRetriableError{Message: msg}
+			}
+			return reports, err
+		}
+
+		policy, err := getCopiedObject(object, copyRef.TargetNamespace)
+		if err != nil {
+			return reports, err
+		}
+
+		ref := &corev1.ObjectReference{
+			Kind:      copyRef.Kind,
+			Namespace: object.GetNamespace(),
+			Name:      object.GetName(),
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("copying %s %s/%s", ref.Kind, ref.Namespace, ref.Name))
+		tmpReports, err := deployUnstructured(ctx, false, remoteConfig, remoteClient,
+			[]*unstructured.Unstructured{policy}, ref, configv1alpha1.FeatureResources, clusterSummary, logger)
+		reports = append(reports, tmpReports...)
+		if err != nil {
+			return reports, err
+		}
+	}
+
+	return reports, nil
+}
+
+// getCopyRefObject returns the ConfigMap/Secret referenced by copyRef
+func getCopyRefObject(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	copyRef *configv1alpha1.CopyRef) (client.Object, error) {
+
+	namespace := getReferenceResourceNamespace(clusterSummary.Namespace, copyRef.Namespace)
+	key := types.NamespacedName{Namespace: namespace, Name: copyRef.Name}
+
+	if copyRef.Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
+		return getSecret(ctx, c, key)
+	}
+	return getConfigMap(ctx, c, key)
+}
+
+// getCopiedObject returns the copy of a ConfigMap/Secret to create in the managed cluster. Only
+// name, labels, annotations and content are kept. When targetNamespace is set, the copy is
+// created in that namespace.
+func getCopiedObject(object client.Object, targetNamespace string) (*unstructured.Unstructured, error) {
+	objectMeta := metav1.ObjectMeta{
+		Namespace:   object.GetNamespace(),
+		Name:        object.GetName(),
+		Labels:      object.GetLabels(),
+		Annotations: filterCopiedAnnotations(object.GetAnnotations()),
+	}
+	if targetNamespace != "" {
+		objectMeta.Namespace = targetNamespace
+	}
+
+	var copied runtime.Object
+	switch o := object.(type) {
+	case *corev1.ConfigMap:
+		copied = &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+				APIVersion: corev1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: objectMeta,
+			Immutable:  o.Immutable,
+			Data:       o.Data,
+			BinaryData: o.BinaryData,
+		}
+	case *corev1.Secret:
+		copied = &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       string(libsveltosv1alpha1.SecretReferencedResourceKind),
+				APIVersion: corev1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: objectMeta,
+			Immutable:  o.Immutable,
+			Type:       o.Type,
+			Data:       o.Data,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", object.GetObjectKind().GroupVersionKind().Kind)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(copied)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// filterCopiedAnnotations drops annotations only meaningful for the original object
+func filterCopiedAnnotations(annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	filtered := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if k == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered
+}
+
+// getCopyRefsHash returns a string representing CopyRefs and the content of the referenced
+// ConfigMaps/Secrets. Missing ones are ignored: deployment reports them.
+func getCopyRefsHash(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary) string {
+	var config string
+	for i := range clusterSummary.Spec.ClusterProfileSpec.CopyRefs {
+		copyRef := &clusterSummary.Spec.ClusterProfileSpec.CopyRefs[i]
+		config += fmt.Sprintf("%s:%s/%s:%s", copyRef.Kind, copyRef.Namespace, copyRef.Name, copyRef.TargetNamespace)
+
+		object, err := getCopyRefObject(ctx, c, clusterSummary, copyRef)
+		if err != nil {
+			continue
+		}
+
+		config += getReferencedObjectIdentity(object)
+		config += getDataSectionHash(object.GetLabels())
+		config += getDataSectionHash(filterCopiedAnnotations(object.GetAnnotations()))
+		switch o := object.(type) {
+		case *corev1.ConfigMap:
+			config += getDataSectionHash(o.Data)
+			config += getDataSectionHash(o.BinaryData)
+		case *corev1.Secret:
+			config += string(o.Type)
+			config += getDataSectionHash(o.Data)
+		}
+	}
+	return config
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("CopyRefs", func() {
+	It("getCopiedObject copies ConfigMap content into target namespace", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       randomString(),
+				Name:            randomString(),
+				Labels:          map[string]string{randomString(): randomString()},
+				ResourceVersion: "10",
+				UID:             "abc",
+				Annotations: map[string]string{
+					corev1.LastAppliedConfigAnnotation: randomString(),
+				},
+			},
+			Data:       map[string]string{randomString(): randomString()},
+			BinaryData: map[string][]byte{randomString(): []byte(randomString())},
+		}
+
+		targetNamespace := randomString()
+		u, err := controllers.GetCopiedObject(configMap, targetNamespace)
+		Expect(err).To(BeNil())
+		Expect(u.GetKind()).To(Equal(string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)))
+		Expect(u.GetNamespace()).To(Equal(targetNamespace))
+		Expect(u.GetName()).To(Equal(configMap.Name))
+		Expect(u.GetResourceVersion()).To(BeEmpty())
+		Expect(u.GetUID()).To(BeEmpty())
+		Expect(u.GetLabels()).To(Equal(configMap.Labels))
+		Expect(u.GetAnnotations()).To(BeEmpty())
+
+		copied := &corev1.ConfigMap{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, copied)).To(Succeed())
+		Expect(copied.Data).To(Equal(configMap.Data))
+		Expect(copied.BinaryData).To(Equal(configMap.BinaryData))
+	})
+
+	It("getCopiedObject keeps Secret namespace and type when target namespace is not set", func() {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Type: libsveltosv1alpha1.ClusterProfileSecretType,
+			Data: map[string][]byte{randomString(): []byte(randomString())},
+		}
+
+		u, err := controllers.GetCopiedObject(secret, "")
+		Expect(err).To(BeNil())
+		Expect(u.GetKind()).To(Equal(string(libsveltosv1alpha1.SecretReferencedResourceKind)))
+		Expect(u.GetNamespace()).To(Equal(secret.Namespace))
+
+		copied := &corev1.Secret{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, copied)).To(Succeed())
+		Expect(copied.Type).To(Equal(secret.Type))
+		Expect(copied.Data).To(Equal(secret.Data))
+	})
+
+	It("getCopyRefsHash changes when referenced ConfigMap changes", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{randomString(): randomString()},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: configMap.Namespace,
+				Name:      randomString(),
+			},
+		}
+		clusterSummary.Spec.ClusterProfileSpec.CopyRefs = []configv1alpha1.CopyRef{
+			{Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind), Name: configMap.Name},
+		}
+
+		hash := controllers.GetCopyRefsHash(context.TODO(), c, clusterSummary)
+
+		configMap.Data = map[string]string{randomString(): randomString()}
+		Expect(c.Update(context.TODO(), configMap)).To(Succeed())
+		Expect(controllers.GetCopyRefsHash(context.TODO(), c, clusterSummary)).ToNot(Equal(hash))
+	})
+})
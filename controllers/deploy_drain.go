@@ -0,0 +1,163 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// interruptedDeployMessage is reported as failure message for features whose deployment
+	// was still in progress when the controller shut down
+	interruptedDeployMessage = "deployment interrupted by controller shutdown. It will be resumed"
+
+	drainPollInterval = 200 * time.Millisecond
+	recordTimeout     = 5 * time.Second
+)
+
+var (
+	// errDraining is returned for deployments requested while the controller is shutting down
+	errDraining = errors.New("controller is shutting down")
+)
+
+// inflightDeploy is a deployment (or cleanup) of a feature in a managed cluster currently in progress
+type inflightDeploy struct {
+	clusterNamespace string
+	clusterName      string
+	applicant        string
+	featureID        configv1alpha1.FeatureID
+	clusterType      libsveltosv1alpha1.ClusterType
+	cleanup          bool
+}
+
+func newInflightDeploy(clusterNamespace, clusterName, applicant string, featureID configv1alpha1.FeatureID,
+	clusterType libsveltosv1alpha1.ClusterType, cleanup bool) inflightDeploy {
+
+	return inflightDeploy{clusterNamespace: clusterNamespace, clusterName: clusterName, applicant: applicant,
+		featureID: featureID, clusterType: clusterType, cleanup: cleanup}
+}
+
+var (
+	drainMux        sync.Mutex
+	draining        bool
+	inflightDeploys = map[inflightDeploy]int{}
+)
+
+// startDeploy tracks a deployment as in progress. Once the controller is shutting down
+// no new deployment is started and errDraining is returned.
+func startDeploy(d inflightDeploy) error {
+	drainMux.Lock()
+	defer drainMux.Unlock()
+
+	if draining {
+		return errDraining
+	}
+	inflightDeploys[d]++
+	return nil
+}
+
+// endDeploy marks a deployment previously started with startDeploy as completed
+func endDeploy(d inflightDeploy) {
+	drainMux.Lock()
+	defer drainMux.Unlock()
+
+	inflightDeploys[d]--
+	if inflightDeploys[d] <= 0 {
+		delete(inflightDeploys, d)
+	}
+}
+
+func getInflightDeploys() []inflightDeploy {
+	drainMux.Lock()
+	defer drainMux.Unlock()
+
+	result := make([]inflightDeploy, 0, len(inflightDeploys))
+	for d := range inflightDeploys {
+		result = append(result, d)
+	}
+	return result
+}
+
+// DrainDeploys is meant to be invoked when the controller is shutting down. It:
+// - stops accepting new deployments;
+// - waits, up to gracePeriod, for the deployments in progress to complete;
+// - invokes cancelDeploys to abort the deployments still in progress, if any;
+// - records the interrupted deployments in the ClusterSummary status. Feature hash is reset
+// so the next leader redeploys the feature.
+func DrainDeploys(gracePeriod time.Duration, cancelDeploys context.CancelFunc, logger logr.Logger) {
+	drainMux.Lock()
+	draining = true
+	drainMux.Unlock()
+
+	logger.V(logs.LogInfo).Info(fmt.Sprintf("waiting up to %s for in-flight deployments", gracePeriod))
+	deadline := time.Now().Add(gracePeriod)
+	for len(getInflightDeploys()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+	}
+
+	interrupted := getInflightDeploys()
+	cancelDeploys()
+
+	for i := range interrupted {
+		d := &interrupted[i]
+		l := logger.WithValues("clusternamespace", d.clusterNamespace, "clustername", d.clusterName,
+			"applicant", d.applicant, "feature", d.featureID)
+		l.V(logs.LogInfo).Info("deployment interrupted")
+		if err := recordInterruptedDeploy(d); err != nil {
+			l.V(logs.LogInfo).Info(fmt.Sprintf("failed to record interrupted deployment: %v", err))
+		}
+	}
+}
+
+// recordInterruptedDeploy reports in the ClusterSummary status the feature deployment was interrupted.
+func recordInterruptedDeploy(d *inflightDeploy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+
+	c := getManagementClusterClient()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		clusterSummary := &configv1alpha1.ClusterSummary{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: d.clusterNamespace, Name: d.applicant}, clusterSummary)
+		if err != nil {
+			return err
+		}
+
+		clusterSummaryScope := &scope.ClusterSummaryScope{ClusterSummary: clusterSummary}
+		status := configv1alpha1.FeatureStatusProvisioning
+		if d.cleanup {
+			status = configv1alpha1.FeatureStatusRemoving
+		}
+		message := interruptedDeployMessage
+		clusterSummaryScope.SetFeatureStatus(d.featureID, status, nil)
+		clusterSummaryScope.SetFailureMessage(d.featureID, &message)
+
+		return c.Status().Update(ctx, clusterSummary)
+	})
+}
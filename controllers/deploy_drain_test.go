@@ -0,0 +1,114 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Deploy drain", func() {
+	AfterEach(func() {
+		controllers.ResetDeployDrain()
+	})
+
+	It("DrainDeploys waits for in-flight deployments and stops accepting new ones", func() {
+		inflight := controllers.NewInflightDeploy(randomString(), randomString(), randomString(),
+			configv1alpha1.FeatureHelm, libsveltosv1alpha1.ClusterTypeCapi, false)
+		Expect(controllers.StartDeploy(inflight)).To(Succeed())
+
+		go func() {
+			time.Sleep(time.Second)
+			controllers.EndDeploy(inflight)
+		}()
+
+		canceled := false
+		start := time.Now()
+		controllers.DrainDeploys(time.Minute, func() { canceled = true },
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(canceled).To(BeTrue())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Minute))
+
+		Expect(controllers.StartDeploy(inflight)).ToNot(Succeed())
+	})
+
+	It("DrainDeploys records deployments interrupted once grace period expires", func() {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+		Expect(testEnv.Client.Create(context.TODO(), ns)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, ns)).To(Succeed())
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns.Name,
+				Name:      randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: ns.Name,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+			},
+		}
+		Expect(testEnv.Client.Create(context.TODO(), clusterSummary)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, clusterSummary)).To(Succeed())
+
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{
+				FeatureID: configv1alpha1.FeatureHelm,
+				Status:    configv1alpha1.FeatureStatusProvisioning,
+				Hash:      []byte(randomString()),
+			},
+		}
+		Expect(testEnv.Client.Status().Update(context.TODO(), clusterSummary)).To(Succeed())
+
+		inflight := controllers.NewInflightDeploy(ns.Name, clusterSummary.Spec.ClusterName, clusterSummary.Name,
+			configv1alpha1.FeatureHelm, libsveltosv1alpha1.ClusterTypeCapi, false)
+		Expect(controllers.StartDeploy(inflight)).To(Succeed())
+
+		canceled := false
+		controllers.DrainDeploys(0, func() { canceled = true },
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(canceled).To(BeTrue())
+
+		Eventually(func() bool {
+			currentClusterSummary := &configv1alpha1.ClusterSummary{}
+			err := testEnv.Client.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+				currentClusterSummary)
+			if err != nil || len(currentClusterSummary.Status.FeatureSummaries) != 1 {
+				return false
+			}
+			fs := currentClusterSummary.Status.FeatureSummaries[0]
+			return fs.Hash == nil && fs.Status == configv1alpha1.FeatureStatusProvisioning &&
+				fs.FailureMessage != nil && *fs.FailureMessage == controllers.InterruptedDeployMessage
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// getFeatureExcludedObjects returns the resources which must not be deployed because of featureID
+func getFeatureExcludedObjects(clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID) []configv1alpha1.ExcludedObject {
+
+	var excluded []configv1alpha1.ExcludedObject
+	for i := range clusterSummary.Spec.ClusterProfileSpec.ExcludedObjects {
+		if clusterSummary.Spec.ClusterProfileSpec.ExcludedObjects[i].FeatureID == featureID {
+			excluded = append(excluded, clusterSummary.Spec.ClusterProfileSpec.ExcludedObjects[i])
+		}
+	}
+	return excluded
+}
+
+// isExcludedObject returns true if resource matches excludedObject
+func isExcludedObject(resource *unstructured.Unstructured, excludedObject *configv1alpha1.ExcludedObject) bool {
+	gvk := resource.GroupVersionKind()
+	if gvk.Group != excludedObject.Group || gvk.Kind != excludedObject.Kind {
+		return false
+	}
+	if excludedObject.Version != "" && gvk.Version != excludedObject.Version {
+		return false
+	}
+	if excludedObject.Namespace != "" && resource.GetNamespace() != excludedObject.Namespace {
+		return false
+	}
+	return resource.GetName() == excludedObject.Name
+}
+
+// filterExcludedObjects returns resources without the ones excluded for featureID
+func filterExcludedObjects(resources []*unstructured.Unstructured, featureID configv1alpha1.FeatureID,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) []*unstructured.Unstructured {
+
+	excluded := getFeatureExcludedObjects(clusterSummary, featureID)
+	if len(excluded) == 0 {
+		return resources
+	}
+
+	result := make([]*unstructured.Unstructured, 0, len(resources))
+	for i := range resources {
+		skip := false
+		for j := range excluded {
+			if isExcludedObject(resources[i], &excluded[j]) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("resource %s %s/%s is excluded",
+				resources[i].GetKind(), resources[i].GetNamespace(), resources[i].GetName()))
+			continue
+		}
+		result = append(result, resources[i])
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+var _ = Describe("ExcludedObjects", func() {
+	It("filterExcludedObjects skips only resources matching an exclusion for the feature", func() {
+		namespace := randomString()
+		deplName := randomString()
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(patchDeployment, deplName, namespace)))
+		Expect(err).To(BeNil())
+		serviceAccount, err := utils.GetUnstructured([]byte(fmt.Sprintf(patchServiceAccount, randomString(), namespace)))
+		Expect(err).To(BeNil())
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1alpha1.Spec{
+					ExcludedObjects: []configv1alpha1.ExcludedObject{
+						{FeatureID: configv1alpha1.FeatureResources, Group: "apps", Kind: "Deployment", Name: deplName},
+						{FeatureID: configv1alpha1.FeatureKustomize, Kind: "ServiceAccount", Name: serviceAccount.GetName()},
+					},
+				},
+			},
+		}
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		resources := []*unstructured.Unstructured{depl, serviceAccount}
+
+		result := controllers.FilterExcludedObjects(resources, configv1alpha1.FeatureResources, clusterSummary, logger)
+		Expect(result).To(ConsistOf(serviceAccount))
+
+		result = controllers.FilterExcludedObjects(resources, configv1alpha1.FeatureKustomize, clusterSummary, logger)
+		Expect(result).To(ConsistOf(depl))
+
+		clusterSummary.Spec.ClusterProfileSpec.ExcludedObjects[0].Namespace = randomString()
+		result = controllers.FilterExcludedObjects(resources, configv1alpha1.FeatureResources, clusterSummary, logger)
+		Expect(len(result)).To(Equal(2))
+	})
+})
@@ -16,6 +16,15 @@ limitations under the License.
 
 package controllers
 
+import (
+	"fmt"
+	"time"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
 var (
 	UpdateClusterSummaries                = updateClusterSummaries
 	CreateClusterSummary                  = createClusterSummary
@@ -24,10 +33,16 @@ var (
 	CleanClusterConfiguration             = cleanClusterConfiguration
 	CleanClusterReports                   = cleanClusterReports
 	CleanClusterSummaries                 = cleanClusterSummaries
+	ReconcileDeleteCommon                 = reconcileDeleteCommon
 	UpdateClusterSummarySyncMode          = updateClusterSummarySyncMode
 	UpdateClusterReports                  = updateClusterReports
 	GetMatchingClusters                   = getMatchingClusters
+	GetClustersMatchingAnySelector        = getClustersMatchingAnySelector
+	ValidateSelectors                     = validateSelectors
 	GetMaxUpdate                          = getMaxUpdate
+	LimitMatchingClusters                 = limitMatchingClusters
+	FilterByRequiredClusterLabels         = filterByRequiredClusterLabels
+	FilterByClusterNamespaces             = filterByClusterNamespaces
 	ReviseUpdatedAndUpdatingClusters      = reviseUpdatedAndUpdatingClusters
 	GetUpdatedAndUpdatingClusters         = getUpdatedAndUpdatingClusters
 )
@@ -51,13 +66,22 @@ var (
 	GetHash                              = (*ClusterSummaryReconciler).getHash
 	UpdateFeatureStatus                  = (*ClusterSummaryReconciler).updateFeatureStatus
 	DeployFeature                        = (*ClusterSummaryReconciler).deployFeature
+	GetSuccessRequeueAfter               = (*ClusterSummaryReconciler).getSuccessRequeueAfter
+	HandleReconcileNowRequest            = (*ClusterSummaryReconciler).handleReconcileNowRequest
+	UpdateDryRunDiff                     = (*ClusterSummaryReconciler).updateDryRunDiff
 	UndeployFeature                      = (*ClusterSummaryReconciler).undeployFeature
+	DisableFeature                       = (*ClusterSummaryReconciler).disableFeature
 	GetCurrentReferences                 = (*ClusterSummaryReconciler).getCurrentReferences
 	IsPaused                             = (*ClusterSummaryReconciler).isPaused
 	IsReady                              = (*ClusterSummaryReconciler).isReady
+	IsOrphan                             = (*ClusterSummaryReconciler).isOrphan
 	ShouldReconcile                      = (*ClusterSummaryReconciler).shouldReconcile
 	UpdateChartMap                       = (*ClusterSummaryReconciler).updateChartMap
 	ShouldRedeploy                       = (*ClusterSummaryReconciler).shouldRedeploy
+	IsOneTimeProvisioned                 = (*ClusterSummaryReconciler).isOneTimeProvisioned
+	RemoveResourceSummary                = (*ClusterSummaryReconciler).removeResourceSummary
+	SetResourceSummaryRemoved            = setResourceSummaryRemoved
+	ForgetResourceSummaryRemoved         = forgetResourceSummaryRemoved
 	CanRemoveFinalizer                   = (*ClusterSummaryReconciler).canRemoveFinalizer
 	ReconcileDelete                      = (*ClusterSummaryReconciler).reconcileDelete
 	AreDependenciesDeployed              = (*ClusterSummaryReconciler).areDependenciesDeployed
@@ -66,6 +90,7 @@ var (
 
 	ConvertResultStatus               = (*ClusterSummaryReconciler).convertResultStatus
 	RequeueClusterSummaryForReference = (*ClusterSummaryReconciler).requeueClusterSummaryForReference
+	EnqueueClusterSummaryForReference = (*ClusterSummaryReconciler).enqueueClusterSummaryForReference
 	RequeueClusterSummaryForCluster   = (*ClusterSummaryReconciler).requeueClusterSummaryForCluster
 )
 
@@ -75,32 +100,44 @@ var (
 	GenericDeploy           = genericDeploy
 	GenericUndeploy         = genericUndeploy
 
-	GetClusterSummary             = getClusterSummary
-	AddLabel                      = addLabel
-	CreateNamespace               = createNamespace
-	GetEntryKey                   = getEntryKey
-	DeployContentOfConfigMap      = deployContentOfConfigMap
-	DeployContentOfSecret         = deployContentOfSecret
-	DeployContent                 = deployContent
-	GetClusterSummaryAdmin        = getClusterSummaryAdmin
-	AddAnnotation                 = addAnnotation
-	ComputePolicyHash             = computePolicyHash
-	GetPolicyInfo                 = getPolicyInfo
-	CollectContent                = collectContent
-	CustomSplit                   = customSplit
-	UndeployStaleResources        = undeployStaleResources
-	GetDeployedGroupVersionKinds  = getDeployedGroupVersionKinds
-	CanDelete                     = canDelete
-	HandleResourceDelete          = handleResourceDelete
-	GetSecret                     = getSecret
-	GetReferenceResourceNamespace = getReferenceResourceNamespace
-	ReadFiles                     = readFiles
-
-	AddExtraLabels      = addExtraLabels
-	AddExtraAnnotations = addExtraAnnotations
-
-	ResourcesHash   = resourcesHash
-	GetResourceRefs = getResourceRefs
+	GetClusterSummary              = getClusterSummary
+	AddLabel                       = addLabel
+	CreateNamespace                = createNamespace
+	GetEntryKey                    = getEntryKey
+	DeployObjects                  = deployObjects
+	DeployContent                  = deployContent
+	GetClusterSummaryAdmin         = getClusterSummaryAdmin
+	AddAnnotation                  = addAnnotation
+	ComputePolicyHash              = computePolicyHash
+	GetPolicyInfo                  = getPolicyInfo
+	CollectContent                 = collectContent
+	CustomSplit                    = customSplit
+	UndeployStaleResources         = undeployStaleResources
+	GetDeployedGroupVersionKinds   = getDeployedGroupVersionKinds
+	AddPreviouslyDeployedResources = addPreviouslyDeployedResources
+	CanDelete                      = canDelete
+	CanDeployResource              = canDeployResource
+	KeepCurrentOwnerReferences     = keepCurrentOwnerReferences
+	HandleResourceDelete           = handleResourceDelete
+	GetSecret                      = getSecret
+	GetReferenceResourceNamespace  = getReferenceResourceNamespace
+	ReadFiles                      = readFiles
+	ApplyImpersonation             = applyImpersonation
+	ApplyKubeconfigImpersonation   = applyKubeconfigImpersonation
+	GetImpersonatedClient          = getImpersonatedClient
+	SelectReferencedKeys           = selectReferencedKeys
+
+	AddExtraLabels        = addExtraLabels
+	AddExtraAnnotations   = addExtraAnnotations
+	SetPriorityClassName  = setPriorityClassName
+	EnsurePriorityClass   = ensurePriorityClass
+	RewriteImage          = rewriteImage
+	SetWorkloadScheduling = setWorkloadScheduling
+	ValidateTolerations   = validateTolerations
+
+	ResourcesHash       = resourcesHash
+	GetResourceRefs     = getResourceRefs
+	GetPolicyRefsStatus = getPolicyRefsStatus
 
 	UndeployKustomizeRefs             = undeployKustomizeRefs
 	KustomizationHash                 = kustomizationHash
@@ -127,12 +164,16 @@ var (
 	HandleCharts                             = handleCharts
 	GetHelmReferenceResourceHash             = getHelmReferenceResourceHash
 	GetHelmChartValuesHash                   = getHelmChartValuesHash
+	GetHelmChartVersionConflict              = getHelmChartVersionConflict
 
 	InstantiateTemplateValues = instantiateTemplateValues
 
 	IsCluterSummaryProvisioned = isCluterSummaryProvisioned
 	IsNamespaced               = isNamespaced
 	StringifyMap               = stringifyMap
+	IsTransientError           = isTransientError
+	MaxTransientFailures       = maxTransientFailures
+	GetTransientRetryAfter     = getTransientRetryAfter
 	ParseMapFromString         = parseMapFromString
 )
 
@@ -152,6 +193,7 @@ var (
 	DeployResourceSummaryInstance                    = deployResourceSummaryInstance
 	UpdateDeployedGroupVersionKind                   = updateDeployedGroupVersionKind
 	DeployDriftDetectionManagerInManagementCluster   = deployDriftDetectionManagerInManagementCluster
+	GetDriftDetectionManagerResources                = getDriftDetectionManagerResources
 	GetDriftDetectionManagerLabels                   = getDriftDetectionManagerLabels
 	RemoveDriftDetectionManagerFromManagementCluster = removeDriftDetectionManagerFromManagementCluster
 	GetDriftDetectionNamespaceInMgmtCluster          = getDriftDetectionNamespaceInMgmtCluster
@@ -168,6 +210,17 @@ var (
 	InitializeManager = initializeManager
 )
 
+var (
+	RenderResources = renderResources
+)
+
+var (
+	GetKubernetesRestConfig = getKubernetesRestConfig
+	GetKubernetesClient     = getKubernetesClient
+	GetKubeconfigContent    = getKubeconfigContent
+	RestConfigToKubeconfig  = restConfigToKubeconfig
+)
+
 const (
 	ReasonLabel = reasonLabel
 )
@@ -201,3 +254,213 @@ var (
 var (
 	RemoveDuplicates = removeDuplicates
 )
+
+var (
+	ReconcilePodSecurityLabels      = reconcilePodSecurityLabels
+	GetPodSecurityLabels            = getPodSecurityLabels
+	GetPodSecurityNamespaceSelector = getPodSecurityNamespaceSelector
+)
+
+var (
+	ApplyClusterRateLimits      = applyClusterRateLimits
+	ApplyKubeconfigRateLimits   = applyKubeconfigRateLimits
+	RegisterKubeconfigCluster   = registerKubeconfigCluster
+	UnregisterKubeconfigCluster = unregisterKubeconfigCluster
+	RemoveClusterLimiter        = removeClusterLimiter
+	GetClusterCABundle          = getClusterCABundle
+	ApplyClusterCABundle        = applyClusterCABundle
+	ApplyClusterProxy           = applyClusterProxy
+)
+
+var (
+	ShouldProbeConnectivity = shouldProbeConnectivity
+	ProbeAPIServer          = probeAPIServer
+	SetClusterConnectivity  = setClusterConnectivity
+)
+
+var (
+	VerifyAPISupport     = verifyAPISupport
+	GetAPISupportError   = getAPISupportError
+	GetDiscoveryCacheKey = getDiscoveryCacheKey
+)
+
+var (
+	NewPriorityQueue = newPriorityQueue
+)
+
+type (
+	PriorityQueueOptions = priorityQueueOptions
+)
+
+var (
+	NewAuditRecord        = newAuditRecord
+	StoreAuditRecord      = storeAuditRecord
+	PostAuditRecord       = postAuditRecord
+	TrimAuditEntries      = trimAuditEntries
+	GetAuditConfigMapName = getAuditConfigMapName
+)
+
+var (
+	RunPostDeployJobs            = runPostDeployJobs
+	PostDeployJobHashAnnotation  = postDeployJobHashAnnotation
+	PostDeployJobLabel           = postDeployJobLabel
+	PostDeployJobOwnerAnnotation = postDeployJobOwnerAnnotation
+	RemovePostDeployJobs         = removePostDeployJobs
+	AppendPostDeployJobResources = appendPostDeployJobResources
+	IsPostDeployJobResource      = isPostDeployJobResource
+)
+
+var (
+	GetFeatureStatuses  = getFeatureStatuses
+	RecordClusterEvents = recordClusterEvents
+)
+
+var (
+	NewInflightDeploy        = newInflightDeploy
+	StartDeploy              = startDeploy
+	EndDeploy                = endDeploy
+	InterruptedDeployMessage = interruptedDeployMessage
+)
+
+// ResetDeployDrain allows deployments again after DrainDeploys was invoked
+func ResetDeployDrain() {
+	drainMux.Lock()
+	defer drainMux.Unlock()
+
+	draining = false
+	inflightDeploys = map[inflightDeploy]int{}
+}
+
+var (
+	ApplyPatches        = applyPatches
+	ValidatePatchTarget = validatePatchTarget
+)
+
+var (
+	FilterExcludedObjects = filterExcludedObjects
+)
+
+var (
+	RetryOnApplyConflict = retryOnApplyConflict
+)
+
+var (
+	GetPendingLowerPriorityFeature = getPendingLowerPriorityFeature
+)
+
+var (
+	EvaluatePreconditions = evaluatePreconditions
+)
+
+var (
+	GetSizeLimitWarning        = getSizeLimitWarning
+	GetMissingReferenceMessage = getMissingReferenceMessage
+)
+
+var (
+	GetDeploymentSummary  = getDeploymentSummary
+	GetDeploymentProgress = getDeploymentProgress
+)
+
+var (
+	GetImagePullSecrets       = getImagePullSecrets
+	SyncImagePullSecret       = syncImagePullSecret
+	SetImagePullSecrets       = setImagePullSecrets
+	RemoveImagePullSecrets    = removeImagePullSecrets
+	GetNeededImagePullSecrets = getNeededImagePullSecrets
+	ImagePullSecretLabel      = imagePullSecretLabel
+)
+
+var (
+	NotifyCompletion = notifyCompletion
+)
+
+// SetCompletionRetryInterval sets the delay before a failed completion notification is sent again
+func SetCompletionRetryInterval(interval time.Duration) {
+	completionRetryInterval = interval
+}
+
+var (
+	GetMinKubernetesVersion = getMinKubernetesVersion
+	CheckKubernetesVersion  = (*ClusterSummaryReconciler).checkKubernetesVersion
+	FetchKubernetesVersion  = fetchKubernetesVersion
+)
+
+// SetClusterKubernetesVersion caches the Kubernetes version of a managed cluster
+func SetClusterKubernetesVersion(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType,
+	kubernetesVersion string) {
+
+	kubernetesVersionCacheMux.Lock()
+	defer kubernetesVersionCacheMux.Unlock()
+
+	kubernetesVersionCache[fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)] =
+		&kubernetesVersionCacheEntry{
+			version: utilversion.MustParseGeneric(kubernetesVersion),
+			expires: time.Now().Add(kubernetesVersionCacheTTL),
+		}
+}
+
+var (
+	GetActiveMaintenanceWindowEnd       = getActiveMaintenanceWindowEnd
+	GetNextMaintenanceWindowStart       = getNextMaintenanceWindowStart
+	ScaleDownWorkload                   = scaleDownWorkload
+	RestoreWorkload                     = restoreWorkload
+	ReplicasBeforeMaintenanceAnnotation = replicasBeforeMaintenanceAnnotation
+)
+
+var (
+	AllowClusterDeploy         = allowClusterDeploy
+	RecordClusterSevereFailure = recordClusterSevereFailure
+	RecordClusterSuccess       = recordClusterSuccess
+	UpdateCircuitOpenCondition = updateCircuitOpenCondition
+)
+
+var (
+	RunRecoveringPanic = runRecoveringPanic
+)
+
+var (
+	NewFeatureHash              = newFeatureHash
+	IsHashAlgorithmCurrent      = isHashAlgorithmCurrent
+	GetReferencedObjectIdentity = getReferencedObjectIdentity
+)
+
+var (
+	StoreInventory            = storeInventory
+	GetInventoryEntries       = getInventoryEntries
+	GetInventoryConfigMapName = getInventoryConfigMapName
+)
+
+var (
+	GetCopiedObject = getCopiedObject
+	GetCopyRefsHash = getCopyRefsHash
+)
+
+var (
+	UpdateMatchingClusters = updateMatchingClusters
+)
+
+var (
+	RecordFeatureHashChange   = recordFeatureHashChange
+	FeatureHashChangesCounter = featureHashChangesCounter
+)
+
+var (
+	ExplainMatchingClusters = explainMatchingClusters
+)
+
+var (
+	ValidateObjectsPerReference = validateObjectsPerReference
+)
+
+var (
+	SortByApplyOrder = sortByApplyOrder
+)
+
+var (
+	GetResourcesOfRemovedReferences = getResourcesOfRemovedReferences
+)
+
+var (
+	IsHealthyByCriteria = isHealthyByCriteria
+)
@@ -0,0 +1,85 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+)
+
+// getFeaturePriority returns the priority of featureID. Features with a lower priority are deployed first.
+func getFeaturePriority(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) int32 {
+	priorities := clusterSummary.Spec.ClusterProfileSpec.FeaturePriorities
+	for i := range priorities {
+		if priorities[i].FeatureID == featureID {
+			return priorities[i].Priority
+		}
+	}
+	return 0
+}
+
+// isFeatureConfigured returns true if clusterSummary contains something to deploy for featureID
+// and featureID is not disabled
+func isFeatureConfigured(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) bool {
+	if isFeatureDisabled(clusterSummary, featureID) {
+		return false
+	}
+
+	spec := &clusterSummary.Spec.ClusterProfileSpec
+	switch featureID {
+	case configv1alpha1.FeatureResources:
+		return len(spec.PolicyRefs) != 0 || len(spec.InlinePolicies) != 0 || len(spec.CopyRefs) != 0
+	case configv1alpha1.FeatureHelm:
+		return len(spec.HelmCharts) != 0
+	case configv1alpha1.FeatureKustomize:
+		return len(spec.KustomizationRefs) != 0
+	case configv1alpha1.FeaturePodSecurity:
+		return spec.PodSecurity != nil
+	}
+	return false
+}
+
+// isFeatureProvisioned returns true if featureID status is provisioned
+func isFeatureProvisioned(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) bool {
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.FeatureID == featureID {
+			return fs.Status == configv1alpha1.FeatureStatusProvisioned
+		}
+	}
+	return false
+}
+
+// getPendingLowerPriorityFeature returns a feature with a lower priority than featureID which is
+// configured but not provisioned yet. featureID must not be deployed till then.
+// Returns nil if there is none.
+func getPendingLowerPriorityFeature(clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID) *configv1alpha1.FeatureID {
+
+	priority := getFeaturePriority(clusterSummary, featureID)
+	for _, id := range []configv1alpha1.FeatureID{configv1alpha1.FeatureResources, configv1alpha1.FeatureHelm,
+		configv1alpha1.FeatureKustomize, configv1alpha1.FeaturePodSecurity} {
+
+		if id == featureID || getFeaturePriority(clusterSummary, id) >= priority {
+			continue
+		}
+		if isFeatureConfigured(clusterSummary, id) && !isFeatureProvisioned(clusterSummary, id) {
+			pending := id
+			return &pending
+		}
+	}
+	return nil
+}
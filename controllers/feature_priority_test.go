@@ -0,0 +1,66 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("FeaturePriorities", func() {
+	It("getPendingLowerPriorityFeature returns features with lower priority not provisioned yet", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1alpha1.Spec{
+					PolicyRefs: []configv1alpha1.PolicyRef{
+						{Namespace: randomString(), Name: randomString(),
+							Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)},
+					},
+					HelmCharts: []configv1alpha1.HelmChart{
+						{RepositoryURL: randomString(), RepositoryName: randomString(), ChartName: randomString(),
+							ChartVersion: randomString(), ReleaseName: randomString(), ReleaseNamespace: randomString()},
+					},
+				},
+			},
+		}
+
+		// No priority set. Features do not wait for each other
+		Expect(controllers.GetPendingLowerPriorityFeature(clusterSummary, configv1alpha1.FeatureHelm)).To(BeNil())
+
+		clusterSummary.Spec.ClusterProfileSpec.FeaturePriorities = []configv1alpha1.FeaturePriority{
+			{FeatureID: configv1alpha1.FeatureHelm, Priority: 10},
+		}
+		pending := controllers.GetPendingLowerPriorityFeature(clusterSummary, configv1alpha1.FeatureHelm)
+		Expect(pending).ToNot(BeNil())
+		Expect(*pending).To(Equal(configv1alpha1.FeatureResources))
+		Expect(controllers.GetPendingLowerPriorityFeature(clusterSummary, configv1alpha1.FeatureResources)).To(BeNil())
+
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{FeatureID: configv1alpha1.FeatureResources, Status: configv1alpha1.FeatureStatusProvisioned},
+		}
+		Expect(controllers.GetPendingLowerPriorityFeature(clusterSummary, configv1alpha1.FeatureHelm)).To(BeNil())
+
+		// Disabled features are not waited for
+		clusterSummary.Status.FeatureSummaries = nil
+		clusterSummary.Spec.ClusterProfileSpec.DisabledFeatures = []configv1alpha1.FeatureID{configv1alpha1.FeatureResources}
+		Expect(controllers.GetPendingLowerPriorityFeature(clusterSummary, configv1alpha1.FeatureHelm)).To(BeNil())
+	})
+})
@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"os"
 	"reflect"
@@ -106,7 +105,7 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
 		err = deployDriftDetectionManagerInCluster(ctx, c, clusterNamespace, clusterName, applicant,
-			clusterType, startInMgmtCluster, logger)
+			clusterType, startInMgmtCluster, clusterSummary.Spec.ClusterProfileSpec.RegistryOverrides, logger)
 		if err != nil {
 			return err
 		}
@@ -127,11 +126,16 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
 	logger = logger.WithValues("admin", fmt.Sprintf("%s/%s", adminNamespace, adminName))
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+	kubeconfigContent, err := getKubeconfigContent(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
 	}
+	kubeconfigContent, err = applyKubeconfigImpersonation(kubeconfigContent,
+		clusterSummary.Spec.ClusterProfileSpec.Impersonation)
+	if err != nil {
+		return err
+	}
 
 	var kubeconfig string
 	kubeconfig, err = clusterproxy.CreateKubeconfig(logger, kubeconfigContent)
@@ -139,6 +143,31 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 		return err
 	}
 	defer os.Remove(kubeconfig)
+	registerKubeconfigCluster(kubeconfig, clusterNamespace, clusterName, clusterSummary.Spec.ClusterType)
+	defer unregisterKubeconfigCluster(kubeconfig)
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts) > 0 {
+		err = validateTolerations(clusterSummary.Spec.ClusterProfileSpec.Tolerations)
+		if err != nil {
+			return err
+		}
+
+		// Workloads deployed by helm charts are not known in advance
+		err = ensurePriorityClass(ctx, remoteClient, clusterSummary, nil, logger)
+		if err != nil {
+			return err
+		}
+
+		// Workloads deployed by helm charts are not known in advance. Make image pull Secrets
+		// available in all release namespaces.
+		for i := range clusterSummary.Spec.ClusterProfileSpec.HelmCharts {
+			err = ensureHelmImagePullSecrets(ctx, remoteClient, clusterSummary,
+				&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[i], logger)
+			if err != nil {
+				return err
+			}
+		}
+	}
 
 	err = handleCharts(ctx, clusterSummary, c, remoteClient, kubeconfig, logger)
 	if err != nil {
@@ -181,12 +210,17 @@ func deployHelmCharts(ctx context.Context, c client.Client,
 		return err
 	}
 
-	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
+	remoteRestConfig, err := getKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
 	}
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureHelm, logger)
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureHelm, logger)
+	if err != nil {
+		return err
+	}
+
+	return runPostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureHelm, logger)
 }
 
 func undeployHelmCharts(ctx context.Context, c client.Client,
@@ -211,11 +245,16 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployHelmCharts")
 
-	kubeconfigContent, err := clusterproxy.GetSecretData(ctx, c, clusterNamespace, clusterName,
+	kubeconfigContent, err := getKubeconfigContent(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return err
 	}
+	kubeconfigContent, err = applyKubeconfigImpersonation(kubeconfigContent,
+		clusterSummary.Spec.ClusterProfileSpec.Impersonation)
+	if err != nil {
+		return err
+	}
 
 	var kubeconfig string
 	kubeconfig, err = clusterproxy.CreateKubeconfig(logger, kubeconfigContent)
@@ -223,6 +262,8 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 		return err
 	}
 	defer os.Remove(kubeconfig)
+	registerKubeconfigCluster(kubeconfig, clusterNamespace, clusterName, clusterSummary.Spec.ClusterType)
+	defer unregisterKubeconfigCluster(kubeconfig)
 
 	var releaseReports []configv1alpha1.ReleaseReport
 	releaseReports, err = uninstallHelmCharts(ctx, c, clusterSummary, kubeconfig, logger)
@@ -240,6 +281,16 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 	}
 	releaseReports = append(releaseReports, undeployedReports...)
 
+	remoteClient, err := getKubernetesClient(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+	err = removePostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureHelm, nil, logger)
+	if err != nil {
+		return err
+	}
+
 	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
 		return err
@@ -252,7 +303,7 @@ func undeployHelmCharts(ctx context.Context, c client.Client,
 	}
 
 	err = updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef,
-		configv1alpha1.FeatureHelm, nil, []configv1alpha1.Chart{})
+		configv1alpha1.FeatureHelm, []configv1alpha1.Resource{}, []configv1alpha1.Chart{})
 	if err != nil {
 		return err
 	}
@@ -345,7 +396,7 @@ func uninstallHelmCharts(ctx context.Context, c client.Client, clusterSummary *c
 func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
 	logger logr.Logger) ([]byte, error) {
 
-	h := sha256.New()
+	h := newFeatureHash()
 	var config string
 
 	// If SyncMode changes (from not ContinuousWithDriftDetection to ContinuousWithDriftDetection
@@ -361,6 +412,26 @@ func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.C
 	config += fmt.Sprintf("%d", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tier)
 	config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict)
 
+	// If PriorityClass changes, workloads need to be updated
+	config += clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassName
+	if v := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassValue; v != nil {
+		config += fmt.Sprintf("%d", *v)
+	}
+
+	// If NodeSelector or Tolerations change, workloads need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector)
+	}
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations)
+	}
+	// If RegistryOverrides change, workload images need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides)
+	}
+	// If ImagePullSecrets (or their content) change, Secrets and workloads need to be updated
+	config += getImagePullSecretsHash(ctx, c, clusterSummaryScope.ClusterSummary)
+
 	clusterSummary := clusterSummaryScope.ClusterSummary
 	if clusterSummary.Spec.ClusterProfileSpec.HelmCharts == nil {
 		return h.Sum(nil), nil
@@ -388,6 +459,13 @@ func helmHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.C
 		}
 	}
 
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		j := &clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+		if j.FeatureID == configv1alpha1.FeatureHelm {
+			config += render.AsCode(j)
+		}
+	}
+
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeContinuousWithDriftDetection {
 		// Use the version. This will cause drift-detection, Sveltos CRDs
 		// to be redeployed on upgrade
@@ -575,10 +653,57 @@ func generateConflictForHelmChart(ctx context.Context, clusterSummary *configv1a
 	if err != nil {
 		return message
 	}
-	message += fmt.Sprintf(" ClusterSummary %s managing it.\n", managerName)
+	message += fmt.Sprintf(" ClusterSummary %s managing it.", managerName)
+	versionConflict := getHelmChartVersionConflict(ctx, c, clusterSummary, currentChart, managerName)
+	if versionConflict != "" {
+		message += " " + versionConflict
+	}
+	message += "\n"
 	return message
 }
 
+// getHelmChartVersionConflict returns, when the ClusterSummary managing currentChart in the cluster deploys
+// a different version of it, a message naming both ClusterProfiles/Profiles and the conflicting versions.
+// Returns an empty string otherwise.
+func getHelmChartVersionConflict(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	currentChart *configv1alpha1.HelmChart, managerName string) string {
+
+	manager := &configv1alpha1.ClusterSummary{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Namespace, Name: managerName}, manager)
+	if err != nil {
+		return ""
+	}
+
+	for i := range manager.Spec.ClusterProfileSpec.HelmCharts {
+		managedChart := &manager.Spec.ClusterProfileSpec.HelmCharts[i]
+		if managedChart.ReleaseNamespace != currentChart.ReleaseNamespace ||
+			managedChart.ReleaseName != currentChart.ReleaseName {
+
+			continue
+		}
+		if managedChart.ChartName == currentChart.ChartName && managedChart.ChartVersion == currentChart.ChartVersion {
+			return ""
+		}
+		return fmt.Sprintf("Version conflict: %s deploys %s version %s while %s deploys %s version %s.",
+			getProfileDescription(manager), managedChart.ChartName, managedChart.ChartVersion,
+			getProfileDescription(clusterSummary), currentChart.ChartName, currentChart.ChartVersion)
+	}
+
+	return ""
+}
+
+// getProfileDescription returns the kind and name of the ClusterProfile/Profile owning clusterSummary
+func getProfileDescription(clusterSummary *configv1alpha1.ClusterSummary) string {
+	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
+	if err != nil {
+		return fmt.Sprintf("ClusterSummary %s", clusterSummary.Name)
+	}
+	if profileOwnerRef.Kind == configv1alpha1.ProfileKind {
+		return fmt.Sprintf("%s %s/%s", profileOwnerRef.Kind, clusterSummary.Namespace, profileOwnerRef.Name)
+	}
+	return fmt.Sprintf("%s %s", profileOwnerRef.Kind, profileOwnerRef.Name)
+}
+
 // determineChartOwnership determines whether the provided cluster summary (`claimingHelmManager`) has the authority to manage
 // the given Helm chart (`currentChart`).
 //
@@ -1070,8 +1195,15 @@ func actionConfigInit(namespace, kubeconfig string, enableClientCache bool) (*ac
 	configFlags := genericclioptions.NewConfigFlags(true)
 	configFlags.KubeConfig = &kubeconfig
 	configFlags.Namespace = &namespace
-	insecure := true
-	configFlags.Insecure = &insecure
+	configFlags.WrapConfigFn = func(restConfig *rest.Config) *rest.Config {
+		return applyKubeconfigRateLimits(restConfig, kubeconfig)
+	}
+	// When an additional CA bundle is configured, kubeconfig carries it and the API server certificate
+	// is verified against it. Otherwise keep skipping verification.
+	if getClusterCABundleRef() == nil {
+		insecure := true
+		configFlags.Insecure = &insecure
+	}
 
 	registryClient, err := registry.NewClient(
 		registry.ClientOptDebug(settings.Debug),
@@ -1346,7 +1478,9 @@ func updateChartsInClusterConfiguration(ctx context.Context, c client.Client, cl
 		return err
 	}
 
-	return updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, configv1alpha1.FeatureHelm, nil, chartDeployed)
+	return updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, configv1alpha1.FeatureHelm,
+		appendPostDeployJobResources([]configv1alpha1.Resource{}, clusterSummary, configv1alpha1.FeatureHelm),
+		chartDeployed)
 }
 
 // undeployStaleReleases uninstalls all helm charts previously managed and not referenced anyomre
@@ -1466,11 +1600,17 @@ func updateStatusForeferencedHelmReleases(ctx context.Context, c client.Client,
 				if err != nil {
 					return err
 				}
+				conflictMessage := fmt.Sprintf("ClusterSummary %s managing it", managerName)
+				if versionConflict := getHelmChartVersionConflict(ctx, c, currentClusterSummary, currentChart,
+					managerName); versionConflict != "" {
+
+					conflictMessage += ". " + versionConflict
+				}
 				helmReleaseSummaries[i] = configv1alpha1.HelmChartSummary{
 					ReleaseName:      currentChart.ReleaseName,
 					ReleaseNamespace: currentChart.ReleaseNamespace,
 					Status:           configv1alpha1.HelmChartStatusConflict,
-					ConflictMessage:  fmt.Sprintf("ClusterSummary %s managing it", managerName),
+					ConflictMessage:  conflictMessage,
 				}
 				conflict = true
 			}
@@ -2043,7 +2183,12 @@ func addExtraMetadata(ctx context.Context, requestedChart *configv1alpha1.HelmCh
 	}
 
 	if clusterSummary.Spec.ClusterProfileSpec.ExtraLabels == nil &&
-		clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations == nil {
+		clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations == nil &&
+		clusterSummary.Spec.ClusterProfileSpec.PriorityClassName == "" &&
+		clusterSummary.Spec.ClusterProfileSpec.NodeSelector == nil &&
+		clusterSummary.Spec.ClusterProfileSpec.Tolerations == nil &&
+		clusterSummary.Spec.ClusterProfileSpec.RegistryOverrides == nil &&
+		clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets == nil {
 
 		return nil
 	}
@@ -2089,6 +2234,10 @@ func addExtraMetadata(ctx context.Context, requestedChart *configv1alpha1.HelmCh
 
 		addExtraLabels(r, clusterSummary.Spec.ClusterProfileSpec.ExtraLabels)
 		addExtraAnnotations(r, clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations)
+		err = setWorkloadScheduling(r, &clusterSummary.Spec.ClusterProfileSpec)
+		if err != nil {
+			return err
+		}
 
 		err = updateResource(ctx, dr, clusterSummary, r, logger)
 		if err != nil {
@@ -2124,7 +2273,7 @@ func getHelmChartValuesHash(ctx context.Context, c client.Client, requestedChart
 		return nil, err
 	}
 
-	h := sha256.New()
+	h := newFeatureHash()
 	config := render.AsCode(requestedChart.Values)
 	config += valuesFromHash
 	h.Write([]byte(config))
@@ -312,6 +312,50 @@ var _ = Describe("HandlersHelm", func() {
 		Expect(currentClusterSummary.Status.HelmReleaseSummaries[0].ReleaseNamespace).To(Equal(contourChart.ReleaseNamespace))
 	})
 
+	It("getHelmChartVersionConflict names both ClusterProfiles and the conflicting versions", func() {
+		kyvernoChart := configv1alpha1.HelmChart{
+			RepositoryURL:    "https://kyverno.github.io/kyverno/",
+			RepositoryName:   "kyverno",
+			ChartName:        "kyverno/kyverno",
+			ChartVersion:     "v3.0.1",
+			ReleaseName:      "kyverno-latest",
+			ReleaseNamespace: "kyverno",
+			HelmChartAction:  configv1alpha1.HelmChartActionInstall,
+		}
+
+		managingProfileName := clusterProfileNamePrefix + randomString()
+		managingClusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: clusterSummary.Namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						Kind:       configv1alpha1.ClusterProfileKind,
+						Name:       managingProfileName,
+						APIVersion: "config.projectsveltos.io/v1alpha1",
+					},
+				},
+			},
+			Spec: clusterSummary.Spec,
+		}
+		managingClusterSummary.Spec.ClusterProfileSpec.HelmCharts = []configv1alpha1.HelmChart{kyvernoChart}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managingClusterSummary).Build()
+
+		// Same version: no version conflict
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts = []configv1alpha1.HelmChart{kyvernoChart}
+		Expect(controllers.GetHelmChartVersionConflict(context.TODO(), c, clusterSummary,
+			&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0], managingClusterSummary.Name)).To(BeEmpty())
+
+		clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0].ChartVersion = "v3.1.4"
+		message := controllers.GetHelmChartVersionConflict(context.TODO(), c, clusterSummary,
+			&clusterSummary.Spec.ClusterProfileSpec.HelmCharts[0], managingClusterSummary.Name)
+		Expect(message).To(ContainSubstring(fmt.Sprintf("ClusterProfile %s deploys kyverno/kyverno version v3.0.1",
+			managingProfileName)))
+		Expect(message).To(ContainSubstring(fmt.Sprintf("ClusterProfile %s deploys kyverno/kyverno version v3.1.4",
+			clusterProfile.Name)))
+	})
+
 	It("updateChartsInClusterConfiguration updates ClusterConfiguration with deployed helm releases", func() {
 		chartDeployed := []configv1alpha1.Chart{
 			{
@@ -21,7 +21,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
@@ -49,7 +48,6 @@ import (
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 	"github.com/projectsveltos/libsveltos/lib/utils"
@@ -116,7 +114,8 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 	}
 
 	// TODO: track resource deployed in the management cluster
-	err = updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, featureHandler.id, remoteDeployed, nil)
+	err = updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, featureHandler.id,
+		appendPostDeployJobResources(remoteDeployed, clusterSummary, featureHandler.id), nil)
 	if err != nil {
 		return err
 	}
@@ -153,7 +152,12 @@ func deployKustomizeRefs(ctx context.Context, c client.Client,
 		return deployError
 	}
 
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureKustomize, logger)
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureKustomize, logger)
+	if err != nil {
+		return err
+	}
+
+	return runPostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureKustomize, logger)
 }
 
 func cleanStaleKustomizeResources(ctx context.Context, remoteRestConfig *rest.Config, remoteClient client.Client,
@@ -206,14 +210,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
-	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
-	if err != nil {
-		return err
-	}
-
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	remoteRestConfig, remoteClient, err := getImpersonatedRemoteAccess(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
 	}
@@ -226,6 +223,22 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 		return err
 	}
 
+	// Remove image pull Secrets not needed anymore
+	err = removeStaleImagePullSecrets(ctx, c, true, clusterSummary, configv1alpha1.FeatureKustomize, true, logger)
+	if err != nil {
+		return err
+	}
+	err = removeStaleImagePullSecrets(ctx, remoteClient, false, clusterSummary, configv1alpha1.FeatureKustomize,
+		true, logger)
+	if err != nil {
+		return err
+	}
+
+	err = removePostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureKustomize, nil, logger)
+	if err != nil {
+		return err
+	}
+
 	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
 		return err
@@ -262,7 +275,7 @@ func undeployKustomizeRefs(ctx context.Context, c client.Client,
 func kustomizationHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
 	logger logr.Logger) ([]byte, error) {
 
-	h := sha256.New()
+	h := newFeatureHash()
 	var config string
 
 	// If SyncMode changes (from not ContinuousWithDriftDetection to ContinuousWithDriftDetection
@@ -278,6 +291,36 @@ func kustomizationHash(ctx context.Context, c client.Client, clusterSummaryScope
 	config += fmt.Sprintf("%d", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tier)
 	config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict)
 
+	// If PriorityClass changes, workloads need to be updated
+	config += clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassName
+	if v := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassValue; v != nil {
+		config += fmt.Sprintf("%d", *v)
+	}
+
+	// If NodeSelector or Tolerations change, workloads need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector)
+	}
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations)
+	}
+	// If RegistryOverrides change, workload images need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides)
+	}
+	// If ImagePullSecrets (or their content) change, Secrets and workloads need to be updated
+	config += getImagePullSecretsHash(ctx, c, clusterSummaryScope.ClusterSummary)
+
+	// If Patches change, resources need to be patched differently
+	if patches := getFeaturePatches(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureKustomize); len(patches) > 0 {
+		config += render.AsCode(patches)
+	}
+
+	// If ExcludedObjects change, resources need to be deployed or removed
+	if excluded := getFeatureExcludedObjects(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureKustomize); len(excluded) > 0 {
+		config += render.AsCode(excluded)
+	}
+
 	config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs)
 
 	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.KustomizationRefs {
@@ -308,6 +351,13 @@ func kustomizationHash(ctx context.Context, c client.Client, clusterSummaryScope
 		}
 	}
 
+	for i := range clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		j := &clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+		if j.FeatureID == configv1alpha1.FeatureKustomize {
+			config += render.AsCode(j)
+		}
+	}
+
 	if clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.SyncMode ==
 		configv1alpha1.SyncModeContinuousWithDriftDetection {
 		// Use the version. This will cause drift-detection, Sveltos CRDs
@@ -348,6 +398,7 @@ func getHashFromKustomizationRef(ctx context.Context, c client.Client, clusterSu
 		if configMap == nil {
 			return nil, nil
 		}
+		result += getReferencedObjectIdentity(configMap)
 		result += getDataSectionHash(configMap.Data)
 		result += getDataSectionHash(configMap.BinaryData)
 	} else if kustomizationRef.Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
@@ -359,6 +410,7 @@ func getHashFromKustomizationRef(ctx context.Context, c client.Client, clusterSu
 		if secret == nil {
 			return nil, nil
 		}
+		result += getReferencedObjectIdentity(secret)
 		result += getDataSectionHash(secret.Data)
 		result += getDataSectionHash(secret.StringData)
 	} else {
@@ -717,6 +769,12 @@ func cleanKustomizeResources(ctx context.Context, isMgmtCluster bool, destRestCo
 	if err != nil {
 		return nil, err
 	}
+
+	err = removeStaleImagePullSecrets(ctx, destClient, isMgmtCluster, clusterSummary,
+		configv1alpha1.FeatureKustomize, false, logger)
+	if err != nil {
+		return nil, err
+	}
 	return undeployed, nil
 }
 
@@ -729,7 +787,8 @@ func handleDriftDetectionManagerDeploymentForKustomize(ctx context.Context, clus
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
 		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(), clusterNamespace,
-			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster, logger)
+			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster,
+			clusterSummary.Spec.ClusterProfileSpec.RegistryOverrides, logger)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,308 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gdexlab/go-render/render"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+
+	// podSecurityOwnerAnnotation is set on each namespace whose Pod Security Admission labels
+	// are managed by Sveltos. Its value is the name of the ClusterSummary managing them.
+	podSecurityOwnerAnnotation = "projectsveltos.io/pod-security-owner"
+
+	// podSecurityOriginalLabelsAnnotation contains, in JSON format, the values Pod Security Admission
+	// labels had before Sveltos changed them. A null value means the label was not set.
+	podSecurityOriginalLabelsAnnotation = "projectsveltos.io/pod-security-original-labels"
+)
+
+var (
+	podSecurityModes = []string{"enforce", "audit", "warn"}
+)
+
+func deployPodSecurity(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1alpha1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	clusterSummary, remoteClient, err := getClusterSummaryAndClusterClient(ctx, clusterNamespace, applicant, c, logger)
+	if err != nil {
+		return err
+	}
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
+	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
+	logger.V(logs.LogDebug).Info("deployPodSecurity")
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+		return &configv1alpha1.DryRunReconciliationError{}
+	}
+
+	podSecurity := clusterSummary.Spec.ClusterProfileSpec.PodSecurity
+	selector, err := getPodSecurityNamespaceSelector(podSecurity)
+	if err != nil {
+		return err
+	}
+
+	return reconcilePodSecurityLabels(ctx, remoteClient, clusterSummary.Name, selector,
+		getPodSecurityLabels(podSecurity), logger)
+}
+
+func undeployPodSecurity(ctx context.Context, c client.Client,
+	clusterNamespace, clusterName, applicant, _ string,
+	clusterType libsveltosv1alpha1.ClusterType,
+	o deployer.Options, logger logr.Logger) error {
+
+	clusterSummary, err := configv1alpha1.GetClusterSummary(ctx, c, clusterNamespace, applicant)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", clusterNamespace, clusterName))
+	logger = logger.WithValues("clusterSummary", clusterSummary.Name)
+	logger.V(logs.LogDebug).Info("undeployPodSecurity")
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+		return &configv1alpha1.DryRunReconciliationError{}
+	}
+
+	_, remoteClient, err := getImpersonatedRemoteAccess(ctx, c, clusterSummary, logger)
+	if err != nil {
+		return err
+	}
+
+	// A nil selector matches no namespace, so all labels previously set are reverted
+	return reconcilePodSecurityLabels(ctx, remoteClient, clusterSummary.Name, nil, nil, logger)
+}
+
+// getPodSecurityNamespaceSelector returns the selector identifying the namespaces Pod Security
+// Admission labels are set on. A nil selector, matching no namespace, is returned if podSecurity is nil.
+// An empty selector is rejected, as it would match all namespaces, including system ones.
+func getPodSecurityNamespaceSelector(podSecurity *configv1alpha1.PodSecurityConfiguration) (labels.Selector, error) {
+	if podSecurity == nil {
+		return nil, nil
+	}
+
+	selector, err := labels.Parse(string(podSecurity.NamespaceSelector))
+	if err != nil {
+		return nil, &NonRetriableError{Message: fmt.Sprintf("invalid namespaceSelector: %v", err)}
+	}
+	if selector.Empty() {
+		return nil, &NonRetriableError{Message: "namespaceSelector cannot be empty: it would match all namespaces"}
+	}
+
+	return selector, nil
+}
+
+// reconcilePodSecurityLabels sets desiredLabels on all namespaces matching selector and reverts
+// the Pod Security Admission labels on all namespaces previously managed by owner which
+// are not matching anymore.
+func reconcilePodSecurityLabels(ctx context.Context, remoteClient client.Client, owner string,
+	selector labels.Selector, desiredLabels map[string]string, logger logr.Logger) error {
+
+	namespaces := &corev1.NamespaceList{}
+	if err := remoteClient.List(ctx, namespaces); err != nil {
+		return err
+	}
+
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		currentOwner := ns.Annotations[podSecurityOwnerAnnotation]
+
+		if selector != nil && selector.Matches(labels.Set(ns.Labels)) {
+			if currentOwner != "" && currentOwner != owner {
+				return fmt.Errorf("pod security labels of namespace %s are already managed by %s",
+					ns.Name, currentOwner)
+			}
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("setting pod security labels on namespace %s", ns.Name))
+			if err := setPodSecurityLabels(ctx, remoteClient, ns, owner, desiredLabels); err != nil {
+				return err
+			}
+		} else if currentOwner == owner {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("reverting pod security labels on namespace %s", ns.Name))
+			if err := revertPodSecurityLabels(ctx, remoteClient, ns); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setPodSecurityLabels sets desiredLabels on namespace. The first time, the original values of all
+// Pod Security Admission labels are stored in an annotation. Any Pod Security Admission label not
+// in desiredLabels is restored to its original value.
+func setPodSecurityLabels(ctx context.Context, remoteClient client.Client, ns *corev1.Namespace,
+	owner string, desiredLabels map[string]string) error {
+
+	original, err := getPodSecurityOriginalLabels(ns)
+	if err != nil {
+		return err
+	}
+
+	if ns.Annotations[podSecurityOwnerAnnotation] != owner {
+		original = make(map[string]*string)
+		for _, key := range getPodSecurityLabelKeys() {
+			if v, ok := ns.Labels[key]; ok {
+				original[key] = &v
+			} else {
+				original[key] = nil
+			}
+		}
+	}
+
+	raw, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+	ns.Annotations[podSecurityOwnerAnnotation] = owner
+	ns.Annotations[podSecurityOriginalLabelsAnnotation] = string(raw)
+
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	for _, key := range getPodSecurityLabelKeys() {
+		if v, ok := desiredLabels[key]; ok {
+			ns.Labels[key] = v
+		} else {
+			restoreLabel(ns, key, original[key])
+		}
+	}
+
+	return remoteClient.Update(ctx, ns)
+}
+
+// revertPodSecurityLabels restores all Pod Security Admission labels on namespace to the values
+// they had before Sveltos changed them and removes the ownership annotations.
+func revertPodSecurityLabels(ctx context.Context, remoteClient client.Client, ns *corev1.Namespace) error {
+	original, err := getPodSecurityOriginalLabels(ns)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range getPodSecurityLabelKeys() {
+		restoreLabel(ns, key, original[key])
+	}
+
+	delete(ns.Annotations, podSecurityOwnerAnnotation)
+	delete(ns.Annotations, podSecurityOriginalLabelsAnnotation)
+
+	return remoteClient.Update(ctx, ns)
+}
+
+func restoreLabel(ns *corev1.Namespace, key string, value *string) {
+	if value == nil {
+		delete(ns.Labels, key)
+		return
+	}
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	ns.Labels[key] = *value
+}
+
+func getPodSecurityOriginalLabels(ns *corev1.Namespace) (map[string]*string, error) {
+	original := make(map[string]*string)
+	raw, ok := ns.Annotations[podSecurityOriginalLabelsAnnotation]
+	if !ok {
+		return original, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &original); err != nil {
+		return nil, err
+	}
+	return original, nil
+}
+
+// getPodSecurityLabelKeys returns all Pod Security Admission label keys managed by Sveltos
+func getPodSecurityLabelKeys() []string {
+	keys := make([]string, 0, 2*len(podSecurityModes))
+	for _, mode := range podSecurityModes {
+		keys = append(keys, podSecurityLabelPrefix+mode, podSecurityLabelPrefix+mode+"-version")
+	}
+	return keys
+}
+
+// getPodSecurityLabels returns the Pod Security Admission labels corresponding to podSecurity
+func getPodSecurityLabels(podSecurity *configv1alpha1.PodSecurityConfiguration) map[string]string {
+	result := make(map[string]string)
+	if podSecurity == nil {
+		return result
+	}
+
+	version := podSecurity.Version
+	if version == "" {
+		version = "latest"
+	}
+
+	levels := map[string]configv1alpha1.PodSecurityLevel{
+		"enforce": podSecurity.Enforce,
+		"audit":   podSecurity.Audit,
+		"warn":    podSecurity.Warn,
+	}
+	for mode, level := range levels {
+		if level == "" {
+			continue
+		}
+		result[podSecurityLabelPrefix+mode] = string(level)
+		result[podSecurityLabelPrefix+mode+"-version"] = version
+	}
+
+	return result
+}
+
+// podSecurityHash returns the hash of the ClusterSummary PodSecurity configuration.
+func podSecurityHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
+	logger logr.Logger) ([]byte, error) {
+
+	h := newFeatureHash()
+	var config string
+
+	config += fmt.Sprintf("%v", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.SyncMode)
+	config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PodSecurity)
+
+	h.Write([]byte(config))
+	return h.Sum(nil), nil
+}
+
+func getPodSecurityRefs(clusterSummary *configv1alpha1.ClusterSummary) []configv1alpha1.PolicyRef {
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+const (
+	enforceLabel        = "pod-security.kubernetes.io/enforce"
+	enforceVersionLabel = "pod-security.kubernetes.io/enforce-version"
+	auditLabel          = "pod-security.kubernetes.io/audit"
+)
+
+var _ = Describe("PodSecurity", func() {
+	It("getPodSecurityLabels returns labels for each configured mode", func() {
+		podSecurity := &configv1alpha1.PodSecurityConfiguration{
+			Enforce: configv1alpha1.PodSecurityLevelRestricted,
+			Warn:    configv1alpha1.PodSecurityLevelBaseline,
+		}
+
+		result := controllers.GetPodSecurityLabels(podSecurity)
+		Expect(result).To(HaveLen(4))
+		Expect(result[enforceLabel]).To(Equal(string(configv1alpha1.PodSecurityLevelRestricted)))
+		Expect(result[enforceVersionLabel]).To(Equal("latest"))
+		Expect(result["pod-security.kubernetes.io/warn"]).To(Equal(string(configv1alpha1.PodSecurityLevelBaseline)))
+
+		Expect(controllers.GetPodSecurityLabels(nil)).To(BeEmpty())
+	})
+
+	It("getPodSecurityNamespaceSelector rejects an empty selector", func() {
+		selector, err := controllers.GetPodSecurityNamespaceSelector(nil)
+		Expect(err).To(BeNil())
+		Expect(selector).To(BeNil())
+
+		for _, namespaceSelector := range []string{"", "  "} {
+			_, err = controllers.GetPodSecurityNamespaceSelector(&configv1alpha1.PodSecurityConfiguration{
+				NamespaceSelector: libsveltosv1alpha1.Selector(namespaceSelector),
+				Enforce:           configv1alpha1.PodSecurityLevelRestricted,
+			})
+			Expect(err).ToNot(BeNil())
+			var nonRetriableError *controllers.NonRetriableError
+			Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+		}
+
+		selector, err = controllers.GetPodSecurityNamespaceSelector(&configv1alpha1.PodSecurityConfiguration{
+			NamespaceSelector: "env=prod",
+			Enforce:           configv1alpha1.PodSecurityLevelRestricted,
+		})
+		Expect(err).To(BeNil())
+		Expect(selector.Matches(labels.Set{"kubernetes.io/metadata.name": "kube-system"})).To(BeFalse())
+		Expect(selector.Matches(labels.Set{"env": "prod"})).To(BeTrue())
+	})
+
+	It("reconcilePodSecurityLabels sets labels on matching namespaces and reverts them", func() {
+		key := randomString()
+		value := randomString()
+
+		matching := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+				Labels: map[string]string{
+					key:        value,
+					auditLabel: string(configv1alpha1.PodSecurityLevelPrivileged),
+				},
+			},
+		}
+		notMatching := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+
+		initObjects := []client.Object{matching, notMatching}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		selector, err := labels.Parse(key + "=" + value)
+		Expect(err).To(BeNil())
+
+		owner := randomString()
+		desired := controllers.GetPodSecurityLabels(&configv1alpha1.PodSecurityConfiguration{
+			Enforce: configv1alpha1.PodSecurityLevelBaseline,
+			Audit:   configv1alpha1.PodSecurityLevelRestricted,
+			Version: "v1.29",
+		})
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		Expect(controllers.ReconcilePodSecurityLabels(context.TODO(), c, owner, selector, desired, logger)).To(Succeed())
+
+		currentNs := &corev1.Namespace{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: matching.Name}, currentNs)).To(Succeed())
+		Expect(currentNs.Labels[enforceLabel]).To(Equal(string(configv1alpha1.PodSecurityLevelBaseline)))
+		Expect(currentNs.Labels[enforceVersionLabel]).To(Equal("v1.29"))
+		Expect(currentNs.Labels[auditLabel]).To(Equal(string(configv1alpha1.PodSecurityLevelRestricted)))
+		Expect(currentNs.Annotations).ToNot(BeEmpty())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: notMatching.Name}, currentNs)).To(Succeed())
+		Expect(currentNs.Labels).ToNot(HaveKey(enforceLabel))
+
+		// Another owner cannot manage same namespace
+		Expect(controllers.ReconcilePodSecurityLabels(context.TODO(), c, randomString(), selector, desired,
+			logger)).ToNot(Succeed())
+
+		// With no selector, labels are reverted to their original values
+		Expect(controllers.ReconcilePodSecurityLabels(context.TODO(), c, owner, nil, nil, logger)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: matching.Name}, currentNs)).To(Succeed())
+		Expect(currentNs.Labels).ToNot(HaveKey(enforceLabel))
+		Expect(currentNs.Labels).ToNot(HaveKey(enforceVersionLabel))
+		Expect(currentNs.Labels[auditLabel]).To(Equal(string(configv1alpha1.PodSecurityLevelPrivileged)))
+		Expect(currentNs.Labels[key]).To(Equal(value))
+		Expect(currentNs.Annotations).To(BeEmpty())
+	})
+})
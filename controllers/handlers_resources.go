@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"context"
-	"crypto/sha256"
 	"fmt"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
@@ -26,6 +25,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
@@ -35,7 +35,6 @@ import (
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	"github.com/projectsveltos/addon-controller/pkg/scope"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/deployer"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
@@ -92,19 +91,52 @@ func deployResources(ctx context.Context, c client.Client,
 		remoteDeployed = append(remoteDeployed, remoteResourceReports[i].Resource)
 	}
 
-	// TODO: track resource deployed in the management cluster
-	err = updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, featureHandler.id, remoteDeployed, nil)
+	previouslyDeployed, err := getClusterConfigurationDeployedResources(ctx, c, clusterSummary, profileOwnerRef,
+		featureHandler.id)
 	if err != nil {
 		return err
 	}
+	if deployError != nil {
+		// Not all referenced resources could be processed. Resources previously deployed might
+		// still be desired, so keep tracking those. Only resources deployed because of references
+		// which were removed are not desired anymore: those are removed right away.
+		removed := getResourcesOfRemovedReferences(clusterSummary, previouslyDeployed)
+		if len(removed) != 0 {
+			var undeployed []configv1alpha1.ResourceReport
+			undeployed, err = cleanPolicyRefResources(ctx, false, remoteRestConfig, remoteClient, clusterSummary,
+				remoteResourceReports, removed, logger)
+			if err != nil {
+				return err
+			}
+			remoteResourceReports = append(remoteResourceReports, undeployed...)
+		}
 
-	var undeployed []configv1alpha1.ResourceReport
-	_, undeployed, err = cleanStaleResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
-		localResourceReports, remoteResourceReports, logger)
+		stillDesired := make(map[string]configv1alpha1.Resource, len(previouslyDeployed))
+		for key := range previouslyDeployed {
+			if _, ok := removed[key]; !ok {
+				stillDesired[key] = previouslyDeployed[key]
+			}
+		}
+		remoteDeployed = addPreviouslyDeployedResources(remoteDeployed, stillDesired)
+	}
+
+	// TODO: track resource deployed in the management cluster
+	err = updateClusterConfiguration(ctx, c, clusterSummary, profileOwnerRef, featureHandler.id,
+		appendPostDeployJobResources(remoteDeployed, clusterSummary, featureHandler.id), nil)
 	if err != nil {
 		return err
 	}
-	remoteResourceReports = append(remoteResourceReports, undeployed...)
+
+	// Stale resources are removed only once the desired set of resources is fully known
+	if deployError == nil {
+		var undeployed []configv1alpha1.ResourceReport
+		_, undeployed, err = cleanStaleResources(ctx, remoteRestConfig, remoteClient, clusterSummary,
+			localResourceReports, remoteResourceReports, previouslyDeployed, logger)
+		if err != nil {
+			return err
+		}
+		remoteResourceReports = append(remoteResourceReports, undeployed...)
+	}
 
 	err = handleWatchers(ctx, clusterSummary, localResourceReports, featureHandler)
 	if err != nil {
@@ -130,23 +162,30 @@ func deployResources(ctx context.Context, c client.Client,
 		return deployError
 	}
 
-	return validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureResources, logger)
+	err = validateHealthPolicies(ctx, remoteRestConfig, clusterSummary, configv1alpha1.FeatureResources, logger)
+	if err != nil {
+		return err
+	}
+
+	return runPostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureResources, logger)
 }
 
 func cleanStaleResources(ctx context.Context, remoteRestConfig *rest.Config, remoteClient client.Client,
 	clusterSummary *configv1alpha1.ClusterSummary, localResourceReports, remoteResourceReports []configv1alpha1.ResourceReport,
+	remotePreviouslyDeployed map[string]configv1alpha1.Resource,
 	logger logr.Logger) (localUndeployed, remoteUndeployed []configv1alpha1.ResourceReport, err error) {
 
-	// Clean stale resources in the management cluster
+	// Clean stale resources in the management cluster. Resources deployed in the management cluster
+	// are not tracked, so there is no previously deployed set.
 	localUndeployed, err = cleanPolicyRefResources(ctx, true, getManagementClusterConfig(), getManagementClusterClient(),
-		clusterSummary, localResourceReports, logger)
+		clusterSummary, localResourceReports, nil, logger)
 	if err != nil {
 		return localUndeployed, nil, err
 	}
 
 	// Clean stale resources in the remote cluster
 	remoteUndeployed, err = cleanPolicyRefResources(ctx, false, remoteRestConfig, remoteClient, clusterSummary,
-		remoteResourceReports, logger)
+		remoteResourceReports, remotePreviouslyDeployed, logger)
 	if err != nil {
 		return localUndeployed, remoteUndeployed, err
 	}
@@ -163,7 +202,8 @@ func handleDriftDetectionManagerDeployment(ctx context.Context, clusterSummary *
 	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeContinuousWithDriftDetection {
 		// Deploy drift detection manager first. Have manager up by the time resourcesummary is created
 		err := deployDriftDetectionManagerInCluster(ctx, getManagementClusterClient(), clusterNamespace,
-			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster, logger)
+			clusterName, clusterSummary.Name, clusterType, startInMgmtCluster,
+			clusterSummary.Spec.ClusterProfileSpec.RegistryOverrides, logger)
 		if err != nil {
 			return err
 		}
@@ -237,9 +277,13 @@ func handleWatchers(ctx context.Context, clusterSummary *configv1alpha1.ClusterS
 	return nil
 }
 
+// cleanPolicyRefResources removes resources deployed because of PolicyRefs which are not deployed anymore.
+// When previouslyDeployed is set, only resources in previouslyDeployed and not in resourceReports are
+// removed. Otherwise all resources deployed because of PolicyRefs are considered.
 func cleanPolicyRefResources(ctx context.Context, isMgmtCluster bool, destRestConfig *rest.Config,
 	destClient client.Client, clusterSummary *configv1alpha1.ClusterSummary,
-	resourceReports []configv1alpha1.ResourceReport, logger logr.Logger,
+	resourceReports []configv1alpha1.ResourceReport, previouslyDeployed map[string]configv1alpha1.Resource,
+	logger logr.Logger,
 ) ([]configv1alpha1.ResourceReport, error) {
 
 	currentPolicies := make(map[string]configv1alpha1.Resource, 0)
@@ -248,9 +292,22 @@ func cleanPolicyRefResources(ctx context.Context, isMgmtCluster bool, destRestCo
 		currentPolicies[key] = resourceReports[i].Resource
 	}
 
-	undeployed, err := undeployStaleResources(ctx, isMgmtCluster, destRestConfig, destClient, configv1alpha1.FeatureResources,
-		clusterSummary, getDeployedGroupVersionKinds(clusterSummary, configv1alpha1.FeatureResources),
-		currentPolicies, logger)
+	var undeployed []configv1alpha1.ResourceReport
+	var err error
+	if previouslyDeployed != nil {
+		undeployed, err = undeployRemovedResources(ctx, destRestConfig, destClient, configv1alpha1.FeatureResources,
+			clusterSummary, previouslyDeployed, currentPolicies, logger)
+	} else {
+		undeployed, err = undeployStaleResources(ctx, isMgmtCluster, destRestConfig, destClient,
+			configv1alpha1.FeatureResources, clusterSummary,
+			getDeployedGroupVersionKinds(clusterSummary, configv1alpha1.FeatureResources), currentPolicies, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = removeStaleImagePullSecrets(ctx, destClient, isMgmtCluster, clusterSummary,
+		configv1alpha1.FeatureResources, false, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -277,14 +334,7 @@ func undeployResources(ctx context.Context, c client.Client,
 
 	logger.V(logs.LogDebug).Info("undeployResources")
 
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
-	if err != nil {
-		return err
-	}
-
-	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
-		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	remoteRestConfig, remoteClient, err := getImpersonatedRemoteAccess(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return err
 	}
@@ -307,6 +357,22 @@ func undeployResources(ctx context.Context, c client.Client,
 		return err
 	}
 
+	// Remove image pull Secrets not needed anymore
+	err = removeStaleImagePullSecrets(ctx, c, true, clusterSummary, configv1alpha1.FeatureResources, true, logger)
+	if err != nil {
+		return err
+	}
+	err = removeStaleImagePullSecrets(ctx, remoteClient, false, clusterSummary, configv1alpha1.FeatureResources,
+		true, logger)
+	if err != nil {
+		return err
+	}
+
+	err = removePostDeployJobs(ctx, remoteClient, clusterSummary, configv1alpha1.FeatureResources, nil, logger)
+	if err != nil {
+		return err
+	}
+
 	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
 	if err != nil {
 		return err
@@ -343,7 +409,7 @@ func undeployResources(ctx context.Context, c client.Client,
 func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *scope.ClusterSummaryScope,
 	logger logr.Logger) ([]byte, error) {
 
-	h := sha256.New()
+	h := newFeatureHash()
 	var config string
 
 	// If SyncMode changes (from not ContinuousWithDriftDetection to ContinuousWithDriftDetection
@@ -359,6 +425,44 @@ func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *sc
 	config += fmt.Sprintf("%d", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tier)
 	config += fmt.Sprintf("%t", clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict)
 
+	// If PriorityClass changes, workloads need to be updated
+	config += clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassName
+	if v := clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.PriorityClassValue; v != nil {
+		config += fmt.Sprintf("%d", *v)
+	}
+
+	// If NodeSelector or Tolerations change, workloads need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.NodeSelector)
+	}
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.Tolerations)
+	}
+	// If RegistryOverrides change, workload images need to be updated
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.RegistryOverrides)
+	}
+	// If ImagePullSecrets (or their content) change, Secrets and workloads need to be updated
+	config += getImagePullSecretsHash(ctx, c, clusterSummaryScope.ClusterSummary)
+
+	// If Patches change, resources need to be patched differently
+	if patches := getFeaturePatches(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureResources); len(patches) > 0 {
+		config += render.AsCode(patches)
+	}
+
+	// If ExcludedObjects change, resources need to be deployed or removed
+	if excluded := getFeatureExcludedObjects(clusterSummaryScope.ClusterSummary, configv1alpha1.FeatureResources); len(excluded) > 0 {
+		config += render.AsCode(excluded)
+	}
+
+	// If InlinePolicies change, resources need to be redeployed
+	if len(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.InlinePolicies) > 0 {
+		config += render.AsCode(clusterSummaryScope.ClusterSummary.Spec.ClusterProfileSpec.InlinePolicies)
+	}
+
+	// If CopyRefs (or the referenced ConfigMaps/Secrets) change, copies need to be updated
+	config += getCopyRefsHash(ctx, c, clusterSummaryScope.ClusterSummary)
+
 	clusterSummary := clusterSummaryScope.ClusterSummary
 	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
 		reference := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
@@ -368,15 +472,17 @@ func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *sc
 			configmap := &corev1.ConfigMap{}
 			err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: reference.Name}, configmap)
 			if err == nil {
-				config += getDataSectionHash(configmap.Data)
-				config += getDataSectionHash(configmap.BinaryData)
+				config += getReferencedObjectIdentity(configmap)
+				config += getDataSectionHash(filterDataKeys(configmap.Data, reference.Keys))
+				config += getDataSectionHash(filterDataKeys(configmap.BinaryData, reference.Keys))
 			}
 		} else if reference.Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
 			secret := &corev1.Secret{}
 			err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: reference.Name}, secret)
 			if err == nil {
-				config += getDataSectionHash(secret.Data)
-				config += getDataSectionHash(secret.StringData)
+				config += getReferencedObjectIdentity(secret)
+				config += getDataSectionHash(filterDataKeys(secret.Data, reference.Keys))
+				config += getDataSectionHash(filterDataKeys(secret.StringData, reference.Keys))
 			}
 		} else {
 			var source client.Object
@@ -407,6 +513,13 @@ func resourcesHash(ctx context.Context, c client.Client, clusterSummaryScope *sc
 		}
 	}
 
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		j := &clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+		if j.FeatureID == configv1alpha1.FeatureResources {
+			config += render.AsCode(j)
+		}
+	}
+
 	for i := range clusterSummary.Spec.ClusterProfileSpec.TemplateResourceRefs {
 		tr := &clusterSummary.Spec.ClusterProfileSpec.TemplateResourceRefs[i]
 		config += render.AsCode(tr)
@@ -493,6 +606,46 @@ func deployResourceSummary(ctx context.Context, c client.Client,
 		clusterType, resources, nil, nil, logger)
 }
 
+// getResourcesOfRemovedReferences returns, among previouslyDeployed, the resources deployed because of
+// a ConfigMap/Secret/Source not referenced anymore, neither in PolicyRefs nor in CopyRefs.
+// Resources deployed because of InlinePolicies, or with no recorded owner, are never returned.
+func getResourcesOfRemovedReferences(clusterSummary *configv1alpha1.ClusterSummary,
+	previouslyDeployed map[string]configv1alpha1.Resource) map[string]configv1alpha1.Resource {
+
+	referenced := make(map[corev1.ObjectReference]bool)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
+		referenced[corev1.ObjectReference{
+			Kind:      ref.Kind,
+			Namespace: getReferenceResourceNamespace(clusterSummary.Namespace, ref.Namespace),
+			Name:      ref.Name,
+		}] = true
+	}
+
+	// Namespace of copied resources might be defaulted. Consider kind and name only.
+	copied := make(map[corev1.ObjectReference]bool)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.CopyRefs {
+		ref := &clusterSummary.Spec.ClusterProfileSpec.CopyRefs[i]
+		copied[corev1.ObjectReference{Kind: ref.Kind, Name: ref.Name}] = true
+	}
+
+	removed := make(map[string]configv1alpha1.Resource)
+	for key := range previouslyDeployed {
+		owner := previouslyDeployed[key].Owner
+		if owner.Kind == "" || owner.Kind == configv1alpha1.ClusterSummaryKind {
+			continue
+		}
+		if referenced[corev1.ObjectReference{Kind: owner.Kind, Namespace: owner.Namespace, Name: owner.Name}] ||
+			copied[corev1.ObjectReference{Kind: owner.Kind, Name: owner.Name}] {
+
+			continue
+		}
+		removed[key] = previouslyDeployed[key]
+	}
+
+	return removed
+}
+
 // deployPolicyRefs deploys in a managed Cluster the policies contained in the Data section of each
 // referenced ConfigMap/Secret
 func deployPolicyRefs(ctx context.Context, c client.Client, remoteConfig *rest.Config,
@@ -511,6 +664,108 @@ func deployPolicyRefs(ctx context.Context, c client.Client, remoteConfig *rest.C
 		return nil, nil, err
 	}
 
-	return deployReferencedObjects(ctx, c, remoteConfig, clusterSummary,
+	localReports, remoteReports, err = deployReferencedObjects(ctx, c, remoteConfig, clusterSummary,
 		objectsToDeployLocally, objectsToDeployRemotely, logger)
+	if err != nil {
+		return localReports, remoteReports, err
+	}
+
+	inlineReports, err := deployInlinePolicies(ctx, remoteConfig, clusterSummary, logger)
+	remoteReports = append(remoteReports, inlineReports...)
+	if err != nil {
+		return localReports, remoteReports, err
+	}
+
+	copyReports, err := deployCopyRefs(ctx, c, remoteConfig, clusterSummary, logger)
+	remoteReports = append(remoteReports, copyReports...)
+	return localReports, remoteReports, err
+}
+
+// deployInlinePolicies deploys, in the managed cluster, the resources contained in InlinePolicies.
+// Those are deployed as if contained in a ConfigMap named after the ClusterSummary.
+func deployInlinePolicies(ctx context.Context, remoteConfig *rest.Config,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) ([]configv1alpha1.ResourceReport, error) {
+
+	inlinePolicies := clusterSummary.Spec.ClusterProfileSpec.InlinePolicies
+	if len(inlinePolicies) == 0 {
+		return nil, nil
+	}
+
+	remoteClient, err := client.New(remoteConfig, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string, len(inlinePolicies))
+	for i := range inlinePolicies {
+		data[fmt.Sprintf("inline-policy-%d", i)] = inlinePolicies[i]
+	}
+
+	logger.V(logs.LogDebug).Info("deploying inline policies")
+	return deployContent(ctx, false, remoteConfig, remoteClient, getInlinePoliciesReference(clusterSummary), data,
+		clusterSummary, mgmtResources, logger)
+}
+
+// getInlinePoliciesReference returns the object resources contained in InlinePolicies are
+// reported to be deployed because of.
+func getInlinePoliciesReference(clusterSummary *configv1alpha1.ClusterSummary) client.Object {
+	return &configv1alpha1.ClusterSummary{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       configv1alpha1.ClusterSummaryKind,
+			APIVersion: configv1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterSummary.Namespace,
+			Name:      clusterSummary.Name,
+		},
+	}
+}
+
+// getPolicyRefsStatus returns the PolicyRefs of clusterSummary which were found in the management
+// cluster and the ones which do not exist (yet).
+func getPolicyRefsStatus(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+) (resolved, missing []corev1.ObjectReference, err error) {
+
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PolicyRefs {
+		reference := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[i]
+		namespace := getReferenceResourceNamespace(clusterSummary.Namespace, reference.Namespace)
+		ref := corev1.ObjectReference{Kind: reference.Kind, Namespace: namespace, Name: reference.Name}
+
+		var object client.Object
+		switch reference.Kind {
+		case string(libsveltosv1alpha1.ConfigMapReferencedResourceKind):
+			object = &corev1.ConfigMap{}
+		case string(libsveltosv1alpha1.SecretReferencedResourceKind):
+			object = &corev1.Secret{}
+		default:
+			var source client.Object
+			source, err = getSource(ctx, c, namespace, reference.Name, reference.Kind)
+			if err != nil {
+				return nil, nil, err
+			}
+			if source == nil {
+				missing = append(missing, ref)
+			} else {
+				resolved = append(resolved, ref)
+			}
+			continue
+		}
+
+		err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: reference.Name}, object)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, ref)
+				continue
+			}
+			return nil, nil, err
+		}
+		resolved = append(resolved, ref)
+	}
+
+	return resolved, missing, nil
 }
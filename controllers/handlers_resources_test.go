@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/gdexlab/go-render/render"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -383,5 +384,116 @@ var _ = Describe("Hash methods", func() {
 		hash, err := controllers.ResourcesHash(context.TODO(), c, clusterSummaryScope, textlogger.NewLogger(textlogger.NewConfig()))
 		Expect(err).To(BeNil())
 		Expect(reflect.DeepEqual(hash, expectHash)).To(BeTrue())
+
+		// Computing the hash has no side effect on status
+		Expect(clusterSummary.Status.FeatureSummaries).To(BeEmpty())
+
+		// Status reports which PolicyRefs were found and which were missing
+		resolved, missing, err := controllers.GetPolicyRefsStatus(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(resolved).To(ConsistOf(
+			corev1.ObjectReference{Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+				Namespace: configMap1.Namespace, Name: configMap1.Name},
+			corev1.ObjectReference{Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+				Namespace: configMap2.Namespace, Name: configMap2.Name},
+		))
+		missingRef := &clusterSummary.Spec.ClusterProfileSpec.PolicyRefs[2]
+		Expect(missing).To(ConsistOf(
+			corev1.ObjectReference{Kind: missingRef.Kind, Namespace: missingRef.Namespace, Name: missingRef.Name},
+		))
+	})
+
+	It("ResourcesHash changes when InlinePolicies change", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					InlinePolicies: []string{fmt.Sprintf(viewClusterRole, randomString())},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(clusterSummary).
+			WithObjects(clusterSummary).Build()
+
+		clusterSummaryScope, err := scope.NewClusterSummaryScope(&scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			ClusterSummary: clusterSummary,
+			ControllerName: "clustersummary",
+		})
+		Expect(err).To(BeNil())
+
+		hash, err := controllers.ResourcesHash(context.TODO(), c, clusterSummaryScope, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		clusterSummary.Spec.ClusterProfileSpec.InlinePolicies = append(clusterSummary.Spec.ClusterProfileSpec.InlinePolicies,
+			fmt.Sprintf(viewClusterRole, randomString()))
+		newHash, err := controllers.ResourcesHash(context.TODO(), c, clusterSummaryScope, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(reflect.DeepEqual(hash, newHash)).To(BeFalse())
+	})
+
+	It("getResourcesOfRemovedReferences returns only resources of references not present anymore", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+
+		keptRef := configv1alpha1.PolicyRef{
+			Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind), Namespace: randomString(), Name: randomString(),
+		}
+		// Namespace is not set, so cluster namespace is used
+		defaultNamespaceRef := configv1alpha1.PolicyRef{
+			Kind: string(libsveltosv1alpha1.SecretReferencedResourceKind), Name: randomString(),
+		}
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{keptRef, defaultNamespaceRef}
+
+		kept := configv1alpha1.Resource{
+			Kind: "ConfigMap", Namespace: randomString(), Name: randomString(), Version: "v1",
+			Owner: corev1.ObjectReference{Kind: keptRef.Kind, Namespace: keptRef.Namespace, Name: keptRef.Name},
+		}
+		keptInDefaultNamespace := configv1alpha1.Resource{
+			Kind: "ServiceAccount", Namespace: randomString(), Name: randomString(), Version: "v1",
+			Owner: corev1.ObjectReference{
+				Kind: defaultNamespaceRef.Kind, Namespace: clusterSummary.Namespace, Name: defaultNamespaceRef.Name,
+			},
+		}
+		inline := configv1alpha1.Resource{
+			Kind: "Service", Namespace: randomString(), Name: randomString(), Version: "v1",
+			Owner: corev1.ObjectReference{
+				Kind: configv1alpha1.ClusterSummaryKind, Namespace: clusterSummary.Namespace, Name: clusterSummary.Name,
+			},
+		}
+		removed := configv1alpha1.Resource{
+			Kind: "ClusterRole", Name: randomString(), Group: "rbac.authorization.k8s.io", Version: "v1",
+			Owner: corev1.ObjectReference{
+				Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind), Namespace: randomString(), Name: randomString(),
+			},
+		}
+
+		previouslyDeployed := map[string]configv1alpha1.Resource{}
+		resources := []configv1alpha1.Resource{kept, keptInDefaultNamespace, inline, removed}
+		for i := range resources {
+			previouslyDeployed[controllers.GetPolicyInfo(&resources[i])] = resources[i]
+		}
+
+		result := controllers.GetResourcesOfRemovedReferences(clusterSummary, previouslyDeployed)
+		Expect(len(result)).To(Equal(1))
+		Expect(result).To(HaveKey(controllers.GetPolicyInfo(&removed)))
+
+		// Once the other reference is removed, its resources are returned as well
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{defaultNamespaceRef}
+		result = controllers.GetResourcesOfRemovedReferences(clusterSummary, previouslyDeployed)
+		Expect(len(result)).To(Equal(2))
+		Expect(result).To(HaveKey(controllers.GetPolicyInfo(&removed)))
+		Expect(result).To(HaveKey(controllers.GetPolicyInfo(&kept)))
 	})
 })
@@ -24,14 +24,20 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"strings"
 	"time"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,10 +46,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,6 +71,16 @@ const (
 	pathAnnotation           = "path"
 )
 
+var (
+	// defaultReferenceNamespace, when set, is the namespace referenced resources without
+	// a namespace are looked up in
+	defaultReferenceNamespace string
+
+	// maxObjectsPerReference, when positive, is the maximum number of objects a single
+	// referenced ConfigMap/Secret/Source can contain
+	maxObjectsPerReference int
+)
+
 func getClusterSummaryAnnotationValue(clusterSummary *configv1alpha1.ClusterSummary) string {
 	prefix := getPrefix(clusterSummary.Spec.ClusterType)
 	return fmt.Sprintf("%s-%s-%s", prefix, clusterSummary.Spec.ClusterNamespace,
@@ -98,41 +116,10 @@ func createNamespace(ctx context.Context, clusterClient client.Client,
 	return nil
 }
 
-// deployContentOfConfigMap deploys policies contained in a ConfigMap.
-// Returns an error if one occurred. Otherwise it returns a slice containing the name of
-// the policies deployed in the form of kind.group:namespace:name for namespaced policies
-// and kind.group::name for cluster wide policies.
-func deployContentOfConfigMap(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
-	destClient client.Client, configMap *corev1.ConfigMap, clusterSummary *configv1alpha1.ClusterSummary,
-	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
-) ([]configv1alpha1.ResourceReport, error) {
-
-	return deployContent(ctx, deployingToMgmtCluster, destConfig, destClient, configMap, configMap.Data,
-		clusterSummary, mgmtResources, logger)
-}
-
-// deployContentOfSecret deploys policies contained in a Secret.
-// Returns an error if one occurred. Otherwise it returns a slice containing the name of
-// the policies deployed in the form of kind.group:namespace:name for namespaced policies
-// and kind.group::name for cluster wide policies.
-func deployContentOfSecret(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
-	destClient client.Client, secret *corev1.Secret, clusterSummary *configv1alpha1.ClusterSummary,
-	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
-) ([]configv1alpha1.ResourceReport, error) {
-
-	data := make(map[string]string)
-	for key, value := range secret.Data {
-		data[key] = string(value)
-	}
-
-	return deployContent(ctx, deployingToMgmtCluster, destConfig, destClient, secret, data,
-		clusterSummary, mgmtResources, logger)
-}
-
-func deployContentOfSource(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
-	destClient client.Client, source client.Object, path string, clusterSummary *configv1alpha1.ClusterSummary,
-	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
-) ([]configv1alpha1.ResourceReport, error) {
+// getSourceContent returns the content of the files found at path in the artifact of a Flux Source.
+// Returns nil if the Source has no artifact yet.
+func getSourceContent(ctx context.Context, source client.Object, path string,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) (map[string]string, error) {
 
 	s := source.(sourcev1.Source)
 
@@ -172,8 +159,7 @@ func deployContentOfSource(ctx context.Context, deployingToMgmtCluster bool, des
 		return nil, err
 	}
 
-	return deployContent(ctx, deployingToMgmtCluster, destConfig, destClient, source, content,
-		clusterSummary, mgmtResources, logger)
+	return content, nil
 }
 
 func readFiles(dir string) (map[string]string, error) {
@@ -221,7 +207,12 @@ func updateResource(ctx context.Context, dr dynamic.ResourceInterface,
 		Force:        &forceConflict,
 	}
 	_, err = dr.Patch(ctx, object.GetName(), types.ApplyPatchType, data, options)
-	return err
+	if err != nil {
+		return err
+	}
+
+	recordAudit(clusterSummary, AuditActionApply, object)
+	return nil
 }
 
 func instantiateTemplate(referencedObject client.Object, logger logr.Logger) bool {
@@ -248,6 +239,27 @@ func deployContent(ctx context.Context, deployingToMgmtCluster bool, destConfig
 	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
 ) (reports []configv1alpha1.ResourceReport, err error) {
 
+	content, err := collectReferencedContent(ctx, referencedObject, data, clusterSummary, mgmtResources, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployUnstructured(ctx, deployingToMgmtCluster, destConfig, destClient, content.resources, content.ref,
+		configv1alpha1.FeatureResources, clusterSummary, logger)
+}
+
+// referencedContent contains the resources found in a referenced ConfigMap/Secret/Source
+type referencedContent struct {
+	ref       *corev1.ObjectReference
+	resources []*unstructured.Unstructured
+}
+
+// collectReferencedContent returns the resources contained in data (content of referencedObject),
+// sorted in the order those need to be applied.
+func collectReferencedContent(ctx context.Context, referencedObject client.Object, data map[string]string,
+	clusterSummary *configv1alpha1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) (*referencedContent, error) {
+
 	instantiateTemplate := instantiateTemplate(referencedObject, logger)
 	resources, err := collectContent(ctx, clusterSummary, mgmtResources, data, instantiateTemplate, logger)
 	if err != nil {
@@ -260,8 +272,15 @@ func deployContent(ctx context.Context, deployingToMgmtCluster bool, destConfig
 		Name:      referencedObject.GetName(),
 	}
 
-	return deployUnstructured(ctx, deployingToMgmtCluster, destConfig, destClient, resources, ref,
-		configv1alpha1.FeatureResources, clusterSummary, logger)
+	if err := validateObjectsPerReference(ref, len(resources)); err != nil {
+		logger.V(logs.LogInfo).Info(err.Error())
+		return nil, err
+	}
+
+	// Apply first the objects others depend on (Namespaces, ServiceAccounts, ...)
+	sortByApplyOrder(resources)
+
+	return &referencedContent{ref: ref, resources: resources}, nil
 }
 
 // setNamespaceIfUnset sets namespace to default for namespaced resource with unset namespace
@@ -289,6 +308,40 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 	featureID configv1alpha1.FeatureID, clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger,
 ) (reports []configv1alpha1.ResourceReport, err error) {
 
+	referencedUnstructured, err = prepareUnstructured(referencedUnstructured, featureID, clusterSummary, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail fast, with a clear message, if the destination cluster does not serve any of the
+	// resources instead of failing midway
+	err = verifyAPISupport(getDiscoveryCacheKey(deployingToMgmtCluster, clusterSummary), destConfig,
+		referencedUnstructured, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyUnstructured(ctx, deployingToMgmtCluster, destConfig, destClient, referencedUnstructured,
+		referencedObject, featureID, clusterSummary, logger)
+}
+
+// prepareUnstructured removes excluded objects from referencedUnstructured and applies patches
+// to the remaining ones
+func prepareUnstructured(referencedUnstructured []*unstructured.Unstructured, featureID configv1alpha1.FeatureID,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) ([]*unstructured.Unstructured, error) {
+
+	referencedUnstructured = filterExcludedObjects(referencedUnstructured, featureID, clusterSummary, logger)
+
+	return applyPatches(referencedUnstructured, featureID, clusterSummary)
+}
+
+// applyUnstructured applies referencedUnstructured objects, which must have already been prepared
+// (see prepareUnstructured), to the destination cluster.
+func applyUnstructured(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
+	destClient client.Client, referencedUnstructured []*unstructured.Unstructured, referencedObject *corev1.ObjectReference,
+	featureID configv1alpha1.FeatureID, clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger,
+) (reports []configv1alpha1.ResourceReport, err error) {
+
 	profile, profileTier, err := configv1alpha1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
 	if err != nil {
 		return nil, err
@@ -297,55 +350,133 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 		profile.SetName(profileNameToOwnerReferenceName(profile))
 	}
 
+	err = validateTolerations(clusterSummary.Spec.ClusterProfileSpec.Tolerations)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ensurePriorityClass(ctx, destClient, clusterSummary, referencedUnstructured, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	conflictErrorMsg := ""
+	var applyErrors []error
+	imagePullSecretNamespaces := make(map[string]bool)
 	reports = make([]configv1alpha1.ResourceReport, 0)
 	for i := range referencedUnstructured {
 		policy := referencedUnstructured[i]
 
-		err := setNamespaceIfUnset(policy, destConfig)
-		if err != nil {
-			return nil, err
-		}
-
-		logger.V(logs.LogDebug).Info(fmt.Sprintf("deploying resource %s %s/%s (deploy to management cluster: %v)",
-			policy.GetKind(), policy.GetNamespace(), policy.GetName(), deployingToMgmtCluster))
-
-		resource, policyHash := getResource(policy, referencedObject, profileTier, featureID, logger)
-
-		// If policy is namespaced, create namespace if not already existing
-		err = createNamespace(ctx, destClient, clusterSummary, policy.GetNamespace())
-		if err != nil {
-			return nil, err
-		}
-
-		// If policy already exists, just get current version and update it by overridding
-		// all metadata and spec.
-		// If policy does not exist already, create it
-		dr, err := utils.GetDynamicResourceInterface(destConfig, policy.GroupVersionKind(), policy.GetNamespace())
-		if err != nil {
-			return nil, err
-		}
-
-		var resourceInfo *deployer.ResourceInfo
-		var requeue bool
-		resourceInfo, requeue, err = canDeployResource(ctx, dr, policy, referencedObject, profile, profileTier, logger)
+		report, err := deployObject(ctx, deployingToMgmtCluster, destConfig, destClient, policy, referencedObject,
+			profile, profileTier, featureID, clusterSummary, imagePullSecretNamespaces, logger)
 		if err != nil {
 			var conflictErr *deployer.ConflictError
 			ok := errors.As(err, &conflictErr)
 			if ok {
-				conflictResourceReport := generateConflictResourceReport(ctx, dr, resource)
 				if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
-					reports = append(reports, *conflictResourceReport)
+					reports = append(reports, *report)
 					continue
 				} else {
-					conflictErrorMsg += conflictResourceReport.Message
+					conflictErrorMsg += report.Message
 					if clusterSummary.Spec.ClusterProfileSpec.ContinueOnConflict {
 						continue
 					}
 					return reports, deployer.NewConflictError(conflictErrorMsg)
 				}
 			}
-			return reports, err
+			if !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				return reports, err
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to deploy resource %s %s/%s: %v. Continuing with next one",
+				policy.GetKind(), policy.GetNamespace(), policy.GetName(), err))
+			applyErrors = append(applyErrors,
+				fmt.Errorf("%s %s/%s: %w", policy.GetKind(), policy.GetNamespace(), policy.GetName(), err))
+			continue
+		}
+
+		reports = append(reports, *report)
+	}
+
+	if len(applyErrors) != 0 {
+		if conflictErrorMsg != "" {
+			applyErrors = append(applyErrors, deployer.NewConflictError(conflictErrorMsg))
+		}
+		return reports, utilerrors.NewAggregate(applyErrors)
+	}
+
+	if conflictErrorMsg != "" {
+		return reports, deployer.NewConflictError(conflictErrorMsg)
+	}
+
+	return reports, nil
+}
+
+// deployObject deploys a single object. imagePullSecretNamespaces contains the namespaces where image
+// pull secrets have already been deployed and it is updated by this method.
+// On conflict, a report describing the conflict is returned along with a deployer.ConflictError.
+func deployObject(ctx context.Context, deployingToMgmtCluster bool, destConfig *rest.Config,
+	destClient client.Client, policy *unstructured.Unstructured, referencedObject *corev1.ObjectReference,
+	profile client.Object, profileTier int32, featureID configv1alpha1.FeatureID,
+	clusterSummary *configv1alpha1.ClusterSummary, imagePullSecretNamespaces map[string]bool, logger logr.Logger,
+) (*configv1alpha1.ResourceReport, error) {
+
+	err := setNamespaceIfUnset(policy, destConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setWorkloadScheduling(policy, &clusterSummary.Spec.ClusterProfileSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("deploying resource %s %s/%s (deploy to management cluster: %v)",
+		policy.GetKind(), policy.GetNamespace(), policy.GetName(), deployingToMgmtCluster))
+
+	resource, policyHash := getResource(policy, referencedObject, profileTier, featureID, logger)
+
+	// If policy is namespaced, create namespace if not already existing
+	err = createNamespace(ctx, destClient, clusterSummary, policy.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	// Image pull Secrets must be in place before workloads needing them are deployed
+	if isWorkload(policy) && !imagePullSecretNamespaces[policy.GetNamespace()] {
+		err = ensureImagePullSecrets(ctx, destClient, deployingToMgmtCluster, clusterSummary,
+			policy.GetNamespace(), logger)
+		if err != nil {
+			return nil, err
+		}
+		imagePullSecretNamespaces[policy.GetNamespace()] = true
+	}
+
+	// If policy already exists, just get current version and update it by overridding
+	// all metadata and spec.
+	// If policy does not exist already, create it
+	dr, err := utils.GetDynamicResourceInterface(destConfig, policy.GroupVersionKind(), policy.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource might be modified in the managed cluster between the time it is fetched and
+	// the time it is applied. On such conflicts, fetch it again and retry.
+	var resourceInfo *deployer.ResourceInfo
+	err = retryOnApplyConflict(func() error {
+		var requeue bool
+		var applyErr error
+		resourceInfo, requeue, applyErr = canDeployResource(ctx, dr, policy, referencedObject, profile,
+			profileTier, logger)
+		if applyErr != nil {
+			return applyErr
+		}
+
+		if isCustomResourceDefinition(policy) && resourceInfo.ResourceVersion != "" {
+			// CustomResourceDefinitions are shared. Keep all current owners so the CustomResourceDefinition
+			// is removed only once no (Cluster)Profile needs it anymore.
+			if applyErr = keepCurrentOwnerReferences(ctx, dr, policy); applyErr != nil {
+				return applyErr
+			}
 		}
 
 		addMetadata(policy, resourceInfo.ResourceVersion, profile,
@@ -362,26 +493,24 @@ func deployUnstructured(ctx context.Context, deployingToMgmtCluster bool, destCo
 		}
 
 		if requeue {
-			err = requeueAllOldOwners(ctx, resourceInfo.OwnerReferences, featureID, clusterSummary, logger)
-			if err != nil {
-				return reports, err
+			applyErr = requeueAllOldOwners(ctx, resourceInfo.OwnerReferences, featureID, clusterSummary, logger)
+			if applyErr != nil {
+				return applyErr
 			}
 		}
 
-		err = updateResource(ctx, dr, clusterSummary, policy, logger)
-		if err != nil {
-			return reports, err
+		return updateResource(ctx, dr, clusterSummary, policy, logger)
+	})
+	if err != nil {
+		var conflictErr *deployer.ConflictError
+		if errors.As(err, &conflictErr) {
+			return generateConflictResourceReport(ctx, dr, resource), err
 		}
-
-		resource.LastAppliedTime = &metav1.Time{Time: time.Now()}
-		reports = append(reports, *generateResourceReport(policyHash, resourceInfo, resource))
-	}
-
-	if conflictErrorMsg != "" {
-		return reports, deployer.NewConflictError(conflictErrorMsg)
+		return nil, err
 	}
 
-	return reports, nil
+	resource.LastAppliedTime = &metav1.Time{Time: time.Now()}
+	return generateResourceReport(policyHash, resourceInfo, resource), nil
 }
 
 func addMetadata(policy *unstructured.Unstructured, resourceVersion string, profile client.Object,
@@ -464,6 +593,7 @@ func requeueAllOldOwners(ctx context.Context, profileOwners []corev1.ObjectRefer
 // referenced resource => it cannot be updated
 // - if resource is currently already deployed in the managed cluster but owned by different (Cluster)Profile
 // => it can be updated only if current (Cluster)Profile tier is lower than profile currently deploying the resource
+// - CustomResourceDefinitions are shared, so they can always be updated
 //
 // If resource cannot be deployed, return a ConflictError.
 // If any other error occurs while doing those verification, the error is returned
@@ -480,6 +610,11 @@ func canDeployResource(ctx context.Context, dr dynamic.ResourceInterface, policy
 		ok := errors.As(err, &conflictErr)
 		if ok {
 			// There is a conflict.
+			if isCustomResourceDefinition(policy) {
+				// CustomResourceDefinitions are shared by all (Cluster)Profiles deploying them
+				l.V(logs.LogDebug).Info("CustomResourceDefinition is already deployed. Sharing it.")
+				return resourceInfo, false, nil
+			}
 			if hasHigherOwnershipPriority(getTier(resourceInfo.OwnerTier), profileTier) {
 				l.V(logs.LogDebug).Info("conflict detected but resource ownership can change")
 				// Because of tier, ownership must change. Which also means current ClusterProfile/Profile
@@ -557,6 +692,288 @@ func addExtraAnnotations(policy *unstructured.Unstructured, extraAnnotations map
 	policy.SetAnnotations(annotations)
 }
 
+// setWorkloadScheduling sets PriorityClassName, NodeSelector and Tolerations, when set in the
+// ClusterProfile/Profile spec, on the pod template of policy.
+func setWorkloadScheduling(policy *unstructured.Unstructured, spec *configv1alpha1.Spec) error {
+	err := setPriorityClassName(policy, spec.PriorityClassName)
+	if err != nil {
+		return err
+	}
+
+	err = setNodeSelector(policy, spec.NodeSelector)
+	if err != nil {
+		return err
+	}
+
+	err = setTolerations(policy, spec.Tolerations)
+	if err != nil {
+		return err
+	}
+
+	err = setImagePullSecrets(policy, spec.ImagePullSecrets)
+	if err != nil {
+		return err
+	}
+
+	return rewriteImages(policy, spec.RegistryOverrides)
+}
+
+// setPriorityClassName sets priorityClassName on the pod spec of policy when policy is a
+// workload (see isWorkload). Any other resource is left untouched.
+func setPriorityClassName(policy *unstructured.Unstructured, priorityClassName string) error {
+	if priorityClassName == "" || !isWorkload(policy) {
+		return nil
+	}
+
+	return unstructured.SetNestedField(policy.Object, priorityClassName,
+		podSpecField(policy, "priorityClassName")...)
+}
+
+// setNodeSelector adds nodeSelector to the pod spec of policy when policy is a workload.
+// If pod spec already has a nodeSelector with a key present in nodeSelector, the value from
+// nodeSelector will override the existing value.
+func setNodeSelector(policy *unstructured.Unstructured, nodeSelector map[string]string) error {
+	if len(nodeSelector) == 0 || !isWorkload(policy) {
+		return nil
+	}
+
+	current, _, err := unstructured.NestedStringMap(policy.Object, podSpecField(policy, "nodeSelector")...)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		current = map[string]string{}
+	}
+	for k := range nodeSelector {
+		current[k] = nodeSelector[k]
+	}
+
+	return unstructured.SetNestedStringMap(policy.Object, current, podSpecField(policy, "nodeSelector")...)
+}
+
+// setTolerations adds tolerations to the pod spec of policy when policy is a workload. Tolerations already present are not duplicated.
+func setTolerations(policy *unstructured.Unstructured, tolerations []corev1.Toleration) error {
+	if len(tolerations) == 0 || !isWorkload(policy) {
+		return nil
+	}
+
+	current, _, err := unstructured.NestedSlice(policy.Object, podSpecField(policy, "tolerations")...)
+	if err != nil {
+		return err
+	}
+
+	for i := range tolerations {
+		var toleration map[string]interface{}
+		toleration, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for j := range current {
+			if reflect.DeepEqual(current[j], toleration) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			current = append(current, toleration)
+		}
+	}
+
+	return unstructured.SetNestedSlice(policy.Object, current, podSpecField(policy, "tolerations")...)
+}
+
+// rewriteImages rewrites the image of all containers and init containers of the pod spec of
+// policy, when policy is a workload, according to registryOverrides.
+func rewriteImages(policy *unstructured.Unstructured, registryOverrides map[string]string) error {
+	if len(registryOverrides) == 0 || !isWorkload(policy) {
+		return nil
+	}
+
+	for _, field := range []string{"containers", "initContainers"} {
+		containers, found, err := unstructured.NestedSlice(policy.Object, podSpecField(policy, field)...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		for i := range containers {
+			container, ok := containers[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, ok := container["image"].(string); ok {
+				container["image"] = rewriteImage(image, registryOverrides)
+			}
+		}
+
+		err = unstructured.SetNestedSlice(policy.Object, containers, podSpecField(policy, field)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewriteImage returns image with the longest matching registryOverrides prefix replaced.
+// Image is returned unchanged if no prefix matches.
+func rewriteImage(image string, registryOverrides map[string]string) string {
+	fullName := normalizeImageName(image)
+
+	matching, matchingKey := "", ""
+	for key := range registryOverrides {
+		prefix := strings.TrimSuffix(key, "/")
+		if (fullName == prefix || strings.HasPrefix(fullName, prefix+"/")) && len(prefix) > len(matching) {
+			matching, matchingKey = prefix, key
+		}
+	}
+	if matching == "" {
+		return image
+	}
+
+	return strings.TrimSuffix(registryOverrides[matchingKey], "/") + strings.TrimPrefix(fullName, matching)
+}
+
+// normalizeImageName returns image fully qualified. Images with no registry are hosted on docker.io,
+// with official images in the library repository.
+func normalizeImageName(image string) string {
+	const defaultRegistry = "docker.io"
+
+	i := strings.Index(image, "/")
+	if i == -1 {
+		return defaultRegistry + "/library/" + image
+	}
+
+	// First component is a registry only if it contains a "." or a ":" (port), or it is localhost
+	registry := image[:i]
+	if !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		return defaultRegistry + "/" + image
+	}
+	return image
+}
+
+// validateTolerations verifies tolerations are valid:
+// - operator, if set, must be either Exists or Equal;
+// - an empty key requires operator Exists;
+// - operator Exists requires an empty value.
+func validateTolerations(tolerations []corev1.Toleration) error {
+	for i := range tolerations {
+		t := &tolerations[i]
+		switch t.Operator {
+		case "", corev1.TolerationOpEqual:
+			if t.Key == "" {
+				return fmt.Errorf("toleration %d: operator must be Exists when key is empty", i)
+			}
+		case corev1.TolerationOpExists:
+			if t.Value != "" {
+				return fmt.Errorf("toleration %d: value must be empty when operator is Exists", i)
+			}
+		default:
+			return fmt.Errorf("toleration %d: unsupported operator %q", i, t.Operator)
+		}
+	}
+
+	return nil
+}
+
+// getPodSpecPath returns the path of the pod spec within policy when policy is a workload:
+// - spec.template.spec for Deployments, StatefulSets, DaemonSets and Jobs;
+// - spec.jobTemplate.spec.template.spec for CronJobs;
+// - spec for Pods.
+// Nil is returned for any other resource.
+func getPodSpecPath(policy *unstructured.Unstructured) []string {
+	gvk := policy.GroupVersionKind()
+	switch {
+	case gvk.Group == appsv1.GroupName &&
+		(gvk.Kind == "Deployment" || gvk.Kind == "StatefulSet" || gvk.Kind == "DaemonSet"),
+		gvk.Group == batchv1.GroupName && gvk.Kind == "Job":
+		return []string{"spec", "template", "spec"}
+	case gvk.Group == batchv1.GroupName && gvk.Kind == "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case gvk.Group == corev1.GroupName && gvk.Kind == "Pod":
+		return []string{"spec"}
+	default:
+		return nil
+	}
+}
+
+// podSpecField returns the path of field within the pod spec of policy
+func podSpecField(policy *unstructured.Unstructured, field string) []string {
+	return append(getPodSpecPath(policy), field)
+}
+
+// isWorkload returns true if policy is a Deployment, a StatefulSet, a DaemonSet, a Job,
+// a CronJob or a Pod
+func isWorkload(policy *unstructured.Unstructured) bool {
+	return getPodSpecPath(policy) != nil
+}
+
+// ensurePriorityClass verifies the PriorityClass set in the ClusterProfile/Profile exists in the
+// cluster. If it does not exist and PriorityClassValue is set, PriorityClass is created. Otherwise
+// an error is returned.
+// No-op if no PriorityClassName is set, if none of the resources is a workload or if the PriorityClass
+// is one of the resources being deployed. A nil resources means the resources are not known in advance
+// (helm charts), so the PriorityClass is always verified.
+func ensurePriorityClass(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	resources []*unstructured.Unstructured, logger logr.Logger) error {
+
+	priorityClassName := clusterSummary.Spec.ClusterProfileSpec.PriorityClassName
+	if priorityClassName == "" {
+		return nil
+	}
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+		return nil
+	}
+
+	hasWorkload := false
+	for i := range resources {
+		if resources[i].GetKind() == "PriorityClass" && resources[i].GetName() == priorityClassName &&
+			resources[i].GroupVersionKind().Group == schedulingv1.GroupName {
+
+			return nil
+		}
+		if isWorkload(resources[i]) {
+			hasWorkload = true
+		}
+	}
+
+	if resources != nil && !hasWorkload {
+		return nil
+	}
+
+	priorityClass := &schedulingv1.PriorityClass{}
+	err := c.Get(ctx, types.NamespacedName{Name: priorityClassName}, priorityClass)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if clusterSummary.Spec.ClusterProfileSpec.PriorityClassValue == nil {
+		return fmt.Errorf("PriorityClass %s does not exist in the cluster", priorityClassName)
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("creating PriorityClass %s", priorityClassName))
+	priorityClass = &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: priorityClassName,
+		},
+		Value:       *clusterSummary.Spec.ClusterProfileSpec.PriorityClassValue,
+		Description: "Created by Sveltos for add-ons deployed by " + clusterSummary.Name,
+	}
+	err = c.Create(ctx, priorityClass)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
 // getResource returns sveltos Resource and the resource hash hash
 func getResource(policy *unstructured.Unstructured, referencedObject *corev1.ObjectReference, tier int32,
 	featureID configv1alpha1.FeatureID, logger logr.Logger) (resource *configv1alpha1.Resource, policyHash string) {
@@ -767,9 +1184,7 @@ func getClusterSummaryAndClusterClient(ctx context.Context, clusterNamespace, cl
 		return nil, nil, fmt.Errorf("cluster is marked for deletion")
 	}
 
-	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
-	clusterClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
-		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	_, clusterClient, err := getImpersonatedRemoteAccess(ctx, c, clusterSummary, logger)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -777,17 +1192,85 @@ func getClusterSummaryAndClusterClient(ctx context.Context, clusterNamespace, cl
 	return clusterSummary, clusterClient, nil
 }
 
+// SetDefaultReferenceNamespace sets the management cluster namespace referenced resources
+// (PolicyRefs, CopyRefs, KustomizationRefs and ValuesFrom) without a namespace are looked up in.
+// When empty (default), those are looked up in the cluster namespace.
+func SetDefaultReferenceNamespace(namespace string) {
+	defaultReferenceNamespace = namespace
+}
+
+// SetMaxObjectsPerReference sets the maximum number of objects a single referenced ConfigMap,
+// Secret or Flux Source can contain. Deploying a reference containing more objects fails
+// without applying any of them. Zero or a negative value means no limit.
+func SetMaxObjectsPerReference(limit int) {
+	maxObjectsPerReference = limit
+}
+
+// validateObjectsPerReference returns a NonRetriableError if the referenced object contains more
+// objects than allowed. Retrying would not help till the referenced object is changed.
+func validateObjectsPerReference(ref *corev1.ObjectReference, count int) error {
+	if maxObjectsPerReference <= 0 || count <= maxObjectsPerReference {
+		return nil
+	}
+
+	return &NonRetriableError{
+		Message: fmt.Sprintf("%s %s/%s contains %d objects, more than the maximum of %d allowed per reference",
+			ref.Kind, ref.Namespace, ref.Name, count, maxObjectsPerReference),
+	}
+}
+
 // getReferenceResourceNamespace returns the namespace to use for a referenced resource.
-// If namespace is set on referencedResource, that namespace will be used.
-// If namespace is not set, cluster namespace will be used
+// Precedence is:
+// - namespace set on referencedResource;
+// - controller default namespace, if configured;
+// - cluster namespace.
+// There is no per-feature default: a referenced resource either sets its namespace or not.
 func getReferenceResourceNamespace(clusterNamespace, referencedResourceNamespace string) string {
 	if referencedResourceNamespace != "" {
 		return referencedResourceNamespace
 	}
 
+	if defaultReferenceNamespace != "" {
+		return defaultReferenceNamespace
+	}
+
 	return clusterNamespace
 }
 
+// selectReferencedKeys removes from referenced ConfigMap/Secret all data keys not listed
+// in reference.Keys. If reference.Keys is empty, object is left untouched.
+func selectReferencedKeys(object client.Object, reference *configv1alpha1.PolicyRef) {
+	if object == nil || len(reference.Keys) == 0 {
+		return
+	}
+
+	switch o := object.(type) {
+	case *corev1.ConfigMap:
+		o.Data = filterDataKeys(o.Data, reference.Keys)
+		o.BinaryData = filterDataKeys(o.BinaryData, reference.Keys)
+	case *corev1.Secret:
+		o.Data = filterDataKeys(o.Data, reference.Keys)
+		o.StringData = filterDataKeys(o.StringData, reference.Keys)
+	}
+}
+
+// filterDataKeys returns a map containing only the entries of data whose key is in keys.
+// If keys is empty, data is returned unchanged.
+func filterDataKeys[T any](data map[string]T, keys []string) map[string]T {
+	if len(keys) == 0 || data == nil {
+		return data
+	}
+
+	result := make(map[string]T)
+	for i := range keys {
+		if v, ok := data[keys[i]]; ok {
+			result[keys[i]] = v
+		}
+	}
+
+	return result
+}
+
 func appendPathAnnotations(object client.Object, reference *configv1alpha1.PolicyRef) {
 	if object == nil {
 		return
@@ -827,14 +1310,19 @@ func collectReferencedObjects(ctx context.Context, controlClusterClient client.C
 		}
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				msg := fmt.Sprintf("Referenced resource: %s %s/%s does not exist",
-					reference.Kind, reference.Namespace, reference.Name)
+				msg := getMissingReferenceMessage(reference.Kind, reference.Namespace, reference.Name)
 				logger.V(logs.LogInfo).Info(msg)
 				return nil, nil, &NonRetriableError{Message: msg}
 			}
 			return nil, nil, err
 		}
 
+		if msg := getSizeLimitWarning(reference.Kind, object); msg != "" {
+			logger.V(logs.LogInfo).Info(msg)
+		}
+
+		selectReferencedKeys(object, reference)
+
 		if reference.DeploymentType == configv1alpha1.DeploymentTypeLocal {
 			local = append(local, object)
 		} else {
@@ -895,36 +1383,53 @@ func deployReferencedObjects(ctx context.Context, c client.Client, remoteConfig
 	return localReports, remoteReports, nil
 }
 
-// deployObjects deploys content of referencedObjects
+// deployObjects deploys content of referencedObjects.
+// Content of all referencedObjects is collected first, so that whether the destination cluster serves
+// all resources is verified once, before anything is deployed.
 func deployObjects(ctx context.Context, deployingToMgmtCluster bool, destClient client.Client, destConfig *rest.Config,
 	referencedObjects []client.Object, clusterSummary *configv1alpha1.ClusterSummary,
 	mgmtResources map[string]*unstructured.Unstructured, logger logr.Logger,
 ) (reports []configv1alpha1.ResourceReport, err error) {
 
+	var deployErrors []error
+	contents := make([]*referencedContent, 0, len(referencedObjects))
+	allResources := make([]*unstructured.Unstructured, 0)
 	for i := range referencedObjects {
+		var content *referencedContent
+		content, err = getPreparedContent(ctx, referencedObjects[i], clusterSummary, mgmtResources, logger)
+		if err != nil {
+			if !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				return nil, err
+			}
+			// Content of remaining referenced resources is still deployed. All errors are reported at the end.
+			deployErrors = append(deployErrors, err)
+			continue
+		}
+		if content == nil {
+			continue
+		}
+
+		contents = append(contents, content)
+		allResources = append(allResources, content.resources...)
+	}
+
+	// Fail fast, with a clear message, if the destination cluster does not serve any of the
+	// resources instead of failing midway
+	unsupported, err := getUnsupportedGroupVersionKinds(getDiscoveryCacheKey(deployingToMgmtCluster, clusterSummary),
+		destConfig, allResources)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contents {
 		var tmpResourceReports []configv1alpha1.ResourceReport
-		if referencedObjects[i].GetObjectKind().GroupVersionKind().Kind == string(libsveltosv1alpha1.ConfigMapReferencedResourceKind) {
-			configMap := referencedObjects[i].(*corev1.ConfigMap)
-			l := logger.WithValues("configMapNamespace", configMap.Namespace, "configMapName", configMap.Name)
-			l.V(logs.LogDebug).Info("deploying ConfigMap content")
-			tmpResourceReports, err =
-				deployContentOfConfigMap(ctx, deployingToMgmtCluster, destConfig, destClient, configMap,
-					clusterSummary, mgmtResources, l)
-		} else if referencedObjects[i].GetObjectKind().GroupVersionKind().Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
-			secret := referencedObjects[i].(*corev1.Secret)
-			l := logger.WithValues("secretNamespace", secret.Namespace, "secretName", secret.Name)
-			l.V(logs.LogDebug).Info("deploying Secret content")
-			tmpResourceReports, err =
-				deployContentOfSecret(ctx, deployingToMgmtCluster, destConfig, destClient, secret,
-					clusterSummary, mgmtResources, l)
-		} else {
-			source := referencedObjects[i]
-			logger.V(logs.LogDebug).Info("deploying Source content")
-			annotations := source.GetAnnotations()
-			path := annotations[pathAnnotation]
-			tmpResourceReports, err =
-				deployContentOfSource(ctx, deployingToMgmtCluster, destConfig, destClient, source, path,
-					clusterSummary, mgmtResources, logger)
+		err = getAPISupportError(contents[i].resources, unsupported, logger)
+		if err == nil {
+			l := logger.WithValues("referenceKind", contents[i].ref.Kind,
+				"referenceNamespace", contents[i].ref.Namespace, "referenceName", contents[i].ref.Name)
+			l.V(logs.LogDebug).Info("deploying content")
+			tmpResourceReports, err = applyUnstructured(ctx, deployingToMgmtCluster, destConfig, destClient,
+				contents[i].resources, contents[i].ref, configv1alpha1.FeatureResources, clusterSummary, l)
 		}
 
 		if tmpResourceReports != nil {
@@ -932,11 +1437,72 @@ func deployObjects(ctx context.Context, deployingToMgmtCluster bool, destClient
 		}
 
 		if err != nil {
-			return reports, err
+			var conflictErr *deployer.ConflictError
+			if errors.As(err, &conflictErr) || !clusterSummary.Spec.ClusterProfileSpec.ContinueOnError {
+				return reports, err
+			}
+			// Content of remaining referenced resources is still deployed. All errors are reported at the end.
+			deployErrors = append(deployErrors, err)
 		}
 	}
 
-	return reports, nil
+	return reports, utilerrors.NewAggregate(deployErrors)
+}
+
+// getPreparedContent returns the resources contained in a referenced ConfigMap, Secret or Flux Source
+// as those are deployed: sorted in apply order, without excluded objects and with patches applied.
+// Returns nil if a Flux Source has no artifact yet.
+func getPreparedContent(ctx context.Context, referencedObject client.Object,
+	clusterSummary *configv1alpha1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) (*referencedContent, error) {
+
+	content, err := getReferencedContent(ctx, referencedObject, clusterSummary, mgmtResources, logger)
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	content.resources, err = prepareUnstructured(content.resources, configv1alpha1.FeatureResources,
+		clusterSummary, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// getReferencedContent returns the resources contained in a referenced ConfigMap, Secret or Flux Source.
+// Returns nil if a Flux Source has no artifact yet.
+func getReferencedContent(ctx context.Context, referencedObject client.Object,
+	clusterSummary *configv1alpha1.ClusterSummary, mgmtResources map[string]*unstructured.Unstructured,
+	logger logr.Logger) (*referencedContent, error) {
+
+	var data map[string]string
+	switch o := referencedObject.(type) {
+	case *corev1.ConfigMap:
+		logger = logger.WithValues("configMapNamespace", o.Namespace, "configMapName", o.Name)
+		logger.V(logs.LogDebug).Info("collecting ConfigMap content")
+		data = o.Data
+	case *corev1.Secret:
+		logger = logger.WithValues("secretNamespace", o.Namespace, "secretName", o.Name)
+		logger.V(logs.LogDebug).Info("collecting Secret content")
+		data = make(map[string]string)
+		for key, value := range o.Data {
+			data[key] = string(value)
+		}
+	default:
+		logger.V(logs.LogDebug).Info("collecting Source content")
+		var err error
+		data, err = getSourceContent(ctx, referencedObject, referencedObject.GetAnnotations()[pathAnnotation],
+			clusterSummary, logger)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, nil
+		}
+	}
+
+	return collectReferencedContent(ctx, referencedObject, data, clusterSummary, mgmtResources, logger)
 }
 
 func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
@@ -946,13 +1512,10 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 
 	logger.V(logs.LogDebug).Info("removing stale resources")
 
-	profile, _, err := configv1alpha1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
+	profile, err := getStaleResourcesOwner(ctx, clusterSummary)
 	if err != nil {
 		return nil, err
 	}
-	if profile.GetObjectKind().GroupVersionKind().Kind == configv1alpha1.ProfileKind {
-		profile.SetName(profileNameToOwnerReferenceName(profile))
-	}
 
 	undeployed := make([]configv1alpha1.ResourceReport, 0)
 
@@ -1001,7 +1564,7 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 
 		for j := range list.Items {
 			r := list.Items[j]
-			rr, err := undeployStaleResource(ctx, isMgmtCluster, remoteClient, profile, clusterSummary,
+			rr, err := undeployStaleResource(ctx, isMgmtCluster, remoteClient, featureID, profile, clusterSummary,
 				r, currentPolicies, logger)
 			if err != nil {
 				return nil, err
@@ -1016,8 +1579,97 @@ func undeployStaleResources(ctx context.Context, isMgmtCluster bool,
 	return undeployed, nil
 }
 
+// undeployRemovedResources removes from the managed cluster the resources previously deployed because
+// of featureID (previousPolicies, as tracked in the ClusterConfiguration status) which are not deployed
+// anymore (not in currentPolicies). Only the difference is considered: resources still desired are never
+// looked at, and no resource is listed from the managed cluster.
+func undeployRemovedResources(ctx context.Context, remoteConfig *rest.Config, remoteClient client.Client,
+	featureID configv1alpha1.FeatureID, clusterSummary *configv1alpha1.ClusterSummary,
+	previousPolicies, currentPolicies map[string]configv1alpha1.Resource, logger logr.Logger,
+) ([]configv1alpha1.ResourceReport, error) {
+
+	logger.V(logs.LogDebug).Info("removing resources not deployed anymore")
+
+	profile, err := getStaleResourcesOwner(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := discovery.NewDiscoveryClientForConfigOrDie(remoteConfig)
+	groupResources, err := restmapper.GetAPIGroupResources(dc)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	d := dynamic.NewForConfigOrDie(remoteConfig)
+
+	undeployed := make([]configv1alpha1.ResourceReport, 0)
+	for key := range previousPolicies {
+		if _, ok := currentPolicies[key]; ok {
+			continue
+		}
+
+		resource := previousPolicies[key]
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// if CRDs does not exist anymore, ignore error. No instances of
+			// such CRD can be left anyway.
+			if errors.Is(err, &meta.NoKindMatchError{}) {
+				continue
+			}
+			return nil, err
+		}
+
+		var dr dynamic.ResourceInterface = d.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			dr = d.Resource(mapping.Resource).Namespace(resource.Namespace)
+		}
+
+		u, err := dr.Get(ctx, resource.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if !hasLabel(u, reasonLabel, string(featureID)) {
+			continue
+		}
+
+		rr, err := undeployStaleResource(ctx, false, remoteClient, featureID, profile, clusterSummary,
+			*u, currentPolicies, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		if rr != nil {
+			undeployed = append(undeployed, *rr)
+		}
+	}
+
+	return undeployed, nil
+}
+
+// getStaleResourcesOwner returns the profile owning clusterSummary, named as it appears in
+// the OwnerReferences of the resources deployed in the managed cluster
+func getStaleResourcesOwner(ctx context.Context, clusterSummary *configv1alpha1.ClusterSummary,
+) (client.Object, error) {
+
+	profile, _, err := configv1alpha1.GetProfileOwnerAndTier(ctx, getManagementClusterClient(), clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+	if profile.GetObjectKind().GroupVersionKind().Kind == configv1alpha1.ProfileKind {
+		profile.SetName(profileNameToOwnerReferenceName(profile))
+	}
+	return profile, nil
+}
+
 func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient client.Client,
-	profile client.Object, clusterSummary *configv1alpha1.ClusterSummary, r unstructured.Unstructured,
+	featureID configv1alpha1.FeatureID, profile client.Object, clusterSummary *configv1alpha1.ClusterSummary, r unstructured.Unstructured,
 	currentPolicies map[string]configv1alpha1.Resource, logger logr.Logger) (*configv1alpha1.ResourceReport, error) {
 
 	logger.V(logs.LogVerbose).Info(fmt.Sprintf("considering %s/%s", r.GetNamespace(), r.GetName()))
@@ -1058,14 +1710,19 @@ func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient
 	} else if canDelete(&r, currentPolicies) {
 		logger.V(logs.LogVerbose).Info(fmt.Sprintf("remove owner reference %s/%s", r.GetNamespace(), r.GetName()))
 
+		isOwner := deployer.IsOwnerReference(&r, profile)
 		deployer.RemoveOwnerReference(&r, profile)
 
 		if len(r.GetOwnerReferences()) != 0 {
 			// Other ClusterSummary are still deploying this very same policy
+			// (for instance a shared CustomResourceDefinition). Only drop this profile as owner.
+			if isOwner {
+				return nil, remoteClient.Update(ctx, &r)
+			}
 			return nil, nil
 		}
 
-		err := handleResourceDelete(ctx, remoteClient, &r, clusterSummary, logger)
+		err := handleResourceDelete(ctx, remoteClient, featureID, &r, clusterSummary, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -1074,8 +1731,30 @@ func undeployStaleResource(ctx context.Context, isMgmtCluster bool, remoteClient
 	return resourceReport, nil
 }
 
-func handleResourceDelete(ctx context.Context, remoteClient client.Client, policy client.Object,
-	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) error {
+func isCustomResourceDefinition(policy client.Object) bool {
+	gvk := policy.GetObjectKind().GroupVersionKind()
+	return gvk.Group == apiextensionsv1.GroupName && gvk.Kind == "CustomResourceDefinition"
+}
+
+// keepCurrentOwnerReferences sets on policy the OwnerReferences of the object currently deployed
+// in the managed cluster. Otherwise applying policy would drop them.
+func keepCurrentOwnerReferences(ctx context.Context, dr dynamic.ResourceInterface,
+	policy *unstructured.Unstructured) error {
+
+	currentObject, err := dr.Get(ctx, policy.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	policy.SetOwnerReferences(currentObject.GetOwnerReferences())
+	return nil
+}
+
+func handleResourceDelete(ctx context.Context, remoteClient client.Client, featureID configv1alpha1.FeatureID,
+	policy client.Object, clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) error {
 
 	// If mode is set to LeavePolicies, leave policies in the workload cluster.
 	// Remove all labels added by Sveltos.
@@ -1088,9 +1767,37 @@ func handleResourceDelete(ctx context.Context, remoteClient client.Client, polic
 		return remoteClient.Update(ctx, policy)
 	}
 
-	logger.V(logs.LogDebug).Info(fmt.Sprintf("removing resource %s %s/%s",
-		policy.GetObjectKind().GroupVersionKind().Kind, policy.GetNamespace(), policy.GetName()))
-	return remoteClient.Delete(ctx, policy)
+	propagationPolicy := getDeletePropagationPolicy(clusterSummary, featureID, policy)
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("removing resource %s %s/%s (propagation policy %s)",
+		policy.GetObjectKind().GroupVersionKind().Kind, policy.GetNamespace(), policy.GetName(),
+		propagationPolicy))
+	if err := remoteClient.Delete(ctx, policy, client.PropagationPolicy(propagationPolicy)); err != nil {
+		return err
+	}
+
+	recordAudit(clusterSummary, AuditActionDelete, policy)
+	return nil
+}
+
+// getDeletePropagationPolicy returns the propagation policy to use when deleting policy, a resource
+// deployed because of featureID. The DeletionPolicies set in the ClusterSummary take precedence.
+// Otherwise CustomResourceDefinitions are deleted in Foreground, so that all of their instances are
+// removed first, and any other resource in Background.
+func getDeletePropagationPolicy(clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID, policy client.Object) metav1.DeletionPropagation {
+
+	deletionPolicies := clusterSummary.Spec.ClusterProfileSpec.DeletionPolicies
+	for i := range deletionPolicies {
+		if deletionPolicies[i].FeatureID == featureID {
+			return deletionPolicies[i].PropagationPolicy
+		}
+	}
+
+	if isCustomResourceDefinition(policy) {
+		return metav1.DeletePropagationForeground
+	}
+
+	return metav1.DeletePropagationBackground
 }
 
 // canDelete returns true if a policy can be deleted. For a policy to be deleted:
@@ -1184,6 +1891,77 @@ func getDeployedGroupVersionKinds(clusterSummary *configv1alpha1.ClusterSummary,
 	return gvks
 }
 
+// getClusterConfigurationDeployedResources returns the resources the ClusterConfiguration status reports
+// as deployed in the managed cluster because of featureID by the profile owning clusterSummary.
+// Returns nil if nothing has been reported yet.
+func getClusterConfigurationDeployedResources(ctx context.Context, c client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary, profileOwnerRef *metav1.OwnerReference,
+	featureID configv1alpha1.FeatureID) (map[string]configv1alpha1.Resource, error) {
+
+	clusterConfiguration := &configv1alpha1.ClusterConfiguration{}
+	err := c.Get(ctx,
+		types.NamespacedName{
+			Namespace: clusterSummary.Spec.ClusterNamespace,
+			Name:      getClusterConfigurationName(clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType),
+		},
+		clusterConfiguration)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	index, err := configv1alpha1.GetClusterConfigurationSectionIndex(clusterConfiguration, profileOwnerRef.Kind,
+		profileOwnerRef.Name)
+	if err != nil {
+		// Section not created yet, so no resource has been reported as deployed
+		return nil, nil
+	}
+
+	var features []configv1alpha1.Feature
+	if profileOwnerRef.Kind == configv1alpha1.ClusterProfileKind {
+		features = clusterConfiguration.Status.ClusterProfileResources[index].Features
+	} else {
+		features = clusterConfiguration.Status.ProfileResources[index].Features
+	}
+
+	for i := range features {
+		if features[i].FeatureID == featureID {
+			deployed := make(map[string]configv1alpha1.Resource, len(features[i].Resources))
+			for j := range features[i].Resources {
+				if isPostDeployJobResource(&features[i].Resources[j]) {
+					// PostDeployJobs are managed separately
+					continue
+				}
+				deployed[getPolicyInfo(&features[i].Resources[j])] = features[i].Resources[j]
+			}
+			return deployed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// addPreviouslyDeployedResources appends to deployed all previously deployed resources not in
+// deployed already
+func addPreviouslyDeployedResources(deployed []configv1alpha1.Resource,
+	previouslyDeployed map[string]configv1alpha1.Resource) []configv1alpha1.Resource {
+
+	current := make(map[string]bool, len(deployed))
+	for i := range deployed {
+		current[getPolicyInfo(&deployed[i])] = true
+	}
+
+	for key := range previouslyDeployed {
+		if !current[key] {
+			deployed = append(deployed, previouslyDeployed[key])
+		}
+	}
+
+	return deployed
+}
+
 // No action in DryRun mode.
 func updateClusterConfiguration(ctx context.Context, c client.Client,
 	clusterSummary *configv1alpha1.ClusterSummary,
@@ -1228,8 +2006,12 @@ func updateClusterConfiguration(ctx context.Context, c client.Client,
 				index, featureID, policyDeployed, chartDeployed)
 		}
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	updateWorkloadInventory(ctx, c, clusterSummary, profileOwnerRef, featureID, policyDeployed, chartDeployed)
+	return nil
 }
 
 func updateClusterProfileResources(ctx context.Context, c client.Client, profileOwnerRef *metav1.OwnerReference,
@@ -1464,13 +2246,89 @@ func getRestConfig(ctx context.Context, c client.Client, clusterSummary *configv
 		WithValues("clusterSummary", clusterSummary.Name).WithValues("admin", fmt.Sprintf("%s/%s", adminNamespace, adminName))
 
 	logger.V(logs.LogDebug).Info("get remote restConfig")
-	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
+	remoteRestConfig, err := getKubernetesRestConfig(ctx, c, clusterNamespace, clusterName,
 		adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
 	if err != nil {
 		return nil, logger, err
 	}
 
-	return remoteRestConfig, logger, nil
+	return applyImpersonation(remoteRestConfig, clusterSummary.Spec.ClusterProfileSpec.Impersonation, logger), logger, nil
+}
+
+// applyImpersonation returns a copy of restConfig configured to impersonate the identity
+// specified in the ClusterProfile/Profile. If no impersonation is requested, restConfig is
+// returned unchanged.
+func applyImpersonation(restConfig *rest.Config, impersonation *configv1alpha1.Impersonation,
+	logger logr.Logger) *rest.Config {
+
+	if impersonation == nil || impersonation.UserName == "" {
+		return restConfig
+	}
+
+	logger.V(logs.LogDebug).Info(fmt.Sprintf("impersonating user %s", impersonation.UserName))
+	impersonatedConfig := rest.CopyConfig(restConfig)
+	impersonatedConfig.Impersonate = rest.ImpersonationConfig{
+		UserName: impersonation.UserName,
+		Groups:   impersonation.Groups,
+	}
+	return impersonatedConfig
+}
+
+// getImpersonatedClient returns a client built from restConfig when impersonation is requested.
+// restConfig is expected to be already configured for impersonation (see applyImpersonation).
+// If no impersonation is requested, remoteClient is returned unchanged.
+func getImpersonatedClient(remoteClient client.Client, restConfig *rest.Config,
+	impersonation *configv1alpha1.Impersonation) (client.Client, error) {
+
+	if impersonation == nil || impersonation.UserName == "" {
+		return remoteClient, nil
+	}
+
+	return client.New(restConfig, client.Options{Scheme: remoteClient.Scheme()})
+}
+
+// getImpersonatedRemoteAccess returns restConfig and client to access the managed cluster. When
+// impersonation is requested in the ClusterProfile/Profile, both impersonate the requested identity.
+func getImpersonatedRemoteAccess(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	logger logr.Logger) (*rest.Config, client.Client, error) {
+
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	remoteRestConfig, err := getKubernetesRestConfig(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteClient, err := getKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	impersonation := clusterSummary.Spec.ClusterProfileSpec.Impersonation
+	remoteRestConfig = applyImpersonation(remoteRestConfig, impersonation, logger)
+	remoteClient, err = getImpersonatedClient(remoteClient, remoteRestConfig, impersonation)
+	if err != nil {
+		return nil, nil, err
+	}
+	return remoteRestConfig, remoteClient, nil
+}
+
+// applyKubeconfigImpersonation returns kubeconfig configured to impersonate the identity specified
+// in the ClusterProfile/Profile. If no impersonation is requested, kubeconfig is returned unchanged.
+func applyKubeconfigImpersonation(kubeconfig []byte, impersonation *configv1alpha1.Impersonation) ([]byte, error) {
+	if impersonation == nil || impersonation.UserName == "" {
+		return kubeconfig, nil
+	}
+
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	for i := range config.AuthInfos {
+		config.AuthInfos[i].Impersonate = impersonation.UserName
+		config.AuthInfos[i].ImpersonateGroups = impersonation.Groups
+	}
+	return clientcmd.Write(*config)
 }
 
 func getValuesFromResourceHash(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
@@ -1489,6 +2347,7 @@ func getValuesFromResourceHash(ctx context.Context, c client.Client, clusterSumm
 			if configMap == nil {
 				continue
 			}
+			config += getReferencedObjectIdentity(configMap)
 			config += getDataSectionHash(configMap.Data)
 			config += getDataSectionHash(configMap.BinaryData)
 		} else if valuesFrom[i].Kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
@@ -1501,6 +2360,7 @@ func getValuesFromResourceHash(ctx context.Context, c client.Client, clusterSumm
 			if secret == nil {
 				continue
 			}
+			config += getReferencedObjectIdentity(secret)
 			config += getDataSectionHash(secret.Data)
 			config += getDataSectionHash(secret.StringData)
 		}
@@ -18,6 +18,7 @@ package controllers_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,17 +28,24 @@ import (
 	. "github.com/onsi/gomega"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
@@ -99,6 +107,49 @@ spec:
         ports:
         - containerPort: 80`
 
+	jobTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: pi
+  namespace: %s
+spec:
+  template:
+    spec:
+      containers:
+      - name: pi
+        image: perl:5.34.0
+        command: ["perl",  "-Mbignum=bpi", "-wle", "print bpi(2000)"]
+      restartPolicy: Never`
+
+	cronJobTemplate = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: hello
+  namespace: %s
+spec:
+  schedule: "* * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: hello
+            image: busybox:1.28
+            command: ["date"]
+          restartPolicy: OnFailure`
+
+	podTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+  namespace: %s
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.14.2
+    ports:
+    - containerPort: 80`
+
 	multusData = `apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
 metadata:
@@ -719,6 +770,42 @@ var _ = Describe("HandlersUtils", func() {
 		}
 	})
 
+	It("deployContent with ContinueOnError deploys remaining policies when one fails", func() {
+		invalidConfigMap := fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: Invalid_Name
+  namespace: %s`, namespace)
+		validConfigMap := fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: %s`, randomString(), namespace)
+		content := invalidConfigMap + "\n---\n" + validConfigMap
+
+		secret := createSecretWithPolicy(namespace, randomString(), content)
+		Expect(testEnv.Client.Create(context.TODO(), secret)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, secret)).To(Succeed())
+		Expect(addTypeInformationToObject(testEnv.Scheme(), secret)).To(Succeed())
+		Expect(addTypeInformationToObject(testEnv.Scheme(), clusterSummary)).To(Succeed())
+
+		// By default deployment stops at first failure
+		resourceReports, err := controllers.DeployContent(context.TODO(), false,
+			testEnv.Config, testEnv.Client, secret, map[string]string{"policy": content}, clusterSummary, nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(resourceReports).To(BeEmpty())
+
+		clusterSummary.Spec.ClusterProfileSpec.ContinueOnError = true
+		resourceReports, err = controllers.DeployContent(context.TODO(), false,
+			testEnv.Config, testEnv.Client, secret, map[string]string{"policy": content}, clusterSummary, nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("ConfigMap %s/Invalid_Name", namespace)))
+		Expect(resourceReports).To(HaveLen(1))
+		Expect(resourceReports[0].Resource.Name).ToNot(Equal("Invalid_Name"))
+	})
+
 	It("getReferenceResourceNamespace returns the referenced resource namespace when set. cluster namespace otherwise.", func() {
 		referecedResource := libsveltosv1alpha1.PolicyRef{
 			Namespace: "",
@@ -735,7 +822,38 @@ var _ = Describe("HandlersUtils", func() {
 			Equal(referecedResource.Namespace))
 	})
 
-	It("deployContentOfSecret deploys all policies contained in a ConfigMap", func() {
+	It("getReferenceResourceNamespace uses controller default namespace, when set, for references without namespace", func() {
+		defaultNamespace := randomString()
+		controllers.SetDefaultReferenceNamespace(defaultNamespace)
+		defer controllers.SetDefaultReferenceNamespace("")
+
+		clusterNamespace := randomString()
+		Expect(controllers.GetReferenceResourceNamespace(clusterNamespace, "")).To(Equal(defaultNamespace))
+
+		referencedNamespace := randomString()
+		Expect(controllers.GetReferenceResourceNamespace(clusterNamespace, referencedNamespace)).To(
+			Equal(referencedNamespace))
+	})
+
+	It("validateObjectsPerReference fails when a reference contains too many objects", func() {
+		ref := &corev1.ObjectReference{Kind: "ConfigMap", Namespace: randomString(), Name: randomString()}
+
+		// No limit by default
+		Expect(controllers.ValidateObjectsPerReference(ref, 100000)).To(Succeed())
+
+		controllers.SetMaxObjectsPerReference(10)
+		defer controllers.SetMaxObjectsPerReference(0)
+
+		Expect(controllers.ValidateObjectsPerReference(ref, 10)).To(Succeed())
+
+		err := controllers.ValidateObjectsPerReference(ref, 11)
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(ref.Name))
+	})
+
+	It("deployObjects deploys all policies contained in a Secret", func() {
 		services := fmt.Sprintf(serviceTemplate, namespace, namespace)
 		depl := fmt.Sprintf(deplTemplate, namespace)
 
@@ -747,14 +865,14 @@ var _ = Describe("HandlersUtils", func() {
 
 		Expect(addTypeInformationToObject(testEnv.Scheme(), clusterSummary)).To(Succeed())
 
-		resourceReports, err := controllers.DeployContentOfSecret(context.TODO(), false,
-			testEnv.Config, testEnv.Client, secret, clusterSummary, nil,
+		resourceReports, err := controllers.DeployObjects(context.TODO(), false, testEnv.Client,
+			testEnv.Config, []client.Object{secret}, clusterSummary, nil,
 			textlogger.NewLogger(textlogger.NewConfig()))
 		Expect(err).To(BeNil())
 		Expect(len(resourceReports)).To(Equal(3))
 	})
 
-	It("deployContentOfConfigMap deploys all policies contained in a Secret", func() {
+	It("deployObjects deploys all policies contained in a ConfigMap", func() {
 		services := fmt.Sprintf(serviceTemplate, namespace, namespace)
 		depl := fmt.Sprintf(deplTemplate, namespace)
 
@@ -766,8 +884,8 @@ var _ = Describe("HandlersUtils", func() {
 
 		Expect(addTypeInformationToObject(testEnv.Scheme(), clusterSummary)).To(Succeed())
 
-		resourceReports, err := controllers.DeployContentOfConfigMap(context.TODO(), false,
-			testEnv.Config, testEnv.Client, configMap, clusterSummary, nil,
+		resourceReports, err := controllers.DeployObjects(context.TODO(), false, testEnv.Client,
+			testEnv.Config, []client.Object{configMap}, clusterSummary, nil,
 			textlogger.NewLogger(textlogger.NewConfig()))
 		Expect(err).To(BeNil())
 		Expect(len(resourceReports)).To(Equal(3))
@@ -1008,6 +1126,151 @@ var _ = Describe("HandlersUtils", func() {
 		}, timeout, pollingInterval).Should(BeTrue())
 	})
 
+	It("canDeployResource allows sharing a CustomResourceDefinition deployed by a different ClusterProfile", func() {
+		group := randomString() + ".projectsveltos.io"
+		crd, err := utils.GetUnstructured([]byte(fmt.Sprintf(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.%s
+spec:
+  group: %s
+  names:
+    kind: Widget
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object`, group, group)))
+		Expect(err).To(BeNil())
+
+		otherClusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), otherClusterProfile)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, otherClusterProfile)).To(Succeed())
+		Expect(addTypeInformationToObject(testEnv.Scheme(), otherClusterProfile)).To(Succeed())
+
+		// CustomResourceDefinition currently deployed because of a different ConfigMap and ClusterProfile
+		deployedCRD := crd.DeepCopy()
+		deployedCRD.SetLabels(map[string]string{
+			deployer.ReferenceKindLabel:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			deployer.ReferenceNameLabel:      randomString(),
+			deployer.ReferenceNamespaceLabel: randomString(),
+		})
+		deployedCRD.SetOwnerReferences([]metav1.OwnerReference{
+			{Kind: configv1alpha1.ClusterProfileKind, Name: otherClusterProfile.Name,
+				UID: otherClusterProfile.UID, APIVersion: configv1alpha1.GroupVersion.String()},
+		})
+		Expect(testEnv.Create(context.TODO(), deployedCRD)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, deployedCRD)).To(Succeed())
+
+		currentClusterProfile := &configv1alpha1.ClusterProfile{}
+		Expect(testEnv.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name},
+			currentClusterProfile)).To(Succeed())
+		Expect(addTypeInformationToObject(testEnv.Scheme(), currentClusterProfile)).To(Succeed())
+
+		dr, err := utils.GetDynamicResourceInterface(testEnv.Config, crd.GroupVersionKind(), "")
+		Expect(err).To(BeNil())
+
+		referencedObject := &corev1.ObjectReference{
+			Kind:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+			Namespace: randomString(),
+			Name:      randomString(),
+		}
+		resourceInfo, requeue, err := controllers.CanDeployResource(context.TODO(), dr, crd, referencedObject,
+			currentClusterProfile, 100, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(requeue).To(BeFalse())
+		Expect(resourceInfo.ResourceVersion).ToNot(BeEmpty())
+
+		// Current owners are kept so CustomResourceDefinition is not removed while still needed
+		Expect(controllers.KeepCurrentOwnerReferences(context.TODO(), dr, crd)).To(Succeed())
+		deployer.AddOwnerReference(crd, currentClusterProfile)
+		Expect(crd.GetOwnerReferences()).To(HaveLen(2))
+		Expect(deployer.IsOwnerReference(crd, otherClusterProfile)).To(BeTrue())
+		Expect(deployer.IsOwnerReference(crd, currentClusterProfile)).To(BeTrue())
+	})
+
+	It("undeployStaleResources only removes ClusterProfile as owner when resource is still needed by others", func() {
+		otherClusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), otherClusterProfile)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, otherClusterProfile)).To(Succeed())
+
+		currentClusterProfile := &configv1alpha1.ClusterProfile{}
+		Expect(testEnv.Get(context.TODO(), types.NamespacedName{Name: clusterProfile.Name},
+			currentClusterProfile)).To(Succeed())
+
+		currentClusterSummary := &configv1alpha1.ClusterSummary{}
+		Expect(testEnv.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		currentClusterSummary.Status.DeployedGVKs = []configv1alpha1.FeatureDeploymentInfo{
+			{
+				FeatureID: configv1alpha1.FeatureResources,
+				DeployedGroupVersionKind: []string{
+					"ClusterRole.v1.rbac.authorization.k8s.io",
+				},
+			},
+		}
+
+		clusterRoleName := randomString()
+		clusterRole := &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterRoleName,
+				Labels: map[string]string{
+					deployer.ReferenceKindLabel:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+					deployer.ReferenceNamespaceLabel: randomString(),
+					deployer.ReferenceNameLabel:      randomString(),
+					controllers.ReasonLabel:          string(configv1alpha1.FeatureResources),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1alpha1.GroupVersion.String(),
+						Kind:       configv1alpha1.ClusterProfileKind,
+						Name:       currentClusterProfile.Name,
+						UID:        currentClusterProfile.UID,
+					},
+					{
+						APIVersion: configv1alpha1.GroupVersion.String(),
+						Kind:       configv1alpha1.ClusterProfileKind,
+						Name:       otherClusterProfile.Name,
+						UID:        otherClusterProfile.UID,
+					},
+				},
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), clusterRole)).To(Succeed())
+		Expect(waitForObject(ctx, testEnv.Client, clusterRole)).To(Succeed())
+
+		deployedGKVs := controllers.GetDeployedGroupVersionKinds(currentClusterSummary, configv1alpha1.FeatureResources)
+		_, err := controllers.UndeployStaleResources(context.TODO(), false, testEnv.Config, testEnv.Client,
+			configv1alpha1.FeatureResources, currentClusterSummary, deployedGKVs, map[string]configv1alpha1.Resource{},
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		// ClusterRole is still needed by otherClusterProfile
+		Eventually(func() bool {
+			currentClusterRole := &rbacv1.ClusterRole{}
+			err = testEnv.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName}, currentClusterRole)
+			if err != nil {
+				return false
+			}
+			return len(currentClusterRole.OwnerReferences) == 1 &&
+				currentClusterRole.OwnerReferences[0].Name == otherClusterProfile.Name
+		}, timeout, pollingInterval).Should(BeTrue())
+	})
+
 	It("customSplit returns all sections separated by ---", func() {
 		sections, err := controllers.CustomSplit(multusData)
 		Expect(err).To(BeNil())
@@ -1076,6 +1339,26 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(controllers.CanDelete(depl, map[string]configv1alpha1.Resource{name: {}})).To(BeFalse())
 	})
 
+	It("addPreviouslyDeployedResources keeps tracking previously deployed resources", func() {
+		current := configv1alpha1.Resource{
+			Kind: "ConfigMap", Name: randomString(), Namespace: randomString(),
+		}
+		previous := configv1alpha1.Resource{
+			Kind: "Secret", Name: randomString(), Namespace: randomString(),
+		}
+
+		previouslyDeployed := map[string]configv1alpha1.Resource{
+			controllers.GetPolicyInfo(&current):  current,
+			controllers.GetPolicyInfo(&previous): previous,
+		}
+
+		deployed := controllers.AddPreviouslyDeployedResources([]configv1alpha1.Resource{current},
+			previouslyDeployed)
+		Expect(len(deployed)).To(Equal(2))
+		Expect(deployed).To(ContainElement(current))
+		Expect(deployed).To(ContainElement(previous))
+	})
+
 	It("addExtraLabels adds extra labels on unstructured", func() {
 		u := &unstructured.Unstructured{}
 		extraLabels := map[string]string{
@@ -1132,6 +1415,268 @@ var _ = Describe("HandlersUtils", func() {
 		}
 	})
 
+	It("setPriorityClassName sets priorityClassName on workloads only", func() {
+		priorityClassName := randomString()
+
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(deplTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetPriorityClassName(depl, priorityClassName)).To(Succeed())
+		value, found, err := unstructured.NestedString(depl.Object, "spec", "template", "spec", "priorityClassName")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(priorityClassName))
+
+		clusterRole, err := utils.GetUnstructured([]byte(fmt.Sprintf(viewClusterRole, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetPriorityClassName(clusterRole, priorityClassName)).To(Succeed())
+		_, found, err = unstructured.NestedString(clusterRole.Object, "spec", "template", "spec", "priorityClassName")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeFalse())
+	})
+
+	It("setWorkloadScheduling adds nodeSelector and tolerations to workloads", func() {
+		spec := &configv1alpha1.Spec{
+			NodeSelector: map[string]string{randomString(): randomString()},
+			Tolerations: []corev1.Toleration{
+				{Key: randomString(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			},
+		}
+
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(deplTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetWorkloadScheduling(depl, spec)).To(Succeed())
+		// Applying twice does not duplicate tolerations
+		Expect(controllers.SetWorkloadScheduling(depl, spec)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(depl.UnstructuredContent(), deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.NodeSelector).To(Equal(spec.NodeSelector))
+		Expect(deployment.Spec.Template.Spec.Tolerations).To(Equal(spec.Tolerations))
+	})
+
+	It("rewriteImage rewrites images according to the longest matching registry override", func() {
+		registryOverrides := map[string]string{
+			"docker.io":          "mirror.example.com/dockerhub",
+			"quay.io/":           "mirror.example.com/quay/",
+			"quay.io/jetstack":   "mirror.example.com/cert-manager",
+			"registry.k8s.io:80": "mirror.example.com/k8s",
+		}
+
+		Expect(controllers.RewriteImage("nginx:1.25", registryOverrides)).To(
+			Equal("mirror.example.com/dockerhub/library/nginx:1.25"))
+		Expect(controllers.RewriteImage("bitnami/redis:7", registryOverrides)).To(
+			Equal("mirror.example.com/dockerhub/bitnami/redis:7"))
+		Expect(controllers.RewriteImage("quay.io/prometheus/prometheus:v2.52.0", registryOverrides)).To(
+			Equal("mirror.example.com/quay/prometheus/prometheus:v2.52.0"))
+		Expect(controllers.RewriteImage("quay.io/jetstack/cert-manager-controller:v1.14.5", registryOverrides)).To(
+			Equal("mirror.example.com/cert-manager/cert-manager-controller:v1.14.5"))
+		Expect(controllers.RewriteImage("registry.k8s.io:80/pause:3.9", registryOverrides)).To(
+			Equal("mirror.example.com/k8s/pause:3.9"))
+		// Prefixes only match full path components
+		Expect(controllers.RewriteImage("quay.io/jetstackio/app:v1", registryOverrides)).To(
+			Equal("mirror.example.com/quay/jetstackio/app:v1"))
+		Expect(controllers.RewriteImage("ghcr.io/kyverno/kyverno:v1.12.1", registryOverrides)).To(
+			Equal("ghcr.io/kyverno/kyverno:v1.12.1"))
+	})
+
+	It("setWorkloadScheduling rewrites images of workloads", func() {
+		spec := &configv1alpha1.Spec{
+			RegistryOverrides: map[string]string{"docker.io": "mirror.example.com"},
+		}
+
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(deplTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetWorkloadScheduling(depl, spec)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(depl.UnstructuredContent(), deployment)).To(Succeed())
+		for i := range deployment.Spec.Template.Spec.Containers {
+			Expect(deployment.Spec.Template.Spec.Containers[i].Image).To(HavePrefix("mirror.example.com/"))
+		}
+	})
+
+	It("setWorkloadScheduling sets the pod spec of Jobs", func() {
+		spec := getWorkloadSchedulingSpec()
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(jobTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetWorkloadScheduling(u, spec)).To(Succeed())
+
+		job := &batchv1.Job{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), job)).To(Succeed())
+		verifyWorkloadScheduling(&job.Spec.Template.Spec, spec)
+	})
+
+	It("setWorkloadScheduling sets the pod spec of CronJobs", func() {
+		spec := getWorkloadSchedulingSpec()
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(cronJobTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetWorkloadScheduling(u, spec)).To(Succeed())
+
+		cronJob := &batchv1.CronJob{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), cronJob)).To(Succeed())
+		verifyWorkloadScheduling(&cronJob.Spec.JobTemplate.Spec.Template.Spec, spec)
+		Expect(u.Object["spec"]).ToNot(HaveKey("template"))
+	})
+
+	It("setWorkloadScheduling sets the pod spec of Pods", func() {
+		spec := getWorkloadSchedulingSpec()
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(podTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetWorkloadScheduling(u, spec)).To(Succeed())
+
+		pod := &corev1.Pod{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), pod)).To(Succeed())
+		verifyWorkloadScheduling(&pod.Spec, spec)
+		Expect(u.Object["spec"]).ToNot(HaveKey("template"))
+	})
+
+	It("validateTolerations returns an error for invalid tolerations", func() {
+		Expect(controllers.ValidateTolerations(nil)).To(Succeed())
+		Expect(controllers.ValidateTolerations([]corev1.Toleration{
+			{Key: randomString(), Value: randomString()},
+			{Operator: corev1.TolerationOpExists},
+		})).To(Succeed())
+
+		Expect(controllers.ValidateTolerations([]corev1.Toleration{
+			{Value: randomString()},
+		})).ToNot(Succeed())
+		Expect(controllers.ValidateTolerations([]corev1.Toleration{
+			{Key: randomString(), Operator: corev1.TolerationOpExists, Value: randomString()},
+		})).ToNot(Succeed())
+		Expect(controllers.ValidateTolerations([]corev1.Toleration{
+			{Key: randomString(), Operator: corev1.TolerationOperator(randomString())},
+		})).ToNot(Succeed())
+	})
+
+	It("ensurePriorityClass creates PriorityClass only when value is set", func() {
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(deplTemplate, randomString())))
+		Expect(err).To(BeNil())
+
+		clusterSummary.Spec.ClusterProfileSpec.PriorityClassName = randomString()
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		// PriorityClass does not exist and no value is set
+		Expect(controllers.EnsurePriorityClass(context.TODO(), c, clusterSummary,
+			[]*unstructured.Unstructured{depl}, textlogger.NewLogger(textlogger.NewConfig()))).ToNot(Succeed())
+
+		// No workload is deployed
+		Expect(controllers.EnsurePriorityClass(context.TODO(), c, clusterSummary,
+			[]*unstructured.Unstructured{}, textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		value := int32(1000000)
+		clusterSummary.Spec.ClusterProfileSpec.PriorityClassValue = &value
+		Expect(controllers.EnsurePriorityClass(context.TODO(), c, clusterSummary,
+			[]*unstructured.Unstructured{depl}, textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		priorityClass := &schedulingv1.PriorityClass{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Name: clusterSummary.Spec.ClusterProfileSpec.PriorityClassName}, priorityClass)).To(Succeed())
+		Expect(priorityClass.Value).To(Equal(value))
+	})
+
+	It("applyImpersonation sets impersonation on a copy of the restConfig", func() {
+		restConfig := &rest.Config{Host: randomString()}
+
+		Expect(controllers.ApplyImpersonation(restConfig, nil, textlogger.NewLogger(textlogger.NewConfig()))).
+			To(Equal(restConfig))
+
+		impersonation := &configv1alpha1.Impersonation{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", randomString(), randomString()),
+			Groups:   []string{randomString(), randomString()},
+		}
+		impersonatedConfig := controllers.ApplyImpersonation(restConfig, impersonation,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(impersonatedConfig.Host).To(Equal(restConfig.Host))
+		Expect(impersonatedConfig.Impersonate.UserName).To(Equal(impersonation.UserName))
+		Expect(impersonatedConfig.Impersonate.Groups).To(Equal(impersonation.Groups))
+
+		// Original restConfig is not modified
+		Expect(restConfig.Impersonate.UserName).To(BeEmpty())
+	})
+
+	It("getImpersonatedClient builds client with the scheme of the remote client", func() {
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		restConfig := &rest.Config{Host: "https://" + randomString()}
+
+		c, err := controllers.GetImpersonatedClient(remoteClient, restConfig, nil)
+		Expect(err).To(BeNil())
+		Expect(c).To(Equal(remoteClient))
+
+		impersonation := &configv1alpha1.Impersonation{UserName: randomString()}
+		c, err = controllers.GetImpersonatedClient(remoteClient, restConfig, impersonation)
+		Expect(err).To(BeNil())
+		Expect(c.Scheme()).To(Equal(remoteClient.Scheme()))
+	})
+
+	It("applyKubeconfigImpersonation sets impersonation in the kubeconfig used by Helm", func() {
+		kubeconfig, err := controllers.RestConfigToKubeconfig(&rest.Config{Host: "https://" + randomString()})
+		Expect(err).To(BeNil())
+
+		result, err := controllers.ApplyKubeconfigImpersonation(kubeconfig, nil)
+		Expect(err).To(BeNil())
+		Expect(result).To(Equal(kubeconfig))
+
+		impersonation := &configv1alpha1.Impersonation{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", randomString(), randomString()),
+			Groups:   []string{randomString(), randomString()},
+		}
+		result, err = controllers.ApplyKubeconfigImpersonation(kubeconfig, impersonation)
+		Expect(err).To(BeNil())
+
+		restConfig, err := clientcmd.RESTConfigFromKubeConfig(result)
+		Expect(err).To(BeNil())
+		Expect(restConfig.Impersonate.UserName).To(Equal(impersonation.UserName))
+		Expect(restConfig.Impersonate.Groups).To(Equal(impersonation.Groups))
+	})
+
+	It("selectReferencedKeys keeps only the data keys listed in the PolicyRef", func() {
+		selectedKey := randomString()
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Data: map[string]string{
+				selectedKey:    randomString(),
+				randomString(): randomString(),
+				randomString(): randomString(),
+			},
+		}
+
+		reference := &configv1alpha1.PolicyRef{
+			Namespace: configMap.Namespace,
+			Name:      configMap.Name,
+			Kind:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+		}
+
+		// When Keys is not set, all keys are kept
+		controllers.SelectReferencedKeys(configMap, reference)
+		Expect(len(configMap.Data)).To(Equal(3))
+
+		value := configMap.Data[selectedKey]
+		reference.Keys = []string{selectedKey, randomString()}
+		controllers.SelectReferencedKeys(configMap, reference)
+		Expect(len(configMap.Data)).To(Equal(1))
+		Expect(configMap.Data[selectedKey]).To(Equal(value))
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Data: map[string][]byte{
+				selectedKey:    []byte(randomString()),
+				randomString(): []byte(randomString()),
+			},
+		}
+		controllers.SelectReferencedKeys(secret, reference)
+		Expect(len(secret.Data)).To(Equal(1))
+		Expect(secret.Data).To(HaveKey(selectedKey))
+	})
+
 	It("readFiles loads content of all files in a directory", func() {
 		dir, err := os.MkdirTemp("", "my-temp-dir")
 		Expect(err).To(BeNil())
@@ -1193,7 +1738,7 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
 			currentClusterSummary)).To(Succeed())
 
-		Expect(controllers.HandleResourceDelete(ctx, c, depl, currentClusterSummary,
+		Expect(controllers.HandleResourceDelete(ctx, c, configv1alpha1.FeatureResources, depl, currentClusterSummary,
 			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
 
 		currentDepl := &appsv1.Deployment{}
@@ -1204,6 +1749,57 @@ var _ = Describe("HandlersUtils", func() {
 		Expect(v).To(Equal(randomValue))
 	})
 
+	It("handleResourceDelete uses the configured delete propagation policy", func() {
+		depl := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, depl)).To(Succeed())
+
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, crd)).To(Succeed())
+
+		propagationPolicies := map[string]metav1.DeletionPropagation{}
+		initObjects := []client.Object{depl, crd}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					deleteOptions := &client.DeleteOptions{}
+					deleteOptions.ApplyOptions(opts)
+					Expect(deleteOptions.PropagationPolicy).ToNot(BeNil())
+					propagationPolicies[obj.GetName()] = *deleteOptions.PropagationPolicy
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).Build()
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// Defaults: Background for any resource, Foreground for CustomResourceDefinitions
+		Expect(controllers.HandleResourceDelete(ctx, c, configv1alpha1.FeatureResources, depl,
+			clusterSummary, logger)).To(Succeed())
+		Expect(propagationPolicies[depl.Name]).To(Equal(metav1.DeletePropagationBackground))
+
+		Expect(controllers.HandleResourceDelete(ctx, c, configv1alpha1.FeatureResources, crd,
+			clusterSummary, logger)).To(Succeed())
+		Expect(propagationPolicies[crd.Name]).To(Equal(metav1.DeletePropagationForeground))
+
+		// DeletionPolicies set for the feature take precedence
+		Expect(c.Create(ctx, depl)).To(Succeed())
+		clusterSummary.Spec.ClusterProfileSpec.DeletionPolicies = []configv1alpha1.DeletionPolicy{
+			{FeatureID: configv1alpha1.FeatureKustomize, PropagationPolicy: metav1.DeletePropagationForeground},
+			{FeatureID: configv1alpha1.FeatureResources, PropagationPolicy: metav1.DeletePropagationOrphan},
+		}
+		Expect(controllers.HandleResourceDelete(ctx, c, configv1alpha1.FeatureResources, depl,
+			clusterSummary, logger)).To(Succeed())
+		Expect(propagationPolicies[depl.Name]).To(Equal(metav1.DeletePropagationOrphan))
+	})
+
 	It("collectContent collect contents with no error even when there are section with just comments", func() {
 		content := `# This file is generated from the individual YAML files by generate-provisioner-deployment.sh. Do not
 # edit this file directly but instead edit the source files and re-render.
@@ -1326,3 +1922,29 @@ func validateResourceReports(resourceReports []configv1alpha1.ResourceReport,
 	Expect(foundNoAction).To(Equal(noAction))
 	Expect(foundConflict).To(Equal(conflict))
 }
+
+// getWorkloadSchedulingSpec returns a Spec setting PriorityClassName, NodeSelector, Tolerations,
+// RegistryOverrides and ImagePullSecrets
+func getWorkloadSchedulingSpec() *configv1alpha1.Spec {
+	return &configv1alpha1.Spec{
+		PriorityClassName: randomString(),
+		NodeSelector:      map[string]string{randomString(): randomString()},
+		Tolerations: []corev1.Toleration{
+			{Key: randomString(), Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		},
+		RegistryOverrides: map[string]string{"docker.io": "mirror.example.com"},
+		ImagePullSecrets:  []corev1.LocalObjectReference{{Name: randomString()}},
+	}
+}
+
+// verifyWorkloadScheduling verifies podSpec has been updated according to spec
+func verifyWorkloadScheduling(podSpec *corev1.PodSpec, spec *configv1alpha1.Spec) {
+	Expect(podSpec.PriorityClassName).To(Equal(spec.PriorityClassName))
+	Expect(podSpec.NodeSelector).To(Equal(spec.NodeSelector))
+	Expect(podSpec.Tolerations).To(Equal(spec.Tolerations))
+	Expect(podSpec.ImagePullSecrets).To(Equal(spec.ImagePullSecrets))
+	Expect(podSpec.Containers).ToNot(BeEmpty())
+	for i := range podSpec.Containers {
+		Expect(podSpec.Containers[i].Image).To(HavePrefix("mirror.example.com/"))
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/fnv"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+)
+
+// HashAlgorithm is the algorithm feature hashes are computed with
+type HashAlgorithm string
+
+const (
+	HashAlgorithmSHA256 = HashAlgorithm("sha256")
+	HashAlgorithmSHA384 = HashAlgorithm("sha384")
+	HashAlgorithmSHA512 = HashAlgorithm("sha512")
+	// HashAlgorithmFNV128a is not a cryptographic hash. It is faster and enough to detect changes.
+	HashAlgorithmFNV128a = HashAlgorithm("fnv128a")
+)
+
+var (
+	featureHashAlgorithm = HashAlgorithmSHA256
+
+	// strictHash, when set, makes feature hashes include the identity of referenced ConfigMaps/Secrets
+	strictHash bool
+)
+
+// SetHashAlgorithm sets the algorithm used to compute feature hashes. Changing the algorithm
+// causes all features to be redeployed.
+func SetHashAlgorithm(algorithm HashAlgorithm) error {
+	switch algorithm {
+	case HashAlgorithmSHA256, HashAlgorithmSHA384, HashAlgorithmSHA512, HashAlgorithmFNV128a:
+		featureHashAlgorithm = algorithm
+		return nil
+	}
+	return fmt.Errorf("unsupported hash algorithm %q", algorithm)
+}
+
+// SetStrictHash when enabled makes feature hashes include the UID and resourceVersion of each
+// referenced ConfigMap and Secret. Any change to those, even one not altering their data (or a
+// replacement with identical data), then causes the feature to be redeployed.
+func SetStrictHash(enabled bool) {
+	strictHash = enabled
+}
+
+// getReferencedObjectIdentity returns, in strict mode, a string identifying obj and its version.
+// It returns an empty string otherwise.
+func getReferencedObjectIdentity(obj client.Object) string {
+	if !strictHash {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", obj.GetUID(), obj.GetResourceVersion())
+}
+
+func getHashAlgorithm() HashAlgorithm {
+	return featureHashAlgorithm
+}
+
+// newFeatureHash returns a hash.Hash using the configured algorithm.
+// All feature hashes must be computed with it.
+func newFeatureHash() hash.Hash {
+	switch featureHashAlgorithm {
+	case HashAlgorithmSHA384:
+		return sha512.New384()
+	case HashAlgorithmSHA512:
+		return sha512.New()
+	case HashAlgorithmFNV128a:
+		return fnv.New128a()
+	default:
+		return sha256.New()
+	}
+}
+
+// isHashAlgorithmCurrent returns true if the hash stored for featureID was computed with
+// the configured algorithm
+func isHashAlgorithmCurrent(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) bool {
+	algorithm := HashAlgorithmSHA256
+	if fs := getFeatureSummaryForFeatureID(clusterSummary, featureID); fs != nil && fs.HashAlgorithm != "" {
+		algorithm = HashAlgorithm(fs.HashAlgorithm)
+	}
+	return algorithm == getHashAlgorithm()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("HashAlgorithm", func() {
+	AfterEach(func() {
+		Expect(controllers.SetHashAlgorithm(controllers.HashAlgorithmSHA256)).To(Succeed())
+		controllers.SetStrictHash(false)
+	})
+
+	It("newFeatureHash uses the configured algorithm", func() {
+		data := []byte(randomString())
+
+		h := controllers.NewFeatureHash()
+		h.Write(data)
+		expected := sha256.Sum256(data)
+		Expect(h.Sum(nil)).To(Equal(expected[:]))
+
+		Expect(controllers.SetHashAlgorithm(controllers.HashAlgorithmSHA512)).To(Succeed())
+		h = controllers.NewFeatureHash()
+		h.Write(data)
+		expected512 := sha512.Sum512(data)
+		Expect(h.Sum(nil)).To(Equal(expected512[:]))
+
+		Expect(controllers.SetHashAlgorithm(controllers.HashAlgorithm(randomString()))).ToNot(Succeed())
+	})
+
+	It("isHashAlgorithmCurrent returns false when hash was computed with a different algorithm", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{FeatureID: configv1alpha1.FeatureResources, Hash: []byte(randomString())},
+					{
+						FeatureID: configv1alpha1.FeatureHelm, Hash: []byte(randomString()),
+						HashAlgorithm: string(controllers.HashAlgorithmFNV128a),
+					},
+				},
+			},
+		}
+
+		// Empty algorithm means sha256
+		Expect(controllers.IsHashAlgorithmCurrent(clusterSummary, configv1alpha1.FeatureResources)).To(BeTrue())
+		Expect(controllers.IsHashAlgorithmCurrent(clusterSummary, configv1alpha1.FeatureHelm)).To(BeFalse())
+
+		Expect(controllers.SetHashAlgorithm(controllers.HashAlgorithmFNV128a)).To(Succeed())
+		Expect(controllers.IsHashAlgorithmCurrent(clusterSummary, configv1alpha1.FeatureResources)).To(BeFalse())
+		Expect(controllers.IsHashAlgorithmCurrent(clusterSummary, configv1alpha1.FeatureHelm)).To(BeTrue())
+	})
+
+	It("getReferencedObjectIdentity includes UID and resourceVersion only in strict mode", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       randomString(),
+				Name:            randomString(),
+				UID:             types.UID(randomString()),
+				ResourceVersion: "1",
+			},
+		}
+
+		Expect(controllers.GetReferencedObjectIdentity(configMap)).To(BeEmpty())
+
+		controllers.SetStrictHash(true)
+		identity := controllers.GetReferencedObjectIdentity(configMap)
+		Expect(identity).To(ContainSubstring(string(configMap.UID)))
+
+		configMap.ResourceVersion = "2"
+		Expect(controllers.GetReferencedObjectIdentity(configMap)).ToNot(Equal(identity))
+	})
+})
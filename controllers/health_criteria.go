@@ -0,0 +1,119 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+)
+
+// isHealthyByCriteria verifies whether resource is healthy according to the declarative criteria
+// (Condition and WorkloadReady) set in check. When not healthy, a message explaining why is returned.
+func isHealthyByCriteria(resource *unstructured.Unstructured, check *configv1alpha1.ValidateHealth,
+) (healthy bool, msg string) {
+
+	if check.Condition != nil {
+		if healthy, msg = hasHealthCondition(resource, check.Condition); !healthy {
+			return false, fmt.Sprintf("resource %s/%s is not healthy: %s", resource.GetNamespace(), resource.GetName(), msg)
+		}
+	}
+
+	if check.WorkloadReady {
+		if healthy, msg = isWorkloadReady(resource); !healthy {
+			return false, fmt.Sprintf("resource %s/%s is not healthy: %s", resource.GetNamespace(), resource.GetName(), msg)
+		}
+	}
+
+	return true, ""
+}
+
+// hasHealthCondition returns true if resource reports, in status.conditions, a condition of
+// the expected type with the expected status (True if not specified)
+func hasHealthCondition(resource *unstructured.Unstructured, condition *configv1alpha1.HealthCondition,
+) (found bool, msg string) {
+
+	expectedStatus := condition.Status
+	if expectedStatus == "" {
+		expectedStatus = metav1.ConditionTrue
+	}
+
+	conditions, _, err := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	if err != nil {
+		return false, fmt.Sprintf("failed to read conditions: %v", err)
+	}
+
+	for i := range conditions {
+		c, ok := conditions[i].(map[string]interface{})
+		if !ok || c["type"] != condition.Type {
+			continue
+		}
+		if c["status"] == string(expectedStatus) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("condition %s is %v (expected %s): %v", condition.Type, c["status"],
+			expectedStatus, c["message"])
+	}
+
+	return false, fmt.Sprintf("condition %s not reported", condition.Type)
+}
+
+// isWorkloadReady returns true if all replicas of the Deployment, StatefulSet, DaemonSet or
+// ReplicaSet are updated and ready. The latest spec must have been observed as well.
+func isWorkloadReady(resource *unstructured.Unstructured) (ready bool, msg string) {
+	observedGeneration, _, _ := unstructured.NestedInt64(resource.Object, "status", "observedGeneration")
+	if observedGeneration < resource.GetGeneration() {
+		return false, "latest spec not observed yet"
+	}
+
+	getStatusField := func(field string) int64 {
+		value, _, _ := unstructured.NestedInt64(resource.Object, "status", field)
+		return value
+	}
+
+	switch resource.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		replicas, found, _ := unstructured.NestedInt64(resource.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready := getStatusField("readyReplicas")
+		if ready < replicas {
+			return false, fmt.Sprintf("%d out of %d replicas ready", ready, replicas)
+		}
+		if resource.GetKind() != "ReplicaSet" {
+			if updated := getStatusField("updatedReplicas"); updated < replicas {
+				return false, fmt.Sprintf("%d out of %d replicas updated", updated, replicas)
+			}
+		}
+		return true, ""
+	case "DaemonSet":
+		desired := getStatusField("desiredNumberScheduled")
+		if ready := getStatusField("numberReady"); ready < desired {
+			return false, fmt.Sprintf("%d out of %d pods ready", ready, desired)
+		}
+		if updated := getStatusField("updatedNumberScheduled"); updated < desired {
+			return false, fmt.Sprintf("%d out of %d pods updated", updated, desired)
+		}
+		return true, ""
+	}
+
+	return false, fmt.Sprintf("kind %s is not a Deployment, StatefulSet, DaemonSet or ReplicaSet", resource.GetKind())
+}
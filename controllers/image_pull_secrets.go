@@ -0,0 +1,310 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// imagePullSecretLabel is added to the image pull Secrets copied by Sveltos in the
+	// managed clusters
+	imagePullSecretLabel = "projectsveltos.io/image-pull-secret"
+)
+
+// getImagePullSecrets returns the image pull Secrets referenced by clusterSummary. Secrets are
+// fetched from the management cluster, in the managed cluster namespace.
+func getImagePullSecrets(ctx context.Context, c client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary) ([]*corev1.Secret, error) {
+
+	refs := clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets
+	secrets := make([]*corev1.Secret, len(refs))
+	for i := range refs {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Spec.ClusterNamespace, Name: refs[i].Name},
+			secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image pull Secret %s/%s: %w",
+				clusterSummary.Spec.ClusterNamespace, refs[i].Name, err)
+		}
+
+		if secret.Type != corev1.SecretTypeDockerConfigJson && secret.Type != corev1.SecretTypeDockercfg {
+			return nil, fmt.Errorf("image pull Secret %s/%s has type %s. Expected %s or %s",
+				secret.Namespace, secret.Name, secret.Type, corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg)
+		}
+		secrets[i] = secret
+	}
+
+	return secrets, nil
+}
+
+// getImagePullSecretsHash returns a string representing the image pull Secrets referenced by
+// clusterSummary and their content. Missing Secrets are ignored.
+func getImagePullSecretsHash(ctx context.Context, c client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary) string {
+
+	var config string
+	for _, ref := range clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets {
+		config += ref.Name
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: clusterSummary.Spec.ClusterNamespace, Name: ref.Name},
+			secret)
+		if err == nil {
+			config += getReferencedObjectIdentity(secret)
+			config += getDataSectionHash(secret.Data)
+		}
+	}
+	return config
+}
+
+// ensureImagePullSecrets copies the image pull Secrets referenced by clusterSummary in namespace
+// of the destination cluster, so workloads deployed in that namespace can use them.
+// No-op in DryRun mode, or if no image pull Secret is referenced.
+func ensureImagePullSecrets(ctx context.Context, destClient client.Client, deployingToMgmtCluster bool,
+	clusterSummary *configv1alpha1.ClusterSummary, namespace string, logger logr.Logger) error {
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets) == 0 ||
+		clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+
+		return nil
+	}
+
+	if deployingToMgmtCluster && namespace == clusterSummary.Spec.ClusterNamespace {
+		// Secrets are already there
+		return nil
+	}
+
+	secrets, err := getImagePullSecrets(ctx, getManagementClusterClient(), clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	profile, err := getStaleResourcesOwner(ctx, clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	for i := range secrets {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("syncing image pull Secret %s in namespace %s",
+			secrets[i].Name, namespace))
+		if err := syncImagePullSecret(ctx, destClient, deployingToMgmtCluster, clusterSummary, profile,
+			secrets[i], namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncImagePullSecret copies secret in namespace of the destination cluster, setting profile as
+// OwnerReference. An existing Secret not created by Sveltos is never overwritten.
+func syncImagePullSecret(ctx context.Context, destClient client.Client, deployingToMgmtCluster bool,
+	clusterSummary *configv1alpha1.ClusterSummary, profile client.Object, secret *corev1.Secret,
+	namespace string) error {
+
+	current := &corev1.Secret{}
+	err := destClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secret.Name}, current)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		copied := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      secret.Name,
+				Labels:    map[string]string{imagePullSecretLabel: "ok"},
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}
+		if deployingToMgmtCluster {
+			copied.Annotations = map[string]string{
+				clusterSummaryAnnotation: getClusterSummaryAnnotationValue(clusterSummary),
+			}
+		}
+		deployer.AddOwnerReference(copied, profile)
+		return destClient.Create(ctx, copied)
+	}
+
+	if _, ok := current.Labels[imagePullSecretLabel]; !ok {
+		return deployer.NewConflictError(fmt.Sprintf("Secret %s/%s already exists and was not created by Sveltos",
+			namespace, secret.Name))
+	}
+
+	if current.Type != secret.Type {
+		return fmt.Errorf("secret %s/%s already exists in the managed cluster with type %s",
+			namespace, secret.Name, current.Type)
+	}
+
+	current.Data = secret.Data
+	deployer.AddOwnerReference(current, profile)
+	return destClient.Update(ctx, current)
+}
+
+// removeStaleImagePullSecrets removes profile as owner of the image pull Secrets copied in the
+// destination cluster which clusterSummary does not need anymore. Secrets left without owners are
+// deleted.
+// When undeploying featureID, Secrets are kept only if other features of clusterSummary still deploy
+// content. Otherwise only Secrets not referenced anymore in ImagePullSecrets are removed.
+func removeStaleImagePullSecrets(ctx context.Context, destClient client.Client, isMgmtCluster bool,
+	clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID, undeploying bool,
+	logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+		return nil
+	}
+
+	profile, err := getStaleResourcesOwner(ctx, clusterSummary)
+	if err != nil {
+		return err
+	}
+
+	return removeImagePullSecrets(ctx, destClient, isMgmtCluster, clusterSummary, profile,
+		getNeededImagePullSecrets(clusterSummary, featureID, undeploying), logger)
+}
+
+// removeImagePullSecrets removes profile as owner of the image pull Secrets copied in the destination
+// cluster whose name is not in needed. Secrets left without owners are deleted.
+func removeImagePullSecrets(ctx context.Context, destClient client.Client, isMgmtCluster bool,
+	clusterSummary *configv1alpha1.ClusterSummary, profile client.Object, needed map[string]bool,
+	logger logr.Logger) error {
+
+	secrets := &corev1.SecretList{}
+	err := destClient.List(ctx, secrets, client.HasLabels{imagePullSecretLabel})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("not allowed to list image pull Secrets: %v", err))
+			return nil
+		}
+		return err
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if needed[secret.Name] || !deployer.IsOwnerReference(secret, profile) {
+			continue
+		}
+		if isMgmtCluster &&
+			secret.Annotations[clusterSummaryAnnotation] != getClusterSummaryAnnotationValue(clusterSummary) {
+
+			continue
+		}
+
+		deployer.RemoveOwnerReference(secret, profile)
+		if len(secret.GetOwnerReferences()) != 0 {
+			// Secret is still needed by other ClusterProfiles/Profiles
+			if err := destClient.Update(ctx, secret); err != nil {
+				return err
+			}
+			continue
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("removing image pull Secret %s/%s",
+			secret.Namespace, secret.Name))
+		if err := destClient.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getNeededImagePullSecrets returns the names of the image pull Secrets clusterSummary still needs
+func getNeededImagePullSecrets(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID,
+	undeploying bool) map[string]bool {
+
+	needed := make(map[string]bool)
+	if !clusterSummary.DeletionTimestamp.IsZero() {
+		return needed
+	}
+
+	spec := &clusterSummary.Spec.ClusterProfileSpec
+	if undeploying {
+		inUse := (featureID != configv1alpha1.FeatureResources && len(spec.PolicyRefs) > 0) ||
+			(featureID != configv1alpha1.FeatureKustomize && len(spec.KustomizationRefs) > 0) ||
+			(featureID != configv1alpha1.FeatureHelm && len(spec.HelmCharts) > 0)
+		if !inUse {
+			return needed
+		}
+	}
+
+	for i := range spec.ImagePullSecrets {
+		needed[spec.ImagePullSecrets[i].Name] = true
+	}
+	return needed
+}
+
+// setImagePullSecrets adds imagePullSecrets to the pod spec of policy when policy is a
+// workload (see isWorkload). ImagePullSecrets already present are not duplicated.
+func setImagePullSecrets(policy *unstructured.Unstructured, imagePullSecrets []corev1.LocalObjectReference) error {
+	if len(imagePullSecrets) == 0 || !isWorkload(policy) {
+		return nil
+	}
+
+	current, _, err := unstructured.NestedSlice(policy.Object, podSpecField(policy, "imagePullSecrets")...)
+	if err != nil {
+		return err
+	}
+
+	for i := range imagePullSecrets {
+		found := false
+		for j := range current {
+			if m, ok := current[j].(map[string]interface{}); ok && m["name"] == imagePullSecrets[i].Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			current = append(current, map[string]interface{}{"name": imagePullSecrets[i].Name})
+		}
+	}
+
+	return unstructured.SetNestedSlice(policy.Object, current, podSpecField(policy, "imagePullSecrets")...)
+}
+
+// ensureHelmImagePullSecrets copies the image pull Secrets referenced by clusterSummary in the
+// release namespace of helmChart, creating the namespace if needed
+func ensureHelmImagePullSecrets(ctx context.Context, remoteClient client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary, helmChart *configv1alpha1.HelmChart, logger logr.Logger) error {
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets) == 0 ||
+		helmChart.HelmChartAction == configv1alpha1.HelmChartActionUninstall {
+
+		return nil
+	}
+
+	err := createNamespace(ctx, remoteClient, clusterSummary, helmChart.ReleaseNamespace)
+	if err != nil {
+		return err
+	}
+
+	return ensureImagePullSecrets(ctx, remoteClient, false, clusterSummary, helmChart.ReleaseNamespace, logger)
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+var _ = Describe("Image pull Secrets", func() {
+	var clusterSummary *configv1alpha1.ClusterSummary
+
+	BeforeEach(func() {
+		clusterSummary = &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+			},
+		}
+	})
+
+	It("getImagePullSecrets returns referenced Secrets and rejects Secrets of the wrong type", func() {
+		pullSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      randomString(),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+		}
+		opaqueSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      randomString(),
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+
+		initObjects := []client.Object{pullSecret, opaqueSecret}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: pullSecret.Name},
+		}
+		secrets, err := controllers.GetImagePullSecrets(context.TODO(), c, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(len(secrets)).To(Equal(1))
+		Expect(secrets[0].Name).To(Equal(pullSecret.Name))
+
+		clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets = append(
+			clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets, corev1.LocalObjectReference{Name: opaqueSecret.Name})
+		_, err = controllers.GetImagePullSecrets(context.TODO(), c, clusterSummary)
+		Expect(err).ToNot(BeNil())
+
+		clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets = []corev1.LocalObjectReference{
+			{Name: randomString()},
+		}
+		_, err = controllers.GetImagePullSecrets(context.TODO(), c, clusterSummary)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("syncImagePullSecret creates and updates the Secret in the destination namespace", func() {
+		pullSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      randomString(),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		clusterProfile := getImagePullSecretOwner()
+		namespace := randomString()
+		Expect(controllers.SyncImagePullSecret(context.TODO(), c, false, clusterSummary, clusterProfile,
+			pullSecret, namespace)).To(Succeed())
+
+		current := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: pullSecret.Name},
+			current)).To(Succeed())
+		Expect(current.Type).To(Equal(pullSecret.Type))
+		Expect(current.Data).To(Equal(pullSecret.Data))
+		Expect(current.Labels).To(HaveKey(controllers.ImagePullSecretLabel))
+		Expect(current.OwnerReferences).To(HaveLen(1))
+		Expect(current.OwnerReferences[0].Name).To(Equal(clusterProfile.Name))
+
+		pullSecret.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"mirror.example.com":{}}}`)}
+		Expect(controllers.SyncImagePullSecret(context.TODO(), c, false, clusterSummary, clusterProfile,
+			pullSecret, namespace)).To(Succeed())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: pullSecret.Name},
+			current)).To(Succeed())
+		Expect(current.Data).To(Equal(pullSecret.Data))
+	})
+
+	It("syncImagePullSecret does not overwrite a Secret not created by Sveltos", func() {
+		pullSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterSummary.Spec.ClusterNamespace,
+				Name:      randomString(),
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+		}
+
+		existing := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      pullSecret.Name,
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"registry.example.com":{}}}`)},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+
+		err := controllers.SyncImagePullSecret(context.TODO(), c, false, clusterSummary, getImagePullSecretOwner(),
+			pullSecret, existing.Namespace)
+		Expect(err).ToNot(BeNil())
+		var conflictErr *deployer.ConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+
+		current := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: existing.Namespace, Name: existing.Name},
+			current)).To(Succeed())
+		Expect(current.Data).To(Equal(existing.Data))
+	})
+
+	It("getNeededImagePullSecrets keeps Secrets only while some feature still deploys content", func() {
+		name := randomString()
+		clusterSummary.Spec.ClusterProfileSpec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: name}}
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs = []configv1alpha1.PolicyRef{
+			{Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)},
+		}
+
+		Expect(controllers.GetNeededImagePullSecrets(clusterSummary, configv1alpha1.FeatureResources, false)).
+			To(HaveKey(name))
+		// Undeploying the only feature deploying content
+		Expect(controllers.GetNeededImagePullSecrets(clusterSummary, configv1alpha1.FeatureResources, true)).
+			To(BeEmpty())
+		// Undeploying another feature
+		Expect(controllers.GetNeededImagePullSecrets(clusterSummary, configv1alpha1.FeatureKustomize, true)).
+			To(HaveKey(name))
+	})
+
+	It("removeImagePullSecrets removes copied Secrets not needed anymore", func() {
+		clusterProfile := getImagePullSecretOwner()
+		otherClusterProfile := getImagePullSecretOwner()
+
+		namespace := randomString()
+		newCopiedSecret := func(owners ...client.Object) *corev1.Secret {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      randomString(),
+					Labels:    map[string]string{controllers.ImagePullSecretLabel: "ok"},
+				},
+				Type: corev1.SecretTypeDockerConfigJson,
+			}
+			for i := range owners {
+				deployer.AddOwnerReference(secret, owners[i])
+			}
+			return secret
+		}
+
+		needed := newCopiedSecret(clusterProfile)
+		stale := newCopiedSecret(clusterProfile)
+		shared := newCopiedSecret(clusterProfile, otherClusterProfile)
+		notOwned := newCopiedSecret(otherClusterProfile)
+		notCopied := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: randomString()},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(needed, stale, shared, notOwned, notCopied).Build()
+
+		Expect(controllers.RemoveImagePullSecrets(context.TODO(), c, false, clusterSummary, clusterProfile,
+			map[string]bool{needed.Name: true}, textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
+
+		current := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: needed.Name},
+			current)).To(Succeed())
+		err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: stale.Name}, current)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: shared.Name},
+			current)).To(Succeed())
+		Expect(current.OwnerReferences).To(HaveLen(1))
+		Expect(current.OwnerReferences[0].Name).To(Equal(otherClusterProfile.Name))
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: notOwned.Name},
+			current)).To(Succeed())
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: notCopied.Name},
+			current)).To(Succeed())
+	})
+
+	It("setImagePullSecrets adds imagePullSecrets to workloads only once", func() {
+		imagePullSecrets := []corev1.LocalObjectReference{{Name: randomString()}, {Name: randomString()}}
+
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(deplTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetImagePullSecrets(depl, imagePullSecrets)).To(Succeed())
+		Expect(controllers.SetImagePullSecrets(depl, imagePullSecrets)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(depl.UnstructuredContent(), deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.ImagePullSecrets).To(Equal(imagePullSecrets))
+
+		serviceAccount, err := utils.GetUnstructured([]byte(fmt.Sprintf(patchServiceAccount, randomString(), randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetImagePullSecrets(serviceAccount, imagePullSecrets)).To(Succeed())
+		Expect(serviceAccount.Object).ToNot(HaveKey("spec"))
+	})
+
+	It("setImagePullSecrets adds imagePullSecrets to the pod spec of Jobs", func() {
+		imagePullSecrets := []corev1.LocalObjectReference{{Name: randomString()}}
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(jobTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetImagePullSecrets(u, imagePullSecrets)).To(Succeed())
+
+		job := &batchv1.Job{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), job)).To(Succeed())
+		Expect(job.Spec.Template.Spec.ImagePullSecrets).To(Equal(imagePullSecrets))
+	})
+
+	It("setImagePullSecrets adds imagePullSecrets to the pod spec of CronJobs", func() {
+		imagePullSecrets := []corev1.LocalObjectReference{{Name: randomString()}}
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(cronJobTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetImagePullSecrets(u, imagePullSecrets)).To(Succeed())
+
+		cronJob := &batchv1.CronJob{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), cronJob)).To(Succeed())
+		Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets).To(Equal(imagePullSecrets))
+	})
+
+	It("setImagePullSecrets adds imagePullSecrets to the spec of Pods", func() {
+		imagePullSecrets := []corev1.LocalObjectReference{{Name: randomString()}}
+
+		u, err := utils.GetUnstructured([]byte(fmt.Sprintf(podTemplate, randomString())))
+		Expect(err).To(BeNil())
+		Expect(controllers.SetImagePullSecrets(u, imagePullSecrets)).To(Succeed())
+
+		pod := &corev1.Pod{}
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), pod)).To(Succeed())
+		Expect(pod.Spec.ImagePullSecrets).To(Equal(imagePullSecrets))
+	})
+})
+
+func getImagePullSecretOwner() *configv1alpha1.ClusterProfile {
+	return &configv1alpha1.ClusterProfile{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       configv1alpha1.ClusterProfileKind,
+			APIVersion: configv1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randomString(),
+			UID:  types.UID(randomString()),
+		},
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+	libsveltosset "github.com/projectsveltos/libsveltos/lib/set"
+)
+
+// clusterChanges tracks, for each (Cluster)Profile, the clusters which changed since its
+// MatchingClusterRefs were last computed. When a (Cluster)Profile is reconciled only because
+// some clusters changed, membership of those clusters alone is re-evaluated instead of listing
+// all clusters again.
+// Zero value is ready to use.
+type clusterChanges struct {
+	mux sync.Mutex
+
+	// key: (Cluster)Profile; value: clusters changed since last reconciliation
+	pending map[types.NamespacedName]*libsveltosset.Set
+
+	// key: (Cluster)Profile; value: generation MatchingClusterRefs were last fully computed for
+	generations map[types.NamespacedName]int64
+}
+
+// record marks cluster as changed for all (Cluster)Profiles in requests
+func (c *clusterChanges) record(requests []reconcile.Request, cluster *corev1.ObjectReference) {
+	if cluster == nil {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.pending == nil {
+		c.pending = make(map[types.NamespacedName]*libsveltosset.Set)
+	}
+
+	for i := range requests {
+		getConsumersForKey(c.pending, requests[i].NamespacedName).Insert(cluster)
+	}
+}
+
+// take returns, and forgets, the clusters changed since the (Cluster)Profile was last reconciled.
+// incremental is true only when MatchingClusterRefs can be updated from those clusters alone:
+// - MatchingClusterRefs were fully computed by this process for the current generation, so selectors
+// and ClusterRefs are unchanged;
+// - at least one cluster changed (any other event, like a resync, triggers a full recompute);
+// - neither ClusterLimit nor SetRefs are used, as those depend on clusters other than the changed ones.
+func (c *clusterChanges) take(profileScope *scope.ProfileScope) (changed []corev1.ObjectReference, incremental bool) {
+	key := types.NamespacedName{Namespace: profileScope.Profile.GetNamespace(), Name: profileScope.Profile.GetName()}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if clusters, ok := c.pending[key]; ok {
+		changed = clusters.Items()
+		delete(c.pending, key)
+	}
+
+	generation, ok := c.generations[key]
+	if !ok || generation != profileScope.Profile.GetGeneration() {
+		return changed, false
+	}
+
+	spec := profileScope.GetSpec()
+	if spec.ClusterLimit != nil || len(spec.SetRefs) != 0 {
+		return changed, false
+	}
+
+	return changed, len(changed) != 0
+}
+
+// computed records MatchingClusterRefs were fully computed for current (Cluster)Profile generation
+func (c *clusterChanges) computed(profileScope *scope.ProfileScope) {
+	key := types.NamespacedName{Namespace: profileScope.Profile.GetNamespace(), Name: profileScope.Profile.GetName()}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.generations == nil {
+		c.generations = make(map[types.NamespacedName]int64)
+	}
+	c.generations[key] = profileScope.Profile.GetGeneration()
+}
+
+// forget makes next reconciliation of the (Cluster)Profile fully recompute MatchingClusterRefs
+func (c *clusterChanges) forget(profileScope *scope.ProfileScope) {
+	key := types.NamespacedName{Namespace: profileScope.Profile.GetNamespace(), Name: profileScope.Profile.GetName()}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.generations, key)
+	delete(c.pending, key)
+}
+
+func getConsumersForKey(m map[types.NamespacedName]*libsveltosset.Set, key types.NamespacedName) *libsveltosset.Set {
+	s, ok := m[key]
+	if !ok {
+		s = &libsveltosset.Set{}
+		m[key] = s
+	}
+	return s
+}
+
+// updateMatchingClusters returns the clusters matching the (Cluster)Profile, starting from its current
+// MatchingClusterRefs and re-evaluating only the changed clusters. namespace, when set, restricts
+// matching to clusters in such namespace.
+func updateMatchingClusters(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	namespace string, changed []corev1.ObjectReference, logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	changedClusters := make(map[corev1.ObjectReference]bool, len(changed))
+	for i := range changed {
+		changedClusters[getClusterIdentity(&changed[i])] = true
+	}
+
+	current := profileScope.GetStatus().MatchingClusterRefs
+	matching := make([]corev1.ObjectReference, 0, len(current)+len(changed))
+	for i := range current {
+		if !changedClusters[getClusterIdentity(&current[i])] {
+			matching = append(matching, current[i])
+		}
+	}
+
+	for i := range changed {
+		ref, err := getClusterIfMatching(ctx, c, profileScope, namespace, &changed[i], logger)
+		if err != nil {
+			return nil, err
+		}
+		if ref != nil {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("cluster %s/%s is matching", ref.Namespace, ref.Name))
+			matching = append(matching, *ref)
+		}
+	}
+
+	return matching, nil
+}
+
+// getClusterIfMatching evaluates a single cluster the same way a full recompute would:
+// cluster must be listed in ClusterRefs or, if ready, match ClusterSelector or any of
+// ClusterSelectors. It must then be in a namespace considered by this controller and have all
+// RequiredClusterLabels. Returns nil if cluster is not matching.
+func getClusterIfMatching(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	namespace string, ref *corev1.ObjectReference, logger logr.Logger) (*corev1.ObjectReference, error) {
+
+	if namespace != "" && ref.Namespace != namespace {
+		return nil, nil
+	}
+	if !isClusterNamespaceAllowed(ref.Namespace) {
+		return nil, nil
+	}
+
+	var matching *corev1.ObjectReference
+	for i := range profileScope.GetSpec().ClusterRefs {
+		clusterRef := &profileScope.GetSpec().ClusterRefs[i]
+		if getClusterIdentity(clusterRef) == getClusterIdentity(ref) {
+			matching = clusterRef
+			break
+		}
+	}
+
+	requiredLabels := profileScope.GetSpec().RequiredClusterLabels
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, ref.Namespace, ref.Name, clusterproxy.GetClusterType(ref))
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// ClusterRefs are matching even when cluster does not exist, unless
+			// RequiredClusterLabels need to be verified
+			if len(requiredLabels) != 0 {
+				return nil, nil
+			}
+			return matching, nil
+		}
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get cluster %s/%s: %v", ref.Namespace, ref.Name, err))
+		return nil, err
+	}
+
+	clusterLabels := labels.Set(cluster.GetLabels())
+	if matching == nil && isClusterReadyForSelection(cluster) {
+		selectors := profileScope.GetSelectors()
+		if selector := profileScope.GetSelector(); selector != "" {
+			selectors = append([]string{selector}, selectors...)
+		}
+		for i := range selectors {
+			parsedSelector, err := labels.Parse(selectors[i])
+			if err != nil {
+				return nil, err
+			}
+			if parsedSelector.Matches(clusterLabels) {
+				matching = &corev1.ObjectReference{
+					Namespace: ref.Namespace, Name: ref.Name, Kind: ref.Kind, APIVersion: ref.APIVersion,
+				}
+				break
+			}
+		}
+	}
+
+	if matching != nil && len(requiredLabels) != 0 &&
+		!labels.SelectorFromSet(requiredLabels).Matches(clusterLabels) {
+
+		return nil, nil
+	}
+
+	return matching, nil
+}
+
+// isClusterReadyForSelection returns true if cluster can be selected by a ClusterSelector:
+// it is not being deleted and it is ready.
+func isClusterReadyForSelection(cluster client.Object) bool {
+	if !cluster.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	switch c := cluster.(type) {
+	case *libsveltosv1alpha1.SveltosCluster:
+		return c.Status.Ready
+	case *clusterv1.Cluster:
+		for i := range c.Status.Conditions {
+			if c.Status.Conditions[i].Type == clusterv1.ControlPlaneInitializedCondition &&
+				c.Status.Conditions[i].Status == corev1.ConditionTrue {
+
+				return true
+			}
+		}
+		return c.Status.ControlPlaneReady
+	}
+
+	return false
+}
+
+// getClusterIdentity returns the fields identifying a cluster, ignoring the others
+func getClusterIdentity(ref *corev1.ObjectReference) corev1.ObjectReference {
+	return corev1.ObjectReference{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Incremental matching clusters", func() {
+	getClusterRef := func(cluster *libsveltosv1alpha1.SveltosCluster) corev1.ObjectReference {
+		return corev1.ObjectReference{
+			Namespace: cluster.Namespace, Name: cluster.Name,
+			Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+		}
+	}
+
+	getSveltosCluster := func(namespace string, clusterLabels map[string]string) *libsveltosv1alpha1.SveltosCluster {
+		return &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels:    clusterLabels,
+			},
+			Status: libsveltosv1alpha1.SveltosClusterStatus{
+				Ready: true,
+			},
+		}
+	}
+
+	It("updateMatchingClusters re-evaluates only changed clusters", func() {
+		namespace := randomString()
+
+		unchanged := getSveltosCluster(namespace, map[string]string{"env": "prod"})
+		leaving := getSveltosCluster(namespace, map[string]string{"env": "staging"})
+		joining := getSveltosCluster(namespace, map[string]string{"env": "prod"})
+		notReady := getSveltosCluster(namespace, map[string]string{"env": "prod"})
+		notReady.Status.Ready = false
+
+		clusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randomString(),
+			},
+			Spec: configv1alpha1.Spec{
+				ClusterSelector: libsveltosv1alpha1.Selector("env=prod"),
+			},
+			Status: configv1alpha1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{
+					getClusterRef(unchanged), getClusterRef(leaving),
+				},
+			},
+		}
+
+		Expect(addTypeInformationToObject(scheme, clusterProfile)).To(Succeed())
+
+		initObjects := []client.Object{unchanged, leaving, joining, notReady, clusterProfile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		// Cluster leaving is not matching anymore
+		matching, err := controllers.UpdateMatchingClusters(context.TODO(), c, profileScope, "",
+			[]corev1.ObjectReference{getClusterRef(leaving), getClusterRef(joining), getClusterRef(notReady)},
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(ConsistOf(getClusterRef(unchanged), getClusterRef(joining)))
+
+		// Deleted cluster is removed unless listed in ClusterRefs
+		Expect(c.Delete(context.TODO(), unchanged)).To(Succeed())
+		matching, err = controllers.UpdateMatchingClusters(context.TODO(), c, profileScope, "",
+			[]corev1.ObjectReference{getClusterRef(unchanged)}, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(ConsistOf(getClusterRef(leaving)))
+
+		clusterProfile.Spec.ClusterRefs = []corev1.ObjectReference{getClusterRef(unchanged)}
+		matching, err = controllers.UpdateMatchingClusters(context.TODO(), c, profileScope, "",
+			[]corev1.ObjectReference{getClusterRef(unchanged)}, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(ConsistOf(getClusterRef(unchanged), getClusterRef(leaving)))
+	})
+
+	It("updateMatchingClusters only considers clusters in namespace and with RequiredClusterLabels", func() {
+		namespace := randomString()
+
+		otherNamespace := getSveltosCluster(randomString(), map[string]string{"env": "prod", "tier": "gold"})
+		missingLabel := getSveltosCluster(namespace, map[string]string{"env": "prod"})
+		matchingCluster := getSveltosCluster(namespace, map[string]string{"env": "prod", "tier": "gold"})
+
+		profile := &configv1alpha1.Profile{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+			},
+			Spec: configv1alpha1.Spec{
+				ClusterSelector:       libsveltosv1alpha1.Selector("env=prod"),
+				RequiredClusterLabels: map[string]string{"tier": "gold"},
+			},
+		}
+
+		Expect(addTypeInformationToObject(scheme, profile)).To(Succeed())
+
+		initObjects := []client.Object{otherNamespace, missingLabel, matchingCluster, profile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        profile,
+			ControllerName: "profile",
+		})
+		Expect(err).To(BeNil())
+
+		matching, err := controllers.UpdateMatchingClusters(context.TODO(), c, profileScope, namespace,
+			[]corev1.ObjectReference{getClusterRef(otherNamespace), getClusterRef(missingLabel), getClusterRef(matchingCluster)},
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(matching).To(ConsistOf(getClusterRef(matchingCluster)))
+	})
+})
@@ -0,0 +1,147 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// kubernetesVersionCacheTTL is how long the Kubernetes version of a managed cluster is cached.
+	// It is also how often features skipped because of an unsupported version are reevaluated.
+	kubernetesVersionCacheTTL = 5 * time.Minute
+)
+
+var (
+	kubernetesVersionCacheMux sync.Mutex
+	kubernetesVersionCache    = map[string]*kubernetesVersionCacheEntry{}
+)
+
+type kubernetesVersionCacheEntry struct {
+	version *utilversion.Version
+	expires time.Time
+}
+
+// getMinKubernetesVersion returns the minimum Kubernetes version featureID requires.
+// Empty if none is set.
+func getMinKubernetesVersion(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) string {
+	requirements := clusterSummary.Spec.ClusterProfileSpec.KubernetesVersionRequirements
+	for i := range requirements {
+		if requirements[i].FeatureID == featureID {
+			return requirements[i].MinKubernetesVersion
+		}
+	}
+	return ""
+}
+
+// checkKubernetesVersion verifies the managed cluster runs at least the Kubernetes version
+// feature requires. If not, feature status is set to UnsupportedKubernetesVersion and an error
+// is returned so that feature is not deployed. Version is reevaluated once cached version expires.
+func (r *ClusterSummaryReconciler) checkKubernetesVersion(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1alpha1.FeatureID, logger logr.Logger) error {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	minVersion := getMinKubernetesVersion(clusterSummary, featureID)
+	if minVersion == "" {
+		return nil
+	}
+
+	requiredVersion, err := utilversion.ParseGeneric(minVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minimum Kubernetes version %q: %w", minVersion, err)
+	}
+
+	clusterVersion, err := getClusterKubernetesVersion(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	if clusterVersion.AtLeast(requiredVersion) {
+		return nil
+	}
+
+	message := fmt.Sprintf("feature requires Kubernetes %s or later. Cluster runs %s", minVersion, clusterVersion)
+	logger.V(logs.LogInfo).Info(message)
+	clusterSummaryScope.SetFeatureStatus(featureID, configv1alpha1.FeatureStatusUnsupportedKubernetesVersion, nil)
+	clusterSummaryScope.SetFailureMessage(featureID, &message)
+	return &TransientError{Message: message, RetryAfter: kubernetesVersionCacheTTL}
+}
+
+// getClusterKubernetesVersion returns the Kubernetes version the managed cluster runs.
+// Version is cached for kubernetesVersionCacheTTL.
+func getClusterKubernetesVersion(ctx context.Context, c client.Client, clusterNamespace,
+	clusterName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger) (*utilversion.Version, error) {
+
+	key := fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)
+
+	kubernetesVersionCacheMux.Lock()
+	entry, ok := kubernetesVersionCache[key]
+	kubernetesVersionCacheMux.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.version, nil
+	}
+
+	restConfig, err := getKubernetesRestConfig(ctx, c, clusterNamespace, clusterName, "", "",
+		clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterVersion, err := fetchKubernetesVersion(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetesVersionCacheMux.Lock()
+	kubernetesVersionCache[key] = &kubernetesVersionCacheEntry{
+		version: clusterVersion,
+		expires: time.Now().Add(kubernetesVersionCacheTTL),
+	}
+	kubernetesVersionCacheMux.Unlock()
+
+	return clusterVersion, nil
+}
+
+// fetchKubernetesVersion returns the Kubernetes version of the cluster reachable with restConfig
+func fetchKubernetesVersion(restConfig *rest.Config) (*utilversion.Version, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return utilversion.ParseGeneric(info.GitVersion)
+}
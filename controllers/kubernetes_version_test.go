@@ -0,0 +1,127 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Kubernetes version requirements", func() {
+	It("getMinKubernetesVersion returns the version required by a feature", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterProfileSpec: configv1alpha1.Spec{
+					KubernetesVersionRequirements: []configv1alpha1.KubernetesVersionRequirement{
+						{FeatureID: configv1alpha1.FeatureHelm, MinKubernetesVersion: "v1.28"},
+					},
+				},
+			},
+		}
+
+		Expect(controllers.GetMinKubernetesVersion(clusterSummary, configv1alpha1.FeatureHelm)).To(Equal("v1.28"))
+		Expect(controllers.GetMinKubernetesVersion(clusterSummary, configv1alpha1.FeatureResources)).To(BeEmpty())
+	})
+
+	It("fetchKubernetesVersion returns the cluster version", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"major":"1","minor":"28","gitVersion":"v1.28.3-eks-4f4795d"}`)
+		}))
+		defer server.Close()
+
+		clusterVersion, err := controllers.FetchKubernetesVersion(&rest.Config{Host: server.URL})
+		Expect(err).To(BeNil())
+		Expect(clusterVersion.String()).To(Equal("1.28.3"))
+	})
+
+	It("checkKubernetesVersion marks feature as UnsupportedKubernetesVersion when cluster is too old", func() {
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		clusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+		}
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					KubernetesVersionRequirements: []configv1alpha1.KubernetesVersionRequirement{
+						{FeatureID: configv1alpha1.FeatureHelm, MinKubernetesVersion: "v1.28"},
+					},
+				},
+			},
+		}
+		controllers.SetClusterKubernetesVersion(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Spec.ClusterType, "v1.27.9")
+
+		initObjects := []client.Object{
+			clusterSummary,
+			clusterProfile,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		reconciler := getClusterSummaryReconciler(c, nil)
+		clusterSummaryScope := getClusterSummaryScope(c, logger, clusterProfile, clusterSummary)
+
+		// Resources has no requirement
+		Expect(controllers.CheckKubernetesVersion(reconciler, context.TODO(), clusterSummaryScope,
+			configv1alpha1.FeatureResources, logger)).To(Succeed())
+
+		err := controllers.CheckKubernetesVersion(reconciler, context.TODO(), clusterSummaryScope,
+			configv1alpha1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+		var transientErr *controllers.TransientError
+		Expect(errors.As(err, &transientErr)).To(BeTrue())
+
+		Expect(clusterSummary.Status.FeatureSummaries).To(HaveLen(1))
+		Expect(clusterSummary.Status.FeatureSummaries[0].FeatureID).To(Equal(configv1alpha1.FeatureHelm))
+		Expect(clusterSummary.Status.FeatureSummaries[0].Status).To(
+			Equal(configv1alpha1.FeatureStatusUnsupportedKubernetesVersion))
+		Expect(clusterSummary.Status.FeatureSummaries[0].FailureMessage).ToNot(BeNil())
+
+		controllers.SetClusterKubernetesVersion(clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+			clusterSummary.Spec.ClusterType, "v1.28.0")
+		Expect(controllers.CheckKubernetesVersion(reconciler, context.TODO(), clusterSummaryScope,
+			configv1alpha1.FeatureHelm, logger)).To(Succeed())
+	})
+})
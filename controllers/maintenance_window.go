@@ -0,0 +1,351 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// replicasBeforeMaintenanceAnnotation is added to workloads scaled down during a maintenance
+	// window. It contains the replicas to restore once the window ends.
+	replicasBeforeMaintenanceAnnotation = "projectsveltos.io/replicas-before-maintenance"
+
+	maintenanceStartLayout = "15:04"
+	daysPerWeek            = 7
+)
+
+// getMaintenanceLocation returns the location maintenance windows are evaluated in
+func getMaintenanceLocation(spec *configv1alpha1.Spec) (*time.Location, error) {
+	if spec.MaintenanceTimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(spec.MaintenanceTimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance time zone %q: %w", spec.MaintenanceTimeZone, err)
+	}
+	return loc, nil
+}
+
+// getMaintenanceWindowStarts returns the times, in loc, window starts on each day from firstDay
+// to lastDay days after day (negative values for days before). Days window does not start on are skipped.
+func getMaintenanceWindowStarts(window *configv1alpha1.MaintenanceWindow, day time.Time, loc *time.Location,
+	firstDay, lastDay int) ([]time.Time, error) {
+
+	start, err := time.Parse(maintenanceStartLayout, window.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window start %q: %w", window.Start, err)
+	}
+
+	day = day.In(loc)
+	starts := make([]time.Time, 0)
+	for d := firstDay; d <= lastDay; d++ {
+		candidate := time.Date(day.Year(), day.Month(), day.Day()+d, start.Hour(), start.Minute(), 0, 0, loc)
+		if isMaintenanceWindowDay(window, candidate.Weekday()) {
+			starts = append(starts, candidate)
+		}
+	}
+	return starts, nil
+}
+
+func isMaintenanceWindowDay(window *configv1alpha1.MaintenanceWindow, weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for i := range window.Days {
+		if string(window.Days[i]) == weekday.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// getActiveMaintenanceWindowEnd returns, if any maintenance window is active at now, the time
+// the last active window ends. Returns the zero time if no window is active.
+func getActiveMaintenanceWindowEnd(spec *configv1alpha1.Spec, now time.Time) (time.Time, error) {
+	var end time.Time
+	if len(spec.MaintenanceWindows) == 0 {
+		return end, nil
+	}
+
+	loc, err := getMaintenanceLocation(spec)
+	if err != nil {
+		return end, err
+	}
+
+	for i := range spec.MaintenanceWindows {
+		window := &spec.MaintenanceWindows[i]
+		// A window started on a previous day might still be active
+		daysBack := int(window.Duration.Duration/(24*time.Hour)) + 1
+		starts, err := getMaintenanceWindowStarts(window, now, loc, -daysBack, 0)
+		if err != nil {
+			return end, err
+		}
+		for j := range starts {
+			windowEnd := starts[j].Add(window.Duration.Duration)
+			if !now.Before(starts[j]) && now.Before(windowEnd) && windowEnd.After(end) {
+				end = windowEnd
+			}
+		}
+	}
+
+	return end, nil
+}
+
+// getNextMaintenanceWindowStart returns the time the next maintenance window starts after now.
+// Returns the zero time if no window is defined.
+func getNextMaintenanceWindowStart(spec *configv1alpha1.Spec, now time.Time) (time.Time, error) {
+	var next time.Time
+	if len(spec.MaintenanceWindows) == 0 {
+		return next, nil
+	}
+
+	loc, err := getMaintenanceLocation(spec)
+	if err != nil {
+		return next, err
+	}
+
+	for i := range spec.MaintenanceWindows {
+		starts, err := getMaintenanceWindowStarts(&spec.MaintenanceWindows[i], now, loc, 0, daysPerWeek)
+		if err != nil {
+			return next, err
+		}
+		for j := range starts {
+			if starts[j].After(now) && (next.IsZero() || starts[j].Before(next)) {
+				next = starts[j]
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// reconcileMaintenanceWindows returns true if a maintenance window is currently active, along with
+// the time the ClusterSummary needs to be reconciled again (window end or next window start).
+// When a window starts, workloads are scaled down if requested. When it ends, they are restored.
+// The InMaintenanceWindow condition reflects whether a window is active.
+func (r *ClusterSummaryReconciler) reconcileMaintenanceWindows(ctx context.Context,
+	clusterSummary *configv1alpha1.ClusterSummary, logger logr.Logger) (bool, time.Duration, error) {
+
+	spec := &clusterSummary.Spec.ClusterProfileSpec
+	now := time.Now()
+	condition := meta.FindStatusCondition(clusterSummary.Status.Conditions,
+		configv1alpha1.ClusterSummaryMaintenanceCondition)
+
+	end, err := getActiveMaintenanceWindowEnd(spec, now)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if end.IsZero() {
+		if condition != nil {
+			if condition.Reason == configv1alpha1.ClusterSummaryScaledDownReason {
+				logger.V(logs.LogInfo).Info("maintenance window ended. Restoring workloads")
+				if err := r.scaleMaintenanceWorkloads(ctx, clusterSummary, false, logger); err != nil {
+					return false, 0, err
+				}
+			}
+			meta.RemoveStatusCondition(&clusterSummary.Status.Conditions,
+				configv1alpha1.ClusterSummaryMaintenanceCondition)
+		}
+
+		var requeueAfter time.Duration
+		if spec.ScaleDownDuringMaintenance {
+			// Wake up when next window starts, so workloads are scaled down on time
+			next, err := getNextMaintenanceWindowStart(spec, now)
+			if err != nil {
+				return false, 0, err
+			}
+			if !next.IsZero() {
+				requeueAfter = next.Sub(now)
+			}
+		}
+		return false, requeueAfter, nil
+	}
+
+	reason := configv1alpha1.ClusterSummaryMaintenanceReason
+	if condition != nil {
+		reason = condition.Reason
+	}
+	if spec.ScaleDownDuringMaintenance && reason != configv1alpha1.ClusterSummaryScaledDownReason {
+		logger.V(logs.LogInfo).Info("maintenance window started. Scaling down workloads")
+		if err := r.scaleMaintenanceWorkloads(ctx, clusterSummary, true, logger); err != nil {
+			return true, 0, err
+		}
+		reason = configv1alpha1.ClusterSummaryScaledDownReason
+	}
+
+	meta.SetStatusCondition(&clusterSummary.Status.Conditions, metav1.Condition{
+		Type:               configv1alpha1.ClusterSummaryMaintenanceCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            fmt.Sprintf("maintenance window ends at %s", end.UTC().Format(time.RFC3339)),
+		ObservedGeneration: clusterSummary.Generation,
+	})
+
+	return true, end.Sub(now), nil
+}
+
+// getMaintenanceWorkloads returns the Deployments and StatefulSets deployed in the managed cluster
+// because of PolicyRefs and KustomizationRefs
+func getMaintenanceWorkloads(ctx context.Context, c client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary) ([]configv1alpha1.Resource, error) {
+
+	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || profileOwnerRef == nil {
+		return nil, err
+	}
+
+	workloads := make([]configv1alpha1.Resource, 0)
+	for _, featureID := range []configv1alpha1.FeatureID{configv1alpha1.FeatureResources,
+		configv1alpha1.FeatureKustomize} {
+
+		deployed, err := getClusterConfigurationDeployedResources(ctx, c, clusterSummary, profileOwnerRef, featureID)
+		if err != nil {
+			return nil, err
+		}
+		for key := range deployed {
+			resource := deployed[key]
+			if resource.Group == appsv1.GroupName &&
+				(resource.Kind == "Deployment" || resource.Kind == "StatefulSet") {
+
+				workloads = append(workloads, resource)
+			}
+		}
+	}
+
+	return workloads, nil
+}
+
+// scaleMaintenanceWorkloads scales to zero (scaleDown set) or back to their previous replicas the
+// workloads deployed in the managed cluster
+func (r *ClusterSummaryReconciler) scaleMaintenanceWorkloads(ctx context.Context,
+	clusterSummary *configv1alpha1.ClusterSummary, scaleDown bool, logger logr.Logger) error {
+
+	workloads, err := getMaintenanceWorkloads(ctx, r.Client, clusterSummary)
+	if err != nil {
+		return err
+	}
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	// Workloads were deployed on behalf of the tenant admin, if any. Scale them with same permissions.
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	remoteClient, err := getKubernetesClient(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	for i := range workloads {
+		workload := &unstructured.Unstructured{}
+		workload.SetGroupVersionKind(schema.GroupVersionKind{
+			Group: workloads[i].Group, Version: workloads[i].Version, Kind: workloads[i].Kind})
+		err = remoteClient.Get(ctx, types.NamespacedName{Namespace: workloads[i].Namespace, Name: workloads[i].Name},
+			workload)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		var changed bool
+		if scaleDown {
+			changed, err = scaleDownWorkload(workload)
+		} else {
+			changed, err = restoreWorkload(workload)
+		}
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("scaling %s %s/%s to %d replicas", workload.GetKind(),
+			workload.GetNamespace(), workload.GetName(), getWorkloadReplicas(workload)))
+		if err := remoteClient.Update(ctx, workload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getWorkloadReplicas returns the replicas of a Deployment/StatefulSet. Defaults to 1 when not set.
+func getWorkloadReplicas(workload *unstructured.Unstructured) int64 {
+	replicas, found, err := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+	if err != nil || !found {
+		return 1
+	}
+	return replicas
+}
+
+// scaleDownWorkload sets workload replicas to zero, storing current replicas in an annotation.
+// Returns false if workload was already scaled down.
+func scaleDownWorkload(workload *unstructured.Unstructured) (bool, error) {
+	annotations := workload.GetAnnotations()
+	if _, ok := annotations[replicasBeforeMaintenanceAnnotation]; ok {
+		return false, nil
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[replicasBeforeMaintenanceAnnotation] = strconv.FormatInt(getWorkloadReplicas(workload), 10)
+	workload.SetAnnotations(annotations)
+
+	return true, unstructured.SetNestedField(workload.Object, int64(0), "spec", "replicas")
+}
+
+// restoreWorkload sets workload replicas back to the value stored when it was scaled down.
+// Returns false if workload was not scaled down.
+func restoreWorkload(workload *unstructured.Unstructured) (bool, error) {
+	annotations := workload.GetAnnotations()
+	value, ok := annotations[replicasBeforeMaintenanceAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q: %w", replicasBeforeMaintenanceAnnotation, value, err)
+	}
+
+	delete(annotations, replicasBeforeMaintenanceAnnotation)
+	workload.SetAnnotations(annotations)
+
+	return true, unstructured.SetNestedField(workload.Object, replicas, "spec", "replicas")
+}
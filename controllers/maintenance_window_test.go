@@ -0,0 +1,135 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Maintenance windows", func() {
+	// Wednesday
+	day := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+
+	It("getActiveMaintenanceWindowEnd returns the end of the active window", func() {
+		spec := &configv1alpha1.Spec{
+			MaintenanceWindows: []configv1alpha1.MaintenanceWindow{
+				{Start: "22:00", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			},
+		}
+
+		end, err := controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(12*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(end.IsZero()).To(BeTrue())
+
+		end, err = controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(23*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(end).To(BeTemporally("==", day.Add(26*time.Hour)))
+
+		// Window started the day before
+		end, err = controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(time.Hour))
+		Expect(err).To(BeNil())
+		Expect(end).To(BeTemporally("==", day.Add(2*time.Hour)))
+	})
+
+	It("getActiveMaintenanceWindowEnd considers only the days window starts on", func() {
+		spec := &configv1alpha1.Spec{
+			MaintenanceWindows: []configv1alpha1.MaintenanceWindow{
+				{
+					Days:     []configv1alpha1.Weekday{"Saturday", "Sunday"},
+					Start:    "00:00",
+					Duration: metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+		}
+
+		end, err := controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(12*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(end.IsZero()).To(BeTrue())
+
+		saturday := day.Add(3 * 24 * time.Hour)
+		end, err = controllers.GetActiveMaintenanceWindowEnd(spec, saturday.Add(12*time.Hour))
+		Expect(err).To(BeNil())
+		Expect(end).To(BeTemporally("==", saturday.Add(24*time.Hour)))
+
+		next, err := controllers.GetNextMaintenanceWindowStart(spec, day)
+		Expect(err).To(BeNil())
+		Expect(next).To(BeTemporally("==", saturday))
+	})
+
+	It("maintenance windows are evaluated in MaintenanceTimeZone", func() {
+		spec := &configv1alpha1.Spec{
+			MaintenanceWindows: []configv1alpha1.MaintenanceWindow{
+				{Start: "02:00", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			MaintenanceTimeZone: "Asia/Tokyo",
+		}
+
+		// 02:30 in Tokyo is 17:30 UTC the day before
+		end, err := controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(17*time.Hour+30*time.Minute))
+		Expect(err).To(BeNil())
+		Expect(end).To(BeTemporally("==", day.Add(18*time.Hour)))
+
+		end, err = controllers.GetActiveMaintenanceWindowEnd(spec, day.Add(2*time.Hour+30*time.Minute))
+		Expect(err).To(BeNil())
+		Expect(end.IsZero()).To(BeTrue())
+
+		spec.MaintenanceTimeZone = randomString()
+		_, err = controllers.GetActiveMaintenanceWindowEnd(spec, day)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("scaleDownWorkload and restoreWorkload scale to zero and back", func() {
+		workload := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": randomString(), "namespace": randomString()},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+		}}
+
+		changed, err := controllers.ScaleDownWorkload(workload)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeTrue())
+		Expect(workload.GetAnnotations()).To(HaveKeyWithValue(controllers.ReplicasBeforeMaintenanceAnnotation, "3"))
+		replicas, _, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+		Expect(replicas).To(BeZero())
+
+		// Scaling down again does not lose the original replicas
+		changed, err = controllers.ScaleDownWorkload(workload)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeFalse())
+
+		changed, err = controllers.RestoreWorkload(workload)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeTrue())
+		Expect(workload.GetAnnotations()).ToNot(HaveKey(controllers.ReplicasBeforeMaintenanceAnnotation))
+		replicas, _, _ = unstructured.NestedInt64(workload.Object, "spec", "replicas")
+		Expect(replicas).To(Equal(int64(3)))
+
+		changed, err = controllers.RestoreWorkload(workload)
+		Expect(err).To(BeNil())
+		Expect(changed).To(BeFalse())
+	})
+})
@@ -17,13 +17,25 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
 )
 
 var (
 	managementClusterClient client.Client
 	managementClusterConfig *rest.Config
+	// When set, add-ons and applications are deployed in the management cluster itself
+	// instead of in the matching managed clusters.
+	singleClusterMode bool
 )
 
 func SetManagementClusterAccess(c client.Client, config *rest.Config) {
@@ -38,3 +50,99 @@ func getManagementClusterConfig() *rest.Config {
 func getManagementClusterClient() client.Client {
 	return managementClusterClient
 }
+
+// SetSingleClusterMode when enabled makes the management cluster the target of all deployments.
+// Clusters are still matched as usual but add-ons and applications are deployed in the
+// management cluster. Meant for testing and simple setups.
+func SetSingleClusterMode(enabled bool) {
+	singleClusterMode = enabled
+}
+
+// getKubernetesRestConfig returns the restConfig to access the cluster add-ons and applications
+// are deployed to, as provided by the configured ClusterClientGetter.
+func getKubernetesRestConfig(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+) (*rest.Config, error) {
+
+	return getClusterClientGetter().GetRestConfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+}
+
+// getKubernetesClient returns the client to access the cluster add-ons and applications
+// are deployed to, as provided by the configured ClusterClientGetter.
+func getKubernetesClient(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+) (client.Client, error) {
+
+	return getClusterClientGetter().GetClient(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+}
+
+// getKubeconfigContent returns the kubeconfig to access the cluster add-ons and applications
+// are deployed to, as provided by the configured ClusterClientGetter.
+func getKubeconfigContent(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+) ([]byte, error) {
+
+	return getClusterClientGetter().GetKubeconfig(ctx, c, clusterNamespace, clusterName,
+		adminNamespace, adminName, clusterType, logger)
+}
+
+// restConfigToKubeconfig returns a kubeconfig equivalent to restConfig
+func restConfigToKubeconfig(restConfig *rest.Config) ([]byte, error) {
+	const name = "sveltos"
+
+	proxyURL, err := getRestConfigProxyURL(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[name] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		TLSServerName:            restConfig.ServerName,
+		InsecureSkipTLSVerify:    restConfig.Insecure,
+		CertificateAuthority:     restConfig.CAFile,
+		CertificateAuthorityData: restConfig.CAData,
+		ProxyURL:                 proxyURL,
+	}
+	kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{
+		ClientCertificate:     restConfig.CertFile,
+		ClientCertificateData: restConfig.CertData,
+		ClientKey:             restConfig.KeyFile,
+		ClientKeyData:         restConfig.KeyData,
+		Token:                 restConfig.BearerToken,
+		TokenFile:             restConfig.BearerTokenFile,
+		Username:              restConfig.Username,
+		Password:              restConfig.Password,
+		Impersonate:           restConfig.Impersonate.UserName,
+		ImpersonateGroups:     restConfig.Impersonate.Groups,
+		Exec:                  restConfig.ExecProvider,
+		AuthProvider:          restConfig.AuthProvider,
+	}
+	kubeconfig.Contexts[name] = &clientcmdapi.Context{
+		Cluster:  name,
+		AuthInfo: name,
+	}
+	kubeconfig.CurrentContext = name
+
+	return clientcmd.Write(*kubeconfig)
+}
+
+// getRestConfigProxyURL returns the proxy restConfig uses to reach its API server, or an empty
+// string if the API server is reached directly.
+func getRestConfigProxyURL(restConfig *rest.Config) (string, error) {
+	if restConfig.Proxy == nil {
+		return "", nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, restConfig.Host, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("invalid API server host %q: %w", restConfig.Host, err)
+	}
+	proxyURL, err := restConfig.Proxy(req)
+	if err != nil || proxyURL == nil {
+		return "", err
+	}
+	return proxyURL.String(), nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2/textlogger"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Management cluster", func() {
+	AfterEach(func() {
+		controllers.SetSingleClusterMode(false)
+	})
+
+	It("getKubernetesRestConfig returns management cluster restConfig in single cluster mode", func() {
+		controllers.SetSingleClusterMode(true)
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+		// Cluster does not exist. In single cluster mode, management cluster is used.
+		restConfig, err := controllers.GetKubernetesRestConfig(context.TODO(), testEnv.Client,
+			randomString(), randomString(), "", "", libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(restConfig.Host).To(Equal(testEnv.Config.Host))
+		Expect(restConfig.Impersonate.UserName).To(BeEmpty())
+
+		adminNamespace := randomString()
+		adminName := randomString()
+		restConfig, err = controllers.GetKubernetesRestConfig(context.TODO(), testEnv.Client,
+			randomString(), randomString(), adminNamespace, adminName, libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		Expect(restConfig.Impersonate.UserName).To(Equal(
+			fmt.Sprintf("system:serviceaccount:%s:%s", adminNamespace, adminName)))
+
+		remoteClient, err := controllers.GetKubernetesClient(context.TODO(), testEnv.Client,
+			randomString(), randomString(), "", "", libsveltosv1alpha1.ClusterTypeSveltos, logger)
+		Expect(err).To(BeNil())
+		namespaces := &corev1.NamespaceList{}
+		Expect(remoteClient.List(context.TODO(), namespaces)).To(Succeed())
+	})
+
+	It("restConfigToKubeconfig returns an equivalent kubeconfig", func() {
+		restConfig := rest.CopyConfig(testEnv.Config)
+		restConfig.Impersonate = rest.ImpersonationConfig{UserName: randomString()}
+
+		kubeconfig, err := controllers.RestConfigToKubeconfig(restConfig)
+		Expect(err).To(BeNil())
+
+		currentConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		Expect(err).To(BeNil())
+		Expect(currentConfig.Host).To(Equal(restConfig.Host))
+		Expect(currentConfig.CAData).To(Equal(restConfig.CAData))
+		Expect(currentConfig.CertData).To(Equal(restConfig.CertData))
+		Expect(currentConfig.KeyData).To(Equal(restConfig.KeyData))
+		Expect(currentConfig.Impersonate.UserName).To(Equal(restConfig.Impersonate.UserName))
+	})
+})
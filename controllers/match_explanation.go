@@ -0,0 +1,214 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+)
+
+const (
+	// maxExplainedClusters is the maximum number of clusters listed in a MatchExplanation.
+	// It keeps the ClusterProfile/Profile status within a reasonable size.
+	maxExplainedClusters = 250
+)
+
+// namedSelector is a parsed cluster selector along with the field it comes from
+type namedSelector struct {
+	field    string
+	selector labels.Selector
+}
+
+// explainState contains what is needed to explain whether a cluster is matching.
+// All clusters are indexed by identity.
+type explainState struct {
+	selectors      []namedSelector
+	requiredLabels map[string]string
+	clusterRefs    map[corev1.ObjectReference]bool
+	setClusters    map[corev1.ObjectReference]bool
+	matching       map[corev1.ObjectReference]bool
+	excluded       map[corev1.ObjectReference]bool
+}
+
+// isExplainRequested returns true if the explain annotation is set on the ClusterProfile/Profile
+func isExplainRequested(profile client.Object) bool {
+	_, ok := profile.GetAnnotations()[configv1alpha1.ExplainAnnotation]
+	return ok
+}
+
+// explainMatchingClusters evaluates all clusters (only the ones in namespace when set) and
+// the ones listed in ClusterRefs, and explains why each one is, or is not, matching.
+// setClusters are the clusters selected by the referenced ClusterSets/Sets.
+// MatchingClusterRefs and ExcludedClusterRefs must be already computed.
+func explainMatchingClusters(ctx context.Context, c client.Client, profileScope *scope.ProfileScope,
+	namespace string, setClusters []corev1.ObjectReference, logger logr.Logger) (*configv1alpha1.MatchExplanation, error) {
+
+	explanation := &configv1alpha1.MatchExplanation{}
+
+	status := profileScope.GetStatus()
+	state := &explainState{
+		requiredLabels: profileScope.GetSpec().RequiredClusterLabels,
+		clusterRefs:    getClusterIdentities(profileScope.GetSpec().ClusterRefs),
+		setClusters:    getClusterIdentities(setClusters),
+		matching:       getClusterIdentities(status.MatchingClusterRefs),
+		excluded:       getClusterIdentities(status.ExcludedClusterRefs),
+	}
+
+	if selector := profileScope.GetSelector(); selector != "" {
+		parsedSelector, err := labels.Parse(selector)
+		if err != nil {
+			return nil, err
+		}
+		explanation.ClusterSelector = parsedSelector.String()
+		state.selectors = append(state.selectors, namedSelector{field: "ClusterSelector", selector: parsedSelector})
+	}
+	for i, selector := range profileScope.GetSelectors() {
+		parsedSelector, err := labels.Parse(selector)
+		if err != nil {
+			return nil, err
+		}
+		explanation.ClusterSelectors = append(explanation.ClusterSelectors, parsedSelector.String())
+		state.selectors = append(state.selectors,
+			namedSelector{field: fmt.Sprintf("ClusterSelectors[%d]", i), selector: parsedSelector})
+	}
+
+	clusters, err := clusterproxy.GetListOfClusters(ctx, c, namespace, logger)
+	if err != nil {
+		return nil, err
+	}
+	// ClusterRefs might reference clusters which do not exist
+	clusters = append(clusters, profileScope.GetSpec().ClusterRefs...)
+
+	evaluated := make(map[corev1.ObjectReference]bool, len(clusters))
+	candidates := make([]corev1.ObjectReference, 0, len(clusters))
+	for i := range clusters {
+		identity := getClusterIdentity(&clusters[i])
+		if !evaluated[identity] {
+			evaluated[identity] = true
+			candidates = append(candidates, clusters[i])
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Namespace != candidates[j].Namespace {
+			return candidates[i].Namespace < candidates[j].Namespace
+		}
+		if candidates[i].Name != candidates[j].Name {
+			return candidates[i].Name < candidates[j].Name
+		}
+		return candidates[i].Kind < candidates[j].Kind
+	})
+
+	if len(candidates) > maxExplainedClusters {
+		candidates = candidates[:maxExplainedClusters]
+		explanation.Truncated = true
+	}
+
+	for i := range candidates {
+		clusterExplanation, err := explainCluster(ctx, c, &candidates[i], state)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Clusters = append(explanation.Clusters, *clusterExplanation)
+	}
+
+	return explanation, nil
+}
+
+// explainCluster follows the same steps used to compute matching clusters and reports
+// the outcome for a single cluster
+func explainCluster(ctx context.Context, c client.Client, ref *corev1.ObjectReference,
+	state *explainState) (*configv1alpha1.ClusterExplanation, error) {
+
+	identity := getClusterIdentity(ref)
+	clusterExplanation := &configv1alpha1.ClusterExplanation{
+		Cluster:  *ref,
+		Matching: state.matching[identity],
+	}
+
+	if !isClusterNamespaceAllowed(ref.Namespace) {
+		clusterExplanation.Reason = "cluster namespace is not managed by this controller instance"
+		return clusterExplanation, nil
+	}
+
+	cluster, err := clusterproxy.GetCluster(ctx, c, ref.Namespace, ref.Name, clusterproxy.GetClusterType(ref))
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		cluster = nil
+	}
+
+	var selectedBy string
+	switch {
+	case state.clusterRefs[identity]:
+		selectedBy = "listed in ClusterRefs"
+	case state.setClusters[identity]:
+		selectedBy = "selected by a referenced set"
+	case cluster == nil:
+		clusterExplanation.Reason = "cluster does not exist"
+		return clusterExplanation, nil
+	case !isClusterReadyForSelection(cluster):
+		clusterExplanation.Reason = "cluster is not ready or is being deleted"
+		return clusterExplanation, nil
+	default:
+		for i := range state.selectors {
+			if state.selectors[i].selector.Matches(labels.Set(cluster.GetLabels())) {
+				selectedBy = fmt.Sprintf("matches %s %q", state.selectors[i].field, state.selectors[i].selector.String())
+				break
+			}
+		}
+	}
+
+	if selectedBy == "" {
+		clusterExplanation.Reason = "cluster labels match neither ClusterSelector nor any of ClusterSelectors"
+		return clusterExplanation, nil
+	}
+
+	if len(state.requiredLabels) != 0 &&
+		(cluster == nil || !labels.SelectorFromSet(state.requiredLabels).Matches(labels.Set(cluster.GetLabels()))) {
+
+		clusterExplanation.Reason = selectedBy + " but does not have all RequiredClusterLabels"
+		return clusterExplanation, nil
+	}
+
+	if state.excluded[identity] {
+		clusterExplanation.Reason = selectedBy + " but is beyond ClusterLimit"
+		return clusterExplanation, nil
+	}
+
+	clusterExplanation.Reason = selectedBy
+	return clusterExplanation, nil
+}
+
+func getClusterIdentities(refs []corev1.ObjectReference) map[corev1.ObjectReference]bool {
+	identities := make(map[corev1.ObjectReference]bool, len(refs))
+	for i := range refs {
+		identities[getClusterIdentity(&refs[i])] = true
+	}
+	return identities
+}
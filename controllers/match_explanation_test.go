@@ -0,0 +1,111 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Match explanation", func() {
+	It("explainMatchingClusters reports why each cluster is or is not matching", func() {
+		namespace := randomString()
+
+		getSveltosCluster := func(name string, clusterLabels map[string]string, ready bool) *libsveltosv1alpha1.SveltosCluster {
+			return &libsveltosv1alpha1.SveltosCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: clusterLabels},
+				Status:     libsveltosv1alpha1.SveltosClusterStatus{Ready: ready},
+			}
+		}
+		getClusterRef := func(name string) corev1.ObjectReference {
+			return corev1.ObjectReference{
+				Namespace: namespace, Name: name,
+				Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+			}
+		}
+
+		matching := getSveltosCluster("a", map[string]string{"env": "prod", "tier": "gold"}, true)
+		missingLabel := getSveltosCluster("b", map[string]string{"env": "prod"}, true)
+		notMatching := getSveltosCluster("c", map[string]string{"env": "dev"}, true)
+		notReady := getSveltosCluster("d", map[string]string{"env": "prod", "tier": "gold"}, false)
+
+		clusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        randomString(),
+				Annotations: map[string]string{configv1alpha1.ExplainAnnotation: "true"},
+			},
+			Spec: configv1alpha1.Spec{
+				ClusterSelector:       libsveltosv1alpha1.Selector("env = prod"),
+				RequiredClusterLabels: map[string]string{"tier": "gold"},
+				ClusterRefs:           []corev1.ObjectReference{getClusterRef("e")},
+			},
+			Status: configv1alpha1.Status{
+				MatchingClusterRefs: []corev1.ObjectReference{getClusterRef("a")},
+			},
+		}
+		Expect(addTypeInformationToObject(scheme, clusterProfile)).To(Succeed())
+
+		initObjects := []client.Object{matching, missingLabel, notMatching, notReady, clusterProfile}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).
+			WithObjects(initObjects...).Build()
+
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		explanation, err := controllers.ExplainMatchingClusters(context.TODO(), c, profileScope, "", nil,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(explanation.ClusterSelector).To(Equal("env=prod"))
+		Expect(explanation.Truncated).To(BeFalse())
+		Expect(explanation.Clusters).To(HaveLen(5))
+
+		expected := map[string]struct {
+			matching bool
+			reason   string
+		}{
+			"a": {true, `matches ClusterSelector "env=prod"`},
+			"b": {false, `matches ClusterSelector "env=prod" but does not have all RequiredClusterLabels`},
+			"c": {false, "cluster labels match neither ClusterSelector nor any of ClusterSelectors"},
+			"d": {false, "cluster is not ready or is being deleted"},
+			"e": {false, "listed in ClusterRefs but does not have all RequiredClusterLabels"},
+		}
+		for i := range explanation.Clusters {
+			clusterExplanation := &explanation.Clusters[i]
+			Expect(expected).To(HaveKey(clusterExplanation.Cluster.Name))
+			Expect(clusterExplanation.Matching).To(Equal(expected[clusterExplanation.Cluster.Name].matching))
+			Expect(clusterExplanation.Reason).To(Equal(expected[clusterExplanation.Cluster.Name].reason))
+		}
+	})
+})
@@ -14,6 +14,7 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"strings"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
@@ -46,12 +48,26 @@ var (
 			Buckets:   []float64{1, 10, 30, 60, 120, 180, 240},
 		},
 	)
+
+	// featureHashChangesCounter counts, per (Cluster)Profile and feature, how many times the
+	// configuration of a deployed feature changed. Each matching cluster counts a change once.
+	// A steadily increasing rate indicates a flapping configuration, for instance a ConfigMap
+	// rewritten in a loop or a non-deterministic template.
+	featureHashChangesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "projectsveltos",
+			Name:      "feature_hash_changes_total",
+			Help:      "Number of times the configuration hash of a profile feature changed",
+		},
+		[]string{"profile_kind", "profile_namespace", "profile_name", "feature"},
+	)
 )
 
 //nolint:gochecknoinits // forced pattern, can't workaround
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram)
+	metrics.Registry.MustRegister(programResourceDurationHistogram, programChartDurationHistogram,
+		featureHashChangesCounter)
 }
 
 func newResourceHistogram(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType,
@@ -118,6 +134,111 @@ func newChartHistogram(clusterNamespace, clusterName string, clusterType libsvel
 	return histogram
 }
 
+// workqueueMetricsProvider is a workqueue.MetricsProvider reporting to the same workqueue collectors
+// controller-runtime registers for the default controller queues.
+// Controller-runtime does not expose its provider. So collectors with the very same options are
+// registered here, and the ones already registered by controller-runtime are used instead.
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return getWorkqueueGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.DepthKey,
+		Help:      "Current depth of workqueue",
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return getWorkqueueCounterVec(prometheus.CounterOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.AddsKey,
+		Help:      "Total number of adds handled by workqueue",
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return getWorkqueueHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.QueueLatencyKey,
+		Help:      "How long in seconds an item stays in workqueue before being requested",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return getWorkqueueHistogramVec(prometheus.HistogramOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.WorkDurationKey,
+		Help:      "How long in seconds processing an item from workqueue takes.",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return getWorkqueueGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.UnfinishedWorkKey,
+		Help: "How many seconds of work has been done that " +
+			"is in progress and hasn't been observed by work_duration. Large " +
+			"values indicate stuck threads. One can deduce the number of stuck " +
+			"threads by observing the rate at which this increases.",
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return getWorkqueueGaugeVec(prometheus.GaugeOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.LongestRunningProcessorKey,
+		Help: "How many seconds has the longest running " +
+			"processor for workqueue been running.",
+	}).WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return getWorkqueueCounterVec(prometheus.CounterOpts{
+		Subsystem: metrics.WorkQueueSubsystem,
+		Name:      metrics.RetriesKey,
+		Help:      "Total number of retries handled by workqueue",
+	}).WithLabelValues(name)
+}
+
+// getWorkqueueCollector registers collector. If an identical collector is already registered,
+// that one is returned instead.
+func getWorkqueueCollector(collector prometheus.Collector) prometheus.Collector {
+	err := metrics.Registry.Register(collector)
+	if err != nil {
+		var registrationError prometheus.AlreadyRegisteredError
+		if errors.As(err, &registrationError) {
+			return registrationError.ExistingCollector
+		}
+	}
+	return collector
+}
+
+func getWorkqueueGaugeVec(opts prometheus.GaugeOpts) *prometheus.GaugeVec {
+	gaugeVec := prometheus.NewGaugeVec(opts, []string{"name"})
+	if existing, ok := getWorkqueueCollector(gaugeVec).(*prometheus.GaugeVec); ok {
+		return existing
+	}
+	return gaugeVec
+}
+
+func getWorkqueueCounterVec(opts prometheus.CounterOpts) *prometheus.CounterVec {
+	counterVec := prometheus.NewCounterVec(opts, []string{"name"})
+	if existing, ok := getWorkqueueCollector(counterVec).(*prometheus.CounterVec); ok {
+		return existing
+	}
+	return counterVec
+}
+
+func getWorkqueueHistogramVec(opts prometheus.HistogramOpts) *prometheus.HistogramVec {
+	histogramVec := prometheus.NewHistogramVec(opts, []string{"name"})
+	if existing, ok := getWorkqueueCollector(histogramVec).(*prometheus.HistogramVec); ok {
+		return existing
+	}
+	return histogramVec
+}
+
 func logCollectorError(err error, logger logr.Logger) {
 	logger.V(logs.LogVerbose).Info(fmt.Sprintf("failed to register collector: %s", err))
 }
@@ -143,3 +264,29 @@ func programDuration(elapsed time.Duration, clusterNamespace, clusterName, featu
 		}
 	}
 }
+
+// recordFeatureHashChange increments the hash changes counter of the (Cluster)Profile owning
+// clusterSummary if the feature configuration changed. A missing previous hash (feature never
+// deployed or redeployment explicitly requested) is not a change.
+func recordFeatureHashChange(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID,
+	previousHash, currentHash []byte, logger logr.Logger) {
+
+	if previousHash == nil || bytes.Equal(previousHash, currentHash) {
+		return
+	}
+
+	profileOwnerRef, err := configv1alpha1.GetProfileOwnerReference(clusterSummary)
+	if err != nil || profileOwnerRef == nil {
+		logger.V(logs.LogVerbose).Info("failed to get profile owner. Not recording hash change")
+		return
+	}
+
+	// Profiles only match clusters in their own namespace, which is also the ClusterSummary one
+	var profileNamespace string
+	if profileOwnerRef.Kind == configv1alpha1.ProfileKind {
+		profileNamespace = clusterSummary.Namespace
+	}
+
+	featureHashChangesCounter.WithLabelValues(profileOwnerRef.Kind, profileNamespace, profileOwnerRef.Name,
+		string(featureID)).Inc()
+}
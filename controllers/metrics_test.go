@@ -0,0 +1,65 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Metrics", func() {
+	It("recordFeatureHashChange counts configuration changes only", func() {
+		profileName := randomString()
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: configv1alpha1.GroupVersion.String(),
+						Kind:       configv1alpha1.ProfileKind,
+						Name:       profileName,
+					},
+				},
+			},
+		}
+
+		counter := controllers.FeatureHashChangesCounter.WithLabelValues(configv1alpha1.ProfileKind,
+			clusterSummary.Namespace, profileName, string(configv1alpha1.FeatureHelm))
+
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// First deployment
+		controllers.RecordFeatureHashChange(clusterSummary, configv1alpha1.FeatureHelm, nil, []byte("a"), logger)
+		Expect(testutil.ToFloat64(counter)).To(Equal(float64(0)))
+
+		// Same configuration
+		controllers.RecordFeatureHashChange(clusterSummary, configv1alpha1.FeatureHelm, []byte("a"), []byte("a"), logger)
+		Expect(testutil.ToFloat64(counter)).To(Equal(float64(0)))
+
+		controllers.RecordFeatureHashChange(clusterSummary, configv1alpha1.FeatureHelm, []byte("a"), []byte("b"), logger)
+		controllers.RecordFeatureHashChange(clusterSummary, configv1alpha1.FeatureHelm, []byte("b"), []byte("a"), logger)
+		Expect(testutil.ToFloat64(counter)).To(Equal(float64(2)))
+	})
+})
@@ -0,0 +1,47 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+// runRecoveringPanic invokes work, which deploys to or removes from a managed cluster, and converts
+// any panic into an error. Deployments run in the deployer worker goroutines, where a panic would
+// otherwise crash the controller. The error is reported as deployment result, hence recorded in
+// the ClusterSummary status, and counts as a severe failure for the cluster circuit breaker.
+func runRecoveringPanic(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType,
+	featureID string, logger logr.Logger, work func() error) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("recovered from panic processing feature %s: %v\n%s",
+				featureID, r, debug.Stack()))
+			recordClusterSevereFailure(clusterNamespace, clusterName, clusterType, time.Now())
+			err = fmt.Errorf("internal error (panic) processing feature %s: %v", featureID, r)
+		}
+	}()
+
+	return work()
+}
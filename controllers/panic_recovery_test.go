@@ -0,0 +1,50 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog/v2/textlogger"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("PanicRecovery", func() {
+	It("runRecoveringPanic returns work error", func() {
+		workErr := fmt.Errorf("%s", randomString())
+		err := controllers.RunRecoveringPanic(randomString(), randomString(), libsveltosv1alpha1.ClusterTypeCapi,
+			string(configv1alpha1.FeatureHelm), textlogger.NewLogger(textlogger.NewConfig()), func() error { return workErr })
+		Expect(err).To(Equal(workErr))
+	})
+
+	It("runRecoveringPanic converts a panic into an error", func() {
+		msg := randomString()
+		var err error
+		Expect(func() {
+			err = controllers.RunRecoveringPanic(randomString(), randomString(), libsveltosv1alpha1.ClusterTypeCapi,
+				string(configv1alpha1.FeatureResources), textlogger.NewLogger(textlogger.NewConfig()), func() error { panic(msg) })
+		}).ToNot(Panic())
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring(msg))
+		Expect(err.Error()).To(ContainSubstring(string(configv1alpha1.FeatureResources)))
+	})
+})
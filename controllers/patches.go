@@ -0,0 +1,154 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+)
+
+const (
+	patchesDir            = "/patches"
+	patchesResourcesFile  = "resources.yaml"
+	patchesKustomization  = "kustomization.yaml"
+	patchesYAMLSeparator  = "---\n"
+	emptyPatchSelectorMsg = "patch target must set at least one of kind, name, labelSelector or annotationSelector"
+)
+
+// getFeaturePatches returns the patches to apply to the resources deployed because of featureID
+func getFeaturePatches(clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID) []configv1alpha1.Patch {
+
+	var patches []configv1alpha1.Patch
+	for i := range clusterSummary.Spec.ClusterProfileSpec.Patches {
+		if clusterSummary.Spec.ClusterProfileSpec.Patches[i].FeatureID == featureID {
+			patches = append(patches, clusterSummary.Spec.ClusterProfileSpec.Patches[i])
+		}
+	}
+	return patches
+}
+
+// validatePatchTarget returns an error if target does not select resources in a meaningful way
+func validatePatchTarget(target *configv1alpha1.PatchSelector) error {
+	if target.Kind == "" && target.Name == "" && target.LabelSelector == "" && target.AnnotationSelector == "" {
+		return fmt.Errorf("%s", emptyPatchSelectorMsg)
+	}
+	if target.Name != "" {
+		if _, err := regexp.Compile(target.Name); err != nil {
+			return fmt.Errorf("invalid patch target name %q: %w", target.Name, err)
+		}
+	}
+	if target.LabelSelector != "" {
+		if _, err := labels.Parse(target.LabelSelector); err != nil {
+			return fmt.Errorf("invalid patch target labelSelector %q: %w", target.LabelSelector, err)
+		}
+	}
+	if target.AnnotationSelector != "" {
+		if _, err := labels.Parse(target.AnnotationSelector); err != nil {
+			return fmt.Errorf("invalid patch target annotationSelector %q: %w", target.AnnotationSelector, err)
+		}
+	}
+	return nil
+}
+
+// applyPatches applies the patches defined for featureID to resources. Patches are applied
+// using kustomize, so both strategic merge and JSON6902 patches are supported.
+// Resources not matching any patch target are returned unchanged.
+func applyPatches(resources []*unstructured.Unstructured, featureID configv1alpha1.FeatureID,
+	clusterSummary *configv1alpha1.ClusterSummary) ([]*unstructured.Unstructured, error) {
+
+	patches := getFeaturePatches(clusterSummary, featureID)
+	if len(patches) == 0 || len(resources) == 0 {
+		return resources, nil
+	}
+
+	kustomization := kustomizetypes.Kustomization{
+		Resources: []string{patchesResourcesFile},
+	}
+	for i := range patches {
+		if err := validatePatchTarget(&patches[i].Target); err != nil {
+			return nil, err
+		}
+		kustomization.Patches = append(kustomization.Patches, kustomizetypes.Patch{
+			Patch:  patches[i].Patch,
+			Target: getKustomizeSelector(&patches[i].Target),
+		})
+	}
+
+	content := ""
+	for i := range resources {
+		// JSON is valid YAML
+		data, err := resources[i].MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		content += patchesYAMLSeparator + string(data) + "\n"
+	}
+
+	kustomizationData, err := json.Marshal(kustomization)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := filesys.MakeFsInMemory()
+	if err := fs.WriteFile(filepath.Join(patchesDir, patchesResourcesFile), []byte(content)); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFile(filepath.Join(patchesDir, patchesKustomization), kustomizationData); err != nil {
+		return nil, err
+	}
+
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(fs, patchesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patches: %w", err)
+	}
+
+	patched := make([]*unstructured.Unstructured, resMap.Size())
+	for i, r := range resMap.Resources() {
+		m, err := r.Map()
+		if err != nil {
+			return nil, err
+		}
+		patched[i] = &unstructured.Unstructured{Object: m}
+	}
+
+	return patched, nil
+}
+
+func getKustomizeSelector(target *configv1alpha1.PatchSelector) *kustomizetypes.Selector {
+	return &kustomizetypes.Selector{
+		ResId: resid.ResId{
+			Gvk:       resid.Gvk{Group: target.Group, Version: target.Version, Kind: target.Kind},
+			Name:      target.Name,
+			Namespace: target.Namespace,
+		},
+		LabelSelector:      target.LabelSelector,
+		AnnotationSelector: target.AnnotationSelector,
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	"github.com/projectsveltos/libsveltos/lib/utils"
+)
+
+const (
+	patchDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    app: nginx
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.14.2`
+
+	patchServiceAccount = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: %s
+  namespace: %s`
+)
+
+var _ = Describe("Patches", func() {
+	It("applyPatches applies strategic merge and JSON6902 patches to matching resources only", func() {
+		namespace := randomString()
+		depl, err := utils.GetUnstructured([]byte(fmt.Sprintf(patchDeployment, randomString(), namespace)))
+		Expect(err).To(BeNil())
+		serviceAccount, err := utils.GetUnstructured([]byte(fmt.Sprintf(patchServiceAccount, randomString(), namespace)))
+		Expect(err).To(BeNil())
+
+		registry := randomString()
+		clusterSummary := &configv1alpha1.ClusterSummary{}
+		clusterSummary.Spec.ClusterProfileSpec.Patches = []configv1alpha1.Patch{
+			{
+				FeatureID: configv1alpha1.FeatureResources,
+				Target:    configv1alpha1.PatchSelector{Kind: "Deployment"},
+				Patch: fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: not-important
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: %s/nginx:1.14.2`, registry),
+			},
+			{
+				FeatureID: configv1alpha1.FeatureResources,
+				Target:    configv1alpha1.PatchSelector{LabelSelector: "app=nginx"},
+				Patch: `- op: replace
+  path: /spec/replicas
+  value: 3`,
+			},
+			{
+				// Patches for other features are ignored
+				FeatureID: configv1alpha1.FeatureKustomize,
+				Target:    configv1alpha1.PatchSelector{Kind: "ServiceAccount"},
+				Patch: `- op: add
+  path: /automountServiceAccountToken
+  value: false`,
+			},
+		}
+
+		patched, err := controllers.ApplyPatches([]*unstructured.Unstructured{depl, serviceAccount},
+			configv1alpha1.FeatureResources, clusterSummary)
+		Expect(err).To(BeNil())
+		Expect(len(patched)).To(Equal(2))
+
+		var currentDepl appsv1.Deployment
+		Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(patched[0].UnstructuredContent(),
+			&currentDepl)).To(Succeed())
+		Expect(currentDepl.Name).To(Equal(depl.GetName()))
+		Expect(*currentDepl.Spec.Replicas).To(Equal(int32(3)))
+		Expect(currentDepl.Spec.Template.Spec.Containers[0].Image).To(Equal(registry + "/nginx:1.14.2"))
+
+		Expect(patched[1].Object).To(Equal(serviceAccount.Object))
+	})
+
+	It("validatePatchTarget returns an error for targets not selecting resources", func() {
+		Expect(controllers.ValidatePatchTarget(&configv1alpha1.PatchSelector{})).ToNot(BeNil())
+		Expect(controllers.ValidatePatchTarget(&configv1alpha1.PatchSelector{Namespace: randomString()})).ToNot(BeNil())
+		Expect(controllers.ValidatePatchTarget(&configv1alpha1.PatchSelector{LabelSelector: "app in (("})).ToNot(BeNil())
+		Expect(controllers.ValidatePatchTarget(&configv1alpha1.PatchSelector{Kind: "Deployment"})).To(BeNil())
+		Expect(controllers.ValidatePatchTarget(&configv1alpha1.PatchSelector{LabelSelector: "app=nginx"})).To(BeNil())
+	})
+})
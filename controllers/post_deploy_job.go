@@ -0,0 +1,285 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/gdexlab/go-render/render"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// postDeployJobHashAnnotation is set on each PostDeployJob created in a managed cluster.
+	// It contains the hash of the Job specification and it is used to detect when the Job
+	// needs to be recreated.
+	postDeployJobHashAnnotation = "projectsveltos.io/post-deploy-job-hash"
+
+	// postDeployJobLabel is set on each PostDeployJob created in a managed cluster. Its value
+	// is the feature the Job was created for.
+	postDeployJobLabel = "projectsveltos.io/post-deploy-job"
+
+	// postDeployJobOwnerAnnotation is set on each PostDeployJob created in a managed cluster.
+	// Its value is the ClusterSummary (namespace/name) the Job was created for.
+	postDeployJobOwnerAnnotation = "projectsveltos.io/post-deploy-job-owner"
+
+	jobKind = "Job"
+)
+
+// runPostDeployJobs creates, in the managed cluster, all PostDeployJobs registered for the feature
+// (Helm/Kustomize/Resources) and verifies those have completed. Jobs previously created for the
+// feature and not registered anymore are removed.
+// Returns a ProvisioningError if any Job is still running. Returns a NonRetriableError if any Job failed.
+func runPostDeployJobs(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID, logger logr.Logger) error {
+
+	current := make(map[types.NamespacedName]bool)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		job := &clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+		if job.FeatureID == featureID {
+			current[types.NamespacedName{Namespace: job.Namespace, Name: job.Name}] = true
+		}
+	}
+
+	if err := removePostDeployJobs(ctx, c, clusterSummary, featureID, current, logger); err != nil {
+		return err
+	}
+
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		job := &clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+
+		if job.FeatureID != featureID {
+			continue
+		}
+
+		if err := runPostDeployJob(ctx, c, clusterSummary, job, logger); err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("post deploy job: %s", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runPostDeployJob(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	postDeployJob *configv1alpha1.PostDeployJob, logger logr.Logger) error {
+
+	if postDeployJob.Namespace == "" {
+		return &NonRetriableError{
+			Message: fmt.Sprintf("post deploy job %s: namespace must be set", postDeployJob.Name),
+		}
+	}
+
+	namespace := postDeployJob.Namespace
+	hash := getPostDeployJobHash(postDeployJob)
+
+	l := logger.WithValues("job", fmt.Sprintf("%s/%s", namespace, postDeployJob.Name))
+
+	job := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: postDeployJob.Name}, job)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		l.V(logs.LogDebug).Info("creating post deploy job")
+		err = createPostDeployJob(ctx, c, clusterSummary, postDeployJob, hash)
+		if err != nil {
+			return err
+		}
+		return &ProvisioningError{
+			Message: fmt.Sprintf("post deploy job %s/%s created. Waiting for it to complete",
+				namespace, postDeployJob.Name),
+		}
+	}
+
+	if !isPostDeployJobOwner(job, clusterSummary) {
+		return deployer.NewConflictError(
+			fmt.Sprintf("Job %s/%s already exists and was not created by ClusterSummary %s/%s",
+				namespace, postDeployJob.Name, clusterSummary.Namespace, clusterSummary.Name))
+	}
+
+	if job.Annotations[postDeployJobHashAnnotation] != hash {
+		// Job spec is immutable. Delete current Job so it gets recreated with the new specification.
+		l.V(logs.LogDebug).Info("post deploy job changed. Deleting it")
+		err = c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return &ProvisioningError{
+			Message: fmt.Sprintf("post deploy job %s/%s changed. Waiting for it to be recreated",
+				namespace, postDeployJob.Name),
+		}
+	}
+
+	return getPostDeployJobStatus(job)
+}
+
+func createPostDeployJob(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	postDeployJob *configv1alpha1.PostDeployJob, hash string) error {
+
+	if err := createNamespace(ctx, c, clusterSummary, postDeployJob.Namespace); err != nil {
+		return err
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: postDeployJob.Namespace,
+			Name:      postDeployJob.Name,
+			Labels: map[string]string{
+				postDeployJobLabel:      string(postDeployJob.FeatureID),
+				ClusterSummaryLabelName: clusterSummary.Name,
+			},
+			Annotations: map[string]string{
+				postDeployJobHashAnnotation:  hash,
+				postDeployJobOwnerAnnotation: getPostDeployJobOwner(clusterSummary),
+			},
+		},
+		Spec: *postDeployJob.JobSpec.DeepCopy(),
+	}
+	return c.Create(ctx, job)
+}
+
+// removePostDeployJobs deletes, from the managed cluster, the Jobs created for clusterSummary and featureID
+// which are not in current. Passing no current Jobs removes all of them.
+// No action in DryRun mode.
+func removePostDeployJobs(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID, current map[types.NamespacedName]bool, logger logr.Logger) error {
+
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeDryRun {
+		return nil
+	}
+
+	jobs := &batchv1.JobList{}
+	err := c.List(ctx, jobs, client.MatchingLabels{
+		postDeployJobLabel:      string(featureID),
+		ClusterSummaryLabelName: clusterSummary.Name,
+	})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("not allowed to list post deploy jobs: %v", err))
+			return nil
+		}
+		return err
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if current[types.NamespacedName{Namespace: job.Namespace, Name: job.Name}] ||
+			!isPostDeployJobOwner(job, clusterSummary) {
+
+			continue
+		}
+
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("removing post deploy job %s/%s", job.Namespace, job.Name))
+		err = c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appendPostDeployJobResources returns a copy of resources with, appended, the Jobs clusterSummary
+// creates in the managed cluster for featureID. Jobs are reported in the ClusterConfiguration with
+// the ClusterSummary as Owner.
+func appendPostDeployJobResources(resources []configv1alpha1.Resource, clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID) []configv1alpha1.Resource {
+
+	resources = append([]configv1alpha1.Resource{}, resources...)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs {
+		job := &clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[i]
+		if job.FeatureID != featureID {
+			continue
+		}
+
+		resources = append(resources, configv1alpha1.Resource{
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			Group:     batchv1.SchemeGroupVersion.Group,
+			Version:   batchv1.SchemeGroupVersion.Version,
+			Kind:      jobKind,
+			Owner: corev1.ObjectReference{
+				APIVersion: configv1alpha1.GroupVersion.String(),
+				Kind:       configv1alpha1.ClusterSummaryKind,
+				Namespace:  clusterSummary.Namespace,
+				Name:       clusterSummary.Name,
+			},
+		})
+	}
+
+	return resources
+}
+
+// isPostDeployJobResource returns true if resource, reported in the ClusterConfiguration,
+// is a PostDeployJob
+func isPostDeployJobResource(resource *configv1alpha1.Resource) bool {
+	return resource.Kind == jobKind && resource.Group == batchv1.SchemeGroupVersion.Group &&
+		resource.Owner.Kind == configv1alpha1.ClusterSummaryKind
+}
+
+func isPostDeployJobOwner(job *batchv1.Job, clusterSummary *configv1alpha1.ClusterSummary) bool {
+	return job.Labels[ClusterSummaryLabelName] == clusterSummary.Name &&
+		job.Annotations[postDeployJobOwnerAnnotation] == getPostDeployJobOwner(clusterSummary)
+}
+
+func getPostDeployJobOwner(clusterSummary *configv1alpha1.ClusterSummary) string {
+	return fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name)
+}
+
+// getPostDeployJobStatus returns nil if job has completed, a NonRetriableError if job has failed
+// and a ProvisioningError otherwise
+func getPostDeployJobStatus(job *batchv1.Job) error {
+	for i := range job.Status.Conditions {
+		condition := &job.Status.Conditions[i]
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return nil
+		case batchv1.JobFailed:
+			return &NonRetriableError{
+				Message: fmt.Sprintf("post deploy job %s/%s failed: %s. Logs: kubectl logs -n %s job/%s",
+					job.Namespace, job.Name, condition.Message, job.Namespace, job.Name),
+			}
+		}
+	}
+
+	return &ProvisioningError{
+		Message: fmt.Sprintf("post deploy job %s/%s has not completed yet", job.Namespace, job.Name),
+	}
+}
+
+func getPostDeployJobHash(postDeployJob *configv1alpha1.PostDeployJob) string {
+	h := sha256.New()
+	h.Write([]byte(render.AsCode(postDeployJob.JobSpec)))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+var _ = Describe("PostDeployJobs", func() {
+	var clusterSummary *configv1alpha1.ClusterSummary
+	var postDeployJob configv1alpha1.PostDeployJob
+
+	BeforeEach(func() {
+		postDeployJob = configv1alpha1.PostDeployJob{
+			Name:      randomString(),
+			Namespace: randomString(),
+			FeatureID: configv1alpha1.FeatureHelm,
+			JobSpec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers: []corev1.Container{
+							{Name: "init", Image: "busybox", Command: []string{"true"}},
+						},
+					},
+				},
+			},
+		}
+
+		clusterSummary = &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: randomString(),
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					PostDeployJobs: []configv1alpha1.PostDeployJob{postDeployJob},
+				},
+			},
+		}
+	})
+
+	It("runPostDeployJobs creates Job and waits for it to complete", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// Jobs registered for other features are ignored
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureResources, logger)).To(Succeed())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		job := &batchv1.Job{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: postDeployJob.Namespace, Name: postDeployJob.Name}, job)).To(Succeed())
+		Expect(job.Annotations[controllers.PostDeployJobHashAnnotation]).ToNot(BeEmpty())
+		Expect(job.Labels[controllers.PostDeployJobLabel]).To(Equal(string(configv1alpha1.FeatureHelm)))
+		Expect(job.Labels[controllers.ClusterSummaryLabelName]).To(Equal(clusterSummary.Name))
+		Expect(job.Annotations[controllers.PostDeployJobOwnerAnnotation]).To(
+			Equal(clusterSummary.Namespace + "/" + clusterSummary.Name))
+
+		// Job is still running
+		err := controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary, configv1alpha1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+		var provisioningError *controllers.ProvisioningError
+		Expect(errors.As(err, &provisioningError)).To(BeTrue())
+
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}
+		Expect(c.Status().Update(context.TODO(), job)).To(Succeed())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).To(Succeed())
+	})
+
+	It("runPostDeployJobs returns a NonRetriableError when Job fails", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		job := &batchv1.Job{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: postDeployJob.Namespace, Name: postDeployJob.Name}, job)).To(Succeed())
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: randomString()},
+		}
+		Expect(c.Status().Update(context.TODO(), job)).To(Succeed())
+
+		err := controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary, configv1alpha1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(job.Status.Conditions[0].Message))
+		Expect(err.Error()).To(ContainSubstring("kubectl logs"))
+	})
+
+	It("runPostDeployJobs deletes Job when its specification changes", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[0].JobSpec.Template.Spec.Containers[0].Image = randomString()
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		job := &batchv1.Job{}
+		err := c.Get(context.TODO(),
+			types.NamespacedName{Namespace: postDeployJob.Namespace, Name: postDeployJob.Name}, job)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("runPostDeployJobs returns a NonRetriableError when Job namespace is not set", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[0].Namespace = ""
+		err := controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary, configv1alpha1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+		var nonRetriableError *controllers.NonRetriableError
+		Expect(errors.As(err, &nonRetriableError)).To(BeTrue())
+
+		jobs := &batchv1.JobList{}
+		Expect(c.List(context.TODO(), jobs)).To(Succeed())
+		Expect(jobs.Items).To(BeEmpty())
+	})
+
+	It("runPostDeployJobs returns a ConflictError when Job was created for another ClusterSummary", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		// Another profile registers a Job with same namespace/name
+		otherClusterSummary := clusterSummary.DeepCopy()
+		otherClusterSummary.Name = randomString()
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, otherClusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		err := controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary, configv1alpha1.FeatureHelm, logger)
+		Expect(err).ToNot(BeNil())
+		var conflictErr *deployer.ConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+
+		// Job created for the other ClusterSummary is left untouched
+		job := &batchv1.Job{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: postDeployJob.Namespace, Name: postDeployJob.Name}, job)).To(Succeed())
+		Expect(job.Labels[controllers.ClusterSummaryLabelName]).To(Equal(otherClusterSummary.Name))
+	})
+
+	It("runPostDeployJobs removes Jobs not registered anymore", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		clusterSummary.Spec.ClusterProfileSpec.PostDeployJobs = nil
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).To(Succeed())
+
+		job := &batchv1.Job{}
+		err := c.Get(context.TODO(),
+			types.NamespacedName{Namespace: postDeployJob.Namespace, Name: postDeployJob.Name}, job)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("removePostDeployJobs removes only Jobs created for ClusterSummary and feature", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		logger := textlogger.NewLogger(textlogger.NewConfig())
+
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, logger)).ToNot(Succeed())
+
+		// Job registered for the Resources feature by another ClusterSummary
+		otherClusterSummary := clusterSummary.DeepCopy()
+		otherClusterSummary.Name = randomString()
+		otherClusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[0].Name = randomString()
+		otherClusterSummary.Spec.ClusterProfileSpec.PostDeployJobs[0].FeatureID = configv1alpha1.FeatureResources
+		Expect(controllers.RunPostDeployJobs(context.TODO(), c, otherClusterSummary,
+			configv1alpha1.FeatureResources, logger)).ToNot(Succeed())
+
+		Expect(controllers.RemovePostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureResources, nil, logger)).To(Succeed())
+		Expect(controllers.RemovePostDeployJobs(context.TODO(), c, otherClusterSummary,
+			configv1alpha1.FeatureHelm, nil, logger)).To(Succeed())
+		jobs := &batchv1.JobList{}
+		Expect(c.List(context.TODO(), jobs)).To(Succeed())
+		Expect(len(jobs.Items)).To(Equal(2))
+
+		Expect(controllers.RemovePostDeployJobs(context.TODO(), c, clusterSummary,
+			configv1alpha1.FeatureHelm, nil, logger)).To(Succeed())
+		Expect(c.List(context.TODO(), jobs)).To(Succeed())
+		Expect(len(jobs.Items)).To(Equal(1))
+		Expect(jobs.Items[0].Labels[controllers.ClusterSummaryLabelName]).To(Equal(otherClusterSummary.Name))
+	})
+
+	It("appendPostDeployJobResources reports Jobs registered for the feature", func() {
+		resources := []configv1alpha1.Resource{
+			{Name: randomString(), Namespace: randomString(), Kind: "ConfigMap", Version: "v1"},
+		}
+
+		result := controllers.AppendPostDeployJobResources(resources, clusterSummary, configv1alpha1.FeatureResources)
+		Expect(result).To(Equal(resources))
+
+		result = controllers.AppendPostDeployJobResources(resources, clusterSummary, configv1alpha1.FeatureHelm)
+		Expect(len(result)).To(Equal(2))
+		Expect(len(resources)).To(Equal(1))
+		Expect(result[1].Kind).To(Equal("Job"))
+		Expect(result[1].Group).To(Equal(batchv1.SchemeGroupVersion.Group))
+		Expect(result[1].Namespace).To(Equal(postDeployJob.Namespace))
+		Expect(result[1].Name).To(Equal(postDeployJob.Name))
+		Expect(result[1].Owner.Kind).To(Equal(configv1alpha1.ClusterSummaryKind))
+		Expect(result[1].Owner.Name).To(Equal(clusterSummary.Name))
+		Expect(controllers.IsPostDeployJobResource(&result[1])).To(BeTrue())
+		Expect(controllers.IsPostDeployJobResource(&result[0])).To(BeFalse())
+	})
+})
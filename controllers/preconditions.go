@@ -0,0 +1,153 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// preconditionRequeueAfter is how often preconditions not met are evaluated again
+	preconditionRequeueAfter = time.Minute
+)
+
+// getPreconditions returns the preconditions featureID requires
+func getPreconditions(clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID) []configv1alpha1.Precondition {
+
+	preconditions := make([]configv1alpha1.Precondition, 0)
+	for i := range clusterSummary.Spec.ClusterProfileSpec.Preconditions {
+		if clusterSummary.Spec.ClusterProfileSpec.Preconditions[i].FeatureID == featureID {
+			preconditions = append(preconditions, clusterSummary.Spec.ClusterProfileSpec.Preconditions[i])
+		}
+	}
+	return preconditions
+}
+
+// checkPreconditions verifies all preconditions of feature are met. If not, feature status is set
+// to PreconditionNotMet and an error is returned so that feature is not deployed.
+// Preconditions are evaluated again after preconditionRequeueAfter.
+func (r *ClusterSummaryReconciler) checkPreconditions(ctx context.Context,
+	clusterSummaryScope *scope.ClusterSummaryScope, featureID configv1alpha1.FeatureID, logger logr.Logger) error {
+
+	clusterSummary := clusterSummaryScope.ClusterSummary
+	preconditions := getPreconditions(clusterSummary, featureID)
+	if len(preconditions) == 0 {
+		return nil
+	}
+
+	cluster, err := clusterproxy.GetCluster(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, clusterSummary.Spec.ClusterType)
+	if err != nil {
+		return err
+	}
+
+	adminNamespace, adminName := getClusterSummaryAdmin(clusterSummary)
+	remoteClient, err := getKubernetesClient(ctx, r.Client, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, adminNamespace, adminName, clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		return err
+	}
+
+	message, err := evaluatePreconditions(ctx, remoteClient, cluster, preconditions)
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		return nil
+	}
+
+	logger.V(logs.LogInfo).Info(message)
+	clusterSummaryScope.SetFeatureStatus(featureID, configv1alpha1.FeatureStatusPreconditionNotMet, nil)
+	clusterSummaryScope.SetFailureMessage(featureID, &message)
+	return &TransientError{Message: message, RetryAfter: preconditionRequeueAfter}
+}
+
+// evaluatePreconditions returns a message describing the first precondition not met.
+// Returns an empty message if all preconditions are met.
+func evaluatePreconditions(ctx context.Context, remoteClient client.Client, cluster client.Object,
+	preconditions []configv1alpha1.Precondition) (string, error) {
+
+	for i := range preconditions {
+		p := &preconditions[i]
+		if len(p.ClusterLabels) != 0 &&
+			!labels.SelectorFromSet(p.ClusterLabels).Matches(labels.Set(cluster.GetLabels())) {
+
+			return fmt.Sprintf("precondition not met: cluster does not have labels %v", p.ClusterLabels), nil
+		}
+
+		if p.Resource != nil {
+			found, err := preconditionResourceExists(ctx, remoteClient, p.Resource)
+			if err != nil {
+				return "", err
+			}
+			if !found {
+				return fmt.Sprintf("precondition not met: %s %s/%s not found in the cluster",
+					p.Resource.Kind, p.Resource.Namespace, p.Resource.Name), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// preconditionResourceExists returns true if resource exists in the cluster. When resource name
+// is not set, returns true if at least one resource of that Kind exists.
+// A Kind the cluster does not serve is reported as missing resource.
+func preconditionResourceExists(ctx context.Context, remoteClient client.Client,
+	resource *configv1alpha1.PreconditionResource) (bool, error) {
+
+	gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+
+	var err error
+	if resource.Name != "" {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		err = remoteClient.Get(ctx, types.NamespacedName{Namespace: resource.Namespace, Name: resource.Name}, u)
+	} else {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err = remoteClient.List(ctx, list, client.InNamespace(resource.Namespace), client.Limit(1))
+		if err == nil {
+			return len(list.Items) != 0, nil
+		}
+	}
+
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Preconditions", func() {
+	It("evaluatePreconditions verifies cluster labels and resources in the cluster", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap).Build()
+
+		cluster := &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+
+		preconditions := []configv1alpha1.Precondition{
+			{
+				FeatureID:     configv1alpha1.FeatureHelm,
+				ClusterLabels: map[string]string{"env": "prod"},
+				Resource: &configv1alpha1.PreconditionResource{
+					Version: "v1", Kind: "ConfigMap", Namespace: configMap.Namespace, Name: configMap.Name,
+				},
+			},
+			{
+				FeatureID: configv1alpha1.FeatureHelm,
+				// Any ConfigMap in the namespace
+				Resource: &configv1alpha1.PreconditionResource{
+					Version: "v1", Kind: "ConfigMap", Namespace: configMap.Namespace,
+				},
+			},
+		}
+
+		message, err := controllers.EvaluatePreconditions(context.TODO(), c, cluster, preconditions)
+		Expect(err).To(BeNil())
+		Expect(message).To(BeEmpty())
+
+		cluster.Labels["env"] = "staging"
+		message, err = controllers.EvaluatePreconditions(context.TODO(), c, cluster, preconditions)
+		Expect(err).To(BeNil())
+		Expect(message).ToNot(BeEmpty())
+
+		cluster.Labels["env"] = "prod"
+		preconditions[1].Resource.Namespace = randomString()
+		message, err = controllers.EvaluatePreconditions(context.TODO(), c, cluster, preconditions)
+		Expect(err).To(BeNil())
+		Expect(message).To(ContainSubstring("not found"))
+	})
+})
@@ -0,0 +1,420 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+const (
+	// unfinishedWorkUpdatePeriod is how often unfinished work metrics are updated. Same as
+	// the default workqueue.
+	unfinishedWorkUpdatePeriod = 500 * time.Millisecond
+)
+
+// priorityQueueOptions configures how priorityQueue orders items
+type priorityQueueOptions struct {
+	// IsHighPriority, if set, returns true for items which must be handed out before any other item
+	IsHighPriority func(item interface{}) bool
+
+	// GroupOf, if set, returns the group of an item. Items with normal priority are handed out in
+	// weighted round robin across groups. When not set, all items are in the same group and so are
+	// handed out in FIFO order.
+	GroupOf func(item interface{}) string
+
+	// Weights contains the weight of each group. A group with weight N gets up to N items before
+	// next group is served. Groups not listed have weight 1.
+	Weights map[string]int
+}
+
+// queuedItem contains what priorityQueue knows about a queued item
+type queuedItem struct {
+	group        string
+	highPriority bool
+}
+
+// priorityQueue is a workqueue.Interface with two lanes. Items for which IsHighPriority returns
+// true are always handed out before any other item. All other items are handed out in weighted
+// round robin across groups, so a group with many items (for instance a ClusterProfile matching
+// many clusters) cannot starve the other ones.
+// Same as the default workqueue:
+// - an item is never processed concurrently by more than one worker;
+// - an item added multiple times before being processed is processed only once;
+// - depth, adds, latency, work duration and unfinished work metrics are reported.
+type priorityQueue struct {
+	cond *sync.Cond
+
+	options priorityQueueOptions
+	metrics *priorityQueueMetrics
+
+	high []interface{}
+	// lanes contains, per group, the queued items with normal priority
+	lanes map[string][]interface{}
+	// order contains the groups with queued items, in round robin order
+	order []string
+	// credit is the number of items order[0] group can still get before next group is served
+	credit int
+	// queued is the number of items in lanes
+	queued int
+
+	// dirty contains all items needing processing
+	dirty map[interface{}]queuedItem
+	// processing contains all items currently being processed
+	processing map[interface{}]struct{}
+
+	shuttingDown bool
+	drain        bool
+}
+
+// newPriorityQueue returns a priorityQueue. Metrics are reported, using metricsProvider, only
+// if both name and metricsProvider are set.
+func newPriorityQueue(name string, metricsProvider workqueue.MetricsProvider,
+	options priorityQueueOptions) *priorityQueue {
+
+	q := &priorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		options:    options,
+		metrics:    newPriorityQueueMetrics(name, metricsProvider),
+		lanes:      map[string][]interface{}{},
+		dirty:      map[interface{}]queuedItem{},
+		processing: map[interface{}]struct{}{},
+	}
+
+	if q.metrics != nil {
+		go q.updateUnfinishedWorkLoop()
+	}
+
+	return q
+}
+
+// newPriorityRateLimitingQueue returns a function, matching controller.Options NewQueue, creating
+// a rate limiting queue backed by a priorityQueue. Metrics are reported as for the default
+// controller queue.
+func newPriorityRateLimitingQueue(options priorityQueueOptions,
+) func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+
+	return func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+		return workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
+			Name: controllerName,
+			DelayingQueue: workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{
+				Name:  controllerName,
+				Queue: newPriorityQueue(controllerName, workqueueMetricsProvider{}, options),
+			}),
+		})
+	}
+}
+
+// Add marks item as needing processing. An item already queued with normal priority is
+// moved to the high priority lane if it now has high priority.
+func (q *priorityQueue) Add(item interface{}) {
+	info := queuedItem{
+		highPriority: q.options.IsHighPriority != nil && q.options.IsHighPriority(item),
+	}
+	if q.options.GroupOf != nil {
+		info.group = q.options.GroupOf(item)
+	}
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+
+	if current, ok := q.dirty[item]; ok {
+		if info.highPriority && !current.highPriority {
+			current.highPriority = true
+			q.dirty[item] = current
+			if q.removeFromLane(item, current.group) {
+				q.high = append(q.high, item)
+			}
+		}
+		return
+	}
+
+	q.metrics.add(item)
+
+	q.dirty[item] = info
+	if _, ok := q.processing[item]; ok {
+		// Item will be queued once its processing is done
+		return
+	}
+
+	q.enqueue(item, info)
+	q.cond.Signal()
+}
+
+func (q *priorityQueue) enqueue(item interface{}, info queuedItem) {
+	if info.highPriority {
+		q.high = append(q.high, item)
+		return
+	}
+
+	if _, ok := q.lanes[info.group]; !ok {
+		q.order = append(q.order, info.group)
+	}
+	q.lanes[info.group] = append(q.lanes[info.group], item)
+	q.queued++
+}
+
+// removeFromLane removes item from group lane. Returns false if item was not there.
+func (q *priorityQueue) removeFromLane(item interface{}, group string) bool {
+	lane := q.lanes[group]
+	for i := range lane {
+		if lane[i] == item {
+			q.lanes[group] = append(lane[:i], lane[i+1:]...)
+			q.queued--
+			if len(q.lanes[group]) == 0 {
+				q.removeGroup(group)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// removeGroup removes group, which has no queued item anymore, from the round robin
+func (q *priorityQueue) removeGroup(group string) {
+	delete(q.lanes, group)
+	for i := range q.order {
+		if q.order[i] == group {
+			if i == 0 {
+				q.credit = 0
+			}
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *priorityQueue) getWeight(group string) int {
+	if w := q.options.Weights[group]; w > 0 {
+		return w
+	}
+	return 1
+}
+
+// Len returns the number of items waiting to be processed
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return len(q.high) + q.queued
+}
+
+// Get blocks until an item can be processed. High priority items are returned first, then
+// items are returned in weighted round robin across groups.
+// Once processed, Done must be called with the item.
+func (q *priorityQueue) Get() (item interface{}, shutdown bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.high) == 0 && q.queued == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.high) == 0 && q.queued == 0 {
+		// We must be shutting down
+		return nil, true
+	}
+
+	if len(q.high) != 0 {
+		item = q.high[0]
+		q.high[0] = nil
+		q.high = q.high[1:]
+	} else {
+		group := q.order[0]
+		if q.credit == 0 {
+			q.credit = q.getWeight(group)
+		}
+		lane := q.lanes[group]
+		item = lane[0]
+		lane[0] = nil
+		q.lanes[group] = lane[1:]
+		q.queued--
+		q.credit--
+
+		if len(q.lanes[group]) == 0 {
+			q.removeGroup(group)
+		} else if q.credit == 0 {
+			// Group used all its credit. Move it to the end of the round robin
+			q.order = append(q.order[1:], group)
+		}
+	}
+
+	q.metrics.get(item)
+
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+
+	return item, false
+}
+
+// Done marks item as processed. If item was added again while being processed, it is queued.
+func (q *priorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.metrics.done(item)
+
+	delete(q.processing, item)
+	if info, ok := q.dirty[item]; ok {
+		q.enqueue(item, info)
+		q.cond.Signal()
+	} else if len(q.processing) == 0 {
+		q.cond.Signal()
+	}
+}
+
+// ShutDown makes Get return, so workers can terminate. Items being processed are not waited for.
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.drain = false
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain is like ShutDown but blocks until all items being processed are done
+func (q *priorityQueue) ShutDownWithDrain() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.drain = true
+	q.shuttingDown = true
+	q.cond.Broadcast()
+
+	for len(q.processing) != 0 && q.drain {
+		q.cond.Wait()
+	}
+}
+
+func (q *priorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return q.shuttingDown
+}
+
+// updateUnfinishedWorkLoop periodically updates unfinished work metrics till queue is shut down
+func (q *priorityQueue) updateUnfinishedWorkLoop() {
+	ticker := time.NewTicker(unfinishedWorkUpdatePeriod)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		q.cond.L.Lock()
+		if q.shuttingDown {
+			q.cond.L.Unlock()
+			return
+		}
+		q.metrics.updateUnfinishedWork()
+		q.cond.L.Unlock()
+	}
+}
+
+// priorityQueueMetrics reports the same metrics the default workqueue reports.
+// Caller must hold the queue lock. A nil priorityQueueMetrics reports nothing.
+type priorityQueueMetrics struct {
+	// current depth of the queue
+	depth workqueue.GaugeMetric
+	// total number of adds handled by the queue
+	adds workqueue.CounterMetric
+	// how long an item stays in the queue
+	latency workqueue.HistogramMetric
+	// how long processing an item takes
+	workDuration workqueue.HistogramMetric
+	// how long current workers have been working
+	unfinishedWorkSeconds   workqueue.SettableGaugeMetric
+	longestRunningProcessor workqueue.SettableGaugeMetric
+
+	addTimes             map[interface{}]time.Time
+	processingStartTimes map[interface{}]time.Time
+}
+
+func newPriorityQueueMetrics(name string, metricsProvider workqueue.MetricsProvider) *priorityQueueMetrics {
+	if name == "" || metricsProvider == nil {
+		return nil
+	}
+
+	return &priorityQueueMetrics{
+		depth:                   metricsProvider.NewDepthMetric(name),
+		adds:                    metricsProvider.NewAddsMetric(name),
+		latency:                 metricsProvider.NewLatencyMetric(name),
+		workDuration:            metricsProvider.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   metricsProvider.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: metricsProvider.NewLongestRunningProcessorSecondsMetric(name),
+		addTimes:                map[interface{}]time.Time{},
+		processingStartTimes:    map[interface{}]time.Time{},
+	}
+}
+
+func (m *priorityQueueMetrics) add(item interface{}) {
+	if m == nil {
+		return
+	}
+
+	m.adds.Inc()
+	m.depth.Inc()
+	if _, ok := m.addTimes[item]; !ok {
+		m.addTimes[item] = time.Now()
+	}
+}
+
+func (m *priorityQueueMetrics) get(item interface{}) {
+	if m == nil {
+		return
+	}
+
+	m.depth.Dec()
+	m.processingStartTimes[item] = time.Now()
+	if startTime, ok := m.addTimes[item]; ok {
+		m.latency.Observe(time.Since(startTime).Seconds())
+		delete(m.addTimes, item)
+	}
+}
+
+func (m *priorityQueueMetrics) done(item interface{}) {
+	if m == nil {
+		return
+	}
+
+	if startTime, ok := m.processingStartTimes[item]; ok {
+		m.workDuration.Observe(time.Since(startTime).Seconds())
+		delete(m.processingStartTimes, item)
+	}
+}
+
+func (m *priorityQueueMetrics) updateUnfinishedWork() {
+	if m == nil {
+		return
+	}
+
+	var total, oldest float64
+	for _, startTime := range m.processingStartTimes {
+		age := time.Since(startTime).Seconds()
+		total += age
+		if age > oldest {
+			oldest = age
+		}
+	}
+	m.unfinishedWorkSeconds.Set(total)
+	m.longestRunningProcessor.Set(oldest)
+}
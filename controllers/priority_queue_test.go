@@ -0,0 +1,268 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Priority queue", func() {
+	isHighPriority := func(item interface{}) bool {
+		return strings.HasPrefix(item.(string), "new-")
+	}
+
+	// Items are in the form <group>-<index>
+	groupOf := func(item interface{}) string {
+		return strings.Split(item.(string), "-")[0]
+	}
+
+	getAll := func(q interface {
+		Get() (interface{}, bool)
+		Done(interface{})
+		Len() int
+	}) []interface{} {
+
+		items := []interface{}{}
+		for q.Len() != 0 {
+			item, shutdown := q.Get()
+			Expect(shutdown).To(BeFalse())
+			items = append(items, item)
+			q.Done(item)
+		}
+		return items
+	}
+
+	It("returns high priority items first", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{IsHighPriority: isHighPriority})
+		q.Add("old-1")
+		q.Add("new-1")
+		q.Add("old-2")
+		q.Add("new-2")
+		Expect(q.Len()).To(Equal(4))
+
+		Expect(getAll(q)).To(Equal([]interface{}{"new-1", "new-2", "old-1", "old-2"}))
+		Expect(q.Len()).To(BeZero())
+	})
+
+	It("queues an item only once", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{IsHighPriority: isHighPriority})
+		q.Add("old-1")
+		q.Add("old-1")
+		q.Add("new-1")
+		q.Add("new-1")
+		Expect(q.Len()).To(Equal(2))
+	})
+
+	It("promotes a queued item whose priority increased", func() {
+		highPriority := map[string]bool{}
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{
+			IsHighPriority: func(item interface{}) bool {
+				return highPriority[item.(string)]
+			},
+			GroupOf: groupOf,
+		})
+		q.Add("a-1")
+		q.Add("b-1")
+
+		highPriority["b-1"] = true
+		q.Add("b-1")
+		Expect(q.Len()).To(Equal(2))
+
+		Expect(getAll(q)).To(Equal([]interface{}{"b-1", "a-1"}))
+	})
+
+	It("queues again an item added while being processed only once processing is done", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{IsHighPriority: isHighPriority})
+		q.Add("new-1")
+
+		item, _ := q.Get()
+		q.Add("new-1")
+		Expect(q.Len()).To(BeZero())
+
+		q.Done(item)
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("Get returns shutdown once queue is shut down", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{IsHighPriority: isHighPriority})
+		q.ShutDown()
+		Expect(q.ShuttingDown()).To(BeTrue())
+
+		q.Add("new-1")
+		_, shutdown := q.Get()
+		Expect(shutdown).To(BeTrue())
+	})
+
+	It("returns items in FIFO order when no group is set", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{})
+		for _, item := range []string{"big-1", "big-2", "small-1", "big-3"} {
+			q.Add(item)
+		}
+
+		Expect(getAll(q)).To(Equal([]interface{}{"big-1", "big-2", "small-1", "big-3"}))
+	})
+
+	It("returns items in round robin across groups", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{GroupOf: groupOf})
+		for _, item := range []string{"big-1", "big-2", "big-3", "big-4", "small-1", "other-1"} {
+			q.Add(item)
+		}
+		Expect(q.Len()).To(Equal(6))
+
+		Expect(getAll(q)).To(Equal([]interface{}{"big-1", "small-1", "other-1", "big-2", "big-3", "big-4"}))
+	})
+
+	It("honors group weights", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{
+			GroupOf: groupOf,
+			Weights: map[string]int{"big": 2},
+		})
+		for _, item := range []string{"big-1", "big-2", "big-3", "small-1", "small-2"} {
+			q.Add(item)
+		}
+
+		Expect(getAll(q)).To(Equal([]interface{}{"big-1", "big-2", "small-1", "big-3", "small-2"}))
+	})
+
+	It("returns high priority items before round robin across groups", func() {
+		q := controllers.NewPriorityQueue("", nil, controllers.PriorityQueueOptions{
+			IsHighPriority: func(item interface{}) bool {
+				return strings.HasSuffix(item.(string), "new")
+			},
+			GroupOf: groupOf,
+		})
+		q.Add("a-1")
+		q.Add("a-2")
+		q.Add("b-1")
+		q.Add("b-new")
+
+		Expect(getAll(q)).To(Equal([]interface{}{"b-new", "a-1", "b-1", "a-2"}))
+	})
+
+	It("reports workqueue metrics", func() {
+		metricsProvider := newTestMetricsProvider()
+		q := controllers.NewPriorityQueue(randomString(), metricsProvider,
+			controllers.PriorityQueueOptions{IsHighPriority: isHighPriority, GroupOf: groupOf})
+		defer q.ShutDown()
+
+		q.Add("old-1")
+		q.Add("new-1")
+		q.Add("new-1")
+		Expect(metricsProvider.adds.value()).To(Equal(float64(2)))
+		Expect(metricsProvider.depth.value()).To(Equal(float64(2)))
+
+		item, _ := q.Get()
+		Expect(metricsProvider.depth.value()).To(Equal(float64(1)))
+		Expect(metricsProvider.latency.count()).To(Equal(1))
+
+		q.Done(item)
+		Expect(metricsProvider.workDuration.count()).To(Equal(1))
+	})
+})
+
+// testMetric records the values set through workqueue metric interfaces
+type testMetric struct {
+	mux          sync.Mutex
+	current      float64
+	observations int
+}
+
+func (m *testMetric) Inc() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.current++
+}
+
+func (m *testMetric) Dec() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.current--
+}
+
+func (m *testMetric) Set(v float64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.current = v
+}
+
+func (m *testMetric) Observe(float64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.observations++
+}
+
+func (m *testMetric) value() float64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.current
+}
+
+func (m *testMetric) count() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.observations
+}
+
+// testMetricsProvider is a workqueue.MetricsProvider recording metrics in testMetrics
+type testMetricsProvider struct {
+	depth        *testMetric
+	adds         *testMetric
+	latency      *testMetric
+	workDuration *testMetric
+	unfinished   *testMetric
+	longest      *testMetric
+	retries      *testMetric
+}
+
+func newTestMetricsProvider() *testMetricsProvider {
+	return &testMetricsProvider{
+		depth:        &testMetric{},
+		adds:         &testMetric{},
+		latency:      &testMetric{},
+		workDuration: &testMetric{},
+		unfinished:   &testMetric{},
+		longest:      &testMetric{},
+		retries:      &testMetric{},
+	}
+}
+
+func (p *testMetricsProvider) NewDepthMetric(string) workqueue.GaugeMetric { return p.depth }
+
+func (p *testMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric { return p.adds }
+
+func (p *testMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric { return p.latency }
+
+func (p *testMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return p.workDuration
+}
+
+func (p *testMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return p.unfinished
+}
+
+func (p *testMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return p.longest
+}
+
+func (p *testMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric { return p.retries }
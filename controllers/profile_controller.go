@@ -64,6 +64,9 @@ type ProfileReconciler struct {
 	// key: Profile; value Profile Selector
 	Profiles map[corev1.ObjectReference]libsveltosv1alpha1.Selector
 
+	// key: Profile; value Profile ClusterSelectors (OR semantics)
+	ProfileSelectors map[corev1.ObjectReference][]libsveltosv1alpha1.Selector
+
 	// For each cluster contains current labels
 	// This is needed in following scenario:
 	// - Profile is created
@@ -97,6 +100,9 @@ type ProfileReconciler struct {
 	// Svetos/CAPI Cluster 1 and 2.
 	// So we can remove 2 => A from ClusterMap. Only after this update, we update ProfileMap (so new value will be A => 1)
 
+	// Clusters changed since MatchingClusterRefs were last computed
+	clusterChanges clusterChanges
+
 	ctrl controller.Controller
 }
 
@@ -191,21 +197,40 @@ func (r *ProfileReconciler) reconcileNormal(
 		}
 	}
 
-	// Limit the search of matching cluster to the Profile namespace
-	matchingCluster, err := getMatchingClusters(ctx, r.Client, profileScope.Profile.GetNamespace(),
-		profileScope.GetSelector(), profileScope.GetSpec().ClusterRefs, logger)
+	// A malformed selector would match no cluster. Report it and keep current
+	// matching clusters until it is fixed.
+	err := validateSelectors(profileScope)
+	profileScope.SetInvalidSelector(err)
 	if err != nil {
-		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+		logger.V(logs.LogInfo).Info(err.Error())
+		return reconcile.Result{}
 	}
 
-	// Get all clusters from referenced Sets
-	clusterSetClusters, err := r.getClustersFromSets(ctx, profileScope.Namespace(), profileScope.GetSpec().SetRefs, logger)
+	// When only some clusters changed since MatchingClusterRefs were last computed, re-evaluate
+	// those clusters only. Anything else (selectors changed, resync, ...) requires a full recompute.
+	var matchingCluster, excludedCluster []corev1.ObjectReference
+	changed, incremental := r.clusterChanges.take(profileScope)
+	if incremental {
+		logger.V(logs.LogDebug).Info(fmt.Sprintf("re-evaluating %d changed clusters", len(changed)))
+		matchingCluster, err = updateMatchingClusters(ctx, r.Client, profileScope, profileScope.Profile.GetNamespace(),
+			changed, logger)
+	} else {
+		matchingCluster, excludedCluster, err = r.computeMatchingClusters(ctx, profileScope, logger)
+	}
 	if err != nil {
+		r.clusterChanges.forget(profileScope)
 		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
 	}
-	matchingCluster = append(matchingCluster, clusterSetClusters...)
+	if !incremental {
+		r.clusterChanges.computed(profileScope)
+	}
+
+	profileScope.SetMatchingClusterRefs(matchingCluster)
+	profileScope.SetExcludedClusterRefs(excludedCluster)
 
-	profileScope.SetMatchingClusterRefs(removeDuplicates(matchingCluster))
+	if err := r.setMatchExplanation(ctx, profileScope, logger); err != nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: normalRequeueAfter}
+	}
 
 	r.updateMaps(profileScope)
 
@@ -217,10 +242,82 @@ func (r *ProfileReconciler) reconcileNormal(
 	return reconcile.Result{}
 }
 
+// computeMatchingClusters lists all clusters and returns the ones matching the Profile (targeted)
+// and the ones excluded because of ClusterLimit.
+func (r *ProfileReconciler) computeMatchingClusters(ctx context.Context, profileScope *scope.ProfileScope,
+	logger logr.Logger) (matching, excluded []corev1.ObjectReference, err error) {
+
+	// Limit the search of matching cluster to the Profile namespace
+	matchingCluster, err := getMatchingClusters(ctx, r.Client, profileScope.Profile.GetNamespace(),
+		profileScope.GetSelector(), profileScope.GetSpec().ClusterRefs, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get all clusters, in the Profile namespace, matching any of ClusterSelectors
+	selectorsClusters, err := getClustersMatchingAnySelector(ctx, r.Client, profileScope.Profile.GetNamespace(),
+		profileScope.GetSelectors(), logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	matchingCluster = append(matchingCluster, selectorsClusters...)
+
+	// Get all clusters from referenced Sets
+	clusterSetClusters, err := r.getClustersFromSets(ctx, profileScope.Namespace(), profileScope.GetSpec().SetRefs, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	matchingCluster = append(matchingCluster, clusterSetClusters...)
+
+	// Only clusters in namespaces considered by this controller instance are targeted
+	matchingCluster = filterByClusterNamespaces(removeDuplicates(matchingCluster))
+
+	// Only clusters having all RequiredClusterLabels, if any, are targeted
+	matchingCluster, err = filterByRequiredClusterLabels(ctx, r.Client, matchingCluster,
+		profileScope.GetSpec().RequiredClusterLabels, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only clusters within ClusterLimit, if any, are targeted
+	return limitMatchingClusters(ctx, r.Client, matchingCluster,
+		profileScope.GetSpec().ClusterLimit, logger)
+}
+
+// setMatchExplanation, when the explain annotation is set, reports in status why each cluster
+// is, or is not, matching. Otherwise any previous explanation is removed.
+func (r *ProfileReconciler) setMatchExplanation(ctx context.Context, profileScope *scope.ProfileScope,
+	logger logr.Logger) error {
+
+	if !isExplainRequested(profileScope.Profile) {
+		profileScope.SetMatchExplanation(nil)
+		return nil
+	}
+
+	setClusters, err := r.getClustersFromSets(ctx, profileScope.Namespace(), profileScope.GetSpec().SetRefs, logger)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := explainMatchingClusters(ctx, r.Client, profileScope, profileScope.Profile.GetNamespace(),
+		setClusters, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to explain matching clusters: %v", err))
+		return err
+	}
+
+	profileScope.SetMatchExplanation(explanation)
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c, err := ctrl.NewControllerManagedBy(mgr).
-		For(&configv1alpha1.Profile{}).
+		For(&configv1alpha1.Profile{},
+			builder.WithPredicates(
+				ProfilePredicates(mgr.GetLogger().WithValues("predicate", "profilepredicate")),
+			),
+		).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.ConcurrentReconciles,
 		}).
@@ -236,6 +333,10 @@ func (r *ProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				SveltosClusterPredicates(mgr.GetLogger().WithValues("predicate", "sveltosclusterpredicate")),
 			),
 		).
+		Watches(&configv1alpha1.ClusterSummary{},
+			handler.EnqueueRequestsFromMapFunc(r.requeueProfileForClusterSummary),
+			builder.WithPredicates(ClusterSummaryDeploymentPredicates()),
+		).
 		Build(r)
 	if err != nil {
 		return errors.Wrap(err, "error creating controller")
@@ -288,10 +389,24 @@ func (r *ProfileReconciler) limitReferencesToNamespace(profile *configv1alpha1.P
 		profile.Spec.PolicyRefs[i].Namespace = profile.Namespace
 	}
 
+	for i := range profile.Spec.CopyRefs {
+		profile.Spec.CopyRefs[i].Namespace = profile.Namespace
+	}
+
 	for i := range profile.Spec.KustomizationRefs {
 		profile.Spec.KustomizationRefs[i].Namespace = profile.Namespace
 		r.limitKustomizationRefsToNamespace(profile, &profile.Spec.KustomizationRefs[i])
 	}
+
+	for i := range profile.Spec.HelmCharts {
+		for j := range profile.Spec.HelmCharts[i].ValuesFrom {
+			profile.Spec.HelmCharts[i].ValuesFrom[j].Namespace = profile.Namespace
+		}
+	}
+
+	if profile.Spec.CompletionWebhook != nil && profile.Spec.CompletionWebhook.SecretRef != nil {
+		profile.Spec.CompletionWebhook.SecretRef.Namespace = profile.Namespace
+	}
 }
 
 // limitKustomizationRefsToNamespace reset Namespace of all ConfigMap/Secret
@@ -310,7 +425,10 @@ func (r *ProfileReconciler) cleanMaps(profileScope *scope.ProfileScope) {
 
 	profileInfo := getKeyFromObject(r.Scheme, profileScope.Profile)
 
+	r.clusterChanges.forget(profileScope)
+
 	delete(r.Profiles, *profileInfo)
+	delete(r.ProfileSelectors, *profileInfo)
 
 	// ClusterMap contains for each cluster, set of Profiles matching
 	// that cluster. Remove Profile from this map
@@ -364,6 +482,10 @@ func (r *ProfileReconciler) updateMaps(profileScope *scope.ProfileScope) {
 	}
 
 	r.Profiles[*profileInfo] = profileScope.GetSpec().ClusterSelector
+	if r.ProfileSelectors == nil {
+		r.ProfileSelectors = make(map[corev1.ObjectReference][]libsveltosv1alpha1.Selector)
+	}
+	r.ProfileSelectors[*profileInfo] = profileScope.GetSpec().ClusterSelectors
 }
 
 func (r *ProfileReconciler) GetController() controller.Controller {
@@ -100,6 +100,24 @@ var _ = Describe("Profile Controller", func() {
 					Name:      randomString(),
 				},
 			},
+			HelmCharts: []configv1alpha1.HelmChart{
+				{
+					ValuesFrom: []configv1alpha1.ValueFrom{
+						{
+							Kind:      string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+							Namespace: randomString(),
+							Name:      randomString(),
+						},
+					},
+				},
+			},
+			CompletionWebhook: &configv1alpha1.CompletionWebhook{
+				URL: "https://" + randomString(),
+				SecretRef: &corev1.SecretReference{
+					Namespace: randomString(),
+					Name:      randomString(),
+				},
+			},
 		}
 
 		initObjects := []client.Object{
@@ -130,6 +148,14 @@ var _ = Describe("Profile Controller", func() {
 		for i := range profile.Spec.KustomizationRefs {
 			Expect(profile.Spec.KustomizationRefs[i].Namespace).To(Equal(profile.Namespace))
 		}
+
+		for i := range profile.Spec.HelmCharts {
+			for j := range profile.Spec.HelmCharts[i].ValuesFrom {
+				Expect(profile.Spec.HelmCharts[i].ValuesFrom[j].Namespace).To(Equal(profile.Namespace))
+			}
+		}
+
+		Expect(profile.Spec.CompletionWebhook.SecretRef.Namespace).To(Equal(profile.Namespace))
 	})
 
 	It("getClustersFromClusterSets gets cluster selected by referenced sets", func() {
@@ -0,0 +1,201 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+const (
+	// maxProgress is the progress of a ClusterProfile/Profile once provisioned in all matching clusters
+	maxProgress = 100
+)
+
+// getFailingFeature returns the first feature of clusterSummary whose deployment is failing.
+// Returns nil if no feature is failing.
+func getFailingFeature(clusterSummary *configv1alpha1.ClusterSummary) *configv1alpha1.FeatureSummary {
+	for i := range clusterSummary.Status.FeatureSummaries {
+		fs := &clusterSummary.Status.FeatureSummaries[i]
+		if fs.Status == configv1alpha1.FeatureStatusFailed ||
+			fs.Status == configv1alpha1.FeatureStatusFailedNonRetriable {
+
+			return fs
+		}
+	}
+	return nil
+}
+
+// getClusterSummaryClusterRef returns a reference to the cluster clusterSummary is for
+func getClusterSummaryClusterRef(clusterSummary *configv1alpha1.ClusterSummary) *corev1.ObjectReference {
+	clusterRef := &corev1.ObjectReference{
+		Namespace: clusterSummary.Spec.ClusterNamespace,
+		Name:      clusterSummary.Spec.ClusterName,
+	}
+	if clusterSummary.Spec.ClusterType == libsveltosv1alpha1.ClusterTypeSveltos {
+		clusterRef.Kind = libsveltosv1alpha1.SveltosClusterKind
+		clusterRef.APIVersion = libsveltosv1alpha1.GroupVersion.String()
+	} else {
+		clusterRef.Kind = clusterKind
+		clusterRef.APIVersion = clusterv1.GroupVersion.String()
+	}
+	return clusterRef
+}
+
+// getDeploymentSummary aggregates the deployment status of clusterSummaries
+func getDeploymentSummary(clusterSummaries []configv1alpha1.ClusterSummary) *configv1alpha1.DeploymentSummary {
+	summary := &configv1alpha1.DeploymentSummary{}
+
+	for i := range clusterSummaries {
+		cs := &clusterSummaries[i]
+		if fs := getFailingFeature(cs); fs != nil {
+			summary.Failed++
+			failingCluster := configv1alpha1.FailingCluster{
+				Cluster:   *getClusterSummaryClusterRef(cs),
+				FeatureID: fs.FeatureID,
+			}
+			if fs.FailureMessage != nil {
+				failingCluster.FailureMessage = *fs.FailureMessage
+			}
+			summary.FailingClusters = append(summary.FailingClusters, failingCluster)
+		} else if isCluterSummaryProvisioned(cs) {
+			summary.Provisioned++
+		} else {
+			summary.Provisioning++
+		}
+	}
+
+	sort.Slice(summary.FailingClusters, func(i, j int) bool {
+		if summary.FailingClusters[i].Cluster.Namespace != summary.FailingClusters[j].Cluster.Namespace {
+			return summary.FailingClusters[i].Cluster.Namespace < summary.FailingClusters[j].Cluster.Namespace
+		}
+		return summary.FailingClusters[i].Cluster.Name < summary.FailingClusters[j].Cluster.Name
+	})
+
+	return summary
+}
+
+// updateDeploymentSummary sets profile Status.DeploymentSummary aggregating the status of
+// all the ClusterSummaries created because of the ClusterProfile/Profile
+func updateDeploymentSummary(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) error {
+	listOptions := []client.ListOption{}
+	if profileScope.GetKind() == configv1alpha1.ClusterProfileKind {
+		listOptions = append(listOptions, client.MatchingLabels{ClusterProfileLabelName: profileScope.Name()})
+	} else {
+		listOptions = append(listOptions, client.InNamespace(profileScope.Namespace()),
+			client.MatchingLabels{ProfileLabelName: profileScope.Name()})
+	}
+
+	clusterSummaries := &configv1alpha1.ClusterSummaryList{}
+	if err := c.List(ctx, clusterSummaries, listOptions...); err != nil {
+		return err
+	}
+
+	summary := getDeploymentSummary(clusterSummaries.Items)
+	summary.Matching = int32(len(profileScope.GetStatus().MatchingClusterRefs))
+	summary.Progress = getDeploymentProgress(clusterSummaries.Items, profileScope.GetSpec(),
+		len(profileScope.GetStatus().MatchingClusterRefs))
+	summary.Ready = summary.Progress == maxProgress
+	profileScope.GetStatus().DeploymentSummary = summary
+	return nil
+}
+
+// getDeploymentProgress returns the percentage of the matchingClusters clusters where all features
+// are provisioned with spec, the current ClusterProfile/Profile configuration.
+// A ClusterSummary whose Spec was not updated to spec yet is not counted even if provisioned.
+func getDeploymentProgress(clusterSummaries []configv1alpha1.ClusterSummary, spec *configv1alpha1.Spec,
+	matchingClusters int) int32 {
+
+	if matchingClusters == 0 {
+		return maxProgress
+	}
+
+	upToDate := 0
+	for i := range clusterSummaries {
+		cs := &clusterSummaries[i]
+		if !cs.DeletionTimestamp.IsZero() || !isCluterSummaryProvisioned(cs) {
+			continue
+		}
+		if reflect.DeepEqual(&cs.Spec.ClusterProfileSpec, spec) {
+			upToDate++
+		}
+	}
+
+	if upToDate >= matchingClusters {
+		return maxProgress
+	}
+	return int32(upToDate * maxProgress / matchingClusters)
+}
+
+// ClusterSummaryDeploymentPredicates predicates for ClusterSummary. ClusterProfile/Profile reconcilers
+// watch ClusterSummary events and react to those by updating their deployment summary
+func ClusterSummaryDeploymentPredicates() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			newClusterSummary := e.ObjectNew.(*configv1alpha1.ClusterSummary)
+			oldClusterSummary := e.ObjectOld.(*configv1alpha1.ClusterSummary)
+			if oldClusterSummary == nil {
+				return true
+			}
+			// Spec changed. Deployment progress depends on ClusterSummary Spec being up to date
+			if oldClusterSummary.Generation != newClusterSummary.Generation {
+				return true
+			}
+			return !reflect.DeepEqual(getDeploymentSummary([]configv1alpha1.ClusterSummary{*oldClusterSummary}),
+				getDeploymentSummary([]configv1alpha1.ClusterSummary{*newClusterSummary}))
+		},
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// requeueForClusterSummary returns the ClusterProfile/Profile which created clusterSummary
+func requeueForClusterSummary(clusterSummary client.Object, profileKind string) []reconcile.Request {
+	labels := clusterSummary.GetLabels()
+	if profileKind == configv1alpha1.ClusterProfileKind {
+		if name, ok := labels[ClusterProfileLabelName]; ok {
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: name}}}
+		}
+		return nil
+	}
+
+	if name, ok := labels[ProfileLabelName]; ok {
+		return []reconcile.Request{
+			{NamespacedName: client.ObjectKey{Namespace: clusterSummary.GetNamespace(), Name: name}},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("DeploymentSummary", func() {
+	getClusterSummary := func(status configv1alpha1.FeatureStatus, failureMessage *string) configv1alpha1.ClusterSummary {
+		return configv1alpha1.ClusterSummary{
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: randomString(),
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeSveltos,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					PolicyRefs: []configv1alpha1.PolicyRef{
+						{Namespace: randomString(), Name: randomString(), Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)},
+					},
+				},
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{FeatureID: configv1alpha1.FeatureResources, Status: status, FailureMessage: failureMessage},
+				},
+			},
+		}
+	}
+
+	It("getDeploymentSummary counts clusters by status and lists failing ones", func() {
+		failureMessage := randomString()
+		failing := getClusterSummary(configv1alpha1.FeatureStatusFailed, &failureMessage)
+		clusterSummaries := []configv1alpha1.ClusterSummary{
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioned, nil),
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioning, nil),
+			failing,
+		}
+
+		summary := controllers.GetDeploymentSummary(clusterSummaries)
+		Expect(summary.Provisioned).To(Equal(int32(1)))
+		Expect(summary.Provisioning).To(Equal(int32(1)))
+		Expect(summary.Failed).To(Equal(int32(1)))
+		Expect(len(summary.FailingClusters)).To(Equal(1))
+		Expect(summary.FailingClusters[0].Cluster.Namespace).To(Equal(failing.Spec.ClusterNamespace))
+		Expect(summary.FailingClusters[0].Cluster.Name).To(Equal(failing.Spec.ClusterName))
+		Expect(summary.FailingClusters[0].Cluster.Kind).To(Equal(libsveltosv1alpha1.SveltosClusterKind))
+		Expect(summary.FailingClusters[0].FeatureID).To(Equal(configv1alpha1.FeatureResources))
+		Expect(summary.FailingClusters[0].FailureMessage).To(Equal(failureMessage))
+	})
+
+	It("getDeploymentProgress counts only clusters provisioned with current configuration", func() {
+		upToDate := getClusterSummary(configv1alpha1.FeatureStatusProvisioned, nil)
+		spec := upToDate.Spec.ClusterProfileSpec.DeepCopy()
+
+		outdated := getClusterSummary(configv1alpha1.FeatureStatusProvisioned, nil)
+		clusterSummaries := []configv1alpha1.ClusterSummary{
+			upToDate,
+			outdated,
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioning, nil),
+			getClusterSummary(configv1alpha1.FeatureStatusProvisioning, nil),
+		}
+
+		Expect(controllers.GetDeploymentProgress(clusterSummaries, spec, 4)).To(Equal(int32(25)))
+
+		clusterSummaries[1].Spec.ClusterProfileSpec = *spec.DeepCopy()
+		Expect(controllers.GetDeploymentProgress(clusterSummaries, spec, 4)).To(Equal(int32(50)))
+
+		// No matching cluster
+		Expect(controllers.GetDeploymentProgress(nil, spec, 0)).To(Equal(int32(100)))
+	})
+})
@@ -35,6 +35,7 @@ import (
 
 func requeueForCluster(cluster client.Object,
 	profileSelectors map[corev1.ObjectReference]libsveltosv1alpha1.Selector,
+	profileExtraSelectors map[corev1.ObjectReference][]libsveltosv1alpha1.Selector,
 	clusterLabels map[corev1.ObjectReference]map[string]string,
 	clusterMap map[corev1.ObjectReference]*libsveltosset.Set,
 	kindType string, logger logr.Logger) []reconcile.Request {
@@ -42,13 +43,11 @@ func requeueForCluster(cluster client.Object,
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s/%s", cluster.GetNamespace(), cluster.GetName()))
 	logger.V(logs.LogDebug).Info("reacting to Cluster change")
 
-	apiVersion, kind := cluster.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	clusterInfo := corev1.ObjectReference{APIVersion: apiVersion, Kind: kind,
-		Namespace: cluster.GetNamespace(), Name: cluster.GetName()}
+	clusterInfo := getClusterInfoForCluster(cluster)
 
-	profileCurrentlyMatching := getConsumersForEntry(clusterMap, &clusterInfo)
+	profileCurrentlyMatching := getConsumersForEntry(clusterMap, clusterInfo)
 
-	clusterLabels[clusterInfo] = cluster.GetLabels()
+	clusterLabels[*clusterInfo] = cluster.GetLabels()
 
 	// Get all (Cluster)Profiles previously matching this cluster and reconcile those
 	requests := make([]ctrl.Request, profileCurrentlyMatching.Len())
@@ -87,11 +86,15 @@ func requeueForCluster(cluster client.Object,
 		}
 	}
 
+	requests = append(requests,
+		getRequestsForExtraSelectors(profileExtraSelectors, cluster.GetLabels(), kindType, logger)...)
+
 	return requests
 }
 
 func requeueForMachine(machine client.Object,
 	profileSelectors map[corev1.ObjectReference]libsveltosv1alpha1.Selector,
+	profileExtraSelectors map[corev1.ObjectReference][]libsveltosv1alpha1.Selector,
 	clusterLabels map[corev1.ObjectReference]map[string]string,
 	clusterMap map[corev1.ObjectReference]*libsveltosset.Set,
 	kind string, logger logr.Logger) []reconcile.Request {
@@ -100,26 +103,15 @@ func requeueForMachine(machine client.Object,
 
 	logger.V(logs.LogDebug).Info("reacting to CAPI Machine change")
 
-	machineLabels := machine.GetLabels()
-	if machineLabels == nil {
-		return nil
-	}
-
-	clusterNameLabel, ok := machineLabels[clusterv1.ClusterNameLabel]
-	if !ok {
+	clusterInfo := getClusterInfoForMachine(machine)
+	if clusterInfo == nil {
 		logger.V(logs.LogVerbose).Info("Machine has not ClusterNameLabel")
 		return nil
 	}
 
-	clusterInfo := corev1.ObjectReference{
-		APIVersion: clusterv1.GroupVersion.String(),
-		Kind:       clusterKind,
-		Namespace:  machine.GetNamespace(),
-		Name:       clusterNameLabel}
-
 	// Get all ClusterProfile previously matching this cluster and reconcile those
-	requests := make([]ctrl.Request, getConsumersForEntry(clusterMap, &clusterInfo).Len())
-	consumers := getConsumersForEntry(clusterMap, &clusterInfo).Items()
+	requests := make([]ctrl.Request, getConsumersForEntry(clusterMap, clusterInfo).Len())
+	consumers := getConsumersForEntry(clusterMap, clusterInfo).Items()
 
 	for i := range consumers {
 		requests[i] = ctrl.Request{
@@ -131,7 +123,7 @@ func requeueForMachine(machine client.Object,
 	}
 
 	// Get Cluster labels
-	if clusterLabels, ok := clusterLabels[clusterInfo]; ok {
+	if clusterLabels, ok := clusterLabels[*clusterInfo]; ok {
 		// Iterate over all current ClusterProfile and reconcile the ClusterProfile now
 		// matching the Cluster
 		for k := range profileSelectors {
@@ -153,6 +145,60 @@ func requeueForMachine(machine client.Object,
 				})
 			}
 		}
+
+		requests = append(requests,
+			getRequestsForExtraSelectors(profileExtraSelectors, clusterLabels, kind, logger)...)
+	}
+
+	return requests
+}
+
+// getClusterInfoForCluster returns a reference to cluster
+func getClusterInfoForCluster(cluster client.Object) *corev1.ObjectReference {
+	apiVersion, kind := cluster.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+	return &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind,
+		Namespace: cluster.GetNamespace(), Name: cluster.GetName()}
+}
+
+// getClusterInfoForMachine returns a reference to the CAPI Cluster machine belongs to.
+// Returns nil if machine has no ClusterNameLabel.
+func getClusterInfoForMachine(machine client.Object) *corev1.ObjectReference {
+	clusterNameLabel, ok := machine.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+
+	return &corev1.ObjectReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       clusterKind,
+		Namespace:  machine.GetNamespace(),
+		Name:       clusterNameLabel}
+}
+
+// getRequestsForExtraSelectors returns a request for each (Cluster)Profile with at least one of
+// its ClusterSelectors matching clusterLabels
+func getRequestsForExtraSelectors(profileExtraSelectors map[corev1.ObjectReference][]libsveltosv1alpha1.Selector,
+	clusterLabels map[string]string, kindType string, logger logr.Logger) []reconcile.Request {
+
+	requests := make([]reconcile.Request, 0)
+	for k := range profileExtraSelectors {
+		for i := range profileExtraSelectors[k] {
+			parsedSelector, err := labels.Parse(string(profileExtraSelectors[k][i]))
+			if err != nil {
+				continue
+			}
+			if parsedSelector.Matches(labels.Set(clusterLabels)) {
+				l := logger.WithValues(kindType, k.Name)
+				l.V(logs.LogDebug).Info(fmt.Sprintf("queuing %s", kindType))
+				requests = append(requests, ctrl.Request{
+					NamespacedName: client.ObjectKey{
+						Name:      k.Name,
+						Namespace: k.Namespace,
+					},
+				})
+				break
+			}
+		}
 	}
 
 	return requests
@@ -37,7 +37,10 @@ func (r *ProfileReconciler) requeueProfileForSveltosCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.Profiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	requests := requeueForCluster(cluster, r.Profiles, r.ProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForCluster(cluster))
+
+	return requests
 }
 
 func (r *ProfileReconciler) requeueProfileForCluster(
@@ -49,7 +52,10 @@ func (r *ProfileReconciler) requeueProfileForCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.Profiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	requests := requeueForCluster(cluster, r.Profiles, r.ProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForCluster(cluster))
+
+	return requests
 }
 
 func (r *ProfileReconciler) requeueProfileForMachine(
@@ -61,7 +67,10 @@ func (r *ProfileReconciler) requeueProfileForMachine(
 	r.Mux.Lock()
 	defer r.Mux.Unlock()
 
-	return requeueForMachine(machine, r.Profiles, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	requests := requeueForMachine(machine, r.Profiles, r.ProfileSelectors, r.ClusterLabels, r.ClusterMap, configv1alpha1.ProfileKind, r.Logger)
+	r.clusterChanges.record(requests, getClusterInfoForMachine(machine))
+
+	return requests
 }
 
 func (r *ProfileReconciler) requeueProfileForSet(
@@ -77,3 +86,10 @@ func (r *ProfileReconciler) requeueProfileForSet(
 
 	return requeueForSet(set, r.SetMap, configv1alpha1.ProfileKind, r.Logger)
 }
+
+func (r *ProfileReconciler) requeueProfileForClusterSummary(
+	ctx context.Context, o client.Object,
+) []reconcile.Request {
+
+	return requeueForClusterSummary(o, configv1alpha1.ProfileKind)
+}
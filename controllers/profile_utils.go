@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/dariubs/percent"
@@ -68,6 +69,129 @@ func getMatchingClusters(ctx context.Context, c client.Client, namespace string,
 	return matchingCluster, nil
 }
 
+// validateSelectors parses ClusterSelector and all ClusterSelectors, returning an error
+// identifying the first one which is not valid
+func validateSelectors(profileScope *scope.ProfileScope) error {
+	if selector := profileScope.GetSelector(); selector != "" {
+		if _, err := labels.Parse(selector); err != nil {
+			return fmt.Errorf("invalid clusterSelector %q: %w", selector, err)
+		}
+	}
+
+	for i, selector := range profileScope.GetSelectors() {
+		if _, err := labels.Parse(selector); err != nil {
+			return fmt.Errorf("invalid clusterSelectors[%d] %q: %w", i, selector, err)
+		}
+	}
+
+	return nil
+}
+
+// getClustersMatchingAnySelector returns all clusters matching at least one of the selectors (OR semantics).
+// If namespace is set, only clusters in such namespace are considered.
+func getClustersMatchingAnySelector(ctx context.Context, c client.Client, namespace string, selectors []string,
+	logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	matching := &libsveltosset.Set{}
+	for i := range selectors {
+		clusters, err := getMatchingClusters(ctx, c, namespace, selectors[i], nil, logger)
+		if err != nil {
+			return nil, err
+		}
+		for j := range clusters {
+			matching.Insert(&clusters[j])
+		}
+	}
+
+	return matching.Items(), nil
+}
+
+// filterByRequiredClusterLabels returns the clusters having all requiredLabels.
+// Clusters which cannot be found are dropped.
+func filterByRequiredClusterLabels(ctx context.Context, c client.Client, clusters []corev1.ObjectReference,
+	requiredLabels map[string]string, logger logr.Logger) ([]corev1.ObjectReference, error) {
+
+	if len(requiredLabels) == 0 {
+		return clusters, nil
+	}
+
+	selector := labels.SelectorFromSet(requiredLabels)
+	filtered := make([]corev1.ObjectReference, 0, len(clusters))
+	for i := range clusters {
+		ref := &clusters[i]
+		cluster, err := clusterproxy.GetCluster(ctx, c, ref.Namespace, ref.Name, clusterproxy.GetClusterType(ref))
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get cluster %s/%s: %v", ref.Namespace, ref.Name, err))
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(cluster.GetLabels())) {
+			logger.V(logs.LogDebug).Info(fmt.Sprintf("cluster %s/%s does not have all required labels",
+				ref.Namespace, ref.Name))
+			continue
+		}
+		filtered = append(filtered, *ref)
+	}
+
+	return filtered, nil
+}
+
+// limitMatchingClusters, when clusterLimit is set, orders clusters by creationTimestamp and returns
+// the first clusterLimit.Count ones (targeted) and the remaining ones (excluded).
+// Ties are broken by kind, namespace and name so ordering is stable. Clusters which cannot be found
+// come last.
+func limitMatchingClusters(ctx context.Context, c client.Client, clusters []corev1.ObjectReference,
+	clusterLimit *configv1alpha1.ClusterLimit, logger logr.Logger,
+) (targeted, excluded []corev1.ObjectReference, err error) {
+
+	if clusterLimit == nil || len(clusters) <= int(clusterLimit.Count) {
+		return clusters, nil, nil
+	}
+
+	creationTimes := make(map[corev1.ObjectReference]*metav1.Time, len(clusters))
+	for i := range clusters {
+		ref := &clusters[i]
+		cluster, getErr := clusterproxy.GetCluster(ctx, c, ref.Namespace, ref.Name, clusterproxy.GetClusterType(ref))
+		if getErr != nil {
+			if apierrors.IsNotFound(getErr) {
+				continue
+			}
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get cluster %s/%s: %v", ref.Namespace, ref.Name, getErr))
+			return nil, nil, getErr
+		}
+		creationTime := cluster.GetCreationTimestamp()
+		creationTimes[*ref] = &creationTime
+	}
+
+	ordered := make([]corev1.ObjectReference, len(clusters))
+	copy(ordered, clusters)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti, tj := creationTimes[ordered[i]], creationTimes[ordered[j]]
+		switch {
+		case ti == nil || tj == nil:
+			if (ti == nil) != (tj == nil) {
+				return tj == nil
+			}
+		case !ti.Equal(tj):
+			if clusterLimit.Order == configv1alpha1.ClusterOrderNewest {
+				return tj.Before(ti)
+			}
+			return ti.Before(tj)
+		}
+		if ordered[i].Kind != ordered[j].Kind {
+			return ordered[i].Kind < ordered[j].Kind
+		}
+		if ordered[i].Namespace != ordered[j].Namespace {
+			return ordered[i].Namespace < ordered[j].Namespace
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	return ordered[:clusterLimit.Count], ordered[clusterLimit.Count:], nil
+}
+
 // allClusterSummariesGone returns true if all ClusterSummaries owned by a
 // ClusterProfile/Profile instances are gone.
 func allClusterSummariesGone(ctx context.Context, c client.Client, profileScope *scope.ProfileScope) bool {
@@ -446,6 +570,20 @@ func updateClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 		return nil
 	}
 
+	// In OneTime mode changes were possibly skipped. When moving to a continuous mode, current
+	// configuration must be applied again.
+	if clusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeOneTime &&
+		isContinuousSyncMode(profileScope.GetSpec().SyncMode) {
+
+		profileScope.Logger.V(logs.LogDebug).Info(fmt.Sprintf("sync mode moved from OneTime to %s. Redeploy ClusterSummary %s",
+			profileScope.GetSpec().SyncMode, clusterSummary.Name))
+		// Hashes are reset before Spec is updated, as Spec update is what triggers the ClusterSummary
+		// reconciliation. If Spec update fails, hashes are reset again at next attempt.
+		if err := resetClusterSummaryHashes(ctx, c, clusterSummary); err != nil {
+			return err
+		}
+	}
+
 	clusterSummary.Annotations = profileScope.Profile.GetAnnotations()
 	clusterSummary.Spec.ClusterProfileSpec = *profileScope.GetSpec()
 	clusterSummary.Spec.ClusterType = clusterproxy.GetClusterType(cluster)
@@ -455,6 +593,22 @@ func updateClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 	return c.Update(ctx, clusterSummary)
 }
 
+func isContinuousSyncMode(syncMode configv1alpha1.SyncMode) bool {
+	return syncMode == configv1alpha1.SyncModeContinuous ||
+		syncMode == configv1alpha1.SyncModeContinuousWithDriftDetection
+}
+
+// resetClusterSummaryHashes resets the hash of every feature of clusterSummary so that all
+// features are redeployed at next reconciliation. Features status is left untouched.
+func resetClusterSummaryHashes(ctx context.Context, c client.Client,
+	clusterSummary *configv1alpha1.ClusterSummary) error {
+
+	for i := range clusterSummary.Status.FeatureSummaries {
+		clusterSummary.Status.FeatureSummaries[i].Hash = nil
+	}
+	return c.Status().Update(ctx, clusterSummary)
+}
+
 func addClusterSummaryLabels(clusterSummary *configv1alpha1.ClusterSummary, profileScope *scope.ProfileScope,
 	cluster *corev1.ObjectReference) {
 
@@ -495,12 +649,7 @@ func createClusterSummary(ctx context.Context, c client.Client, profileScope *sc
 			Name:      clusterSummaryName,
 			Namespace: cluster.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
-				{
-					APIVersion: configv1alpha1.GroupVersion.String(),
-					Kind:       profileScope.Profile.GetObjectKind().GroupVersionKind().Kind,
-					Name:       profileScope.Profile.GetName(),
-					UID:        profileScope.Profile.GetUID(),
-				},
+				getClusterSummaryOwnerReference(profileScope.Profile),
 			},
 			Annotations: profileScope.Profile.GetAnnotations(),
 		},
@@ -955,9 +1104,13 @@ func reconcileDeleteCommon(ctx context.Context, c client.Client, profileScope *s
 
 	profileScope.SetMatchingClusterRefs(nil)
 
-	if err := cleanClusterSummaries(ctx, c, profileScope); err != nil {
-		logger.V(logs.LogInfo).Error(err, "failed to clean ClusterSummaries")
-		return err
+	// When garbage collected, ClusterSummaries are deleted by Kubernetes. Profile is kept
+	// till they are all gone.
+	if !areClusterSummariesGarbageCollected(profileScope.Profile) {
+		if err := cleanClusterSummaries(ctx, c, profileScope); err != nil {
+			logger.V(logs.LogInfo).Error(err, "failed to clean ClusterSummaries")
+			return err
+		}
 	}
 
 	if !allClusterSummariesGone(ctx, c, profileScope) {
@@ -1003,6 +1156,11 @@ func reconcileNormalCommon(ctx context.Context, c client.Client, profileScope *s
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterReports")
 		return err
 	}
+	// Aggregate ClusterSummary statuses in the ClusterProfile/Profile status
+	if err := updateDeploymentSummary(ctx, c, profileScope); err != nil {
+		logger.V(logs.LogInfo).Error(err, "failed to update deployment summary")
+		return err
+	}
 	// For each matching Sveltos/Cluster, create/update corresponding ClusterSummary
 	if err := updateClusterSummaries(ctx, c, profileScope); err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to update ClusterSummaries")
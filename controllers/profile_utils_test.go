@@ -35,6 +35,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
 	"github.com/projectsveltos/addon-controller/controllers"
@@ -147,6 +148,113 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(len(matching)).To(Equal(2))
 	})
 
+	It("getClustersMatchingAnySelector returns clusters matching at least one of ClusterSelectors", func() {
+		clusterCRD := external.TestClusterCRD.DeepCopy()
+
+		prodCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upstreamClusterNamePrefix + randomString(),
+				Namespace: namespace,
+				Labels:    map[string]string{"env": "prod"},
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneReady: true,
+			},
+		}
+		criticalCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upstreamClusterNamePrefix + randomString(),
+				Namespace: namespace,
+				Labels:    map[string]string{"env": "qa", "tier": "critical"},
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneReady: true,
+			},
+		}
+		prodCriticalCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upstreamClusterNamePrefix + randomString(),
+				Namespace: namespace,
+				Labels:    map[string]string{"env": "prod", "tier": "critical"},
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneReady: true,
+			},
+		}
+		devCluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upstreamClusterNamePrefix + randomString(),
+				Namespace: namespace,
+				Labels:    map[string]string{"env": "dev"},
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneReady: true,
+			},
+		}
+
+		initObjects := []client.Object{
+			clusterCRD,
+			prodCluster,
+			criticalCluster,
+			prodCriticalCluster,
+			devCluster,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		clusterProfile.Spec.ClusterSelector = ""
+		clusterProfile.Spec.ClusterSelectors = []libsveltosv1alpha1.Selector{"env=prod", "", "tier=critical"}
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         logger,
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		Expect(profileScope.GetSelectors()).To(HaveLen(2))
+
+		// Each cluster matching more than one selector is reported once
+		matching, err := controllers.GetClustersMatchingAnySelector(context.TODO(), c, "",
+			profileScope.GetSelectors(), logger)
+		Expect(err).To(BeNil())
+		Expect(len(matching)).To(Equal(3))
+		for _, cluster := range []*clusterv1.Cluster{prodCluster, criticalCluster, prodCriticalCluster} {
+			Expect(matching).To(ContainElement(corev1.ObjectReference{
+				Kind: clusterKind, APIVersion: clusterv1.GroupVersion.String(),
+				Namespace: cluster.Namespace, Name: cluster.Name,
+			}))
+		}
+
+		// No selector means no cluster
+		matching, err = controllers.GetClustersMatchingAnySelector(context.TODO(), c, "", nil, logger)
+		Expect(err).To(BeNil())
+		Expect(matching).To(BeEmpty())
+	})
+
+	It("validateSelectors returns an error when any selector is malformed", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		clusterProfile.Spec.ClusterSelector = "env=prod"
+		clusterProfile.Spec.ClusterSelectors = []libsveltosv1alpha1.Selector{"tier in (critical,high)"}
+		profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         logger,
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+		Expect(controllers.ValidateSelectors(profileScope)).To(Succeed())
+
+		clusterProfile.Spec.ClusterSelectors = append(clusterProfile.Spec.ClusterSelectors, "tier in (critical")
+		err = controllers.ValidateSelectors(profileScope)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("clusterSelectors[1]"))
+
+		clusterProfile.Spec.ClusterSelectors = nil
+		clusterProfile.Spec.ClusterSelector = "env=prod,,"
+		Expect(controllers.ValidateSelectors(profileScope)).ToNot(Succeed())
+	})
+
 	It("UpdateClusterConfiguration idempotently adds ClusterProfile as OwnerReference and in Status.ClusterProfileResources", func() {
 		ns := &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -464,6 +572,78 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(len(clusterSummaryList.Items[0].Spec.ClusterProfileSpec.PolicyRefs)).To(Equal(2))
 	})
 
+	It("UpdateClusterSummary forces redeploy when ClusterProfile syncmode moves from one time to continuous", func() {
+		clusterProfile.Spec.SyncMode = configv1alpha1.SyncModeOneTime
+
+		clusterSummaryName := controllers.GetClusterSummaryName(configv1alpha1.ClusterProfileKind,
+			clusterProfile.Name, matchingCluster.Name, false)
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterSummaryName,
+				Namespace: matchingCluster.Namespace,
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace:   matchingCluster.Namespace,
+				ClusterName:        matchingCluster.Name,
+				ClusterProfileSpec: clusterProfile.Spec,
+				ClusterType:        libsveltosv1alpha1.ClusterTypeCapi,
+			},
+			Status: configv1alpha1.ClusterSummaryStatus{
+				FeatureSummaries: []configv1alpha1.FeatureSummary{
+					{
+						FeatureID: configv1alpha1.FeatureResources,
+						Hash:      []byte(randomString()),
+						Status:    configv1alpha1.FeatureStatusProvisioned,
+					},
+					{
+						FeatureID: configv1alpha1.FeatureHelm,
+						Hash:      []byte(randomString()),
+						Status:    configv1alpha1.FeatureStatusProvisioned,
+					},
+				},
+			},
+		}
+		addLabelsToClusterSummary(clusterSummary, clusterProfile.Name, matchingCluster.Name, libsveltosv1alpha1.ClusterTypeCapi)
+
+		initObjects := []client.Object{
+			clusterProfile,
+			matchingCluster,
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+		clusterProfile.Spec.SyncMode = configv1alpha1.SyncModeContinuous
+		Expect(c.Update(context.TODO(), clusterProfile)).To(Succeed())
+
+		clusterProfileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+			Client:         c,
+			Logger:         logger,
+			Profile:        clusterProfile,
+			ControllerName: "clusterprofile",
+		})
+		Expect(err).To(BeNil())
+
+		err = controllers.UpdateClusterSummary(context.TODO(), c,
+			clusterProfileScope, &corev1.ObjectReference{
+				Namespace: matchingCluster.Namespace, Name: matchingCluster.Name,
+				Kind: clusterKind, APIVersion: clusterv1.GroupVersion.String()})
+		Expect(err).To(BeNil())
+
+		currentClusterSummary := &configv1alpha1.ClusterSummary{}
+		Expect(c.Get(context.TODO(),
+			types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+			currentClusterSummary)).To(Succeed())
+		Expect(currentClusterSummary.Spec.ClusterProfileSpec.SyncMode).To(Equal(configv1alpha1.SyncModeContinuous))
+		Expect(len(currentClusterSummary.Status.FeatureSummaries)).To(Equal(2))
+		for i := range currentClusterSummary.Status.FeatureSummaries {
+			Expect(currentClusterSummary.Status.FeatureSummaries[i].Hash).To(BeNil())
+			// Only hashes are reset. Status is updated by the ClusterSummary reconciler when redeploying.
+			Expect(currentClusterSummary.Status.FeatureSummaries[i].Status).To(
+				Equal(configv1alpha1.FeatureStatusProvisioned))
+		}
+	})
+
 	It("cleanClusterSummaries removes ClusterSummary for non-matching cluster", func() {
 		clusterProfile.Spec.SyncMode = configv1alpha1.SyncModeOneTime
 		clusterProfile.Spec.PolicyRefs = []configv1alpha1.PolicyRef{
@@ -1046,6 +1226,126 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(err).To(BeNil())
 	})
 
+	It("CreateClusterSummary sets ClusterSummary OwnerReference based on cleanup strategy", func() {
+		defer func() {
+			Expect(controllers.SetClusterSummaryCleanupStrategy(controllers.ClusterSummaryCleanupFinalizer)).To(Succeed())
+		}()
+
+		for _, strategy := range []controllers.ClusterSummaryCleanupStrategy{
+			controllers.ClusterSummaryCleanupFinalizer, controllers.ClusterSummaryCleanupOwnerReference} {
+
+			Expect(controllers.SetClusterSummaryCleanupStrategy(strategy)).To(Succeed())
+
+			initObjects := []client.Object{
+				clusterProfile,
+				matchingCluster,
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+			clusterProfileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+				Client:         c,
+				Logger:         logger,
+				Profile:        clusterProfile,
+				ControllerName: "clusterprofile",
+			})
+			Expect(err).To(BeNil())
+
+			Expect(controllers.CreateClusterSummary(context.TODO(), c, clusterProfileScope,
+				&corev1.ObjectReference{
+					Namespace:  matchingCluster.Namespace,
+					Name:       matchingCluster.Name,
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       clusterKind,
+				})).To(Succeed())
+
+			clusterSummaryList := &configv1alpha1.ClusterSummaryList{}
+			Expect(c.List(context.TODO(), clusterSummaryList)).To(Succeed())
+			Expect(len(clusterSummaryList.Items)).To(Equal(1))
+			Expect(len(clusterSummaryList.Items[0].OwnerReferences)).To(Equal(1))
+			owner := clusterSummaryList.Items[0].OwnerReferences[0]
+			Expect(owner.Name).To(Equal(clusterProfile.Name))
+			Expect(owner.UID).To(Equal(clusterProfile.UID))
+
+			garbageCollected := strategy == controllers.ClusterSummaryCleanupOwnerReference
+			Expect(owner.Controller != nil && *owner.Controller).To(Equal(garbageCollected))
+			Expect(owner.BlockOwnerDeletion != nil && *owner.BlockOwnerDeletion).To(Equal(garbageCollected))
+		}
+	})
+
+	It("SetClusterSummaryCleanupStrategy rejects unknown strategies", func() {
+		Expect(controllers.SetClusterSummaryCleanupStrategy("unknown")).ToNot(Succeed())
+	})
+
+	It("reconcileDeleteCommon leaves ClusterSummaries to the garbage collector only when deleted in foreground", func() {
+		defer func() {
+			Expect(controllers.SetClusterSummaryCleanupStrategy(controllers.ClusterSummaryCleanupFinalizer)).To(Succeed())
+		}()
+
+		for _, strategy := range []controllers.ClusterSummaryCleanupStrategy{
+			controllers.ClusterSummaryCleanupFinalizer, controllers.ClusterSummaryCleanupOwnerReference} {
+
+			Expect(controllers.SetClusterSummaryCleanupStrategy(strategy)).To(Succeed())
+
+			profile := &configv1alpha1.ClusterProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       randomString(),
+					Finalizers: []string{configv1alpha1.ClusterProfileFinalizer, metav1.FinalizerDeleteDependents},
+				},
+			}
+			Expect(addTypeInformationToObject(scheme, profile)).To(Succeed())
+
+			clusterSummary := &configv1alpha1.ClusterSummary{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: randomString(),
+					Name:      randomString(),
+					Labels: map[string]string{
+						controllers.ClusterProfileLabelName: profile.Name,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							Name:       profile.Name,
+							Kind:       configv1alpha1.ClusterProfileKind,
+							APIVersion: configv1alpha1.GroupVersion.String(),
+						},
+					},
+				},
+			}
+
+			initObjects := []client.Object{
+				profile,
+				clusterSummary,
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(initObjects...).WithObjects(initObjects...).Build()
+
+			profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+				Client:         c,
+				Logger:         logger,
+				Profile:        profile,
+				ControllerName: "clusterprofile",
+			})
+			Expect(err).To(BeNil())
+
+			// Profile finalizer cannot be removed while a ClusterSummary exists
+			Expect(controllers.ReconcileDeleteCommon(context.TODO(), c, profileScope,
+				configv1alpha1.ClusterProfileFinalizer, logger)).ToNot(Succeed())
+			Expect(controllerutil.ContainsFinalizer(profile, configv1alpha1.ClusterProfileFinalizer)).To(BeTrue())
+
+			currentClusterSummary := &configv1alpha1.ClusterSummary{}
+			err = c.Get(context.TODO(),
+				types.NamespacedName{Namespace: clusterSummary.Namespace, Name: clusterSummary.Name},
+				currentClusterSummary)
+			if strategy == controllers.ClusterSummaryCleanupOwnerReference {
+				// Deletion is left to the garbage collector
+				Expect(err).To(BeNil())
+			} else {
+				Expect(err).ToNot(BeNil())
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		}
+	})
+
 	It("getMaxUpdate returns max value of clusters that can be updated (fixed)", func() {
 		const maxUpdate = int32(10)
 		clusterProfile.Spec.MaxUpdate = &intstr.IntOrString{Type: intstr.Int, IntVal: maxUpdate}
@@ -1107,6 +1407,99 @@ var _ = Describe("Profile: Reconciler", func() {
 		Expect(controllers.GetMaxUpdate(clusterProfileScope)).To(Equal(int32(1)))
 	})
 
+	It("limitMatchingClusters targets the newest or oldest clusters", func() {
+		namespace := randomString()
+		now := time.Now()
+		initObjects := []client.Object{}
+		clusterRefs := []corev1.ObjectReference{}
+		// clusters[0] is the oldest, clusters[3] the newest
+		for i := 0; i < 4; i++ {
+			cluster := &libsveltosv1alpha1.SveltosCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         namespace,
+					Name:              fmt.Sprintf("cluster-%d", i),
+					CreationTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Hour)),
+				},
+			}
+			initObjects = append(initObjects, cluster)
+			clusterRefs = append(clusterRefs, corev1.ObjectReference{
+				Namespace: namespace, Name: cluster.Name,
+				Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+			})
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		// Listed in random order
+		matching := []corev1.ObjectReference{clusterRefs[2], clusterRefs[0], clusterRefs[3], clusterRefs[1]}
+
+		targeted, excluded, err := controllers.LimitMatchingClusters(context.TODO(), c, matching, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(targeted).To(Equal(matching))
+		Expect(excluded).To(BeEmpty())
+
+		clusterLimit := &configv1alpha1.ClusterLimit{Count: 2, Order: configv1alpha1.ClusterOrderOldest}
+		targeted, excluded, err = controllers.LimitMatchingClusters(context.TODO(), c, matching, clusterLimit, logger)
+		Expect(err).To(BeNil())
+		Expect(targeted).To(Equal([]corev1.ObjectReference{clusterRefs[0], clusterRefs[1]}))
+		Expect(excluded).To(Equal([]corev1.ObjectReference{clusterRefs[2], clusterRefs[3]}))
+
+		clusterLimit.Order = configv1alpha1.ClusterOrderNewest
+		targeted, excluded, err = controllers.LimitMatchingClusters(context.TODO(), c, matching, clusterLimit, logger)
+		Expect(err).To(BeNil())
+		Expect(targeted).To(Equal([]corev1.ObjectReference{clusterRefs[3], clusterRefs[2]}))
+		Expect(excluded).To(Equal([]corev1.ObjectReference{clusterRefs[1], clusterRefs[0]}))
+
+		// Limit greater than number of matching clusters
+		clusterLimit.Count = 10
+		targeted, excluded, err = controllers.LimitMatchingClusters(context.TODO(), c, matching, clusterLimit, logger)
+		Expect(err).To(BeNil())
+		Expect(targeted).To(Equal(matching))
+		Expect(excluded).To(BeEmpty())
+	})
+
+	It("filterByRequiredClusterLabels returns only clusters having all required labels", func() {
+		namespace := randomString()
+		inRegion := &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels:    map[string]string{"region": "eu-west", "env": "prod"},
+			},
+		}
+		outOfRegion := &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      randomString(),
+				Labels:    map[string]string{"region": "us-east", "env": "prod"},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(inRegion, outOfRegion).Build()
+
+		clusterRefs := []corev1.ObjectReference{}
+		for _, cluster := range []*libsveltosv1alpha1.SveltosCluster{inRegion, outOfRegion} {
+			clusterRefs = append(clusterRefs, corev1.ObjectReference{
+				Namespace: cluster.Namespace, Name: cluster.Name,
+				Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+			})
+		}
+		// A cluster which does not exist is dropped
+		clusterRefs = append(clusterRefs, corev1.ObjectReference{
+			Namespace: namespace, Name: randomString(),
+			Kind: libsveltosv1alpha1.SveltosClusterKind, APIVersion: libsveltosv1alpha1.GroupVersion.String(),
+		})
+
+		filtered, err := controllers.FilterByRequiredClusterLabels(context.TODO(), c, clusterRefs, nil, logger)
+		Expect(err).To(BeNil())
+		Expect(filtered).To(Equal(clusterRefs))
+
+		filtered, err = controllers.FilterByRequiredClusterLabels(context.TODO(), c, clusterRefs,
+			map[string]string{"region": "eu-west", "env": "prod"}, logger)
+		Expect(err).To(BeNil())
+		Expect(filtered).To(Equal([]corev1.ObjectReference{clusterRefs[0]}))
+	})
+
 	It("reviseUpdatedAndUpdatingClusters removes non matching clusters from ClusterProfile Updated/Updating Clusters",
 		func() {
 			cluster1 := types.NamespacedName{Namespace: randomString(), Name: randomString()}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// referenceDebouncer implements a trailing-edge debounce: a request is added to the queue only
+// once no new event has been seen for that request for the whole quiet period.
+// Every new event for a request restarts its quiet period. So a referenced resource which keeps
+// changing postpones the reconciliation of its consumers till changes stop.
+type referenceDebouncer struct {
+	quietPeriod time.Duration
+
+	mux sync.Mutex
+	// lastEvent contains, for each request waiting to be queued, the time of its most recent event
+	lastEvent map[reconcile.Request]time.Time
+}
+
+func newReferenceDebouncer(quietPeriod time.Duration) *referenceDebouncer {
+	return &referenceDebouncer{
+		quietPeriod: quietPeriod,
+		lastEvent:   make(map[reconcile.Request]time.Time),
+	}
+}
+
+// add records an event for request. Request is added to q once quietPeriod has elapsed
+// without any further event for it.
+func (d *referenceDebouncer) add(request reconcile.Request, q workqueue.RateLimitingInterface) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	_, pending := d.lastEvent[request]
+	d.lastEvent[request] = time.Now()
+	if pending {
+		// A timer is already running for this request. It will notice the new event.
+		return
+	}
+
+	d.schedule(request, q, d.quietPeriod)
+}
+
+func (d *referenceDebouncer) schedule(request reconcile.Request, q workqueue.RateLimitingInterface,
+	after time.Duration) {
+
+	time.AfterFunc(after, func() {
+		d.mux.Lock()
+		elapsed := time.Since(d.lastEvent[request])
+		if elapsed < d.quietPeriod {
+			// Events were received since timer started. Wait till quiet period is over.
+			d.schedule(request, q, d.quietPeriod-elapsed)
+			d.mux.Unlock()
+			return
+		}
+		delete(d.lastEvent, request)
+		d.mux.Unlock()
+
+		q.Add(request)
+	})
+}
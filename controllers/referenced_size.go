@@ -0,0 +1,86 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+const (
+	// referencedObjectSizeLimit is the maximum size of ConfigMap and Secret data enforced by
+	// the API server (1MiB)
+	referencedObjectSizeLimit = corev1.MaxSecretSize
+
+	// referencedObjectSizeWarningPercentage is the percentage of referencedObjectSizeLimit
+	// above which a referenced ConfigMap/Secret is reported as close to the limit
+	referencedObjectSizeWarningPercentage = 90
+)
+
+// getReferencedObjectDataSize returns the size of the data stored in a ConfigMap/Secret.
+// Returns 0 for any other object.
+func getReferencedObjectDataSize(object client.Object) int {
+	size := 0
+	switch o := object.(type) {
+	case *corev1.ConfigMap:
+		for k, v := range o.Data {
+			size += len(k) + len(v)
+		}
+		for k, v := range o.BinaryData {
+			size += len(k) + len(v)
+		}
+	case *corev1.Secret:
+		for k, v := range o.Data {
+			size += len(k) + len(v)
+		}
+		for k, v := range o.StringData {
+			size += len(k) + len(v)
+		}
+	}
+	return size
+}
+
+// getSizeLimitWarning returns a message recommending to split referenced ConfigMap/Secret
+// when its data is close to the size limit. Returns an empty string otherwise.
+func getSizeLimitWarning(kind string, object client.Object) string {
+	size := getReferencedObjectDataSize(object)
+	if size*100 < referencedObjectSizeLimit*referencedObjectSizeWarningPercentage {
+		return ""
+	}
+
+	return fmt.Sprintf("referenced %s %s/%s data is %d bytes, close to the %d bytes limit enforced "+
+		"on ConfigMaps/Secrets. Consider splitting its content across multiple ConfigMaps/Secrets",
+		kind, object.GetNamespace(), object.GetName(), size, referencedObjectSizeLimit)
+}
+
+// getMissingReferenceMessage returns the message reported when a referenced resource does not exist.
+// For ConfigMaps/Secrets, it mentions the size limit as a ConfigMap/Secret exceeding it cannot be created.
+func getMissingReferenceMessage(kind, namespace, name string) string {
+	msg := fmt.Sprintf("Referenced resource: %s %s/%s does not exist", kind, namespace, name)
+	if kind == string(libsveltosv1alpha1.ConfigMapReferencedResourceKind) ||
+		kind == string(libsveltosv1alpha1.SecretReferencedResourceKind) {
+
+		msg += fmt.Sprintf(". Note that ConfigMap/Secret data cannot exceed %d bytes and the API server rejects "+
+			"creating larger ones: if its content is that large, split it across multiple ConfigMaps/Secrets",
+			referencedObjectSizeLimit)
+	}
+	return msg
+}
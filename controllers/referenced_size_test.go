@@ -0,0 +1,60 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Referenced object size", func() {
+	It("getSizeLimitWarning reports ConfigMaps close to the size limit", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+			Data: map[string]string{
+				"policy.yaml": strings.Repeat("a", 1024),
+			},
+		}
+
+		kind := string(libsveltosv1alpha1.ConfigMapReferencedResourceKind)
+		Expect(controllers.GetSizeLimitWarning(kind, configMap)).To(BeEmpty())
+
+		configMap.Data["policies.yaml"] = strings.Repeat("a", corev1.MaxSecretSize*95/100)
+		msg := controllers.GetSizeLimitWarning(kind, configMap)
+		Expect(msg).To(ContainSubstring(configMap.Name))
+		Expect(msg).To(ContainSubstring("splitting"))
+	})
+
+	It("getMissingReferenceMessage mentions the size limit only for ConfigMaps/Secrets", func() {
+		msg := controllers.GetMissingReferenceMessage(string(libsveltosv1alpha1.SecretReferencedResourceKind),
+			randomString(), randomString())
+		Expect(msg).To(ContainSubstring("1048576 bytes"))
+
+		msg = controllers.GetMissingReferenceMessage("GitRepository", randomString(), randomString())
+		Expect(msg).ToNot(ContainSubstring("bytes"))
+	})
+})
@@ -0,0 +1,204 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// RenderPath is the path the render endpoint is served at.
+	// The endpoint is only served by the secure diagnostics server (so when --insecure-diagnostics
+	// is not set), as rendered resources might contain the content of Secrets.
+	// Query parameters:
+	// - profileKind: ClusterProfile or Profile. Defaults to ClusterProfile;
+	// - profileName: name of the ClusterProfile/Profile;
+	// - clusterNamespace, clusterName: the managed cluster;
+	// - clusterType: Capi or Sveltos. Defaults to Capi.
+	RenderPath = "/render"
+)
+
+// renderResources returns, in YAML format, all resources the PolicyRefs and InlinePolicies of
+// clusterSummary would deploy. The same path used when deploying is used (template instantiation,
+// key selection, apply order, excluded objects, patches, extra labels and annotations) but nothing
+// is applied.
+// KustomizationRefs and HelmCharts are not rendered, nor are Flux Sources with no artifact yet. For
+// each of those, a "# not rendered: <what>" comment is added to the output instead.
+func renderResources(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	logger logr.Logger) ([]byte, error) {
+
+	local, remote, err := collectReferencedObjects(ctx, c, clusterSummary.Namespace,
+		clusterSummary.Spec.ClusterProfileSpec.PolicyRefs, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mgmtResources, err := collectTemplateResourceRefs(ctx, clusterSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	referencedObjects := append(local, remote...)
+	for i := range referencedObjects {
+		var content *referencedContent
+		content, err = getPreparedContent(ctx, referencedObjects[i], clusterSummary, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			result = append(result, getNotRenderedSection(fmt.Sprintf("%s %s %s/%s (no artifact yet)",
+				configv1alpha1.FeatureResources, referencedObjects[i].GetObjectKind().GroupVersionKind().Kind,
+				referencedObjects[i].GetNamespace(), referencedObjects[i].GetName()))...)
+			continue
+		}
+
+		var out []byte
+		out, err = renderContent(clusterSummary, content.resources)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out...)
+	}
+
+	// InlinePolicies are deployed as if contained in a ConfigMap which is never a template
+	// (see deployInlinePolicies)
+	inlinePolicies := clusterSummary.Spec.ClusterProfileSpec.InlinePolicies
+	if len(inlinePolicies) != 0 {
+		data := make(map[string]string, len(inlinePolicies))
+		for i := range inlinePolicies {
+			data[fmt.Sprintf("inline-policy-%d", i)] = inlinePolicies[i]
+		}
+
+		var content *referencedContent
+		content, err = collectReferencedContent(ctx, getInlinePoliciesReference(clusterSummary), data,
+			clusterSummary, mgmtResources, logger)
+		if err != nil {
+			return nil, err
+		}
+		content.resources, err = prepareUnstructured(content.resources, configv1alpha1.FeatureResources,
+			clusterSummary, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		var out []byte
+		out, err = renderContent(clusterSummary, content.resources)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, out...)
+	}
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.KustomizationRefs) != 0 {
+		result = append(result, getNotRenderedSection(string(configv1alpha1.FeatureKustomize))...)
+	}
+
+	if len(clusterSummary.Spec.ClusterProfileSpec.HelmCharts) != 0 {
+		result = append(result, getNotRenderedSection(string(configv1alpha1.FeatureHelm))...)
+	}
+
+	return result, nil
+}
+
+// renderContent returns, in YAML format, resources (already prepared, see getPreparedContent) as those
+// would be deployed
+func renderContent(clusterSummary *configv1alpha1.ClusterSummary, resources []*unstructured.Unstructured,
+) ([]byte, error) {
+
+	var result []byte
+	for i := range resources {
+		addExtraLabels(resources[i], clusterSummary.Spec.ClusterProfileSpec.ExtraLabels)
+		addExtraAnnotations(resources[i], clusterSummary.Spec.ClusterProfileSpec.ExtraAnnotations)
+		err := setWorkloadScheduling(resources[i], &clusterSummary.Spec.ClusterProfileSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		var out []byte
+		out, err = yaml.Marshal(resources[i].Object)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, []byte("---\n")...)
+		result = append(result, out...)
+	}
+
+	return result, nil
+}
+
+// getNotRenderedSection returns the section reported for content the render endpoint does not render
+func getNotRenderedSection(what string) []byte {
+	return []byte(fmt.Sprintf("---\n# not rendered: %s\n", what))
+}
+
+// RenderHandler returns an http.Handler which, given a ClusterProfile/Profile and a managed
+// cluster, writes all resources that would be deployed in such cluster without applying them.
+// See RenderPath for the query parameters and renderResources for what is rendered.
+func RenderHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := ctrl.Log.WithName("render")
+
+		query := r.URL.Query()
+		profileKind := query.Get("profileKind")
+		if profileKind == "" {
+			profileKind = configv1alpha1.ClusterProfileKind
+		}
+		clusterType := libsveltosv1alpha1.ClusterType(query.Get("clusterType"))
+		if clusterType == "" {
+			clusterType = libsveltosv1alpha1.ClusterTypeCapi
+		}
+		profileName := query.Get("profileName")
+		clusterNamespace := query.Get("clusterNamespace")
+		clusterName := query.Get("clusterName")
+		if profileName == "" || clusterNamespace == "" || clusterName == "" {
+			http.Error(w, "profileName, clusterNamespace and clusterName are required", http.StatusBadRequest)
+			return
+		}
+
+		c := getManagementClusterClient()
+		clusterSummary, err := getClusterSummary(r.Context(), c, profileKind, profileName,
+			clusterNamespace, clusterName, clusterType)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		logger = logger.WithValues("clusterSummary", fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name))
+		result, err := renderResources(r.Context(), c, clusterSummary, logger)
+		if err != nil {
+			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to render resources: %v", err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(result)
+	})
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+const (
+	renderClusterRoleTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: %s
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get"]`
+)
+
+var _ = Describe("Render", func() {
+	It("renderResources returns resources referenced by PolicyRefs without deploying those", func() {
+		namespace := randomString()
+		selectedName := randomString()
+		ignoredName := randomString()
+
+		configMap := createConfigMapWithPolicy(namespace, randomString(),
+			fmt.Sprintf(renderClusterRoleTemplate, selectedName),
+			fmt.Sprintf(renderClusterRoleTemplate, ignoredName))
+
+		labelKey := randomString()
+		labelValue := randomString()
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: namespace,
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					PolicyRefs: []configv1alpha1.PolicyRef{
+						{
+							Namespace: namespace, Name: configMap.Name,
+							Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+							Keys: []string{"policy0.yaml"},
+						},
+					},
+					ExtraLabels: map[string]string{labelKey: labelValue},
+				},
+			},
+		}
+
+		initObjects := []client.Object{
+			configMap,
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		result, err := controllers.RenderResources(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		rendered := string(result)
+		Expect(rendered).To(ContainSubstring("kind: ClusterRole"))
+		Expect(rendered).To(ContainSubstring(selectedName))
+		Expect(rendered).To(ContainSubstring(fmt.Sprintf("%s: %s", labelKey, labelValue)))
+		Expect(rendered).ToNot(ContainSubstring(ignoredName))
+	})
+
+	It("renderResources renders InlinePolicies and reports features which are not rendered", func() {
+		namespace := randomString()
+		inlineName := randomString()
+
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: namespace,
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					InlinePolicies: []string{fmt.Sprintf(renderClusterRoleTemplate, inlineName)},
+					KustomizationRefs: []configv1alpha1.KustomizationRef{
+						{
+							Namespace: namespace, Name: randomString(),
+							Kind: sourcev1.GitRepositoryKind,
+						},
+					},
+					HelmCharts: []configv1alpha1.HelmChart{
+						{
+							RepositoryURL: randomString(), RepositoryName: randomString(),
+							ChartName: randomString(), ChartVersion: randomString(),
+							ReleaseName: randomString(), ReleaseNamespace: randomString(),
+						},
+					},
+				},
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterSummary).Build()
+
+		result, err := controllers.RenderResources(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		rendered := string(result)
+		Expect(rendered).To(ContainSubstring(inlineName))
+		Expect(rendered).To(ContainSubstring(fmt.Sprintf("# not rendered: %s", configv1alpha1.FeatureKustomize)))
+		Expect(rendered).To(ContainSubstring(fmt.Sprintf("# not rendered: %s", configv1alpha1.FeatureHelm)))
+	})
+
+	It("renderResources prepares resources as those are deployed", func() {
+		namespace := randomString()
+		keptName := randomString()
+		excludedName := randomString()
+		namespaceName := randomString()
+
+		configMap := createConfigMapWithPolicy(namespace, randomString(),
+			fmt.Sprintf(renderClusterRoleTemplate, keptName),
+			fmt.Sprintf(renderClusterRoleTemplate, excludedName),
+			fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %s`, namespaceName))
+
+		patchedLabel := randomString()
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randomString(),
+				Namespace: namespace,
+			},
+			Spec: configv1alpha1.ClusterSummarySpec{
+				ClusterNamespace: namespace,
+				ClusterName:      randomString(),
+				ClusterType:      libsveltosv1alpha1.ClusterTypeCapi,
+				ClusterProfileSpec: configv1alpha1.Spec{
+					PolicyRefs: []configv1alpha1.PolicyRef{
+						{
+							Namespace: namespace, Name: configMap.Name,
+							Kind: string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+						},
+					},
+					ExcludedObjects: []configv1alpha1.ExcludedObject{
+						{
+							FeatureID: configv1alpha1.FeatureResources,
+							Group:     "rbac.authorization.k8s.io",
+							Kind:      "ClusterRole",
+							Name:      excludedName,
+						},
+					},
+					Patches: []configv1alpha1.Patch{
+						{
+							FeatureID: configv1alpha1.FeatureResources,
+							Target:    configv1alpha1.PatchSelector{Kind: "ClusterRole"},
+							Patch: fmt.Sprintf(`- op: add
+  path: /metadata/labels
+  value:
+    %s: ok`, patchedLabel),
+						},
+					},
+				},
+			},
+		}
+
+		initObjects := []client.Object{
+			configMap,
+			clusterSummary,
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		result, err := controllers.RenderResources(context.TODO(), c, clusterSummary,
+			textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+
+		rendered := string(result)
+		Expect(rendered).To(ContainSubstring(keptName))
+		Expect(rendered).ToNot(ContainSubstring(excludedName))
+		Expect(rendered).To(ContainSubstring(fmt.Sprintf("%s: ok", patchedLabel)))
+		// Namespaces are applied first
+		Expect(strings.Index(rendered, namespaceName)).To(BeNumerically("<", strings.Index(rendered, keptName)))
+	})
+})
@@ -23,6 +23,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -32,7 +33,6 @@ import (
 
 	driftdetection "github.com/projectsveltos/addon-controller/pkg/drift-detection"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
-	"github.com/projectsveltos/libsveltos/lib/clusterproxy"
 	"github.com/projectsveltos/libsveltos/lib/crd"
 	"github.com/projectsveltos/libsveltos/lib/logsettings"
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
@@ -55,16 +55,18 @@ func getResourceSummaryName(clusterSummaryNamespace, clusterSummaryName string)
 	return fmt.Sprintf("%s--%s", clusterSummaryNamespace, clusterSummaryName)
 }
 
+// deployDriftDetectionManagerInCluster deploys drift-detection-manager for a cluster. Container images
+// are rewritten according to registryOverrides (see ClusterProfile/Profile Spec.RegistryOverrides).
 func deployDriftDetectionManagerInCluster(ctx context.Context, c client.Client,
 	clusterNamespace, clusterName, applicant string, clusterType libsveltosv1alpha1.ClusterType,
-	startInMgmtCluster bool, logger logr.Logger) error {
+	startInMgmtCluster bool, registryOverrides map[string]string, logger logr.Logger) error {
 
 	logger = logger.WithValues("clustersummary", applicant)
 	logger = logger.WithValues("cluster", fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName))
 	logger.V(logs.LogDebug).Info("deploy drift detection manager: do not send updates mode")
 
 	// Sveltos resources are deployed using cluster-admin role.
-	remoteRestConfig, err := clusterproxy.GetKubernetesRestConfig(ctx, c, clusterNamespace,
+	remoteRestConfig, err := getKubernetesRestConfig(ctx, c, clusterNamespace,
 		clusterName, "", "", clusterType, logger)
 	if err != nil {
 		logger.V(logs.LogInfo).Error(err, "failed to get cluster rest config")
@@ -81,11 +83,11 @@ func deployDriftDetectionManagerInCluster(ctx context.Context, c client.Client,
 	if startInMgmtCluster {
 		restConfig := getManagementClusterConfig()
 		return deployDriftDetectionManagerInManagementCluster(ctx, restConfig, clusterNamespace,
-			clusterName, "do-not-send-reports", clusterType, logger)
+			clusterName, "do-not-send-reports", clusterType, registryOverrides, logger)
 	}
 
 	return deployDriftDetectionManager(ctx, remoteRestConfig, clusterNamespace,
-		clusterName, "do-not-send-reports", clusterType, logger)
+		clusterName, "do-not-send-reports", clusterType, registryOverrides, logger)
 }
 
 func deployResourceSummaryInCluster(ctx context.Context, c client.Client,
@@ -99,7 +101,7 @@ func deployResourceSummaryInCluster(ctx context.Context, c client.Client,
 	// ResourceSummary is a Sveltos resource created in managed clusters.
 	// Sveltos resources are always created using cluster-admin so that admin does not need to be
 	// given such permissions.
-	remoteClient, err := clusterproxy.GetKubernetesClient(ctx, c, clusterNamespace, clusterName, "", "",
+	remoteClient, err := getKubernetesClient(ctx, c, clusterNamespace, clusterName, "", "",
 		clusterType, logger)
 	if err != nil {
 		return err
@@ -212,15 +214,21 @@ func prepareDriftDetectionManagerYAML(driftDetectionManagerYAML, clusterNamespac
 // deployDriftDetectionManager deploys drift-detection-manager in the managed cluster
 func deployDriftDetectionManager(ctx context.Context, remoteRestConfig *rest.Config,
 	clusterNamespace, clusterName, mode string, clusterType libsveltosv1alpha1.ClusterType,
-	logger logr.Logger) error {
+	registryOverrides map[string]string, logger logr.Logger) error {
 
 	logger.V(logs.LogDebug).Info("deploy drift-detection-manager in managed cluster")
-	driftDetectionManagerYAML := string(driftdetection.GetDriftDetectionManagerYAML())
+	manifest, err := driftdetection.GetDriftDetectionManagerYAML()
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get drift-detection-manager yaml: %v", err))
+		return err
+	}
+	driftDetectionManagerYAML := string(manifest)
 
 	driftDetectionManagerYAML = prepareDriftDetectionManagerYAML(driftDetectionManagerYAML, clusterNamespace,
 		clusterName, mode, clusterType)
 
-	return deployDriftDetectionManagerResources(ctx, remoteRestConfig, driftDetectionManagerYAML, nil, logger)
+	return deployDriftDetectionManagerResources(ctx, remoteRestConfig, driftDetectionManagerYAML, nil,
+		registryOverrides, logger)
 }
 
 // deployDriftDetectionManagerInManagementCluster deploys drift-detection-manager in the management cluster
@@ -229,10 +237,15 @@ func deployDriftDetectionManager(ctx context.Context, remoteRestConfig *rest.Con
 // Those instances are all running in the "projectsveltos" namespace.
 func deployDriftDetectionManagerInManagementCluster(ctx context.Context, restConfig *rest.Config,
 	clusterNamespace, clusterName, mode string, clusterType libsveltosv1alpha1.ClusterType,
-	logger logr.Logger) error {
+	registryOverrides map[string]string, logger logr.Logger) error {
 
 	logger.V(logs.LogDebug).Info("deploy drift-detection-manager in management cluster")
-	driftDetectionManagerYAML := string(driftdetection.GetDriftDetectionManagerInMgmtClusterYAML())
+	manifest, err := driftdetection.GetDriftDetectionManagerInMgmtClusterYAML()
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get drift-detection-manager yaml: %v", err))
+		return err
+	}
+	driftDetectionManagerYAML := string(manifest)
 
 	driftDetectionManagerYAML = prepareDriftDetectionManagerYAML(driftDetectionManagerYAML, clusterNamespace,
 		clusterName, mode, clusterType)
@@ -250,24 +263,61 @@ func deployDriftDetectionManagerInManagementCluster(ctx context.Context, restCon
 
 	if create {
 		driftDetectionManagerYAML = strings.ReplaceAll(driftDetectionManagerYAML, "$NAME", name)
-		return deployDriftDetectionManagerResources(ctx, restConfig, driftDetectionManagerYAML, lbls, logger)
+		return deployDriftDetectionManagerResources(ctx, restConfig, driftDetectionManagerYAML, lbls,
+			registryOverrides, logger)
 	}
 
 	return nil
 }
 
 func deployDriftDetectionManagerResources(ctx context.Context, restConfig *rest.Config,
-	driftDetectionManagerYAML string, lbls map[string]string, logger logr.Logger) error {
+	driftDetectionManagerYAML string, lbls, registryOverrides map[string]string, logger logr.Logger) error {
 
-	elements, err := customSplit(driftDetectionManagerYAML)
+	policies, err := getDriftDetectionManagerResources(driftDetectionManagerYAML, lbls, registryOverrides)
 	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to parse drift detection manager yaml: %v", err))
 		return err
 	}
+
+	for i := range policies {
+		policy := policies[i]
+		dr, err := utils.GetDynamicResourceInterface(restConfig, policy.GroupVersionKind(), policy.GetNamespace())
+		if err != nil {
+			logger.V(logsettings.LogInfo).Info(fmt.Sprintf("failed to get dynamic client: %v", err))
+			return err
+		}
+
+		options := metav1.ApplyOptions{
+			FieldManager: "application/apply-patch",
+		}
+
+		_, err = dr.Apply(ctx, policy.GetName(), policy, options)
+		if err != nil {
+			logger.V(logsettings.LogInfo).Info(fmt.Sprintf("failed to apply policy Kind: %s Name: %s: %v",
+				policy.GetKind(), policy.GetName(), err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getDriftDetectionManagerResources returns the resources contained in driftDetectionManagerYAML.
+// Labels lbls are added to each resource and container images of the workloads are rewritten
+// according to registryOverrides.
+func getDriftDetectionManagerResources(driftDetectionManagerYAML string,
+	lbls, registryOverrides map[string]string) ([]*unstructured.Unstructured, error) {
+
+	elements, err := customSplit(driftDetectionManagerYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*unstructured.Unstructured, len(elements))
 	for i := range elements {
 		policy, err := utils.GetUnstructured([]byte(elements[i]))
 		if err != nil {
-			logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to parse drift detection manager yaml: %v", err))
-			return err
+			return nil, err
 		}
 
 		if lbls != nil {
@@ -282,25 +332,15 @@ func deployDriftDetectionManagerResources(ctx context.Context, restConfig *rest.
 			policy.SetLabels(currentLabels)
 		}
 
-		dr, err := utils.GetDynamicResourceInterface(restConfig, policy.GroupVersionKind(), policy.GetNamespace())
+		err = rewriteImages(policy, registryOverrides)
 		if err != nil {
-			logger.V(logsettings.LogInfo).Info(fmt.Sprintf("failed to get dynamic client: %v", err))
-			return err
+			return nil, err
 		}
 
-		options := metav1.ApplyOptions{
-			FieldManager: "application/apply-patch",
-		}
-
-		_, err = dr.Apply(ctx, policy.GetName(), policy, options)
-		if err != nil {
-			logger.V(logsettings.LogInfo).Info(fmt.Sprintf("failed to apply policy Kind: %s Name: %s: %v",
-				policy.GetKind(), policy.GetName(), err))
-			return err
-		}
+		policies[i] = policy
 	}
 
-	return nil
+	return policies, nil
 }
 
 func deployResourceSummaryInstance(ctx context.Context, remoteClient client.Client,
@@ -486,7 +526,12 @@ func removeDriftDetectionManagerFromManagementCluster(ctx context.Context,
 	logger logr.Logger) error {
 
 	// Get YAML containing drift-detection-manager resources
-	driftDetectionManagerYAML := string(driftdetection.GetDriftDetectionManagerInMgmtClusterYAML())
+	manifest, err := driftdetection.GetDriftDetectionManagerInMgmtClusterYAML()
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get drift-detection-manager yaml: %v", err))
+		return err
+	}
+	driftDetectionManagerYAML := string(manifest)
 	driftDetectionManagerYAML = prepareDriftDetectionManagerYAML(driftDetectionManagerYAML, clusterNamespace,
 		clusterName, "", clusterType)
 
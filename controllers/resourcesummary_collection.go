@@ -36,9 +36,18 @@ import (
 	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
 )
 
+const (
+	// defaultDriftCollectionInterval is the default interval at which ResourceSummaries are collected
+	defaultDriftCollectionInterval = 10 * time.Second
+)
+
 // Periodically collects ResourceSummaries from each CAPI/Sveltos cluster.
-func collectAndProcessResourceSummaries(ctx context.Context, c client.Client, shardkey string, logger logr.Logger) {
-	const interval = 10 * time.Second
+func collectAndProcessResourceSummaries(ctx context.Context, c client.Client, shardkey string,
+	interval time.Duration, logger logr.Logger) {
+
+	if interval <= 0 {
+		interval = defaultDriftCollectionInterval
+	}
 
 	for {
 		logger.V(logs.LogVerbose).Info("collecting ResourceSummaries")
@@ -84,7 +93,7 @@ func collectResourceSummariesFromCluster(ctx context.Context, c client.Client,
 
 	// Use cluster-admin role to collect Sveltos resources from managed clusters
 	var remoteClient client.Client
-	remoteClient, err = clusterproxy.GetKubernetesClient(ctx, c, cluster.Namespace, cluster.Name, "", "",
+	remoteClient, err = getKubernetesClient(ctx, c, cluster.Namespace, cluster.Name, "", "",
 		clusterproxy.GetClusterType(clusterRef), logger)
 	if err != nil {
 		return err
@@ -28,6 +28,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -35,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/projectsveltos/addon-controller/controllers"
+	driftdetection "github.com/projectsveltos/addon-controller/pkg/drift-detection"
 	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
 )
 
@@ -130,13 +132,44 @@ var _ = Describe("ResourceSummary Deployer", func() {
 		}, timeout, pollingInterval).Should(BeNil())
 	})
 
+	It("getDriftDetectionManagerResources rewrites images of drift-detection-manager", func() {
+		registryOverrides := map[string]string{"docker.io": "mirror.example.com"}
+
+		for _, getManifest := range []func() ([]byte, error){
+			driftdetection.GetDriftDetectionManagerYAML,
+			driftdetection.GetDriftDetectionManagerInMgmtClusterYAML,
+		} {
+			manifest, err := getManifest()
+			Expect(err).To(BeNil())
+
+			policies, err := controllers.GetDriftDetectionManagerResources(string(manifest), nil, registryOverrides)
+			Expect(err).To(BeNil())
+
+			found := false
+			for i := range policies {
+				if policies[i].GetKind() != "Deployment" {
+					continue
+				}
+				found = true
+
+				deployment := &appsv1.Deployment{}
+				Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(policies[i].UnstructuredContent(),
+					deployment)).To(Succeed())
+				for j := range deployment.Spec.Template.Spec.Containers {
+					Expect(deployment.Spec.Template.Spec.Containers[j].Image).To(HavePrefix("mirror.example.com/"))
+				}
+			}
+			Expect(found).To(BeTrue())
+		}
+	})
+
 	It("deploy/remove DriftDetectionManager resources to/from management cluster", func() {
 		clusterNamespace := randomString()
 		clusterName := randomString()
 		clusterType := libsveltosv1alpha1.ClusterTypeSveltos
 
 		Expect(controllers.DeployDriftDetectionManagerInManagementCluster(context.TODO(), testEnv.Config,
-			clusterNamespace, clusterName, "", clusterType,
+			clusterNamespace, clusterName, "", clusterType, nil,
 			textlogger.NewLogger(textlogger.NewConfig()))).To(Succeed())
 
 		expectedLabels := controllers.GetDriftDetectionManagerLabels(clusterNamespace, clusterName, clusterType)
@@ -37,7 +37,7 @@ func (r *SetReconciler) requeueSetForSveltosCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.Sets, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.SetKind, r.Logger)
+	return requeueForCluster(cluster, r.Sets, nil, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.SetKind, r.Logger)
 }
 
 func (r *SetReconciler) requeueSetForCluster(
@@ -49,5 +49,5 @@ func (r *SetReconciler) requeueSetForCluster(
 
 	addTypeInformationToObject(r.Scheme, cluster)
 
-	return requeueForCluster(cluster, r.Sets, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.SetKind, r.Logger)
+	return requeueForCluster(cluster, r.Sets, nil, r.ClusterLabels, r.ClusterMap, libsveltosv1alpha1.SetKind, r.Logger)
 }
@@ -0,0 +1,263 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/pkg/scope"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+	"github.com/projectsveltos/libsveltos/lib/deployer"
+)
+
+// SimulatedCluster is a managed cluster part of a Simulation
+type SimulatedCluster struct {
+	Namespace string
+	Name      string
+	Type      libsveltosv1alpha1.ClusterType
+
+	// RestConfig gives access to the cluster API server, for instance a test environment.
+	// Features applying resources (Resources, Kustomize, PodSecurity) and Helm require it.
+	RestConfig *rest.Config
+
+	// Client, when set, is used instead of a client created from RestConfig. For instance
+	// a fake client can be used when only code paths relying on client.Client are exercised.
+	Client client.Client
+}
+
+// Simulation runs the feature deploy handlers, exactly as the deployer would, against
+// simulated managed clusters. No reconciler and no kubeconfig Secret are needed:
+// clients to access managed clusters are provided by the SimulatedClusters.
+// Simulation is the ClusterClientGetter in use while started.
+// It allows table-driven tests verifying the objects each feature deploys.
+type Simulation struct {
+	// Client is the management cluster client
+	Client client.Client
+	// Config is the management cluster restConfig
+	Config *rest.Config
+
+	mux      sync.RWMutex
+	clusters map[string]*SimulatedCluster
+}
+
+// NewSimulation returns a Simulation whose management cluster is accessed with c and config
+func NewSimulation(config *rest.Config, c client.Client) *Simulation {
+	return &Simulation{
+		Client:   c,
+		Config:   config,
+		clusters: map[string]*SimulatedCluster{},
+	}
+}
+
+// Start makes deploy handlers access simulated clusters. Returned function restores
+// default access to managed clusters.
+func (s *Simulation) Start(logger logr.Logger) func() {
+	SetManagementClusterAccess(s.Client, s.Config)
+	initializeManager(logger, s.Config, s.Client)
+	SetClusterClientGetter(s)
+	return func() {
+		SetClusterClientGetter(nil)
+	}
+}
+
+// AddCluster adds cluster to the simulation. Corresponding SveltosCluster/Cluster is created,
+// ready, in the management cluster if it does not exist already.
+func (s *Simulation) AddCluster(ctx context.Context, cluster *SimulatedCluster) error {
+	var obj client.Object
+	if cluster.Type == libsveltosv1alpha1.ClusterTypeSveltos {
+		obj = &libsveltosv1alpha1.SveltosCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name},
+		}
+	} else {
+		obj = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name},
+		}
+	}
+
+	err := s.Client.Create(ctx, obj)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	if err == nil {
+		switch o := obj.(type) {
+		case *libsveltosv1alpha1.SveltosCluster:
+			o.Status.Ready = true
+		case *clusterv1.Cluster:
+			o.Status.ControlPlaneReady = true
+		}
+		if err := s.Client.Status().Update(ctx, obj); err != nil {
+			return err
+		}
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.clusters[getSimulatedClusterKey(cluster.Namespace, cluster.Name, cluster.Type)] = cluster
+	return nil
+}
+
+// CreateClusterSummary creates, in the management cluster, the ClusterSummary and ClusterConfiguration
+// profile (a ClusterProfile or a Profile, which must exist) would create for cluster.
+func (s *Simulation) CreateClusterSummary(ctx context.Context, profile client.Object,
+	cluster *SimulatedCluster) (*configv1alpha1.ClusterSummary, error) {
+
+	addTypeInformationToObject(s.Client.Scheme(), profile)
+	profileScope, err := scope.NewProfileScope(scope.ProfileScopeParams{
+		Client:         s.Client,
+		Logger:         logr.Discard(),
+		Profile:        profile,
+		ControllerName: "simulation",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ref := &corev1.ObjectReference{
+		Namespace:  cluster.Namespace,
+		Name:       cluster.Name,
+		Kind:       clusterv1.ClusterKind,
+		APIVersion: clusterv1.GroupVersion.String(),
+	}
+	if cluster.Type == libsveltosv1alpha1.ClusterTypeSveltos {
+		ref.Kind = libsveltosv1alpha1.SveltosClusterKind
+		ref.APIVersion = libsveltosv1alpha1.GroupVersion.String()
+	}
+
+	if err := createClusterConfiguration(ctx, s.Client, ref); err != nil {
+		return nil, err
+	}
+	if err := updateClusterConfigurationWithProfile(ctx, s.Client, profile, ref); err != nil {
+		return nil, err
+	}
+	if err := createClusterSummary(ctx, s.Client, profileScope, ref); err != nil {
+		return nil, err
+	}
+
+	return getClusterSummary(ctx, s.Client, profileScope.GetKind(), profile.GetName(),
+		cluster.Namespace, cluster.Name, cluster.Type)
+}
+
+// Deploy runs the featureID deploy handler for clusterSummary and returns its outcome
+func (s *Simulation) Deploy(ctx context.Context, clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID, logger logr.Logger) error {
+
+	return genericDeploy(ctx, s.Client, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Name, string(featureID), clusterSummary.Spec.ClusterType,
+		deployer.Options{HandlerOptions: map[string]string{}}, logger)
+}
+
+// Undeploy runs the featureID undeploy handler for clusterSummary and returns its outcome
+func (s *Simulation) Undeploy(ctx context.Context, clusterSummary *configv1alpha1.ClusterSummary,
+	featureID configv1alpha1.FeatureID, logger logr.Logger) error {
+
+	return genericUndeploy(ctx, s.Client, clusterSummary.Spec.ClusterNamespace, clusterSummary.Spec.ClusterName,
+		clusterSummary.Name, string(featureID), clusterSummary.Spec.ClusterType,
+		deployer.Options{HandlerOptions: map[string]string{}}, logger)
+}
+
+// ClusterClient returns the client to access a simulated cluster, for instance to verify
+// the objects deployed there
+func (s *Simulation) ClusterClient(ctx context.Context, clusterNamespace, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) (client.Client, error) {
+
+	return s.GetClient(ctx, s.Client, clusterNamespace, clusterName, "", "", clusterType, logr.Discard())
+}
+
+func getSimulatedClusterKey(clusterNamespace, clusterName string, clusterType libsveltosv1alpha1.ClusterType) string {
+	return fmt.Sprintf("%s:%s/%s", clusterType, clusterNamespace, clusterName)
+}
+
+func (s *Simulation) getCluster(clusterNamespace, clusterName string,
+	clusterType libsveltosv1alpha1.ClusterType) (*SimulatedCluster, error) {
+
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	cluster, ok := s.clusters[getSimulatedClusterKey(clusterNamespace, clusterName, clusterType)]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s:%s/%s is not part of the simulation", clusterType,
+			clusterNamespace, clusterName)
+	}
+	return cluster, nil
+}
+
+// GetRestConfig returns the restConfig of the simulated cluster
+func (s *Simulation) GetRestConfig(_ context.Context, _ client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, _ logr.Logger,
+) (*rest.Config, error) {
+
+	cluster, err := s.getCluster(clusterNamespace, clusterName, clusterType)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.RestConfig == nil {
+		return nil, fmt.Errorf("cluster %s:%s/%s has no restConfig", clusterType, clusterNamespace, clusterName)
+	}
+
+	restConfig := rest.CopyConfig(cluster.RestConfig)
+	if adminName != "" {
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", adminNamespace, adminName),
+		}
+	}
+	return restConfig, nil
+}
+
+// GetClient returns the client of the simulated cluster or, if not set, one created from its restConfig
+func (s *Simulation) GetClient(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+) (client.Client, error) {
+
+	cluster, err := s.getCluster(clusterNamespace, clusterName, clusterType)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Client != nil {
+		return cluster.Client, nil
+	}
+
+	restConfig, err := s.GetRestConfig(ctx, c, clusterNamespace, clusterName, adminNamespace, adminName,
+		clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: c.Scheme()})
+}
+
+// GetKubeconfig returns a kubeconfig equivalent to the restConfig of the simulated cluster
+func (s *Simulation) GetKubeconfig(ctx context.Context, c client.Client, clusterNamespace, clusterName,
+	adminNamespace, adminName string, clusterType libsveltosv1alpha1.ClusterType, logger logr.Logger,
+) ([]byte, error) {
+
+	restConfig, err := s.GetRestConfig(ctx, c, clusterNamespace, clusterName, adminNamespace, adminName,
+		clusterType, logger)
+	if err != nil {
+		return nil, err
+	}
+	return restConfigToKubeconfig(restConfig)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2/textlogger"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+	libsveltosv1alpha1 "github.com/projectsveltos/libsveltos/api/v1alpha1"
+)
+
+var _ = Describe("Simulation", func() {
+	It("gives access to simulated clusters only", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(
+			&libsveltosv1alpha1.SveltosCluster{}).Build()
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		cluster := &controllers.SimulatedCluster{
+			Namespace: randomString(),
+			Name:      randomString(),
+			Type:      libsveltosv1alpha1.ClusterTypeSveltos,
+			Client:    remoteClient,
+		}
+
+		simulation := controllers.NewSimulation(testEnv.Config, c)
+		Expect(simulation.AddCluster(context.TODO(), cluster)).To(Succeed())
+
+		sveltosCluster := &libsveltosv1alpha1.SveltosCluster{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name},
+			sveltosCluster)).To(Succeed())
+		Expect(sveltosCluster.Status.Ready).To(BeTrue())
+
+		stop := simulation.Start(textlogger.NewLogger(textlogger.NewConfig()))
+		defer func() {
+			stop()
+			controllers.SetManagementClusterAccess(testEnv.Client, testEnv.Config)
+		}()
+
+		currentClient, err := controllers.GetKubernetesClient(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", cluster.Type, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).To(BeNil())
+		Expect(currentClient).To(Equal(remoteClient))
+
+		_, err = controllers.GetKubernetesClient(context.TODO(), c, randomString(), randomString(),
+			"", "", cluster.Type, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+
+		_, err = controllers.GetKubernetesRestConfig(context.TODO(), c, cluster.Namespace, cluster.Name,
+			"", "", cluster.Type, textlogger.NewLogger(textlogger.NewConfig()))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("runs deploy handlers against a simulated cluster", func() {
+		namespace := randomString()
+		Expect(testEnv.Create(context.TODO(), &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		})).To(Succeed())
+
+		clusterRoleName := randomString()
+		configMap := createConfigMapWithPolicy(namespace, randomString(), fmt.Sprintf(viewClusterRole, clusterRoleName))
+		Expect(testEnv.Create(context.TODO(), configMap)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, configMap)).To(Succeed())
+
+		// Management cluster is also the simulated cluster. So deploy ClusterRole in both, otherwise
+		// it would be removed as stale resource from the management cluster.
+		clusterProfile := &configv1alpha1.ClusterProfile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: clusterProfileNamePrefix + randomString(),
+			},
+			Spec: configv1alpha1.Spec{
+				PolicyRefs: []configv1alpha1.PolicyRef{
+					{
+						Namespace: configMap.Namespace, Name: configMap.Name,
+						Kind:           string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+						DeploymentType: configv1alpha1.DeploymentTypeLocal,
+					},
+					{
+						Namespace: configMap.Namespace, Name: configMap.Name,
+						Kind:           string(libsveltosv1alpha1.ConfigMapReferencedResourceKind),
+						DeploymentType: configv1alpha1.DeploymentTypeRemote,
+					},
+				},
+			},
+		}
+		Expect(testEnv.Create(context.TODO(), clusterProfile)).To(Succeed())
+		Expect(waitForObject(context.TODO(), testEnv.Client, clusterProfile)).To(Succeed())
+
+		cluster := &controllers.SimulatedCluster{
+			Namespace:  namespace,
+			Name:       randomString(),
+			Type:       libsveltosv1alpha1.ClusterTypeSveltos,
+			RestConfig: testEnv.Config,
+		}
+
+		simulation := controllers.NewSimulation(testEnv.Config, testEnv.Client)
+		Expect(simulation.AddCluster(context.TODO(), cluster)).To(Succeed())
+		stop := simulation.Start(textlogger.NewLogger(textlogger.NewConfig()))
+		defer stop()
+
+		var clusterSummary *configv1alpha1.ClusterSummary
+		Eventually(func() error {
+			var err error
+			clusterSummary, err = simulation.CreateClusterSummary(context.TODO(), clusterProfile, cluster)
+			return err
+		}, timeout, pollingInterval).Should(BeNil())
+
+		// Eventual loop so testEnv Cache is synced
+		Eventually(func() error {
+			return simulation.Deploy(context.TODO(), clusterSummary, configv1alpha1.FeatureResources,
+				textlogger.NewLogger(textlogger.NewConfig()))
+		}, timeout, pollingInterval).Should(BeNil())
+
+		remoteClient, err := simulation.ClusterClient(context.TODO(), cluster.Namespace, cluster.Name, cluster.Type)
+		Expect(err).To(BeNil())
+		Eventually(func() error {
+			return remoteClient.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName}, &rbacv1.ClusterRole{})
+		}, timeout, pollingInterval).Should(BeNil())
+	})
+})
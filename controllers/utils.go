@@ -18,9 +18,12 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
+	"time"
 
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
@@ -34,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/discovery"
 	memory "k8s.io/client-go/discovery/cached"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -70,6 +74,72 @@ func (r *NonRetriableError) Error() string {
 	return r.Message
 }
 
+// ProvisioningError is returned when the content of a feature was deployed but the feature is
+// not ready yet, for instance because its PostDeployJobs have not completed.
+// Feature is reported as Provisioning and deployment is queued again.
+type ProvisioningError struct {
+	Message string
+}
+
+func (r *ProvisioningError) Error() string {
+	return r.Message
+}
+
+// TransientError is returned when deploying a feature failed because the managed cluster
+// API was temporarily unavailable. Deployment is retried after RetryAfter.
+type TransientError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (r *TransientError) Error() string {
+	return r.Message
+}
+
+// isTransientError returns true if err is caused by the managed cluster API being temporarily
+// unavailable (timeouts, connection refused/reset, throttling, server unavailable).
+// Errors like forbidden or not found are permanent and are never considered transient.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsUnauthorized(err) {
+		return false
+	}
+
+	// InternalError is not considered transient: admission webhooks rejecting a resource are
+	// reported as such and would be retried forever.
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) {
+
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || utilnet.IsConnectionRefused(err) ||
+		utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err) {
+
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// Errors coming from helm and other libraries are often wrapped losing their type.
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connection refused", "connection reset by peer", "i/o timeout",
+		"no route to host", "tls handshake timeout", "context deadline exceeded"} {
+
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func InitScheme() (*runtime.Scheme, error) {
 	s := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(s); err != nil {
@@ -106,19 +176,28 @@ func getPrefix(clusterType libsveltosv1alpha1.ClusterType) string {
 }
 
 // GetClusterSummaryName returns the ClusterSummary name given a ClusterProfile/Profile kind/name and
-// cluster type/Name.
+// cluster type/Name. Name depends on the configured ClusterSummaryNamingScheme, so it must only be
+// used to name new ClusterSummaries. Existing ones are looked up by labels (see getClusterSummary).
+// ClusterSummary is created in the cluster namespace, so name only needs to be unique there.
 func GetClusterSummaryName(profileKind, profileName, clusterName string, isSveltosCluster bool) string {
 	clusterType := libsveltosv1alpha1.ClusterTypeCapi
 	if isSveltosCluster {
 		clusterType = libsveltosv1alpha1.ClusterTypeSveltos
 	}
 	prefix := getPrefix(clusterType)
+
+	var name string
 	if profileKind == configv1alpha1.ClusterProfileKind {
 		// For backward compatibility (code before addition of Profiles) do not change this
-		return fmt.Sprintf("%s-%s-%s", profileName, prefix, clusterName)
+		name = fmt.Sprintf("%s-%s-%s", profileName, prefix, clusterName)
+	} else {
+		name = fmt.Sprintf("p--%s-%s-%s", profileName, prefix, clusterName)
 	}
 
-	return fmt.Sprintf("p--%s-%s-%s", profileName, prefix, clusterName)
+	if getClusterSummaryNamingScheme() == ClusterSummaryNamingHashed {
+		name = addClusterSummaryNameHash(name, profileKind, profileName, clusterName, clusterType)
+	}
+	return name
 }
 
 // getClusterSummary returns the ClusterSummary instance created by a specific
@@ -230,26 +309,43 @@ func addTypeInformationToObject(scheme *runtime.Scheme, obj client.Object) {
 	}
 }
 
+// isFeatureDisabled returns true if featureID is listed in the ClusterSummary DisabledFeatures
+func isFeatureDisabled(clusterSummary *configv1alpha1.ClusterSummary, featureID configv1alpha1.FeatureID) bool {
+	for i := range clusterSummary.Spec.ClusterProfileSpec.DisabledFeatures {
+		if clusterSummary.Spec.ClusterProfileSpec.DisabledFeatures[i] == featureID {
+			return true
+		}
+	}
+
+	return false
+}
+
 // isCluterSummaryProvisioned returns true if ClusterSummary is currently fully deployed.
+// Features listed in DisabledFeatures are never deployed, so those are considered satisfied.
 func isCluterSummaryProvisioned(clusterSumary *configv1alpha1.ClusterSummary) bool {
 	hasHelmCharts := false
 	hasRawYAMLs := false
 	hasKustomize := false
+	hasPodSecurity := clusterSumary.Spec.ClusterProfileSpec.PodSecurity != nil &&
+		!isFeatureDisabled(clusterSumary, configv1alpha1.FeaturePodSecurity)
 
 	if clusterSumary.Spec.ClusterProfileSpec.HelmCharts != nil &&
-		len(clusterSumary.Spec.ClusterProfileSpec.HelmCharts) != 0 {
+		len(clusterSumary.Spec.ClusterProfileSpec.HelmCharts) != 0 &&
+		!isFeatureDisabled(clusterSumary, configv1alpha1.FeatureHelm) {
 
 		hasHelmCharts = true
 	}
 
 	if clusterSumary.Spec.ClusterProfileSpec.PolicyRefs != nil &&
-		len(clusterSumary.Spec.ClusterProfileSpec.PolicyRefs) != 0 {
+		len(clusterSumary.Spec.ClusterProfileSpec.PolicyRefs) != 0 &&
+		!isFeatureDisabled(clusterSumary, configv1alpha1.FeatureResources) {
 
 		hasRawYAMLs = true
 	}
 
 	if clusterSumary.Spec.ClusterProfileSpec.KustomizationRefs != nil &&
-		len(clusterSumary.Spec.ClusterProfileSpec.KustomizationRefs) != 0 {
+		len(clusterSumary.Spec.ClusterProfileSpec.KustomizationRefs) != 0 &&
+		!isFeatureDisabled(clusterSumary, configv1alpha1.FeatureKustomize) {
 
 		hasKustomize = true
 	}
@@ -257,9 +353,13 @@ func isCluterSummaryProvisioned(clusterSumary *configv1alpha1.ClusterSummary) bo
 	deployedHelmCharts := false
 	deployedRawYAMLs := false
 	deployedKustomize := false
+	deployedPodSecurity := false
 
 	for i := range clusterSumary.Status.FeatureSummaries {
 		fs := &clusterSumary.Status.FeatureSummaries[i]
+		if fs.Status == configv1alpha1.FeatureStatusDisabled {
+			continue
+		}
 		if fs.Status != configv1alpha1.FeatureStatusProvisioned {
 			return false
 		}
@@ -270,6 +370,8 @@ func isCluterSummaryProvisioned(clusterSumary *configv1alpha1.ClusterSummary) bo
 			deployedRawYAMLs = true
 		case configv1alpha1.FeatureKustomize:
 			deployedKustomize = true
+		case configv1alpha1.FeaturePodSecurity:
+			deployedPodSecurity = true
 		}
 	}
 
@@ -291,6 +393,12 @@ func isCluterSummaryProvisioned(clusterSumary *configv1alpha1.ClusterSummary) bo
 		}
 	}
 
+	if hasPodSecurity {
+		if !deployedPodSecurity {
+			return false
+		}
+	}
+
 	return true
 }
 
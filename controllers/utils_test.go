@@ -19,6 +19,8 @@ package controllers_test
 import (
 	"context"
 	"fmt"
+	"net"
+	"syscall"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -27,8 +29,10 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -392,6 +396,11 @@ var _ = Describe("getClusterProfileOwner ", func() {
 		}
 		// all Features are marked as provisioned
 		Expect(controllers.IsCluterSummaryProvisioned(clusterSummary)).To(BeTrue())
+
+		// Disabled features are considered satisfied
+		clusterSummary.Spec.ClusterProfileSpec.DisabledFeatures = []configv1alpha1.FeatureID{configv1alpha1.FeatureKustomize}
+		clusterSummary.Status.FeatureSummaries[2].Status = configv1alpha1.FeatureStatusDisabled
+		Expect(controllers.IsCluterSummaryProvisioned(clusterSummary)).To(BeTrue())
 	})
 
 	It("stringifyMap and parseMapFromString convert a map[string]string to string and back", func() {
@@ -417,8 +426,57 @@ var _ = Describe("getClusterProfileOwner ", func() {
 			Expect(v).To(Equal(myMap[k]))
 		}
 	})
+
+	It("getDataSectionHash is stable for identical data", func() {
+		data := make(map[string]string)
+		for i := 0; i < 50; i++ {
+			data[fmt.Sprintf("key-%d", i)] = randomString()
+		}
+
+		// Same content, inserted in reverse order
+		reversed := make(map[string]string)
+		for i := 49; i >= 0; i-- {
+			key := fmt.Sprintf("key-%d", i)
+			reversed[key] = data[key]
+		}
+
+		hash := controllers.GetStringDataSectionHash(data)
+		for i := 0; i < 20; i++ {
+			Expect(controllers.GetStringDataSectionHash(data)).To(Equal(hash))
+			Expect(controllers.GetStringDataSectionHash(reversed)).To(Equal(hash))
+		}
+	})
+
+	It("isTransientError returns true only for transient errors", func() {
+		gr := schema.GroupResource{Group: "", Resource: "configmaps"}
+
+		// Transient errors
+		Expect(controllers.IsTransientError(apierrors.NewServerTimeout(gr, "get", 1))).To(BeTrue())
+		Expect(controllers.IsTransientError(apierrors.NewTimeoutError("timeout", 1))).To(BeTrue())
+		Expect(controllers.IsTransientError(apierrors.NewTooManyRequests("throttled", 1))).To(BeTrue())
+		Expect(controllers.IsTransientError(apierrors.NewServiceUnavailable("unavailable"))).To(BeTrue())
+		Expect(controllers.IsTransientError(context.DeadlineExceeded)).To(BeTrue())
+		Expect(controllers.IsTransientError(fmt.Errorf("wrapped: %w", syscall.ECONNREFUSED))).To(BeTrue())
+		Expect(controllers.IsTransientError(&net.OpError{Op: "dial", Err: &timeoutError{}})).To(BeTrue())
+		Expect(controllers.IsTransientError(
+			fmt.Errorf("dial tcp 10.0.0.1:6443: connect: connection refused"))).To(BeTrue())
+
+		// Permanent errors
+		Expect(controllers.IsTransientError(nil)).To(BeFalse())
+		Expect(controllers.IsTransientError(apierrors.NewForbidden(gr, randomString(), fmt.Errorf("denied")))).To(BeFalse())
+		Expect(controllers.IsTransientError(apierrors.NewNotFound(gr, randomString()))).To(BeFalse())
+		// Admission webhooks rejecting a resource are reported as internal errors
+		Expect(controllers.IsTransientError(apierrors.NewInternalError(fmt.Errorf("admission webhook denied the request")))).To(BeFalse())
+		Expect(controllers.IsTransientError(fmt.Errorf("%s", randomString()))).To(BeFalse())
+	})
 })
 
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
 func getClusterRef(cluster client.Object) *corev1.ObjectReference {
 	apiVersion, kind := cluster.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 	return &corev1.ObjectReference{
@@ -86,6 +86,9 @@ func validateHealthPolicy(ctx context.Context, remoteConfig *rest.Config, check
 		if err != nil {
 			return err
 		}
+		if healthy {
+			healthy, msg = isHealthyByCriteria(&list.Items[i], check)
+		}
 		if !healthy {
 			l.V(logs.LogInfo).Info("resource is not healthy")
 			return fmt.Errorf("%s", msg)
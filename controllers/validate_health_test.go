@@ -220,3 +220,74 @@ func getResources(dirName, fileName string) []*unstructured.Unstructured {
 
 	return resources
 }
+
+var _ = Describe("Health criteria", func() {
+	It("isHealthyByCriteria verifies status conditions", func() {
+		prometheus := &unstructured.Unstructured{}
+		prometheus.SetKind("Prometheus")
+		prometheus.SetNamespace(randomString())
+		prometheus.SetName(randomString())
+
+		check := &configv1alpha1.ValidateHealth{
+			Condition: &configv1alpha1.HealthCondition{Type: "Available"},
+		}
+
+		healthy, msg := controllers.IsHealthyByCriteria(prometheus, check)
+		Expect(healthy).To(BeFalse())
+		Expect(msg).To(ContainSubstring("condition Available not reported"))
+
+		Expect(unstructured.SetNestedSlice(prometheus.Object, []interface{}{
+			map[string]interface{}{"type": "Reconciled", "status": "True"},
+			map[string]interface{}{"type": "Available", "status": "False", "message": "no pod ready"},
+		}, "status", "conditions")).To(Succeed())
+		healthy, msg = controllers.IsHealthyByCriteria(prometheus, check)
+		Expect(healthy).To(BeFalse())
+		Expect(msg).To(ContainSubstring("no pod ready"))
+
+		// Expected status can be set
+		check.Condition.Status = metav1.ConditionFalse
+		healthy, _ = controllers.IsHealthyByCriteria(prometheus, check)
+		Expect(healthy).To(BeTrue())
+	})
+
+	It("isHealthyByCriteria verifies workloads are ready", func() {
+		deployment := &unstructured.Unstructured{}
+		deployment.SetKind("Deployment")
+		deployment.SetNamespace(randomString())
+		deployment.SetName(randomString())
+		deployment.SetGeneration(2)
+		Expect(unstructured.SetNestedField(deployment.Object, int64(3), "spec", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(1), "status", "observedGeneration")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(3), "status", "readyReplicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(deployment.Object, int64(3), "status", "updatedReplicas")).To(Succeed())
+
+		check := &configv1alpha1.ValidateHealth{WorkloadReady: true}
+
+		// Latest spec not observed yet
+		healthy, _ := controllers.IsHealthyByCriteria(deployment, check)
+		Expect(healthy).To(BeFalse())
+
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "observedGeneration")).To(Succeed())
+		healthy, _ = controllers.IsHealthyByCriteria(deployment, check)
+		Expect(healthy).To(BeTrue())
+
+		Expect(unstructured.SetNestedField(deployment.Object, int64(2), "status", "readyReplicas")).To(Succeed())
+		healthy, msg := controllers.IsHealthyByCriteria(deployment, check)
+		Expect(healthy).To(BeFalse())
+		Expect(msg).To(ContainSubstring("2 out of 3 replicas ready"))
+
+		daemonSet := &unstructured.Unstructured{}
+		daemonSet.SetKind("DaemonSet")
+		Expect(unstructured.SetNestedField(daemonSet.Object, int64(2), "status", "desiredNumberScheduled")).To(Succeed())
+		Expect(unstructured.SetNestedField(daemonSet.Object, int64(2), "status", "numberReady")).To(Succeed())
+		Expect(unstructured.SetNestedField(daemonSet.Object, int64(1), "status", "updatedNumberScheduled")).To(Succeed())
+		healthy, msg = controllers.IsHealthyByCriteria(daemonSet, check)
+		Expect(healthy).To(BeFalse())
+		Expect(msg).To(ContainSubstring("1 out of 2 pods updated"))
+
+		service := &unstructured.Unstructured{}
+		service.SetKind("Service")
+		healthy, _ = controllers.IsHealthyByCriteria(service, check)
+		Expect(healthy).To(BeFalse())
+	})
+})
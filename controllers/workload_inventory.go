@@ -0,0 +1,228 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	logs "github.com/projectsveltos/libsveltos/lib/logsettings"
+)
+
+const (
+	// InventoryLabel is set on every inventory ConfigMap created in managed clusters
+	InventoryLabel = "projectsveltos.io/inventory"
+
+	// InventoryClusterSummaryAnnotation is set on inventory ConfigMaps to the ClusterSummary
+	// (namespace/name) owning them
+	InventoryClusterSummaryAnnotation = "projectsveltos.io/clustersummary"
+
+	// InventoryProfileAnnotation is set on inventory ConfigMaps to the profile (kind/name)
+	// owning them
+	InventoryProfileAnnotation = "projectsveltos.io/profile"
+
+	// helmReleaseInventoryKind is the kind used to list helm releases in the inventory
+	helmReleaseInventoryKind = "HelmRelease"
+
+	inventoryConfigMapSuffix = "-inventory"
+)
+
+// InventoryEntry describes a resource, or a helm release, deployed in a managed cluster
+type InventoryEntry struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Owner is the ConfigMap/Secret (kind/namespace/name) containing the resource
+	// or, for helm releases, the repository the chart comes from
+	Owner string `json:"owner,omitempty"`
+}
+
+var (
+	workloadInventory bool
+	inventoryLogger   = ctrl.Log.WithName("inventory")
+)
+
+// SetWorkloadInventory when enabled makes the controller maintain, in each managed cluster,
+// an inventory ConfigMap per ClusterSummary listing all resources and helm releases deployed
+// there. Each feature is a key of the ConfigMap. The ConfigMap is created in the projectsveltos
+// namespace and is removed once nothing is deployed anymore.
+func SetWorkloadInventory(enabled bool) {
+	workloadInventory = enabled
+}
+
+func getInventoryConfigMapName(clusterSummaryNamespace, clusterSummaryName string) string {
+	return getResourceSummaryName(clusterSummaryNamespace, clusterSummaryName) + inventoryConfigMapSuffix
+}
+
+// getInventoryEntries returns, sorted, the inventory entries for the resources and the
+// helm charts deployed by a feature
+func getInventoryEntries(policyDeployed []configv1alpha1.Resource,
+	chartDeployed []configv1alpha1.Chart) []InventoryEntry {
+
+	entries := make([]InventoryEntry, 0, len(policyDeployed)+len(chartDeployed))
+	for i := range policyDeployed {
+		r := &policyDeployed[i]
+		entry := InventoryEntry{
+			Group: r.Group, Version: r.Version, Kind: r.Kind,
+			Namespace: r.Namespace, Name: r.Name,
+		}
+		if r.Owner.Name != "" {
+			entry.Owner = fmt.Sprintf("%s/%s/%s", r.Owner.Kind, r.Owner.Namespace, r.Owner.Name)
+		}
+		entries = append(entries, entry)
+	}
+
+	for i := range chartDeployed {
+		chart := &chartDeployed[i]
+		entries = append(entries, InventoryEntry{
+			Version: chart.ChartVersion, Kind: helmReleaseInventoryKind,
+			Namespace: chart.Namespace, Name: chart.ReleaseName, Owner: chart.RepoURL,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return getInventoryEntryKey(&entries[i]) < getInventoryEntryKey(&entries[j])
+	})
+
+	return entries
+}
+
+func getInventoryEntryKey(entry *InventoryEntry) string {
+	return fmt.Sprintf("%s.%s:%s:%s", entry.Kind, entry.Group, entry.Namespace, entry.Name)
+}
+
+// updateWorkloadInventory records, in the managed cluster inventory ConfigMap, what featureID
+// currently deploys. It is a no-op unless the workload inventory is enabled.
+// Inventory is informational only, so failures are logged and never fail a deployment.
+func updateWorkloadInventory(ctx context.Context, c client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	profileOwnerRef *metav1.OwnerReference, featureID configv1alpha1.FeatureID,
+	policyDeployed []configv1alpha1.Resource, chartDeployed []configv1alpha1.Chart) {
+
+	if !workloadInventory {
+		return
+	}
+
+	logger := inventoryLogger.WithValues("clustersummary",
+		fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name), "feature", featureID)
+
+	remoteClient, err := getKubernetesClient(ctx, c, clusterSummary.Spec.ClusterNamespace,
+		clusterSummary.Spec.ClusterName, "", "", clusterSummary.Spec.ClusterType, logger)
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to get managed cluster client: %v", err))
+		return
+	}
+
+	err = storeInventory(ctx, remoteClient, clusterSummary, profileOwnerRef, featureID,
+		getInventoryEntries(policyDeployed, chartDeployed))
+	if err != nil {
+		logger.V(logs.LogInfo).Info(fmt.Sprintf("failed to update inventory: %v", err))
+	}
+}
+
+// storeInventory sets the featureID key of the inventory ConfigMap to entries. When entries is
+// empty the key is removed, and the ConfigMap is deleted once it has no keys left.
+func storeInventory(ctx context.Context, remoteClient client.Client, clusterSummary *configv1alpha1.ClusterSummary,
+	profileOwnerRef *metav1.OwnerReference, featureID configv1alpha1.FeatureID, entries []InventoryEntry) error {
+
+	var value string
+	if len(entries) != 0 {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		value = string(data)
+	}
+
+	name := getInventoryConfigMapName(clusterSummary.Namespace, clusterSummary.Name)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := remoteClient.Get(ctx, types.NamespacedName{Namespace: projectsveltos, Name: name}, configMap)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			if value == "" {
+				return nil
+			}
+			return createInventory(ctx, remoteClient, name, clusterSummary, profileOwnerRef, featureID, value)
+		}
+
+		if value == "" {
+			if _, ok := configMap.Data[string(featureID)]; !ok {
+				return nil
+			}
+			delete(configMap.Data, string(featureID))
+			if len(configMap.Data) == 0 {
+				return client.IgnoreNotFound(remoteClient.Delete(ctx, configMap))
+			}
+			return remoteClient.Update(ctx, configMap)
+		}
+
+		if configMap.Data[string(featureID)] == value {
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[string(featureID)] = value
+		return remoteClient.Update(ctx, configMap)
+	})
+}
+
+func createInventory(ctx context.Context, remoteClient client.Client, name string,
+	clusterSummary *configv1alpha1.ClusterSummary, profileOwnerRef *metav1.OwnerReference,
+	featureID configv1alpha1.FeatureID, value string) error {
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: projectsveltos,
+		},
+	}
+	err := remoteClient.Create(ctx, ns)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: projectsveltos,
+			Name:      name,
+			Labels: map[string]string{
+				InventoryLabel: "true",
+			},
+			Annotations: map[string]string{
+				InventoryClusterSummaryAnnotation: fmt.Sprintf("%s/%s", clusterSummary.Namespace, clusterSummary.Name),
+				InventoryProfileAnnotation:        fmt.Sprintf("%s/%s", profileOwnerRef.Kind, profileOwnerRef.Name),
+			},
+		},
+		Data: map[string]string{string(featureID): value},
+	}
+	return remoteClient.Create(ctx, configMap)
+}
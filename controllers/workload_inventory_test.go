@@ -0,0 +1,101 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1alpha1 "github.com/projectsveltos/addon-controller/api/v1alpha1"
+	"github.com/projectsveltos/addon-controller/controllers"
+)
+
+var _ = Describe("Workload inventory", func() {
+	It("getInventoryEntries lists resources and helm releases sorted", func() {
+		resources := []configv1alpha1.Resource{
+			{
+				Kind: "Deployment", Group: "apps", Version: "v1", Namespace: randomString(), Name: "b",
+				Owner: corev1.ObjectReference{Kind: "ConfigMap", Namespace: randomString(), Name: randomString()},
+			},
+			{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Version: "v1", Name: "a"},
+		}
+		charts := []configv1alpha1.Chart{
+			{RepoURL: randomString(), ReleaseName: randomString(), Namespace: randomString(), ChartVersion: "v1.0.0"},
+		}
+
+		entries := controllers.GetInventoryEntries(resources, charts)
+		Expect(len(entries)).To(Equal(3))
+		Expect(entries[0].Kind).To(Equal("ClusterRole"))
+		Expect(entries[1].Kind).To(Equal("Deployment"))
+		Expect(entries[1].Owner).To(ContainSubstring(resources[0].Owner.Name))
+		Expect(entries[2].Kind).To(Equal("HelmRelease"))
+		Expect(entries[2].Name).To(Equal(charts[0].ReleaseName))
+		Expect(entries[2].Owner).To(Equal(charts[0].RepoURL))
+	})
+
+	It("storeInventory keeps one key per feature and removes ConfigMap when empty", func() {
+		clusterSummary := &configv1alpha1.ClusterSummary{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: randomString(),
+				Name:      randomString(),
+			},
+		}
+		profileOwnerRef := &metav1.OwnerReference{Kind: configv1alpha1.ClusterProfileKind, Name: randomString()}
+
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		entries := controllers.GetInventoryEntries([]configv1alpha1.Resource{
+			{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io", Version: "v1", Name: randomString()},
+		}, nil)
+		Expect(controllers.StoreInventory(context.TODO(), remoteClient, clusterSummary, profileOwnerRef,
+			configv1alpha1.FeatureResources, entries)).To(Succeed())
+		Expect(controllers.StoreInventory(context.TODO(), remoteClient, clusterSummary, profileOwnerRef,
+			configv1alpha1.FeatureKustomize, entries)).To(Succeed())
+
+		key := types.NamespacedName{
+			Namespace: "projectsveltos",
+			Name:      controllers.GetInventoryConfigMapName(clusterSummary.Namespace, clusterSummary.Name),
+		}
+		configMap := &corev1.ConfigMap{}
+		Expect(remoteClient.Get(context.TODO(), key, configMap)).To(Succeed())
+		Expect(configMap.Labels).To(HaveKeyWithValue(controllers.InventoryLabel, "true"))
+		Expect(len(configMap.Data)).To(Equal(2))
+
+		var stored []controllers.InventoryEntry
+		Expect(json.Unmarshal([]byte(configMap.Data[string(configv1alpha1.FeatureResources)]), &stored)).To(Succeed())
+		Expect(stored).To(Equal(entries))
+
+		Expect(controllers.StoreInventory(context.TODO(), remoteClient, clusterSummary, profileOwnerRef,
+			configv1alpha1.FeatureResources, nil)).To(Succeed())
+		Expect(remoteClient.Get(context.TODO(), key, configMap)).To(Succeed())
+		Expect(configMap.Data).ToNot(HaveKey(string(configv1alpha1.FeatureResources)))
+
+		Expect(controllers.StoreInventory(context.TODO(), remoteClient, clusterSummary, profileOwnerRef,
+			configv1alpha1.FeatureKustomize, nil)).To(Succeed())
+		err := remoteClient.Get(context.TODO(), key, configMap)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
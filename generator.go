@@ -20,6 +20,9 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
 	"os"
 	"path/filepath"
 	"text/template"
@@ -44,6 +47,7 @@ limitations under the License.
 */
 package driftdetection
 
+// {{ .ExportedVar }}YAML is the gzip compressed content of {{ .File }}
 var {{ .ExportedVar }}YAML = []byte({{- printf "%s" .YAML -}})
 `
 )
@@ -59,7 +63,19 @@ func generate(filename, outputFilename, manifest string) {
 	if err != nil {
 		panic(err)
 	}
-	contentStr := "`" + string(content) + "`"
+	// Manifests are stored gzip compressed and decompressed on demand
+	var compressed bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := zw.Write(content); err != nil {
+		panic(err)
+	}
+	if err := zw.Close(); err != nil {
+		panic(err)
+	}
+	contentStr := fmt.Sprintf("%+q", compressed.String())
 
 	// Find the output.
 	driftManager, err := os.Create(outputFilename + ".go")
@@ -76,7 +92,7 @@ func generate(filename, outputFilename, manifest string) {
 	}
 	mi := Info{
 		YAML:        contentStr,
-		File:        filename,
+		File:        filepath.Base(filename),
 		ExportedVar: manifest,
 	}
 
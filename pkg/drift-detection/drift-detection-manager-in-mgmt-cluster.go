@@ -16,75 +16,5 @@ limitations under the License.
 */
 package driftdetection
 
-var driftDetectionInMgmtClusterYAML = []byte(`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  labels:
-    control-plane: $NAME
-  name: $NAME
-  namespace: projectsveltos
-spec:
-  replicas: 1
-  selector:
-    matchLabels:
-      control-plane: $NAME
-  template:
-    metadata:
-      annotations:
-        kubectl.kubernetes.io/default-container: manager
-      labels:
-        control-plane: $NAME
-    spec:
-      containers:
-      - args:
-        - --diagnostics-address=:8443
-        - --v=5
-        - --cluster-namespace=
-        - --cluster-name=
-        - --cluster-type=
-        - --current-cluster=management-cluster
-        - --run-mode=do-not-send-updates
-        command:
-        - /manager
-        image: projectsveltos/drift-detection-manager:main
-        livenessProbe:
-          failureThreshold: 3
-          httpGet:
-            path: /healthz
-            port: healthz
-            scheme: HTTP
-          initialDelaySeconds: 15
-          periodSeconds: 20
-        name: manager
-        ports:
-        - containerPort: 8443
-          name: metrics
-          protocol: TCP
-        - containerPort: 9440
-          name: healthz
-          protocol: TCP
-        readinessProbe:
-          failureThreshold: 3
-          httpGet:
-            path: /readyz
-            port: healthz
-            scheme: HTTP
-          initialDelaySeconds: 5
-          periodSeconds: 10
-        resources:
-          limits:
-            cpu: 500m
-            memory: 512Mi
-          requests:
-            cpu: 10m
-            memory: 128Mi
-        securityContext:
-          allowPrivilegeEscalation: false
-          capabilities:
-            drop:
-            - ALL
-      securityContext:
-        runAsNonRoot: true
-      serviceAccountName: drift-detection-manager
-      terminationGracePeriodSeconds: 10
-`)
+// driftDetectionInMgmtClusterYAML is the gzip compressed content of drift-detection-manager-in-mgmt-cluster.yaml
+var driftDetectionInMgmtClusterYAML = []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x02\xff\xb4TKo2G\x10\xbc\xf3+\xfa\x90\xeb~\x80?\x90\x9c\x918 \xdbr\x0e\xb6\x85\x12\x94{3\u04f0\x13\xcf+\u077d$\xe4\xd7G\x8b\xf1z\x97GrI\xb8\xc0LuUW?\x06,\xfeWb\xf19\x19\xc0Rd\xbc\x9f\x8e\xde}r\x06\x1e\xa9\x84|\x88\x94t\x14I\u0461\xa2\x19\x01\x04\xdcP\x90\xf6\x17\x80\xcdI9\x87\xaa\x04Ld\xe0\x87\xb7\xe5\xeb\xd3\b a<;IAK\x06\n\xe7\xdf\u022a\xec)h\x96\x91\x14\xb2\xad\x10S\t\u07a2\x18\x98\x8e\x00\x84\x02Y\xcd\xfc\x91\"\xa2\xda\xfa\xa5\x97\xf3fV\xa5X\x02*\x9dx=\xcb\xed\aS\u028a\xeas\xeat\x00\u079b\rY\r\xdf\xdaoN\xa4$\xdf|\x1e;\xdab\x13\xb4j\xf3\xa0O\xc4\x06\"&\xdc\x11\x9f\x88a`\xe7\xa6!\x80\xcf\x02?\x83\x8ej\x1d\xb1\x02\xe4\x9d|\xc9TPU\xce\xe3.eQo\xa5B\xe7\x98D\x16\xe6~6\xfb>\x88\xda/\u60f3\r\x8d(q\u0575zq\x13\xbe\x8e\u885c#\r3%\xfd\x8cX|t \xf6\xae\x06\xe1\u0724*fG\v\x97\xab\x94\xb5\x12J\xaej\x8aC%\xe9\xf5)FL\xae_\xf1x\xd8Y\x00\x1fqw\xb1)c\xc7~\xab\x95#%\u06ce\xb0:\xb1LD\x9f:j\xf0{J$\xb2\u2f21\xaf$\x00[\xf4\xa1aZ\xd7LR\xe7\xe0\f|\uf875jy&\xed\x13\x00\njm`\\\x13\x06\xad\xff\x1aB\x99\xd5\xc05DlM\xed\xe6\xff\xb4^\xafz\x80O^=\x86G\nx\xf8\x85lN\xae\xdd\xf4y/\xa2\x10\xfb\xec:\xecn\xd2a\x1fO\xe9\xbcI\xad\x85\xc1\xe2t\u02f5:\x9a\x1b,L'B\xca\xdeJ?-g\xcd6\a\x03\xeb\x87\xd5m\xb5\x1fg\xb3\u0245\xdae\xfd\xd7\u0558\xd0\xf9\xffx*\xad\xe6\xe1\xff\x18\xca?\xccd:\xe9\x95$\xb9aK\xd27\x17|\xf4*C\xbb\xb64\x06\xe6\x93I\x1c\xdcF\x8a\x99\x0f\x06\xe6\u04fbW\xdfC\x98~oH\xaejLoHL\xef\xee{\x12B\xb6a\xaf\x87\x87\x9c\x94\xfe\x1c\xb4\x0eC\xc8\x7f\xac\xd8\xef}\xa0\x1d=\x89\xc5p\xfc/4\xb0\xc5 \u050b\xb4Xp\xe3\x83WOgN\x1c\xe72\xbc\xa9`\xf9\xf22\xfa\x97\xe4\u0724\xa5\xbc\xe5\xf4s\xcej@\xb9\xa1\x8e\xc1{oiimn\x92\xbe\x1d\xb7\xea\xc6;?1\x948\xfat4\xfe\xcchiu1\xa2\xbf\a\x00\xa3\xbc\u029b\xce\x06\x00\x00")
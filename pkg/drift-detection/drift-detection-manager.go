@@ -16,159 +16,5 @@ limitations under the License.
 */
 package driftdetection
 
-var driftDetectionYAML = []byte(`apiVersion: v1
-kind: Namespace
-metadata:
-  name: projectsveltos
----
-apiVersion: v1
-kind: ServiceAccount
-metadata:
-  name: drift-detection-manager
-  namespace: projectsveltos
----
-apiVersion: rbac.authorization.k8s.io/v1
-kind: ClusterRole
-metadata:
-  name: drift-detection-manager-role
-rules:
-- apiGroups:
-  - '*'
-  resources:
-  - '*'
-  verbs:
-  - get
-  - list
-  - watch
-- apiGroups:
-  - authentication.k8s.io
-  resources:
-  - tokenreviews
-  verbs:
-  - create
-- apiGroups:
-  - authorization.k8s.io
-  resources:
-  - subjectaccessreviews
-  verbs:
-  - create
-- apiGroups:
-  - lib.projectsveltos.io
-  resources:
-  - debuggingconfigurations
-  verbs:
-  - get
-  - list
-  - watch
-- apiGroups:
-  - lib.projectsveltos.io
-  resources:
-  - resourcesummaries
-  verbs:
-  - create
-  - delete
-  - get
-  - list
-  - patch
-  - update
-  - watch
-- apiGroups:
-  - lib.projectsveltos.io
-  resources:
-  - resourcesummaries/finalizers
-  verbs:
-  - update
-- apiGroups:
-  - lib.projectsveltos.io
-  resources:
-  - resourcesummaries/status
-  verbs:
-  - get
-  - patch
-  - update
----
-apiVersion: rbac.authorization.k8s.io/v1
-kind: ClusterRoleBinding
-metadata:
-  name: drift-detection-manager-rolebinding
-roleRef:
-  apiGroup: rbac.authorization.k8s.io
-  kind: ClusterRole
-  name: drift-detection-manager-role
-subjects:
-- kind: ServiceAccount
-  name: drift-detection-manager
-  namespace: projectsveltos
----
-apiVersion: apps/v1
-kind: Deployment
-metadata:
-  labels:
-    control-plane: drift-detection-manager
-  name: drift-detection-manager
-  namespace: projectsveltos
-spec:
-  replicas: 1
-  selector:
-    matchLabels:
-      control-plane: drift-detection-manager
-  template:
-    metadata:
-      annotations:
-        kubectl.kubernetes.io/default-container: manager
-      labels:
-        control-plane: drift-detection-manager
-    spec:
-      containers:
-      - args:
-        - --diagnostics-address=:8443
-        - --v=5
-        - --cluster-namespace=
-        - --cluster-name=
-        - --cluster-type=
-        - --current-cluster=managed-cluster
-        - --run-mode=do-not-send-updates
-        command:
-        - /manager
-        image: projectsveltos/drift-detection-manager:main
-        livenessProbe:
-          failureThreshold: 3
-          httpGet:
-            path: /healthz
-            port: healthz
-            scheme: HTTP
-          initialDelaySeconds: 15
-          periodSeconds: 20
-        name: manager
-        ports:
-        - containerPort: 8443
-          name: metrics
-          protocol: TCP
-        - containerPort: 9440
-          name: healthz
-          protocol: TCP
-        readinessProbe:
-          failureThreshold: 3
-          httpGet:
-            path: /readyz
-            port: healthz
-            scheme: HTTP
-          initialDelaySeconds: 5
-          periodSeconds: 10
-        resources:
-          limits:
-            cpu: 500m
-            memory: 512Mi
-          requests:
-            cpu: 10m
-            memory: 128Mi
-        securityContext:
-          allowPrivilegeEscalation: false
-          capabilities:
-            drop:
-            - ALL
-      securityContext:
-        runAsNonRoot: true
-      serviceAccountName: drift-detection-manager
-      terminationGracePeriodSeconds: 10
-`)
+// driftDetectionYAML is the gzip compressed content of drift-detection-manager.yaml
+var driftDetectionYAML = []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x02\xff\xb4UQO\xec8\x0f}\xef\xaf\xc8\u06d5>)\xc0pA\xe2\xab\xc4\x03\xcb]\xb1\x0f,\x1aq\u047e\xbb\x89\xa7\xe3%M\xb2\x8e3\xec\xf0\xebW\xed\u0314v\xa6\\\xe0.\u06d7\xb6q|||l'\x10\xe9\x0f\xe4D\xc1\x97j5+\x1e\xc9\xdbR\xddA\x83)\x82\xc1\xa2A\x01\v\x02e\xa1\x94\x87\x06K\x159\xfc\x89F\xd2\n\x9d\x84Th\xad\x8bI\x8c\xef\xc8+2xeL\xc8^&\x80,\xd3B\xb4EA#\x14\xbcn\xc0C\x8d\xbc\xb5w\xe1\u07cc\xc6\x15\x98#\u0232\fL\xcf\xd0\xc2\x1c=^\xa4#\n\xc7=\x8fk\x97\x93 \xdf\a\x87\xef'\xa1\xb9\xdd\xce\xd9a*\v\xad \xd2\r\x87\x1cS\xeb\xa8\u0557\xff})\x94bL!\xb3\xc1\xd1\xe2\n\xb9\xda.\xd4(\xdd\xdbQ\xda|<\x81\x98\xe5!ZK\x1f\xbd\x90\x19\xf2?\u0117\xf0\x88\x9eqE\xf8\x94\u0181\f#\bN\x03\xef\xebr\x88\x9br\xd5*\f\xc6`J\x1f\xc2wT\x1d\x8d\xeb3\x19\xc0b\x95\xeb\x9a|m\x82_P\x9d\xb9\xe3\x93~N\xacw\xc6\xec\x7fs\xd3\x00\x13Ng\xb4a\xe7Pp\x9aC\xec8\xb4_9\u069d\xc3'\x13;^\x90\aG\xcf\xc8{\x1c\xb7!?/P\x12\x90\xfc\x8a\xec\a\x99\xfe\xbbA\xfb\x85\xbc%_\x7fp\u07aa\xadW\xfb}\x8f\x8b\xd6i\x97\xfb\x0f\b\x14J\x1d\x0e\xfa\xbb\xc6{\xdb\xf9\u0744O\x9eY\x9fyRA\x8c\xe9E\xabo\x18]X7\xb8w0:\xa8\xd0u\xa5Q\xca\x04/\x1c\x9c\x8e\x0e\xfc\x9b\x1c~\x8ec\x8ah\u02aeu\xa2#\x03\xa9T\xb3B\xa9\x84\x0e\x8d\x04\xde\xd0h\xda\u03b8\x1d\xf0\xfa\x003\xc1&:\x10\xdc\"\r\x12m\x1f\xf0>\xc8\xe6,\xd8-)\xf5\x98+4\xe2\x8e\xda7{\x14\xecz\xcc\xe2\x02\xb2\x13\xddF\x06\xf2\u0225z\x89\xa2\xd4X\xb8\x0fQTj'\xc2\u03ad\xc3\uf874\x02\xae\xd3\v\xb0VZ[\x82\u0687$d\x92\x06k\x19S\xba,/\xce\u03be\x8ev\xad.\xcfG\xfff\u04de\xba/\xc7\xe5\xab\xe6i\x8b\xac\xe3\xbe%3\xa3\x97\u074e\xcbMZv\xf7?\xda\xcb\xd9\xeb&X\xbc\xb4A\xfb :\xa1\xb7z3\xeci [\u04c0\xb7\xc3t\x8f\xc7B+E\r\xd4\a\xadt\xfc\x8a\xc2e\x03\xe4{WG+\xf4\x98\u049cC\x85/A\x94Z\x00\xb9\xcc\xf8\xb0dL\xcb\xe0l\xa9\xbe\x0e\xacK\x91x\x832tP*\x82,Ku\xbcDp\xb2|\x1e\x9b\x02K\xa9\xa6,\xc9,\xb1\x1d\x96\xdf\x1e\x1e\xe6\x03\x03y\x12\x02\xf7\r\x1d\xac\xbf\xa3\t\u07b6\xa3p>\xd8\x11\x91)\xd8\xdevz\xd2\xdb6\u04f7/RKa\xd45}g\xcd;r\xa3n\xe9AP\x98L\x1a\x86\xe5 \xc1\x04W\xaa\x87\xeb\xf9\xebh\xff?;;9@;\xcc\x7f\x1a\x8d\x11,}rUZ\xcc\xf5\x7fQ\x94\x1f\xd4dv2Hip\x15\xbe4_C\x92\xc6tM\u0325:?9iF\xab\r6\x81\u05e5:\x9f\x9d\xfeN\x03\v\xe3_\x19\xd3$\xc6\xec\x15\x88\xd9\xe9\xc5\x00\"\xa1\xc9L\xb2\xbe\x0e^\xf0\xef\x91t\xe0\\x\x9a3\xad\xc8a\x8d\xbf&\x03\xae;\x1aK\xb5\x00\x97p\xb0\xd3@\x84\x8a\x1c\t\xe1\x1e\x13\xcb!\x8eW\xb4\xba\xba\xbd-\xde\b\xce\xd9_\xa5\xbb\xe0\xefC\x90R\tg\xec=\x86\xb7\xe1\xdd\x1b\xd7L\xfb\brC\xbe#~\xc3`p~P\xa2\x7f\x06\x00F\xa3}}o\f\x00\x00")
@@ -16,16 +16,34 @@ limitations under the License.
 
 package driftdetection
 
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
 //go:generate go run ../../generator.go
 
 // Get the YAML to deploy drift-detection-manager in the
 // managed cluster
-func GetDriftDetectionManagerYAML() []byte {
-	return driftDetectionYAML
+func GetDriftDetectionManagerYAML() ([]byte, error) {
+	return decompress(driftDetectionYAML)
 }
 
 // Get the YAML to deploy drift-detection-manager in the
 // management cluster
-func GetDriftDetectionManagerInMgmtClusterYAML() []byte {
-	return driftDetectionInMgmtClusterYAML
+func GetDriftDetectionManagerInMgmtClusterYAML() ([]byte, error) {
+	return decompress(driftDetectionInMgmtClusterYAML)
+}
+
+// decompress returns the content of a gzip compressed manifest.
+// Manifests are kept compressed in memory and decompressed only when needed.
+func decompress(compressed []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
 }
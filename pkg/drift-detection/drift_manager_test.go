@@ -0,0 +1,46 @@
+/*
+Copyright 2024. projectsveltos.io. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftdetection_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	driftdetection "github.com/projectsveltos/addon-controller/pkg/drift-detection"
+)
+
+var _ = Describe("DriftDetection manifests", func() {
+	It("GetDriftDetectionManagerYAML returns the uncompressed drift-detection-manager manifest", func() {
+		expected, err := os.ReadFile("drift-detection-manager.yaml")
+		Expect(err).To(BeNil())
+
+		manifest, err := driftdetection.GetDriftDetectionManagerYAML()
+		Expect(err).To(BeNil())
+		Expect(manifest).To(Equal(expected))
+	})
+
+	It("GetDriftDetectionManagerInMgmtClusterYAML returns the uncompressed drift-detection-manager manifest", func() {
+		expected, err := os.ReadFile("drift-detection-manager-in-mgmt-cluster.yaml")
+		Expect(err).To(BeNil())
+
+		manifest, err := driftdetection.GetDriftDetectionManagerInMgmtClusterYAML()
+		Expect(err).To(BeNil())
+		Expect(manifest).To(Equal(expected))
+	})
+})
@@ -22,6 +22,8 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -110,12 +112,53 @@ func (s *ProfileScope) GetSelector() string {
 	return string(spec.ClusterSelector)
 }
 
+// GetSelectors returns ClusterSelectors. A cluster matching any of those must be associated.
+func (s *ProfileScope) GetSelectors() []string {
+	spec := s.GetSpec()
+	selectors := make([]string, 0, len(spec.ClusterSelectors))
+	for i := range spec.ClusterSelectors {
+		if spec.ClusterSelectors[i] != "" {
+			selectors = append(selectors, string(spec.ClusterSelectors[i]))
+		}
+	}
+	return selectors
+}
+
+// SetInvalidSelector sets the InvalidSelector condition when err is not nil and
+// removes it otherwise.
+func (s *ProfileScope) SetInvalidSelector(err error) {
+	status := s.GetStatus()
+	if err == nil {
+		meta.RemoveStatusCondition(&status.Conditions, configv1alpha1.ProfileInvalidSelectorCondition)
+		return
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    configv1alpha1.ProfileInvalidSelectorCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  configv1alpha1.ProfileInvalidSelectorReason,
+		Message: err.Error(),
+	})
+}
+
 // SetMatchingClusterRefs sets the feature status.
 func (s *ProfileScope) SetMatchingClusterRefs(matchingClusters []corev1.ObjectReference) {
 	status := s.GetStatus()
 	status.MatchingClusterRefs = matchingClusters
 }
 
+// SetExcludedClusterRefs sets the excludedClusters field.
+func (s *ProfileScope) SetExcludedClusterRefs(excludedClusters []corev1.ObjectReference) {
+	status := s.GetStatus()
+	status.ExcludedClusterRefs = excludedClusters
+}
+
+// SetMatchExplanation sets the matchExplanation field.
+func (s *ProfileScope) SetMatchExplanation(explanation *configv1alpha1.MatchExplanation) {
+	status := s.GetStatus()
+	status.MatchExplanation = explanation
+}
+
 // IsContinuousSync returns true if Profile is set to keep updating workload cluster
 func (s *ProfileScope) IsContinuousSync() bool {
 	spec := s.GetSpec()
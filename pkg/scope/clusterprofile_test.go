@@ -18,6 +18,7 @@ package scope_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -363,4 +364,28 @@ var _ = Describe("ProfileScope/ClusterProfileScope", func() {
 			Expect(scope.IsDryRunSync()).To(BeFalse())
 		}
 	})
+
+	It("SetInvalidSelector sets and removes InvalidSelector condition", func() {
+		objects := []client.Object{clusterProfile, profile}
+		for i := range objects {
+			params := scope.ProfileScopeParams{
+				Client:  c,
+				Profile: objects[i],
+				Logger:  textlogger.NewLogger(textlogger.NewConfig()),
+			}
+
+			scope, err := scope.NewProfileScope(params)
+			Expect(err).ToNot(HaveOccurred())
+
+			message := randomString()
+			scope.SetInvalidSelector(errors.New(message))
+			conditions := scope.GetStatus().Conditions
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0].Type).To(Equal(configv1alpha1.ProfileInvalidSelectorCondition))
+			Expect(conditions[0].Message).To(Equal(message))
+
+			scope.SetInvalidSelector(nil)
+			Expect(scope.GetStatus().Conditions).To(BeEmpty())
+		}
+	})
 })
@@ -21,6 +21,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -171,6 +172,26 @@ func (s *ClusterSummaryScope) SetFailureReason(featureID configv1alpha1.FeatureI
 	)
 }
 
+// SetHashAlgorithm sets, for featureID, the algorithm the feature hash was computed with.
+func (s *ClusterSummaryScope) SetHashAlgorithm(featureID configv1alpha1.FeatureID, hashAlgorithm string) {
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].HashAlgorithm = hashAlgorithm
+			return
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1alpha1.FeatureSummary{
+			FeatureID:     featureID,
+			HashAlgorithm: hashAlgorithm,
+		},
+	)
+}
+
 func (s *ClusterSummaryScope) SetLastAppliedTime(featureID configv1alpha1.FeatureID,
 	lastAppliedTime *metav1.Time) {
 
@@ -192,6 +213,85 @@ func (s *ClusterSummaryScope) SetLastAppliedTime(featureID configv1alpha1.Featur
 	)
 }
 
+// IncrementConsecutiveFailures increments, for featureID, the number of consecutive deployments
+// which failed because of transient errors. Returns the new value.
+func (s *ClusterSummaryScope) IncrementConsecutiveFailures(featureID configv1alpha1.FeatureID) int32 {
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].ConsecutiveFailures++
+			return s.ClusterSummary.Status.FeatureSummaries[i].ConsecutiveFailures
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1alpha1.FeatureSummary{
+			FeatureID:           featureID,
+			ConsecutiveFailures: 1,
+		},
+	)
+	return 1
+}
+
+// ResetConsecutiveFailures resets, for featureID, the number of consecutive failed deployments.
+func (s *ClusterSummaryScope) ResetConsecutiveFailures(featureID configv1alpha1.FeatureID) {
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].ConsecutiveFailures = 0
+			return
+		}
+	}
+}
+
+// SetReferencesStatus sets, for featureID, the referenced resources which were found and the ones
+// which were missing.
+func (s *ClusterSummaryScope) SetReferencesStatus(featureID configv1alpha1.FeatureID,
+	resolved, missing []corev1.ObjectReference) {
+
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].ResolvedReferences = resolved
+			s.ClusterSummary.Status.FeatureSummaries[i].MissingReferences = missing
+			return
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1alpha1.FeatureSummary{
+			FeatureID:          featureID,
+			ResolvedReferences: resolved,
+			MissingReferences:  missing,
+		},
+	)
+}
+
+// SetDryRunDiff sets, for featureID, the resources which would change if feature was deployed.
+func (s *ClusterSummaryScope) SetDryRunDiff(featureID configv1alpha1.FeatureID,
+	diff []configv1alpha1.DryRunDiffEntry) {
+
+	for i := range s.ClusterSummary.Status.FeatureSummaries {
+		if s.ClusterSummary.Status.FeatureSummaries[i].FeatureID == featureID {
+			s.ClusterSummary.Status.FeatureSummaries[i].DryRunDiff = diff
+			return
+		}
+	}
+
+	s.initializeFeatureStatusSummary()
+
+	s.ClusterSummary.Status.FeatureSummaries = append(
+		s.ClusterSummary.Status.FeatureSummaries,
+		configv1alpha1.FeatureSummary{
+			FeatureID:  featureID,
+			DryRunDiff: diff,
+		},
+	)
+}
+
 // IsContinuousWithDriftDetection returns true if ClusterProfile is set to SyncModeContinuousWithDriftDetection
 func (s *ClusterSummaryScope) IsContinuousWithDriftDetection() bool {
 	return s.ClusterSummary.Spec.ClusterProfileSpec.SyncMode == configv1alpha1.SyncModeContinuousWithDriftDetection
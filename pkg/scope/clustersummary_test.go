@@ -22,6 +22,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2/textlogger"
@@ -333,6 +334,32 @@ var _ = Describe("ClusterSummaryScope", func() {
 		Expect(len(currentClusterSummary.Status.FeatureSummaries)).To(Equal(1))
 	})
 
+	It("SetHashAlgorithm updates featureSummary with hash algorithm", func() {
+		clusterSummary.Status.FeatureSummaries = []configv1alpha1.FeatureSummary{
+			{FeatureID: configv1alpha1.FeatureResources, Status: configv1alpha1.FeatureStatusProvisioned},
+		}
+
+		params := &scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Profile:        clusterProfile,
+			ClusterSummary: clusterSummary,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		scope, err := scope.NewClusterSummaryScope(params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).ToNot(BeNil())
+
+		scope.SetHashAlgorithm(configv1alpha1.FeatureResources, "sha512")
+		scope.SetHashAlgorithm(configv1alpha1.FeatureHelm, "fnv128a")
+
+		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(2))
+		Expect(clusterSummary.Status.FeatureSummaries[0].HashAlgorithm).To(Equal("sha512"))
+		Expect(clusterSummary.Status.FeatureSummaries[0].Status).To(Equal(configv1alpha1.FeatureStatusProvisioned))
+		Expect(clusterSummary.Status.FeatureSummaries[1].FeatureID).To(Equal(configv1alpha1.FeatureHelm))
+		Expect(clusterSummary.Status.FeatureSummaries[1].HashAlgorithm).To(Equal("fnv128a"))
+	})
+
 	It("SetLastAppliedTime updates featureSummary with time (entry not existing yet)", func() {
 		params := &scope.ClusterSummaryScopeParams{
 			Client:         c,
@@ -381,6 +408,75 @@ var _ = Describe("ClusterSummaryScope", func() {
 		Expect(*clusterSummary.Status.FeatureSummaries[0].LastAppliedTime).To(Equal(now))
 	})
 
+	It("IncrementConsecutiveFailures and ResetConsecutiveFailures update featureSummary", func() {
+		params := &scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Profile:        clusterProfile,
+			ClusterSummary: clusterSummary,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		scope, err := scope.NewClusterSummaryScope(params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).ToNot(BeNil())
+
+		Expect(scope.IncrementConsecutiveFailures(configv1alpha1.FeatureHelm)).To(Equal(int32(1)))
+		Expect(scope.IncrementConsecutiveFailures(configv1alpha1.FeatureHelm)).To(Equal(int32(2)))
+
+		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(1))
+		Expect(clusterSummary.Status.FeatureSummaries[0].ConsecutiveFailures).To(Equal(int32(2)))
+
+		scope.ResetConsecutiveFailures(configv1alpha1.FeatureHelm)
+		Expect(clusterSummary.Status.FeatureSummaries[0].ConsecutiveFailures).To(BeZero())
+	})
+
+	It("SetReferencesStatus updates featureSummary with resolved and missing references", func() {
+		params := &scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Profile:        clusterProfile,
+			ClusterSummary: clusterSummary,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		scope, err := scope.NewClusterSummaryScope(params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).ToNot(BeNil())
+
+		resolved := []corev1.ObjectReference{{Kind: "ConfigMap", Namespace: randomString(), Name: randomString()}}
+		missing := []corev1.ObjectReference{{Kind: "Secret", Namespace: randomString(), Name: randomString()}}
+		scope.SetReferencesStatus(configv1alpha1.FeatureResources, resolved, missing)
+
+		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(1))
+		Expect(clusterSummary.Status.FeatureSummaries[0].ResolvedReferences).To(Equal(resolved))
+		Expect(clusterSummary.Status.FeatureSummaries[0].MissingReferences).To(Equal(missing))
+	})
+
+	It("SetDryRunDiff updates featureSummary with resources which would change", func() {
+		params := &scope.ClusterSummaryScopeParams{
+			Client:         c,
+			Profile:        clusterProfile,
+			ClusterSummary: clusterSummary,
+			Logger:         textlogger.NewLogger(textlogger.NewConfig()),
+		}
+
+		scope, err := scope.NewClusterSummaryScope(params)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scope).ToNot(BeNil())
+
+		diff := []configv1alpha1.DryRunDiffEntry{
+			{Kind: "ConfigMap", Version: "v1", Namespace: randomString(), Name: randomString(),
+				Change: configv1alpha1.DryRunChangeAdded},
+		}
+		scope.SetDryRunDiff(configv1alpha1.FeatureKustomize, diff)
+
+		Expect(len(clusterSummary.Status.FeatureSummaries)).To(Equal(1))
+		Expect(clusterSummary.Status.FeatureSummaries[0].FeatureID).To(Equal(configv1alpha1.FeatureKustomize))
+		Expect(clusterSummary.Status.FeatureSummaries[0].DryRunDiff).To(Equal(diff))
+
+		scope.SetDryRunDiff(configv1alpha1.FeatureKustomize, nil)
+		Expect(clusterSummary.Status.FeatureSummaries[0].DryRunDiff).To(BeNil())
+	})
+
 	It("IsContinuousSync returns true when mode is Continuous", func() {
 		clusterSummary.Spec.ClusterProfileSpec.SyncMode = configv1alpha1.SyncModeContinuous
 